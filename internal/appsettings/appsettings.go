@@ -0,0 +1,175 @@
+// Package appsettings restores the app_settings section of a config: it
+// writes each app's settings files and imports its defaults domains once
+// the app's cask is installed. The registry and the opt-in list live in
+// internal/config; capture lives in internal/snapshot.
+package appsettings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// BackupSuffix is appended to a settings file openboot replaces, the first
+// time it replaces it.
+const BackupSuffix = ".openboot.bak"
+
+// exportDomain and importDomain wrap `defaults` so tests can run without
+// touching real preferences.
+var (
+	exportDomain = func(domain string) (string, error) {
+		return system.RunCommandOutput("defaults", "export", domain, "-")
+	}
+	importDomain = func(domain, path string) error {
+		out, err := system.RunCommandSilent("defaults", "import", domain, path)
+		if err != nil {
+			return fmt.Errorf("defaults import %s: %s: %w", domain, out, err)
+		}
+		return nil
+	}
+)
+
+// Result reports what Apply did.
+type Result struct {
+	Changed    int      // files written and domains imported
+	Applied    []string // apps whose settings were restored or already current
+	Skipped    []string // apps left alone because their cask is not installed
+	NotOptedIn []string // apps left alone because they are not opted in here
+	Symlinked  []string // settings files left alone because they are symlinks
+}
+
+// Apply restores the settings of each app that is opted in on this Mac
+// (config.EnabledAppSettings) once its cask is in installed: a config
+// carrying an app's settings is not enough on its own. Files are only
+// replaced when their content differs, and the first replacement keeps the
+// original next to it with BackupSuffix. A file that is a symlink —
+// typically a dotfiles link — is left alone and listed in Symlinked.
+func Apply(s *config.AppSettings, installed map[string]bool, dryRun bool) (Result, error) {
+	var res Result
+	if s.Empty() {
+		return res, nil
+	}
+	if err := s.Validate(); err != nil {
+		return res, fmt.Errorf("apply app settings: %w", err)
+	}
+	enabled, err := config.EnabledAppSettings()
+	if err != nil {
+		return res, err
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return res, err
+	}
+
+	var errs []error
+	for _, a := range s.Apps {
+		spec, _ := config.LookupAppSettings(a.App)
+		if !slices.Contains(enabled, a.App) {
+			res.NotOptedIn = append(res.NotOptedIn, a.App)
+			continue
+		}
+		if !installed[spec.Cask] {
+			res.Skipped = append(res.Skipped, a.App)
+			continue
+		}
+		res.Applied = append(res.Applied, a.App)
+		for _, f := range a.Files {
+			data, _ := s.Blob(f.Blob) // checked by Validate
+			changed, linked, err := applyFile(f.Target, data, home, dryRun)
+			if linked {
+				res.Symlinked = append(res.Symlinked, f.Target)
+			}
+			if changed {
+				res.Changed++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", spec.Name, err))
+			}
+		}
+		for _, d := range a.Domains {
+			data, _ := s.Blob(d.Blob)
+			changed, err := applyDomain(d.Target, data, dryRun)
+			if changed {
+				res.Changed++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", spec.Name, err))
+			}
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+// applyFile writes want to target unless it is already current. linked
+// reports a symlinked target, which is left to whatever manages it.
+func applyFile(target string, want []byte, home string, dryRun bool) (changed, linked bool, err error) {
+	path := config.AppSettingsPath(target, home)
+	mode := os.FileMode(0644)
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return false, true, nil
+		}
+		mode = fi.Mode().Perm()
+	}
+	cur, err := os.ReadFile(path) //nolint:gosec // path comes from the embedded registry
+	exists := err == nil
+	if exists && bytes.Equal(cur, want) {
+		return false, false, nil
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would write %s", path)
+		return true, false, nil
+	}
+
+	if exists {
+		backup := path + BackupSuffix
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			if err := os.WriteFile(backup, cur, mode); err != nil {
+				return false, false, fmt.Errorf("back up %s: %w", path, err)
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, false, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, want, mode); err != nil {
+		return false, false, fmt.Errorf("write %s: %w", path, err)
+	}
+	return true, false, nil
+}
+
+func applyDomain(domain string, want []byte, dryRun bool) (changed bool, err error) {
+	if cur, err := exportDomain(domain); err == nil && cur == string(want) {
+		return false, nil
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would run: defaults import %s <captured settings>", domain)
+		return true, nil
+	}
+
+	tmp, err := os.CreateTemp("", "openboot-defaults-*.plist")
+	if err != nil {
+		return false, fmt.Errorf("stage %s: %w", domain, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // best-effort temp cleanup
+	if _, err := tmp.Write(want); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // already failing
+		return false, fmt.Errorf("stage %s: %w", domain, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("stage %s: %w", domain, err)
+	}
+	if err := importDomain(domain, tmp.Name()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,130 @@
+package appsettings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+const vscodeSettings = "~/Library/Application Support/Code/User/settings.json"
+
+func stubDefaults(t *testing.T) (map[string]string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, config.EnableAppSettings("vscode", "rectangle"))
+	domains := map[string]string{}
+	origExport, origImport := exportDomain, importDomain
+	t.Cleanup(func() { exportDomain, importDomain = origExport, origImport })
+	exportDomain = func(domain string) (string, error) { return domains[domain], nil }
+	importDomain = func(domain, path string) error {
+		data, err := os.ReadFile(path)
+		domains[domain] = string(data)
+		return err
+	}
+	return domains, home
+}
+
+func settingsFor(t *testing.T) *config.AppSettings {
+	t.Helper()
+	s := &config.AppSettings{}
+	s.Apps = []config.AppSettingsApp{
+		{App: "vscode", Files: []config.AppSettingsRef{{Target: vscodeSettings, Blob: s.Put([]byte(`{"editor.fontSize": 14}`))}}},
+		{App: "rectangle", Domains: []config.AppSettingsRef{{Target: "com.knollsoft.Rectangle", Blob: s.Put([]byte("<plist>gaps</plist>"))}}},
+	}
+	return s
+}
+
+func TestApply_RestoresInstalledApps(t *testing.T) {
+	domains, home := stubDefaults(t)
+	path := filepath.Join(home, "Library/Application Support/Code/User/settings.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(`{"editor.fontSize": 12}`), 0600))
+
+	res, err := Apply(settingsFor(t), map[string]bool{"visual-studio-code": true, "rectangle": true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Changed)
+	assert.Equal(t, []string{"vscode", "rectangle"}, res.Applied)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"editor.fontSize": 14}`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "existing mode is kept")
+	backup, err := os.ReadFile(path + BackupSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, `{"editor.fontSize": 12}`, string(backup))
+	assert.Equal(t, "<plist>gaps</plist>", domains["com.knollsoft.Rectangle"])
+
+	// Already current: nothing written, and the first backup is kept.
+	res, err = Apply(settingsFor(t), map[string]bool{"visual-studio-code": true, "rectangle": true}, false)
+	require.NoError(t, err)
+	assert.Zero(t, res.Changed)
+	backup, _ = os.ReadFile(path + BackupSuffix)
+	assert.Equal(t, `{"editor.fontSize": 12}`, string(backup))
+}
+
+func TestApply_SkipsAppsNotInstalled(t *testing.T) {
+	domains, home := stubDefaults(t)
+
+	res, err := Apply(settingsFor(t), map[string]bool{"rectangle": true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vscode"}, res.Skipped)
+	assert.Equal(t, 1, res.Changed)
+	assert.NoFileExists(t, filepath.Join(home, "Library/Application Support/Code/User/settings.json"))
+	assert.Contains(t, domains, "com.knollsoft.Rectangle")
+}
+
+func TestApply_LeavesSymlinksAlone(t *testing.T) {
+	_, home := stubDefaults(t)
+	path := filepath.Join(home, "Library/Application Support/Code/User/settings.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	target := filepath.Join(home, "dotfiles-settings.json")
+	require.NoError(t, os.WriteFile(target, []byte("{}"), 0644))
+	require.NoError(t, os.Symlink(target, path))
+
+	res, err := Apply(settingsFor(t), map[string]bool{"visual-studio-code": true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{vscodeSettings}, res.Symlinked)
+	assert.Zero(t, res.Changed)
+	data, _ := os.ReadFile(target)
+	assert.Equal(t, "{}", string(data))
+}
+
+// A config carrying an app's settings is not enough: the app must be opted
+// in on this Mac too.
+func TestApply_OnlyOptedInApps(t *testing.T) {
+	domains, home := stubDefaults(t)
+	require.NoError(t, config.DisableAppSettings("vscode"))
+
+	res, err := Apply(settingsFor(t), map[string]bool{"visual-studio-code": true, "rectangle": true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vscode"}, res.NotOptedIn)
+	assert.Equal(t, []string{"rectangle"}, res.Applied)
+	assert.NoFileExists(t, filepath.Join(home, "Library/Application Support/Code/User/settings.json"))
+	assert.Contains(t, domains, "com.knollsoft.Rectangle")
+}
+
+func TestApply_DryRun(t *testing.T) {
+	domains, home := stubDefaults(t)
+
+	res, err := Apply(settingsFor(t), map[string]bool{"visual-studio-code": true, "rectangle": true}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Changed)
+	assert.NoDirExists(t, filepath.Join(home, "Library"))
+	assert.Empty(t, domains)
+}
+
+func TestApply_RejectsInvalidSettings(t *testing.T) {
+	stubDefaults(t)
+	s := settingsFor(t)
+	s.Apps[0].Files[0].Target = "~/.zshrc"
+	_, err := Apply(s, map[string]bool{"visual-studio-code": true}, false)
+	assert.ErrorContains(t, err, "not one of its settings files")
+}
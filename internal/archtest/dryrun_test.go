@@ -2,6 +2,7 @@ package archtest
 
 import (
 	"go/ast"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -31,6 +32,14 @@ var dryRunExemptFiles = []string{
 	"internal/sync/diff.go",           // read-only dotfiles remote probe for diff computation
 }
 
+// dryRunExemptFuncs lists individual functions exempt from the rule, as
+// "<file>:<func>". The rest of their file is still checked.
+var dryRunExemptFuncs = []string{
+	"internal/signing/signing.go:GenerateKey", // `openboot keys generate`; writing the key is the command
+	"internal/signing/signing.go:SignFile",    // `openboot keys sign`; writes the signed copy it was asked for
+	"internal/signing/policy.go:SavePolicy",   // `openboot keys trust/untrust`; saves the trusted keys
+}
+
 // destructiveOsCalls lists os package functions that modify the filesystem.
 var destructiveOsCalls = []string{
 	"WriteFile",
@@ -108,7 +117,7 @@ func findDestructiveWithoutDryRun(gf goFile) []callSite {
 		if !ok || fn.Body == nil {
 			continue
 		}
-		if hasDryRunReference(fn) {
+		if hasDryRunReference(fn) || slices.Contains(dryRunExemptFuncs, gf.path+":"+fn.Name.Name) {
 			continue
 		}
 
@@ -0,0 +1,105 @@
+package brew
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// Service is one entry of `brew services list --json`.
+type Service struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // started, scheduled, stopped, none, error, ...
+}
+
+// Running reports whether launchd has the service started, or scheduled to
+// start (scheduled services are registered to run on an interval).
+func (s Service) Running() bool {
+	return s.Status == "started" || s.Status == "scheduled"
+}
+
+// parseServices parses the output of `brew services list --json`.
+func parseServices(data []byte) ([]Service, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+	var services []Service
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, fmt.Errorf("parse brew services: %w", err)
+	}
+	return services, nil
+}
+
+// ListServices returns every service Homebrew manages, running or not.
+func ListServices() ([]Service, error) {
+	output, err := currentRunner().Output("services", "list", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("brew services list: %w", err)
+	}
+	return parseServices(output)
+}
+
+// StartedServices returns the names of the services that are running,
+// sorted.
+func StartedServices() ([]string, error) {
+	services, err := ListServices()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range services {
+		if s.Running() {
+			names = append(names, s.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// StartServices starts each named service that is not already running and
+// returns how many it started. The formula must already be installed;
+// services that fail to start are reported and counted in the error.
+func StartServices(names []string, dryRun bool) (int, error) {
+	if len(names) == 0 {
+		return 0, nil
+	}
+
+	// Not being able to list is not fatal: `brew services start` on a
+	// running service is a no-op, so an empty list just tries them all.
+	running, _ := StartedServices()
+	var toStart []string
+	for _, name := range names {
+		if !slices.Contains(running, name) {
+			toStart = append(toStart, name)
+		}
+	}
+	if len(toStart) == 0 {
+		return 0, nil
+	}
+
+	if dryRun {
+		ui.DryRunList("start services", "brew services start %s", toStart)
+		return len(toStart), nil
+	}
+
+	var failed []string
+	started := 0
+	for _, name := range toStart {
+		if output, err := currentRunner().CombinedOutput("services", "start", name); err != nil {
+			ui.Warn(fmt.Sprintf("Failed to start %s: %s", name, strings.TrimSpace(string(output))))
+			failed = append(failed, name)
+		} else {
+			ui.Success(fmt.Sprintf("  ✔ Started %s", name))
+			started++
+		}
+	}
+
+	if len(failed) > 0 {
+		return started, fmt.Errorf("%d service(s) failed to start", len(failed))
+	}
+	return started, nil
+}
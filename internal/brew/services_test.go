@@ -0,0 +1,114 @@
+package brew
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const servicesJSON = `[
+  {"name":"colima","service_name":"homebrew.mxcl.colima","running":true,"loaded":true,"status":"started"},
+  {"name":"postgresql@16","service_name":"homebrew.mxcl.postgresql@16","running":false,"loaded":false,"status":"none"},
+  {"name":"redis","service_name":"homebrew.mxcl.redis","running":false,"loaded":true,"status":"error"},
+  {"name":"unbound","service_name":"homebrew.mxcl.unbound","running":false,"loaded":true,"status":"scheduled"}
+]`
+
+func TestStartedServices_FiltersRunning(t *testing.T) {
+	withFakeBrew(t, func(args []string) ([]byte, error) {
+		assert.Equal(t, []string{"services", "list", "--json"}, args)
+		return []byte(servicesJSON), nil
+	})
+
+	names, err := StartedServices()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"colima", "unbound"}, names)
+}
+
+func TestListServices_EmptyOutput(t *testing.T) {
+	withFakeBrew(t, func(args []string) ([]byte, error) {
+		return []byte("\n"), nil
+	})
+
+	services, err := ListServices()
+	require.NoError(t, err)
+	assert.Empty(t, services)
+}
+
+func TestListServices_BadJSON(t *testing.T) {
+	withFakeBrew(t, func(args []string) ([]byte, error) {
+		return []byte("not json"), nil
+	})
+
+	_, err := ListServices()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parse brew services")
+}
+
+func TestStartServices_SkipsRunningAndStartsTheRest(t *testing.T) {
+	var started []string
+	withFakeBrew(t, func(args []string) ([]byte, error) {
+		if args[0] == "services" && args[1] == "list" {
+			return []byte(servicesJSON), nil
+		}
+		if args[0] == "services" && args[1] == "start" {
+			started = append(started, args[2])
+			return nil, nil
+		}
+		return nil, errors.New("unexpected: " + strings.Join(args, " "))
+	})
+
+	n, err := StartServices([]string{"colima", "postgresql@16", "redis"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"postgresql@16", "redis"}, started)
+}
+
+func TestStartServices_ReportsFailures(t *testing.T) {
+	withFakeBrew(t, func(args []string) ([]byte, error) {
+		if args[1] == "list" {
+			return []byte("[]"), nil
+		}
+		if args[2] == "redis" {
+			return []byte("Error: Formula `redis` is not installed."), errors.New("exit status 1")
+		}
+		return nil, nil
+	})
+
+	n, err := StartServices([]string{"postgresql@16", "redis"}, false)
+	require.Error(t, err)
+	assert.Equal(t, 1, n)
+	assert.Contains(t, err.Error(), "1 service(s) failed to start")
+}
+
+func TestStartServices_DryRunStartsNothing(t *testing.T) {
+	withFakeBrew(t, func(args []string) ([]byte, error) {
+		if args[1] == "list" {
+			return []byte(servicesJSON), nil
+		}
+		t.Fatalf("dry run ran brew %v", args)
+		return nil, nil
+	})
+
+	n, err := StartServices([]string{"colima", "redis"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestStartServices_ListFailureStillStarts(t *testing.T) {
+	var started []string
+	withFakeBrew(t, func(args []string) ([]byte, error) {
+		if args[1] == "list" {
+			return nil, errors.New("brew services unavailable")
+		}
+		started = append(started, args[2])
+		return nil, nil
+	})
+
+	n, err := StartServices([]string{"redis"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"redis"}, started)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+var appSettingsCmd = &cobra.Command{
+	Use:   "app-settings",
+	Short: "Choose which apps' settings snapshots capture",
+	Long: `Snapshots can carry the settings of a few well-known apps — VS Code
+settings and keybindings, iTerm2 and Warp profiles, Raycast and Rectangle
+preferences. Nothing is captured until you opt an app in; the list lives
+in ~/.openboot/app_settings.json.
+
+On install, an app's settings are restored once its cask is installed.
+A settings file that is replaced is kept next to it as <file>.openboot.bak.`,
+	Example: `  openboot app-settings list
+  openboot app-settings enable vscode iterm2
+  openboot app-settings disable iterm2`,
+	SilenceUsage: true,
+}
+
+var appSettingsListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the apps whose settings can be captured",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAppSettingsList()
+	},
+}
+
+var appSettingsEnableCmd = &cobra.Command{
+	Use:          "enable <app>...",
+	Short:        "Capture these apps' settings in snapshots",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.EnableAppSettings(args...); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, snapSuccessStyle.Render("✓ Capturing settings for "+strings.Join(args, ", ")))
+		return nil
+	},
+}
+
+var appSettingsDisableCmd = &cobra.Command{
+	Use:          "disable <app>...",
+	Short:        "Stop capturing these apps' settings",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.DisableAppSettings(args...); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, snapSuccessStyle.Render("✓ No longer capturing settings for "+strings.Join(args, ", ")))
+		return nil
+	},
+}
+
+func init() {
+	appSettingsCmd.AddCommand(appSettingsListCmd, appSettingsEnableCmd, appSettingsDisableCmd)
+}
+
+func runAppSettingsList() error {
+	enabled, err := config.EnabledAppSettings()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr)
+	for _, spec := range config.AppSettingsRegistry() {
+		mark := snapMutedStyle.Render("  ")
+		if slices.Contains(enabled, spec.ID) {
+			mark = snapSuccessStyle.Render("✓ ")
+		}
+		targets := append(append([]string{}, spec.Files...), spec.Domains...)
+		fmt.Fprintf(os.Stderr, "  %s%s %s\n", mark,
+			snapBoldStyle.Render(fmt.Sprintf("%-12s", spec.ID)),
+			snapMutedStyle.Render(spec.Name+" — "+strings.Join(targets, ", ")))
+	}
+	fmt.Fprintln(os.Stderr)
+	if len(enabled) == 0 {
+		fmt.Fprintln(os.Stderr, snapMutedStyle.Render("  No apps opted in; add one with 'openboot app-settings enable <app>'"))
+		fmt.Fprintln(os.Stderr)
+	}
+	return nil
+}
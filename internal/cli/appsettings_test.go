@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func TestAppSettingsCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	out := captureStderr(t, func() { require.NoError(t, runAppSettingsList()) })
+	assert.Contains(t, out, "vscode")
+	assert.Contains(t, out, "No apps opted in")
+
+	require.NoError(t, appSettingsEnableCmd.RunE(appSettingsEnableCmd, []string{"vscode", "rectangle"}))
+	assert.Error(t, appSettingsEnableCmd.RunE(appSettingsEnableCmd, []string{"notepad"}))
+	require.NoError(t, appSettingsDisableCmd.RunE(appSettingsDisableCmd, []string{"rectangle"}))
+
+	ids, err := config.EnabledAppSettings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vscode"}, ids)
+
+	out = captureStderr(t, func() { require.NoError(t, runAppSettingsList()) })
+	assert.NotContains(t, out, "No apps opted in")
+}
@@ -12,7 +12,8 @@ var doctorCmd = &cobra.Command{
 	Long: `Run diagnostic checks on your development environment.
 
 Checks Homebrew, Git, Node.js, npm, shell configuration, Oh-My-Zsh,
-PATH settings, and OpenBoot state. All checks are read-only.`,
+PATH settings, OpenBoot state, and the opt-in security controls (Touch ID
+for sudo, firewall, stealth mode). All checks are read-only.`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		d := doctor.New(version)
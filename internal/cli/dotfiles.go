@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openbootdotdev/openboot/internal/dotfiles"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// dotfiles status flags. Package-level so tests can reset them.
+var (
+	dotfilesStatusJSON  bool
+	dotfilesStatusFetch bool
+)
+
+// dotfiles unlink flags.
+var (
+	dotfilesUnlinkDryRun      bool
+	dotfilesUnlinkRemoveClone bool
+	dotfilesUnlinkJSON        bool
+)
+
+// Test seams — real implementations by default; tests replace via t.Cleanup.
+var (
+	dotfilesGetStatus = dotfiles.GetStatus
+	dotfilesUnlink    = dotfiles.Unlink
+)
+
+var dotfilesCmd = &cobra.Command{
+	Use:   "dotfiles",
+	Short: "Inspect and manage the linked dotfiles repository",
+	Long: `Inspect and manage the dotfiles repository cloned to ~/.dotfiles.
+
+openboot install clones the repo and links its files into your home
+directory (via make install, stow packages, or direct symlinks). These
+commands report on and undo that linking.`,
+	SilenceUsage: true,
+}
+
+var dotfilesStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show link health of each dotfile and the state of the clone",
+	Long: `Walk the stow packages (or direct-link set) in ~/.dotfiles and classify
+each file's target in your home directory:
+
+  linked       symlink resolves to the file in the repo
+  missing      nothing at the target path
+  conflicting  a regular file or directory is in the way
+  broken       symlink whose destination no longer exists
+  foreign      symlink pointing somewhere other than the repo
+
+Also reports uncommitted changes in the clone and how far it is ahead of or
+behind origin. Pass --fetch to refresh origin first; without it the check
+is read-only and compares against the last fetch.`,
+	Example: `  openboot dotfiles status
+  openboot dotfiles status --fetch
+  openboot dotfiles status --json | jq '.files[] | select(.state != "linked")'`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDotfilesStatus,
+}
+
+var dotfilesUnlinkCmd = &cobra.Command{
+	Use:   "unlink",
+	Short: "Remove dotfile symlinks and restore the files they replaced",
+	Long: `Remove every symlink in your home directory that points into ~/.dotfiles,
+including links left dangling by files since deleted from the repo, and move
+each .openboot.bak backup made while linking back to its original path.
+
+A backup is never restored over a file that isn't one of the removed links;
+those are reported as skipped. A manifest of everything removed and restored
+is written to ~/.openboot/dotfiles-unlink-<timestamp>.json.
+
+--remove-clone also deletes ~/.dotfiles, but only when the clone has no
+uncommitted changes or unpushed commits.`,
+	Example: `  openboot dotfiles unlink --dry-run
+  openboot dotfiles unlink
+  openboot dotfiles unlink --remove-clone`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDotfilesUnlink,
+}
+
+func init() {
+	dotfilesStatusCmd.Flags().BoolVar(&dotfilesStatusJSON, "json", false, "output JSON to stdout")
+	dotfilesStatusCmd.Flags().BoolVar(&dotfilesStatusFetch, "fetch", false, "run git fetch before comparing with origin")
+
+	dotfilesUnlinkCmd.Flags().BoolVar(&dotfilesUnlinkDryRun, "dry-run", false, "show what would be removed and restored without changing anything")
+	dotfilesUnlinkCmd.Flags().BoolVar(&dotfilesUnlinkRemoveClone, "remove-clone", false, "also delete ~/.dotfiles (refused if it has unpushed work)")
+	dotfilesUnlinkCmd.Flags().BoolVar(&dotfilesUnlinkJSON, "json", false, "output the manifest as JSON to stdout")
+
+	dotfilesCmd.AddCommand(dotfilesStatusCmd)
+	dotfilesCmd.AddCommand(dotfilesUnlinkCmd)
+}
+
+func runDotfilesStatus(_ *cobra.Command, _ []string) error {
+	st, err := dotfilesGetStatus(dotfilesStatusFetch)
+	if err != nil {
+		return fmt.Errorf("dotfiles status: %w", err)
+	}
+	if dotfilesStatusJSON {
+		data, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal dotfiles status: %w", err)
+		}
+		ui.Println(string(data))
+		return nil
+	}
+	printDotfilesStatus(st)
+	return nil
+}
+
+func printDotfilesStatus(st *dotfiles.Status) {
+	ui.Header("Dotfiles")
+	ui.Info(fmt.Sprintf("Path:   %s (%s layout)", tildePath(st.Path), st.Layout))
+	if r := st.Repo; r != nil {
+		if r.RemoteURL != "" {
+			ui.Info(fmt.Sprintf("Remote: %s", r.RemoteURL))
+		}
+		ui.Info(fmt.Sprintf("Branch: %s", r.Branch))
+		switch {
+		case r.CompareError != "":
+			ui.Warn(fmt.Sprintf("Could not compare with origin: %s", r.CompareError))
+		case r.Behind > 0 && r.Ahead > 0:
+			ui.Warn(fmt.Sprintf("Diverged from origin/%s (%d ahead, %d behind)", r.Branch, r.Ahead, r.Behind))
+		case r.Behind > 0:
+			ui.Warn(fmt.Sprintf("%d commit(s) behind origin/%s", r.Behind, r.Branch))
+		case r.Ahead > 0:
+			ui.Warn(fmt.Sprintf("%d commit(s) ahead of origin/%s (unpushed)", r.Ahead, r.Branch))
+		default:
+			ui.Success(fmt.Sprintf("Up to date with origin/%s", r.Branch))
+		}
+		if r.Dirty {
+			ui.Warn(fmt.Sprintf("%d uncommitted change(s) in the clone", r.DirtyFiles))
+		}
+	}
+	ui.Println()
+
+	if len(st.Files) == 0 {
+		ui.Muted("No files to link.")
+		return
+	}
+
+	for _, f := range st.Files {
+		line := tildePath(f.Target)
+		switch f.State {
+		case dotfiles.StateLinked:
+			ui.Success(line)
+			continue
+		case dotfiles.StateMissing:
+			line += " — missing"
+		case dotfiles.StateConflicting:
+			line += " — conflicting (not a symlink)"
+		case dotfiles.StateBroken:
+			line += fmt.Sprintf(" — broken link to %s", tildePath(f.LinkDest))
+		case dotfiles.StateForeign:
+			line += fmt.Sprintf(" — foreign link to %s", tildePath(f.LinkDest))
+		}
+		if f.State == dotfiles.StateBroken || f.State == dotfiles.StateConflicting {
+			ui.Error(line)
+		} else {
+			ui.Warn(line)
+		}
+		if f.Backup != "" {
+			ui.Muted(fmt.Sprintf("    backup: %s", tildePath(f.Backup)))
+		}
+	}
+
+	counts := st.Counts()
+	parts := []string{fmt.Sprintf("%d linked", counts[dotfiles.StateLinked])}
+	for _, s := range []dotfiles.LinkState{dotfiles.StateMissing, dotfiles.StateConflicting, dotfiles.StateBroken, dotfiles.StateForeign} {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+		}
+	}
+	ui.Println()
+	ui.Info(strings.Join(parts, ", "))
+}
+
+func runDotfilesUnlink(_ *cobra.Command, _ []string) error {
+	m, err := dotfilesUnlink(dotfiles.UnlinkOptions{
+		RemoveClone: dotfilesUnlinkRemoveClone,
+		DryRun:      dotfilesUnlinkDryRun,
+	})
+	if m == nil {
+		return fmt.Errorf("dotfiles unlink: %w", err)
+	}
+	if dotfilesUnlinkJSON {
+		data, mErr := json.MarshalIndent(m, "", "  ")
+		if mErr != nil {
+			return fmt.Errorf("marshal unlink manifest: %w", mErr)
+		}
+		ui.Println(string(data))
+	} else {
+		printUnlinkManifest(m)
+	}
+	if err != nil {
+		return fmt.Errorf("dotfiles unlink: %w", err)
+	}
+	return nil
+}
+
+func printUnlinkManifest(m *dotfiles.UnlinkManifest) {
+	report := func(done, would, detail string) {
+		if m.DryRun {
+			ui.DryRunMsg("%s %s", would, detail)
+			return
+		}
+		ui.Success(done + " " + detail)
+	}
+
+	ui.Header("Dotfiles unlink")
+	if len(m.Removed) == 0 && len(m.Restored) == 0 && len(m.Skipped) == 0 {
+		ui.Muted("No symlinks into " + tildePath(m.Path) + " found.")
+	}
+	for _, l := range m.Removed {
+		report("Removed", "Would remove", fmt.Sprintf("%s -> %s", tildePath(l.Target), tildePath(l.LinkDest)))
+	}
+	for _, r := range m.Restored {
+		report("Restored", "Would restore", fmt.Sprintf("%s from %s", tildePath(r.Target), filepath.Base(r.Backup)))
+	}
+	for _, s := range m.Skipped {
+		ui.Warn(fmt.Sprintf("Left %s in place: %s", tildePath(s.Path), s.Reason))
+	}
+	if m.CloneRemoved {
+		report("Removed clone", "Would remove clone", tildePath(m.Path))
+	}
+	if m.ManifestPath != "" {
+		ui.Println()
+		ui.Muted("Manifest: " + tildePath(m.ManifestPath))
+	}
+}
+
+// tildePath abbreviates a path under the home directory to ~/...
+func tildePath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || path == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		if rel == "." {
+			return "~"
+		}
+		return "~/" + rel
+	}
+	return path
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/dotfiles"
+)
+
+func stubDotfilesStatus(t *testing.T, st *dotfiles.Status, err error) *bool {
+	t.Helper()
+	var gotFetch bool
+	orig := dotfilesGetStatus
+	dotfilesGetStatus = func(fetch bool) (*dotfiles.Status, error) {
+		gotFetch = fetch
+		return st, err
+	}
+	t.Cleanup(func() {
+		dotfilesGetStatus = orig
+		dotfilesStatusJSON = false
+		dotfilesStatusFetch = false
+	})
+	return &gotFetch
+}
+
+func sampleDotfilesStatus(home string) *dotfiles.Status {
+	return &dotfiles.Status{
+		Path:   filepath.Join(home, ".dotfiles"),
+		Layout: dotfiles.LayoutStow,
+		Repo:   &dotfiles.RepoStatus{Branch: "main", Behind: 2, Dirty: true, DirtyFiles: 1},
+		Files: []dotfiles.FileStatus{
+			{Package: "zsh", Target: filepath.Join(home, ".zshrc"), State: dotfiles.StateLinked},
+			{Package: "git", Target: filepath.Join(home, ".gitconfig"), State: dotfiles.StateConflicting,
+				Backup: filepath.Join(home, ".gitconfig.openboot.bak")},
+			{Package: "vim", Target: filepath.Join(home, ".vimrc"), State: dotfiles.StateBroken,
+				LinkDest: filepath.Join(home, ".dotfiles", "vim", ".gone")},
+		},
+	}
+}
+
+func TestDotfilesStatus_Text(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	gotFetch := stubDotfilesStatus(t, sampleDotfilesStatus(home), nil)
+	dotfilesStatusFetch = true
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runDotfilesStatus(nil, nil))
+	})
+
+	assert.True(t, *gotFetch)
+	assert.Contains(t, out, "~/.dotfiles (stow layout)")
+	assert.Contains(t, out, "2 commit(s) behind origin/main")
+	assert.Contains(t, out, "1 uncommitted change(s)")
+	assert.Contains(t, out, "~/.gitconfig — conflicting")
+	assert.Contains(t, out, "backup: ~/.gitconfig.openboot.bak")
+	assert.Contains(t, out, "~/.vimrc — broken link to ~/.dotfiles/vim/.gone")
+	assert.Contains(t, out, "1 linked, 1 conflicting, 1 broken")
+}
+
+func TestDotfilesStatus_JSON(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	stubDotfilesStatus(t, sampleDotfilesStatus(home), nil)
+	dotfilesStatusJSON = true
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runDotfilesStatus(nil, nil))
+	})
+
+	var got dotfiles.Status
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	require.Len(t, got.Files, 3)
+	assert.Equal(t, dotfiles.StateConflicting, got.Files[1].State)
+	assert.Equal(t, 2, got.Repo.Behind)
+}
+
+func TestDotfilesStatus_Error(t *testing.T) {
+	stubDotfilesStatus(t, nil, errors.New("dotfiles directory not found: /x"))
+	err := runDotfilesStatus(nil, nil)
+	assert.ErrorContains(t, err, "dotfiles directory not found")
+}
+
+func stubDotfilesUnlink(t *testing.T, m *dotfiles.UnlinkManifest, err error) *dotfiles.UnlinkOptions {
+	t.Helper()
+	var got dotfiles.UnlinkOptions
+	orig := dotfilesUnlink
+	dotfilesUnlink = func(opts dotfiles.UnlinkOptions) (*dotfiles.UnlinkManifest, error) {
+		got = opts
+		return m, err
+	}
+	t.Cleanup(func() {
+		dotfilesUnlink = orig
+		dotfilesUnlinkDryRun = false
+		dotfilesUnlinkRemoveClone = false
+		dotfilesUnlinkJSON = false
+	})
+	return &got
+}
+
+func TestDotfilesUnlink_DryRunText(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	got := stubDotfilesUnlink(t, &dotfiles.UnlinkManifest{
+		Path:   filepath.Join(home, ".dotfiles"),
+		DryRun: true,
+		Removed: []dotfiles.RemovedLink{
+			{Target: filepath.Join(home, ".zshrc"), LinkDest: filepath.Join(home, ".dotfiles", "zsh", ".zshrc")},
+		},
+		Restored: []dotfiles.RestoredFile{
+			{Backup: filepath.Join(home, ".zshrc.openboot.bak"), Target: filepath.Join(home, ".zshrc")},
+		},
+		Skipped: []dotfiles.SkippedFile{
+			{Path: filepath.Join(home, ".gitconfig.openboot.bak"), Reason: "original path is occupied"},
+		},
+		CloneRemoved: true,
+	}, nil)
+	dotfilesUnlinkDryRun = true
+	dotfilesUnlinkRemoveClone = true
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runDotfilesUnlink(nil, nil))
+	})
+
+	assert.Equal(t, dotfiles.UnlinkOptions{DryRun: true, RemoveClone: true}, *got)
+	assert.Contains(t, out, "[DRY-RUN] Would remove ~/.zshrc -> ~/.dotfiles/zsh/.zshrc")
+	assert.Contains(t, out, "[DRY-RUN] Would restore ~/.zshrc from .zshrc.openboot.bak")
+	assert.Contains(t, out, "Left ~/.gitconfig.openboot.bak in place")
+	assert.Contains(t, out, "[DRY-RUN] Would remove clone ~/.dotfiles")
+	assert.NotContains(t, out, "Manifest:")
+}
+
+func TestDotfilesUnlink_PartialFailureStillReports(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	stubDotfilesUnlink(t, &dotfiles.UnlinkManifest{
+		Path:         filepath.Join(home, ".dotfiles"),
+		Restored:     []dotfiles.RestoredFile{{Backup: filepath.Join(home, ".vimrc.openboot.bak"), Target: filepath.Join(home, ".vimrc")}},
+		ManifestPath: filepath.Join(home, ".openboot", "dotfiles-unlink-1.json"),
+	}, errors.New("remove link /x: permission denied"))
+	dotfilesUnlinkJSON = true
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDotfilesUnlink(nil, nil)
+	})
+
+	assert.ErrorContains(t, err, "permission denied")
+	var m dotfiles.UnlinkManifest
+	require.NoError(t, json.Unmarshal([]byte(out), &m))
+	assert.Len(t, m.Restored, 1)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/openbootdotdev/openboot/internal/auth"
 	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/installer"
+	"github.com/openbootdotdev/openboot/internal/signing"
 	syncpkg "github.com/openbootdotdev/openboot/internal/sync"
 	"github.com/openbootdotdev/openboot/internal/system"
 	"github.com/openbootdotdev/openboot/internal/ui"
@@ -78,7 +80,8 @@ func init() {
 	installCmd.Flags().StringVar(&installCfg.PostInstall, "post-install", "", "post-install script: skip")
 
 	installCmd.Flags().BoolVar(&installCfg.Update, "update", false, "update Homebrew and exit")
-	installCmd.Flags().BoolVar(&installCfg.AllowPostInstall, "allow-post-install", false, "allow post-install scripts in silent mode")
+	installCmd.Flags().BoolVar(&installCfg.AllowPostInstall, "allow-post-install", false, "allow post-install scripts, launch agents, git commands, starship config and shell snippets in silent mode")
+	installCmd.Flags().BoolVar(&installCfg.RequireSignature, "require-signature", false, "refuse configs not signed by a trusted key (see 'openboot keys')")
 }
 
 // applyEnvOverrides applies environment variable overrides to cfg.
@@ -111,6 +114,11 @@ func runInstallCmd(cmd *cobra.Command, args []string) error { //nolint:gocyclo /
 		return installer.RunContext(cmd.Context(), installCfg)
 	}
 
+	policy, err := loadSignaturePolicy(installCfg.RequireSignature)
+	if err != nil {
+		return err
+	}
+
 	if installCfg.RemoteConfig == nil {
 		src, err := resolveInstallSource(cmd, args)
 		if err != nil {
@@ -119,10 +127,10 @@ func runInstallCmd(cmd *cobra.Command, args []string) error { //nolint:gocyclo /
 
 		if src.kind == sourceSyncSource {
 			pickRaw, _ := cmd.Flags().GetString("pick")
-			return runSyncInstall(cmd.Context(), src.syncSource, pickRaw)
+			return runSyncInstall(cmd.Context(), src.syncSource, pickRaw, policy)
 		}
 
-		if err := applyInstallSource(src); err != nil {
+		if err := applyInstallSource(src, policy); err != nil {
 			return fmt.Errorf("apply install source: %w", err)
 		}
 
@@ -163,7 +171,7 @@ func runInstallCmd(cmd *cobra.Command, args []string) error { //nolint:gocyclo /
 		return fmt.Errorf("--pick requires a remote config; use the interactive wizard instead")
 	}
 
-	err := installer.RunContext(cmd.Context(), installCfg)
+	err = installer.RunContext(cmd.Context(), installCfg)
 	if err == nil && !installCfg.DryRun {
 		saveSyncSourceIfRemote(installCfg)
 	}
@@ -332,9 +340,10 @@ func looksLikeUserSlug(s string) bool {
 	return slugPartRe.MatchString(parts[0]) && slugPartRe.MatchString(parts[1])
 }
 
-// applyInstallSource loads the chosen source into cfg so installer.Run can use it.
+// applyInstallSource loads the chosen source into cfg so installer.Run can use it,
+// checking a loaded config's signature against policy.
 // Not used for sourceSyncSource (that path has its own flow).
-func applyInstallSource(src *installSource) error {
+func applyInstallSource(src *installSource, policy signing.Policy) error {
 	switch src.kind {
 	case sourceNone:
 		return nil
@@ -349,6 +358,9 @@ func applyInstallSource(src *installSource) error {
 		if err != nil {
 			return fmt.Errorf("fetch remote config: %w", err)
 		}
+		if err := acceptSignature(policy, rc.Signature); err != nil {
+			return err
+		}
 		installCfg.RemoteConfig = rc
 		if installCfg.Preset == "" {
 			installCfg.Preset = rc.Preset
@@ -360,6 +372,9 @@ func applyInstallSource(src *installSource) error {
 		if err != nil {
 			return fmt.Errorf("load config from file: %w", err)
 		}
+		if err := acceptSignature(policy, rc.Signature); err != nil {
+			return err
+		}
 		installCfg.RemoteConfig = rc
 		if installCfg.Preset == "" {
 			installCfg.Preset = rc.Preset
@@ -385,7 +400,7 @@ func applyInstallSource(src *installSource) error {
 // runSyncInstall is the flow when `openboot install` is called without args
 // and a sync source exists. It fetches the remote config, shows a diff, and
 // applies only the additions (install is add-only).
-func runSyncInstall(ctx context.Context, source *syncpkg.SyncSource, pickRaw string) error { //nolint:gocyclo // orchestrates --pick filter, dry-run, 3-way prompt, and customize TUI for the sync-source path; splitting would scatter the flow
+func runSyncInstall(ctx context.Context, source *syncpkg.SyncSource, pickRaw string, policy signing.Policy) error { //nolint:gocyclo // orchestrates --pick filter, dry-run, 3-way prompt, and customize TUI for the sync-source path; splitting would scatter the flow
 	printSyncSourceHeader(source)
 
 	var token string
@@ -396,6 +411,9 @@ func runSyncInstall(ctx context.Context, source *syncpkg.SyncSource, pickRaw str
 	if err != nil {
 		return fmt.Errorf("fetch remote config: %w", err)
 	}
+	if err := acceptSignature(policy, rc.Signature); err != nil {
+		return err
+	}
 
 	diff, err := syncpkg.ComputeDiff(rc)
 	if err != nil {
@@ -473,6 +491,15 @@ func runSyncInstall(ctx context.Context, source *syncpkg.SyncSource, pickRaw str
 	}
 
 	plan := buildInstallPlan(diff, rc)
+	if err := gateGitCommands(plan, installCfg.Silent, installCfg.AllowPostInstall); err != nil {
+		return err
+	}
+	if err := gateStarshipConfig(plan, installCfg.Silent, installCfg.AllowPostInstall); err != nil {
+		return err
+	}
+	if err := gateShellSnippets(plan, installCfg.Silent, installCfg.AllowPostInstall); err != nil {
+		return err
+	}
 
 	// Sync applies linearly on every path: the results belong in the scrollback,
 	// not in an alt-screen that discards them when it exits.
@@ -563,11 +590,19 @@ func remoteConfigFromSyncDiffAdditions(rc *config.RemoteConfig, diff *syncpkg.Sy
 // with whatever picks the user makes. This means the post-filter
 // TotalMissing() count includes taps even when no picked package depends
 // on them — acceptable because Homebrew skips already-tapped repos quickly.
+// Services are kept unless their formula is missing and was not picked, since
+// starting them would fail.
 func filterSyncDiffByPicks(diff *syncpkg.SyncDiff, picks map[string]bool) *syncpkg.SyncDiff {
 	out := *diff
 	out.MissingFormulae = filterStrings(diff.MissingFormulae, picks)
 	out.MissingCasks = filterStrings(diff.MissingCasks, picks)
 	out.MissingNpm = filterStrings(diff.MissingNpm, picks)
+	out.ServicesNotRunning = nil
+	for _, s := range diff.ServicesNotRunning {
+		if picks[s] || !slices.Contains(diff.MissingFormulae, s) {
+			out.ServicesNotRunning = append(out.ServicesNotRunning, s)
+		}
+	}
 	return &out
 }
 
@@ -373,6 +373,16 @@ func TestFilterSyncDiffByPicks_PreservesTapsUnchanged(t *testing.T) {
 	assert.Equal(t, d.MissingTaps, out.MissingTaps)
 }
 
+func TestFilterSyncDiffByPicks_DropsServicesOfUnpickedFormulae(t *testing.T) {
+	d := &syncpkg.SyncDiff{
+		MissingFormulae:    []string{"postgresql@16", "redis"},
+		ServicesNotRunning: []string{"colima", "postgresql@16", "redis"},
+	}
+	out := filterSyncDiffByPicks(d, map[string]bool{"redis": true})
+	// colima is installed, just stopped; postgresql@16 will not be installed.
+	assert.Equal(t, []string{"colima", "redis"}, out.ServicesNotRunning)
+}
+
 func TestRemoteConfigFromSyncDiffAdditions_ScopesToAdditions(t *testing.T) {
 	rc := &config.RemoteConfig{
 		Packages: config.PackageEntryList{
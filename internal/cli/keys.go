@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openbootdotdev/openboot/internal/signing"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage signing keys and trusted keys",
+	Long: `Manage the ed25519 key you sign configs with, and the public keys
+whose signatures you trust.
+
+Your key lives in ~/.openboot/keys/signing.pem. Trusted keys and the
+signing policy live in ~/.openboot/trusted_keys.json; set
+"require_signature": true there to make every install behave as if
+--require-signature was passed.`,
+	Example: `  # On the machine that publishes the team config
+  openboot keys generate
+  openboot sign team.json
+
+  # On every machine that installs it
+  openboot keys trust team ed25519:Vf3k...
+  openboot install team.json --require-signature`,
+	SilenceUsage: true,
+}
+
+var keysGenerateCmd = &cobra.Command{
+	Use:          "generate",
+	Short:        "Create your signing key",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		pub, err := signing.GenerateKey(force)
+		if err != nil {
+			return err
+		}
+		path, _ := signing.KeyPath()
+		fmt.Fprintln(os.Stderr, snapSuccessStyle.Render("✓ Signing key created: "+path))
+		fmt.Fprintln(os.Stderr, snapMutedStyle.Render("  Share this public key; others trust it with 'openboot keys trust <name> <key>':"))
+		ui.Println(signing.EncodePublicKey(pub))
+		return nil
+	},
+}
+
+var keysShowCmd = &cobra.Command{
+	Use:          "show",
+	Short:        "Print your public key",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := signing.LoadKey()
+		if err != nil {
+			return err
+		}
+		ui.Println(signing.EncodePublicKey(key.Public().(ed25519.PublicKey)))
+		return nil
+	},
+}
+
+var keysListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List trusted keys",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKeysList()
+	},
+}
+
+var keysTrustCmd = &cobra.Command{
+	Use:          "trust <name> <key>",
+	Short:        "Trust signatures made with a public key",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := signing.LoadPolicy()
+		if err != nil {
+			return err
+		}
+		if err := p.Trust(args[0], args[1]); err != nil {
+			return err
+		}
+		if err := signing.SavePolicy(p); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, snapSuccessStyle.Render("✓ Trusted "+args[0]))
+		return nil
+	},
+}
+
+var keysUntrustCmd = &cobra.Command{
+	Use:          "untrust <name>",
+	Short:        "Stop trusting a key",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := signing.LoadPolicy()
+		if err != nil {
+			return err
+		}
+		if !p.Untrust(args[0]) {
+			return fmt.Errorf("no trusted key named %q", args[0])
+		}
+		if err := signing.SavePolicy(p); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, snapSuccessStyle.Render("✓ Removed "+args[0]))
+		return nil
+	},
+}
+
+func init() {
+	keysGenerateCmd.Flags().Bool("force", false, "replace an existing key")
+	keysCmd.AddCommand(keysGenerateCmd, keysShowCmd, keysListCmd, keysTrustCmd, keysUntrustCmd)
+}
+
+func runKeysList() error {
+	p, err := signing.LoadPolicy()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr)
+	if len(p.Keys) == 0 {
+		fmt.Fprintln(os.Stderr, snapMutedStyle.Render("  No trusted keys; add one with 'openboot keys trust <name> <key>'"))
+	}
+	for _, k := range p.Keys {
+		id := "?"
+		if pub, err := signing.ParsePublicKey(k.Key); err == nil {
+			id = signing.KeyID(pub)
+		}
+		fmt.Fprintf(os.Stderr, "  %s %s\n", snapBoldStyle.Render(fmt.Sprintf("%-20s", k.Name)), snapMutedStyle.Render(id))
+	}
+	fmt.Fprintln(os.Stderr)
+	policy := "installs accept unsigned configs"
+	if p.RequireSignature {
+		policy = "installs require a trusted signature"
+	}
+	fmt.Fprintf(os.Stderr, "  %s %s\n", snapBoldStyle.Render("Policy:"), policy)
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+// loadSignaturePolicy loads the trusted keys loaded configs are checked
+// against; require adds --require-signature on top of the file's own
+// setting.
+func loadSignaturePolicy(require bool) (signing.Policy, error) {
+	p, err := signing.LoadPolicy()
+	if err != nil {
+		return signing.Policy{}, err
+	}
+	p.RequireSignature = p.RequireSignature || require
+	if p.RequireSignature && len(p.Keys) == 0 {
+		return signing.Policy{}, fmt.Errorf("signatures are required but no keys are trusted; add one with 'openboot keys trust <name> <key>'")
+	}
+	return p, nil
+}
+
+// acceptSignature checks the signature a config or snapshot was loaded
+// with against p, and notes a signed one.
+func acceptSignature(p signing.Policy, res signing.Result) error {
+	res, err := p.Accept(res)
+	if err != nil {
+		return fmt.Errorf("signature check: %w", err)
+	}
+	switch {
+	case res.Trusted != "":
+		ui.Success(fmt.Sprintf("Signature verified: signed by %s (key %s)", res.Trusted, res.KeyID))
+	case res.Signed:
+		ui.Info(fmt.Sprintf("Notice: config is signed by key %s, which you do not trust (openboot keys trust)", res.KeyID))
+	}
+	return nil
+}
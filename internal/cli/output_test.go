@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/openbootdotdev/openboot/internal/signing"
 	"github.com/openbootdotdev/openboot/internal/snapshot"
 	syncpkg "github.com/openbootdotdev/openboot/internal/sync"
 )
@@ -624,7 +625,7 @@ func TestLoadSnapshot_InvalidJSON(t *testing.T) {
 	badFile := dir + "/bad.json"
 	require.NoError(t, os.WriteFile(badFile, []byte("not valid json {{{"), 0600))
 
-	_, err := loadSnapshot(badFile)
+	_, err := loadSnapshot(badFile, signing.Policy{})
 	require.Error(t, err)
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/envelope"
 	"github.com/openbootdotdev/openboot/internal/logging"
 	"github.com/openbootdotdev/openboot/internal/ui"
 	"github.com/openbootdotdev/openboot/internal/updater"
@@ -49,6 +50,8 @@ shell configuration, and macOS preferences.`,
 		logCloser = closer
 
 		config.SetClientVersion(version)
+		config.SetRedactedPrompt(promptRedactedField)
+		envelope.SetPassphrasePrompt(promptPassphrase)
 		installCfg.Version = version
 
 		// Only the install flow needs the package catalog and auto-update.
@@ -77,6 +80,10 @@ func init() {
 	rootCmd.AddCommand(logoutCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(dotfilesCmd)
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(keysCmd)
+	rootCmd.AddCommand(appSettingsCmd)
 
 	rootCmd.SetUsageTemplate(usageTemplate)
 }
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/signing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "snap.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":1,"captured_at":"2026-01-01T00:00:00Z","hostname":"mac","packages":{"formulae":["git"]}}`), 0600))
+
+	assert.ErrorContains(t, runSign(path, ""), "openboot keys generate")
+	pub, err := signing.GenerateKey(false)
+	require.NoError(t, err)
+	require.NoError(t, runSign(path, ""))
+
+	assert.ErrorContains(t, runVerify(path), "do not trust")
+	p, err := signing.LoadPolicy()
+	require.NoError(t, err)
+	require.NoError(t, p.Trust("me", signing.EncodePublicKey(pub)))
+	require.NoError(t, signing.SavePolicy(p))
+	assert.NoError(t, runVerify(path))
+
+	// A signed snapshot imports under --require-signature; an edited one
+	// is refused whatever the policy.
+	p, err = loadSignaturePolicy(true)
+	require.NoError(t, err)
+	_, err = loadSnapshot(path, p)
+	require.NoError(t, err)
+
+	unsigned := filepath.Join(t.TempDir(), "unsigned.json")
+	require.NoError(t, os.WriteFile(unsigned, []byte(`{"version":1,"captured_at":"2026-01-01T00:00:00Z","hostname":"mac","packages":{"formulae":["git"]}}`), 0600))
+	_, err = loadSnapshot(unsigned, p)
+	assert.ErrorIs(t, err, signing.ErrUnsigned)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), `"git"`, `"evil"`, 1)), 0600))
+	assert.ErrorIs(t, runVerify(path), signing.ErrTampered)
+	p, err = loadSignaturePolicy(false)
+	require.NoError(t, err)
+	_, err = loadSnapshot(path, p)
+	assert.ErrorIs(t, err, signing.ErrTampered)
+}
+
+func TestLoadSignaturePolicy_RequireNeedsTrustedKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := loadSignaturePolicy(false)
+	assert.NoError(t, err)
+	_, err = loadSignaturePolicy(true)
+	assert.ErrorContains(t, err, "no keys are trusted")
+}
@@ -38,9 +38,19 @@ shell config, and dev tools. The destination is chosen by flag or TTY:
 
   openboot snapshot              Interactive menu (TTY) or JSON to stdout (pipe)
   openboot snapshot --local      Save to ~/.openboot/snapshot.json
+  openboot snapshot --local --encrypt
+                                 Save encrypted with a passphrase
   openboot snapshot --publish    Upload to openboot.dev
   openboot snapshot --json       Output JSON to stdout
 
+History (each --local save is kept under ~/.openboot/snapshots):
+  openboot snapshot list         List saved snapshots
+  openboot snapshot show <id>    Show one
+  openboot snapshot compare <a> <b>
+                                 What changed between two snapshots
+  openboot snapshot migrate <file>
+                                 Rewrite an old snapshot in the current format
+
 Restore:
   openboot snapshot --import my-setup.json   Restore from a local file
   openboot snapshot --import https://...     Restore from a URL`,
@@ -54,6 +64,7 @@ func init() {
 	snapshotCmd.Flags().Bool("publish", false, "upload to openboot.dev")
 	snapshotCmd.Flags().String("slug", "", "target an existing config by slug (with --publish)")
 	snapshotCmd.Flags().Bool("json", false, "output JSON to stdout")
+	snapshotCmd.Flags().Bool("encrypt", false, "encrypt the local snapshot with a passphrase (with --local)")
 	snapshotCmd.Flags().Bool("dry-run", false, "preview without modifying anything")
 	snapshotCmd.Flags().String("import", "", "restore from a snapshot file or URL")
 }
@@ -70,6 +81,11 @@ func runSnapshot(cmd *cobra.Command) error {
 	jsonFlag, _ := cmd.Flags().GetBool("json")
 	dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
 	slugFlag, _ := cmd.Flags().GetString("slug")
+	encryptFlag, _ := cmd.Flags().GetBool("encrypt")
+
+	if encryptFlag && !localFlag {
+		return fmt.Errorf("--encrypt applies to --local snapshots")
+	}
 
 	// Explicit flags: route directly to the requested destination(s).
 	// Multiple flags combine (e.g. --local --publish does both).
@@ -90,7 +106,7 @@ func runSnapshot(cmd *cobra.Command) error {
 			return nil
 		}
 		if localFlag {
-			path, err := snapshot.SaveLocal(snap, false)
+			path, err := saveLocalSnapshot(snap, encryptFlag)
 			if err != nil {
 				return fmt.Errorf("save snapshot: %w", err)
 			}
@@ -249,6 +265,10 @@ func captureJSONSnapshot() error {
 	catalogMatch := snapshot.MatchPackages(snap)
 	snap.CatalogMatch = *catalogMatch
 	snap.MatchedPreset = snapshot.DetectBestPreset(snap)
+	snap, err = redactSnapshot(snap)
+	if err != nil {
+		return err
+	}
 	data, err := json.MarshalIndent(snap, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal snapshot: %w", err)
@@ -273,7 +293,7 @@ func captureEnvironment() (*snapshot.Snapshot, error) {
 func captureWithUI() (*snapshot.Snapshot, error) {
 	fmt.Fprintln(os.Stderr)
 
-	progress := ui.NewScanProgress(9)
+	progress := ui.NewScanProgress(snapshot.CaptureStepCount())
 
 	snap, err := snapshot.CaptureWithProgress(func(step snapshot.ScanStep) {
 		progress.Update(step)
@@ -289,13 +309,26 @@ func captureWithUI() (*snapshot.Snapshot, error) {
 		fmt.Fprintln(os.Stderr)
 		ui.Warn(fmt.Sprintf("Snapshot is partial — %d step(s) failed: %s",
 			len(snap.Health.FailedSteps),
-			strings.Join(snap.Health.FailedSteps, ", ")))
+			describeFailedSteps(snap.Health)))
 		fmt.Fprintln(os.Stderr, snapMutedStyle.Render("  The snapshot was saved but may be incomplete."))
 	}
 
 	return snap, nil
 }
 
+// describeFailedSteps lists the failed capture steps, each followed by its
+// recorded reason when there is one.
+func describeFailedSteps(h snapshot.CaptureHealth) string {
+	parts := make([]string, 0, len(h.FailedSteps))
+	for _, name := range h.FailedSteps {
+		if reason := h.Errors[name]; reason != "" {
+			name = fmt.Sprintf("%s (%s)", name, reason)
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func reviewSnapshot(snap *snapshot.Snapshot) (*snapshot.Snapshot, bool, error) {
 	edited, confirmed, err := tui.RunSnapshotEditor(snap)
 	if err != nil {
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/openbootdotdev/openboot/internal/envelope"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// minPassphraseLen is the shortest passphrase --encrypt accepts.
+const minPassphraseLen = 8
+
+// saveLocalSnapshot saves snap to ~/.openboot/snapshot.json, sealed with a
+// new passphrase when encrypt is set.
+func saveLocalSnapshot(snap *snapshot.Snapshot, encrypt bool) (string, error) {
+	if !encrypt {
+		return snapshot.SaveLocal(snap, false)
+	}
+	pass, err := newPassphrase()
+	if err != nil {
+		return "", err
+	}
+	return snapshot.SaveLocalEncrypted(snap, pass, false)
+}
+
+// newPassphrase reads the passphrase for a new encrypted snapshot from
+// OPENBOOT_PASSPHRASE, or asks for it twice.
+func newPassphrase() ([]byte, error) {
+	pass := os.Getenv(envelope.PassphraseEnv)
+	if pass == "" {
+		if !system.HasTTY() {
+			return nil, fmt.Errorf("--encrypt needs a terminal or %s", envelope.PassphraseEnv)
+		}
+		var err error
+		if pass, err = ui.Password("Passphrase for the encrypted snapshot"); err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		again, err := ui.Password("Repeat the passphrase")
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		if again != pass {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	if len(pass) < minPassphraseLen {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", minPassphraseLen)
+	}
+	return []byte(pass), nil
+}
+
+// promptPassphrase asks for the passphrase of an encrypted snapshot or
+// config being loaded.
+func promptPassphrase() ([]byte, error) {
+	if !system.HasTTY() {
+		return nil, fmt.Errorf("file is encrypted; set %s to its passphrase", envelope.PassphraseEnv)
+	}
+	pass, err := ui.Password("Passphrase for the encrypted snapshot")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(pass), nil
+}
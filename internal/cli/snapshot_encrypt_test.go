@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/envelope"
+)
+
+func TestNewPassphrase(t *testing.T) {
+	t.Setenv(envelope.PassphraseEnv, "long enough")
+	pass, err := newPassphrase()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("long enough"), pass)
+
+	t.Setenv(envelope.PassphraseEnv, "short")
+	_, err = newPassphrase()
+	assert.ErrorContains(t, err, "at least")
+
+	// No env and no terminal: refuse rather than hang.
+	t.Setenv(envelope.PassphraseEnv, "")
+	_, err = newPassphrase()
+	assert.Error(t, err)
+}
+
+func TestRunSnapshot_EncryptNeedsLocal(t *testing.T) {
+	cmd := snapshotCmd
+	require.NoError(t, cmd.Flags().Set("encrypt", "true"))
+	t.Cleanup(func() { _ = cmd.Flags().Set("encrypt", "false") })
+	assert.ErrorContains(t, runSnapshot(cmd), "--local")
+}
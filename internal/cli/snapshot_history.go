@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openbootdotdev/openboot/internal/diff"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List local snapshot history",
+	Long: `List the snapshots kept under ~/.openboot/snapshots, newest first.
+Every 'openboot snapshot --local' adds one; the oldest are pruned.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSnapshotList()
+	},
+}
+
+var snapshotShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a snapshot from local history",
+	Long: `Show one snapshot from the local history. <id> is an ID from
+'openboot snapshot list', a unique prefix of one, or "latest".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		return runSnapshotShow(args[0], jsonFlag)
+	},
+}
+
+var snapshotCompareCmd = &cobra.Command{
+	Use:   "compare <a> <b>",
+	Short: "Compare two snapshots from local history",
+	Long: `Show what changed between two snapshots in the local history:
++ marks what <b> has that <a> lacks, - what <a> has that <b> dropped.
+
+  openboot snapshot compare 20260901 latest`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		packagesOnly, _ := cmd.Flags().GetBool("packages-only")
+		return runSnapshotCompare(args[0], args[1], packagesOnly)
+	},
+}
+
+func init() {
+	snapshotShowCmd.Flags().Bool("json", false, "output the snapshot as JSON to stdout")
+	snapshotCompareCmd.Flags().Bool("packages-only", false, "only compare packages")
+	snapshotCmd.AddCommand(snapshotListCmd, snapshotShowCmd, snapshotCompareCmd)
+}
+
+func runSnapshotList() error {
+	entries, err := snapshot.ListHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, snapMutedStyle.Render("No snapshot history yet; save one with 'openboot snapshot --local'"))
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "  %s\n", snapBoldStyle.Render(fmt.Sprintf("%-20s %-20s %9s  %s", "ID", "CAPTURED", "SIZE", "HOST")))
+	for _, e := range entries {
+		captured, host := "-", e.Hostname
+		if !e.CapturedAt.IsZero() {
+			captured = e.CapturedAt.Local().Format("2006-01-02 15:04")
+		}
+		if e.Encrypted {
+			host = snapMutedStyle.Render("(encrypted)")
+		}
+		fmt.Fprintf(os.Stderr, "  %-20s %-20s %9s  %s\n", e.ID, captured, formatSize(e.Size), host)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "  %s\n", snapMutedStyle.Render(fmt.Sprintf("%d snapshot(s) in %s", len(entries), snapshot.HistoryDir())))
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+func runSnapshotShow(ref string, jsonOut bool) error {
+	entry, snap, err := snapshot.LoadHistory(ref)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal snapshot: %w", err)
+		}
+		ui.Println(string(data))
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "  %s %s\n", snapBoldStyle.Render("ID:"), entry.ID)
+	fmt.Fprintf(os.Stderr, "  %s %s\n", snapBoldStyle.Render("Host:"), snap.Hostname)
+	fmt.Fprintf(os.Stderr, "  %s %s\n", snapBoldStyle.Render("Captured:"), snap.CapturedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(os.Stderr, "  %s %s\n", snapBoldStyle.Render("File:"), entry.Path)
+	showSnapshotPreview(snap)
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, snapBoldStyle.Render("  Restore this snapshot:"))
+	fmt.Fprintf(os.Stderr, "    %s\n", snapMutedStyle.Render("openboot snapshot --import "+entry.Path))
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+func runSnapshotCompare(refA, refB string, packagesOnly bool) error {
+	a, snapA, err := snapshot.LoadHistory(refA)
+	if err != nil {
+		return err
+	}
+	b, snapB, err := snapshot.LoadHistory(refB)
+	if err != nil {
+		return err
+	}
+	// The older snapshot stands in for the system, so "missing" entries
+	// are ones b added and "extra" entries are ones b dropped.
+	result := diff.CompareSnapshots(snapA, snapB, diff.Source{Kind: "history", Path: a.ID + " → " + b.ID})
+	diff.FormatTerminal(result, packagesOnly)
+	return nil
+}
+
+// formatSize renders n bytes as B, KB or MB.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
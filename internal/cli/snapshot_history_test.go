@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+)
+
+func saveHistorySnapshot(t *testing.T, at time.Time, formulae ...string) {
+	t.Helper()
+	snap := &snapshot.Snapshot{Version: 1, CapturedAt: at, Hostname: "work-mac", Packages: snapshot.PackageSnapshot{Formulae: formulae}}
+	_, err := snapshot.SaveLocal(snap, false)
+	require.NoError(t, err)
+}
+
+func TestRunSnapshotList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	out := captureStderr(t, func() { require.NoError(t, runSnapshotList()) })
+	assert.Contains(t, out, "No snapshot history yet")
+
+	saveHistorySnapshot(t, time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC), "git")
+	out = captureStderr(t, func() { require.NoError(t, runSnapshotList()) })
+	assert.Contains(t, out, "20260901-100000")
+	assert.Contains(t, out, "work-mac")
+	assert.Contains(t, out, "1 snapshot(s)")
+}
+
+func TestRunSnapshotCompare(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	saveHistorySnapshot(t, time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC), "git", "wget")
+	saveHistorySnapshot(t, time.Date(2026, 9, 2, 10, 0, 0, 0, time.UTC), "git", "ripgrep")
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runSnapshotCompare("20260901", "latest", true))
+	})
+	assert.Contains(t, out, "20260901-100000 → 20260902-100000")
+	assert.Contains(t, out, "ripgrep")
+	assert.Contains(t, out, "wget")
+
+	assert.ErrorContains(t, runSnapshotCompare("2025", "latest", false), "no snapshot")
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "2.0 KB", formatSize(2048))
+	assert.Equal(t, "1.5 MB", formatSize(3<<19))
+}
@@ -9,15 +9,23 @@ import (
 	"strings"
 
 	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/envelope"
 	"github.com/openbootdotdev/openboot/internal/httputil"
 	"github.com/openbootdotdev/openboot/internal/installer"
+	"github.com/openbootdotdev/openboot/internal/redact"
+	"github.com/openbootdotdev/openboot/internal/secrets"
+	"github.com/openbootdotdev/openboot/internal/signing"
 	"github.com/openbootdotdev/openboot/internal/snapshot"
 	"github.com/openbootdotdev/openboot/internal/ui"
 	"github.com/openbootdotdev/openboot/internal/ui/tui"
 )
 
 func runSnapshotImportContext(ctx context.Context, importPath string, dryRun bool) error {
-	snap, err := loadSnapshot(importPath)
+	policy, err := loadSignaturePolicy(false)
+	if err != nil {
+		return err
+	}
+	snap, err := loadSnapshot(importPath, policy)
 	if err != nil {
 		return fmt.Errorf("load snapshot: %w", err)
 	}
@@ -26,7 +34,7 @@ func runSnapshotImportContext(ctx context.Context, importPath string, dryRun boo
 		fmt.Fprintln(os.Stderr)
 		ui.Warn(fmt.Sprintf("This snapshot is incomplete — %d capture step(s) failed: %s",
 			len(snap.Health.FailedSteps),
-			strings.Join(snap.Health.FailedSteps, ", ")))
+			describeFailedSteps(snap.Health)))
 		fmt.Fprintln(os.Stderr, snapMutedStyle.Render("  Some data may be missing. The restore will proceed with what was captured."))
 		fmt.Fprintln(os.Stderr)
 		proceed, err := ui.Confirm("Continue with partial snapshot?", false)
@@ -88,7 +96,7 @@ func downloadSnapshotBytes(url string, client *http.Client) ([]byte, error) {
 	return data, nil
 }
 
-func loadSnapshot(importPath string) (*snapshot.Snapshot, error) {
+func loadSnapshot(importPath string, policy signing.Policy) (*snapshot.Snapshot, error) {
 	var snap *snapshot.Snapshot
 
 	switch {
@@ -100,25 +108,58 @@ func loadSnapshot(importPath string) (*snapshot.Snapshot, error) {
 		if err != nil {
 			return nil, fmt.Errorf("download snapshot: %w", err)
 		}
-		s, err := snapshot.ParseBytes(data)
+		s, err := parseVerifiedSnapshot(data, policy)
 		if err != nil {
 			return nil, fmt.Errorf("parse snapshot: %w", err)
 		}
 		snap = s
 	default:
-		s, err := snapshot.LoadFile(importPath)
+		data, err := os.ReadFile(importPath) //nolint:gosec // user-supplied snapshot path
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("load snapshot file: snapshot file not found: %s", importPath)
+			}
+			return nil, fmt.Errorf("load snapshot file: %w", err)
+		}
+		s, err := parseVerifiedSnapshot(data, policy)
 		if err != nil {
 			return nil, fmt.Errorf("load snapshot file: %w", err)
 		}
 		snap = s
 	}
 
+	if err := redact.Restore(snap, promptRedactedField); err != nil {
+		return nil, fmt.Errorf("restore redacted fields: %w", err)
+	}
+
 	catalogMatch := snapshot.MatchPackages(snap)
 	snap.CatalogMatch = *catalogMatch
 	snap.MatchedPreset = snapshot.DetectBestPreset(snap)
 	return snap, nil
 }
 
+// parseVerifiedSnapshot decrypts data if needed, checks its signature
+// against policy, and parses it.
+func parseVerifiedSnapshot(data []byte, policy signing.Policy) (*snapshot.Snapshot, error) {
+	data, err := envelope.Unseal(data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt snapshot: %w", err)
+	}
+	sig, err := config.CheckSignature(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := acceptSignature(policy, sig); err != nil {
+		return nil, err
+	}
+	// Snapshots have no post_install, the only place references are
+	// resolved; anywhere else one would be restored literally.
+	if refs := secrets.FindReferences(string(data)); len(refs) > 0 {
+		return nil, fmt.Errorf("%s is not resolved in snapshots; secret references only work in post_install", refs[0])
+	}
+	return snapshot.ParseBytes(data)
+}
+
 func showRestoreInfo(snap *snapshot.Snapshot, source string) {
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintln(os.Stderr, snapTitleStyle.Render("=== Restoring from Snapshot ==="))
@@ -196,7 +237,16 @@ func buildImportConfig(edited *snapshot.Snapshot, dryRun bool) *config.Config {
 	cfg.SnapshotGit = &config.SnapshotGitConfig{
 		UserName:  edited.Git.UserName,
 		UserEmail: edited.Git.UserEmail,
+		Config:    edited.Git.GitConfig(),
 	}
+	cfg.SnapshotSSH = edited.SSH
+	cfg.SnapshotDefaultApps = edited.DefaultApps
+	cfg.SnapshotKeyboard = edited.Keyboard
+	cfg.SnapshotDock = edited.Dock
+	cfg.SnapshotLaunchAgents = edited.LaunchAgents
+	cfg.SnapshotAppSettings = edited.AppSettings
+	cfg.SnapshotServices = edited.Services
+	cfg.SnapshotFonts = edited.Fonts
 
 	if edited.Dotfiles.RepoURL != "" {
 		if err := config.ValidateDotfilesURL(edited.Dotfiles.RepoURL); err == nil {
@@ -230,6 +280,11 @@ func buildImportConfig(edited *snapshot.Snapshot, dryRun bool) *config.Config {
 	cfg.SnapshotShellOhMyZsh = edited.Shell.OhMyZsh
 	cfg.SnapshotShellTheme = edited.Shell.Theme
 	cfg.SnapshotShellPlugins = edited.Shell.Plugins
+	cfg.SnapshotShellName = edited.Shell.Shell
+	cfg.SnapshotShellFramework = edited.Shell.Framework
+	cfg.SnapshotStarship = edited.Shell.Starship
+	cfg.SnapshotStarshipConfig = edited.Shell.StarshipConfig
+	cfg.SnapshotShellSnippets = edited.Shell.Snippets
 
 	return cfg
 }
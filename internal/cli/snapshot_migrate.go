@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+)
+
+var snapshotMigrateCmd = &cobra.Command{
+	Use:   "migrate <file>...",
+	Short: "Rewrite snapshot files in the current format",
+	Long: `Rewrite snapshot files written by older versions of openboot in the
+current format. Each original is kept next to it as <file>.v<N>.bak.
+
+Old files load fine without this; migrate is for keeping the files you
+share or commit in the format current openboot writes. A signed file loses
+its signature and needs 'openboot sign' again.`,
+	Example: `  openboot snapshot migrate my-setup.json
+  openboot snapshot migrate ~/.openboot/snapshots/*.json --dry-run`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return runSnapshotMigrate(args, dryRun)
+	},
+}
+
+func init() {
+	snapshotMigrateCmd.Flags().Bool("dry-run", false, "report what would change without writing")
+	snapshotCmd.AddCommand(snapshotMigrateCmd)
+}
+
+func runSnapshotMigrate(paths []string, dryRun bool) error {
+	failed := 0
+	for _, path := range paths {
+		res, err := snapshot.MigrateFile(path, dryRun)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", snapBoldStyle.Render("✗"), path, err)
+		case res.From == res.To:
+			fmt.Fprintf(os.Stderr, "  %s\n", snapMutedStyle.Render(fmt.Sprintf("%s already v%d", path, res.To)))
+		case dryRun:
+			fmt.Fprintf(os.Stderr, "  %s %s: v%d → v%d\n", snapMutedStyle.Render("[DRY-RUN] Would migrate"), path, res.From, res.To)
+		default:
+			fmt.Fprintf(os.Stderr, "  %s %s: v%d → v%d %s\n", snapSuccessStyle.Render("✓ Migrated"), path, res.From, res.To,
+				snapMutedStyle.Render("(original: "+res.Backup+")"))
+		}
+		if err == nil && res.DroppedSignature {
+			fmt.Fprintf(os.Stderr, "    %s\n", snapMutedStyle.Render("signature removed; re-sign with 'openboot sign "+path+"'"))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d file(s) could not be migrated", failed, len(paths))
+	}
+	return nil
+}
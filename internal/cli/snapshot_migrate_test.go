@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSnapshotMigrate(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.json")
+	require.NoError(t, os.WriteFile(old, []byte(`{"version":1,"hostname":"mac","packages":["git"]}`), 0600))
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte(`{"version":1,"packages":42}`), 0600))
+
+	out := captureStderr(t, func() {
+		require.NoError(t, runSnapshotMigrate([]string{old}, false))
+	})
+	assert.Contains(t, out, "v1 → v2")
+	assert.FileExists(t, old+".v1.bak")
+
+	out = captureStderr(t, func() {
+		require.NoError(t, runSnapshotMigrate([]string{old}, false))
+	})
+	assert.Contains(t, out, "already v2")
+
+	err := runSnapshotMigrate([]string{old, bad}, false)
+	assert.ErrorContains(t, err, "1 of 2")
+}
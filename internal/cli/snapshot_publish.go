@@ -46,6 +46,11 @@ func publishSnapshot(ctx context.Context, snap *snapshot.Snapshot, explicitSlug
 		return fmt.Errorf("no valid auth token found — please log in again")
 	}
 
+	snap, err = redactSnapshot(snap)
+	if err != nil {
+		return err
+	}
+
 	targetSlug := resolveTargetSlug(explicitSlug)
 
 	var configName, configDesc, visibility string
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/redact"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// redactSnapshot returns a copy of snap with identifying data removed, per
+// the built-in rules and ~/.openboot/redact.json, and prints what it
+// changed. It runs before a snapshot leaves the machine: publish and
+// --json output.
+func redactSnapshot(snap *snapshot.Snapshot) (*snapshot.Snapshot, error) {
+	cfg, err := redact.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	out, findings, err := snapshot.Redact(snap, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("redact snapshot: %w", err)
+	}
+	showRedactionReview(findings)
+	return out, nil
+}
+
+func showRedactionReview(findings []redact.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "  %s %d value(s)\n", snapBoldStyle.Render("Redacted:"), len(findings))
+	width := 0
+	for _, f := range findings {
+		width = max(width, len(f.Path))
+	}
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "    %-*s  %s → %s %s\n", width, f.Path,
+			truncateValue(f.Before), f.After, snapMutedStyle.Render("("+f.Rule+")"))
+	}
+	fmt.Fprintln(os.Stderr, snapMutedStyle.Render("  Edit "+redact.ConfigPath()+" to add patterns or disable rules."))
+	fmt.Fprintln(os.Stderr)
+}
+
+func truncateValue(s string) string {
+	s = strings.ReplaceAll(s, "\n", "⏎")
+	if r := []rune(s); len(r) > 60 {
+		return string(r[:57]) + "..."
+	}
+	return s
+}
+
+// redactedAnswers remembers what was typed for each redacted value, to
+// offer it again when the same value turns up in another field.
+var redactedAnswers = map[string]string{}
+
+// promptRedactedField asks for the value of a field that was redacted
+// before the snapshot was shared. An empty answer, or no terminal to ask
+// on, leaves the field empty so nothing is applied from it.
+func promptRedactedField(path, value string) (string, error) {
+	// The capturing machine's hostname is informational; nothing applies it.
+	if path == "hostname" || strings.HasSuffix(path, ".hostname") {
+		return "", nil
+	}
+	if !system.HasTTY() {
+		ui.Warn(fmt.Sprintf("Skipping redacted field %s (no terminal to ask for a value)", path))
+		return "", nil
+	}
+	answer, err := ui.InputWithDefault(
+		fmt.Sprintf("%s was redacted (%s) — enter a value, or leave empty to skip", path, value),
+		"", redactedAnswers[value])
+	if err != nil {
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	redactedAnswers[value] = answer
+	return answer, nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+)
+
+func TestRedactSnapshot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	snap := &snapshot.Snapshot{
+		Hostname: "alice-mbp",
+		Git:      snapshot.GitSnapshot{UserName: "Alice", UserEmail: "alice@example.com"},
+	}
+	out, err := redactSnapshot(snap)
+	require.NoError(t, err)
+	assert.Equal(t, "<redacted:email>", out.Git.UserEmail)
+	assert.Equal(t, "<redacted:hostname>", out.Hostname)
+	assert.Equal(t, "alice@example.com", snap.Git.UserEmail)
+}
+
+// Without a terminal, and for the informational hostname, redacted fields
+// restore empty rather than as placeholders.
+func TestPromptRedactedFieldNoTTY(t *testing.T) {
+	for _, path := range []string{"hostname", "git.user_email"} {
+		v, err := promptRedactedField(path, "<redacted:email>")
+		require.NoError(t, err)
+		assert.Empty(t, v, path)
+	}
+}
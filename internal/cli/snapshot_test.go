@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/signing"
 	"github.com/openbootdotdev/openboot/internal/snapshot"
 	syncpkg "github.com/openbootdotdev/openboot/internal/sync"
 )
@@ -202,7 +203,7 @@ func writeSyncSourceForSnap(t *testing.T, slug string) {
 // ── loadSnapshot ──────────────────────────────────────────────────────────────
 
 func TestLoadSnapshot_RejectsInsecureHTTP(t *testing.T) {
-	_, err := loadSnapshot("http://example.com/snap.json")
+	_, err := loadSnapshot("http://example.com/snap.json", signing.Policy{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "insecure HTTP not allowed")
 }
@@ -222,15 +223,29 @@ func TestLoadSnapshot_LocalFile(t *testing.T) {
 	snapFile := filepath.Join(dir, "snap.json")
 	require.NoError(t, os.WriteFile(snapFile, data, 0600))
 
-	loaded, err := loadSnapshot(snapFile)
+	loaded, err := loadSnapshot(snapFile, signing.Policy{})
 	require.NoError(t, err)
 	require.NotNil(t, loaded)
 	assert.Equal(t, []string{"git", "ripgrep"}, loaded.Packages.Formulae)
 	assert.Equal(t, []string{"firefox"}, loaded.Packages.Casks)
 }
 
+func TestLoadSnapshot_RefusesSecretReferences(t *testing.T) {
+	snap := &snapshot.Snapshot{
+		CapturedAt: time.Now(),
+		Packages:   snapshot.PackageSnapshot{Taps: []string{"secret://env/TAP"}},
+	}
+	data, err := json.Marshal(snap)
+	require.NoError(t, err)
+	snapFile := filepath.Join(t.TempDir(), "snap.json")
+	require.NoError(t, os.WriteFile(snapFile, data, 0600))
+
+	_, err = loadSnapshot(snapFile, signing.Policy{})
+	assert.ErrorContains(t, err, "secret references only work in post_install")
+}
+
 func TestLoadSnapshot_LocalFile_NotFound(t *testing.T) {
-	_, err := loadSnapshot("/tmp/this-file-should-not-exist-openboot-test.json")
+	_, err := loadSnapshot("/tmp/this-file-should-not-exist-openboot-test.json", signing.Policy{})
 	require.Error(t, err)
 }
 
@@ -2,11 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/openbootdotdev/openboot/internal/config"
 	syncpkg "github.com/openbootdotdev/openboot/internal/sync"
+	"github.com/openbootdotdev/openboot/internal/system"
 	"github.com/openbootdotdev/openboot/internal/ui"
 )
 
@@ -50,6 +52,12 @@ func printInstallDiff(d *syncpkg.SyncDiff) {
 		ui.Println()
 	}
 
+	if len(d.ServicesNotRunning) > 0 {
+		ui.Printf("  %s\n", ui.Green("Services to start"))
+		printMissing("Services", d.ServicesNotRunning)
+		ui.Println()
+	}
+
 	if len(d.MacOSChanged) > 0 {
 		ui.Printf("  %s\n", ui.Green("macOS Changes"))
 		for _, p := range d.MacOSChanged {
@@ -64,6 +72,9 @@ func printInstallDiff(d *syncpkg.SyncDiff) {
 
 	if d.Shell != nil {
 		ui.Printf("  %s\n", ui.Green("Shell Changes"))
+		if d.Shell.FrameworkChanged {
+			ui.Printf("    Framework: %s %s %s\n", fallbackStr(d.Shell.LocalFramework, "(none)"), ui.Yellow("→"), d.Shell.RemoteFramework)
+		}
 		if d.Shell.ThemeChanged {
 			localTheme := d.Shell.LocalTheme
 			if localTheme == "" {
@@ -78,6 +89,55 @@ func printInstallDiff(d *syncpkg.SyncDiff) {
 			}
 			ui.Printf("    Plugins: %s %s %s\n", localPlugins, ui.Yellow("→"), strings.Join(d.Shell.RemotePlugins, ", "))
 		}
+		if d.Shell.StarshipChanged {
+			ui.Printf("    Starship: prompt or starship.toml differs\n")
+		}
+		if d.Shell.SnippetsChanged {
+			ui.Printf("    Snippets: %s %s %s\n", d.Shell.LocalSnippets.Summary(), ui.Yellow("→"), d.Shell.RemoteSnippets.Summary())
+		}
+		ui.Println()
+	}
+
+	if len(d.GitChanged) > 0 || d.GitignoreChanged || d.GitattributesChanged || len(d.GitProfilesChanged) > 0 || d.GitSigningChanged {
+		ui.Printf("  %s\n", ui.Green("Git Changes"))
+		for _, g := range d.GitChanged {
+			ui.Printf("    %s: %s %s %s\n", g.Key, fallbackStr(g.LocalValue, "(unset)"), ui.Yellow("→"), g.RemoteValue)
+		}
+		if d.GitignoreChanged {
+			ui.Printf("    Global gitignore differs\n")
+		}
+		if d.GitattributesChanged {
+			ui.Printf("    Global gitattributes differs\n")
+		}
+		for _, name := range d.GitProfilesChanged {
+			ui.Printf("    Profile %s differs\n", name)
+		}
+		if d.GitSigningChanged {
+			ui.Printf("    Commit signing differs\n")
+		}
+		ui.Println()
+	}
+
+	if len(d.SSHHostsChanged) > 0 || len(d.SSHKeysMissing) > 0 || d.SSHStale || d.SSHKnownHostsMissing > 0 {
+		ui.Printf("  %s\n", ui.Green("SSH Changes"))
+		for _, h := range d.SSHHostsChanged {
+			ui.Printf("    Host %s differs\n", h)
+		}
+		printMissing("Keys", d.SSHKeysMissing)
+		if d.SSHStale {
+			ui.Printf("    Managed ~/.ssh/config block has entries the config does not\n")
+		}
+		if d.SSHKnownHostsMissing > 0 {
+			ui.Printf("    Known hosts to pin: %d\n", d.SSHKnownHostsMissing)
+		}
+		ui.Println()
+	}
+
+	if len(d.MachineChanged) > 0 {
+		ui.Printf("  %s\n", ui.Green("Machine Name"))
+		for _, m := range d.MachineChanged {
+			ui.Printf("    %s: %s %s %s\n", m.Key, fallbackStr(m.LocalValue, "(unset)"), ui.Yellow("→"), m.RemoteValue)
+		}
 		ui.Println()
 	}
 
@@ -110,19 +170,70 @@ func buildInstallPlan(d *syncpkg.SyncDiff, rc *config.RemoteConfig) *syncpkg.Syn
 		InstallCasks:    d.MissingCasks,
 		InstallNpm:      d.MissingNpm,
 		InstallTaps:     d.MissingTaps,
+		StartServices:   d.ServicesNotRunning,
 	}
 
 	if d.Shell != nil && rc.Shell != nil {
-		plan.UpdateShell = true
+		plan.UpdateShell = d.Shell.FrameworkChanged || d.Shell.ThemeChanged || d.Shell.PluginsChanged || d.Shell.StarshipChanged
+		plan.ShellName = rc.Shell.Shell
+		plan.ShellFramework = rc.Shell.Framework
 		plan.ShellOhMyZsh = rc.Shell.OhMyZsh
 		plan.ShellTheme = rc.Shell.Theme
 		plan.ShellPlugins = rc.Shell.Plugins
+		plan.Starship = rc.Shell.Starship
+		plan.StarshipConfig = rc.Shell.StarshipConfig
+	}
+	if d.Shell != nil && d.Shell.SnippetsChanged {
+		plan.UpdateShellSnippets = true
+		plan.ShellSnippets = d.Shell.RemoteSnippets
 	}
 
 	if d.DotfilesChanged {
 		plan.UpdateDotfiles = d.RemoteDotfiles
 	}
 
+	if rc.Git != nil && (len(d.GitChanged) > 0 || d.GitignoreChanged || d.GitattributesChanged || len(d.GitProfilesChanged) > 0 || d.GitSigningChanged) {
+		plan.UpdateGit = &config.RemoteGitConfig{}
+		for _, g := range d.GitChanged {
+			if plan.UpdateGit.Settings == nil {
+				plan.UpdateGit.Settings = make(map[string]string)
+			}
+			plan.UpdateGit.Settings[g.Key] = g.RemoteValue
+		}
+		if d.GitignoreChanged {
+			plan.UpdateGit.Gitignore = rc.Git.Gitignore
+		}
+		if d.GitattributesChanged {
+			plan.UpdateGit.Gitattributes = rc.Git.Gitattributes
+		}
+		for _, p := range rc.Git.Profiles {
+			if slices.Contains(d.GitProfilesChanged, p.Name) {
+				plan.UpdateGit.Profiles = append(plan.UpdateGit.Profiles, p)
+			}
+		}
+		if d.GitSigningChanged {
+			plan.UpdateGit.Signing = rc.Git.Signing
+		}
+	}
+
+	if rc.SSH != nil && (len(d.SSHHostsChanged) > 0 || len(d.SSHKeysMissing) > 0 || d.SSHStale || d.SSHKnownHostsMissing > 0) {
+		plan.UpdateSSH = rc.SSH
+	}
+
+	if len(d.MachineChanged) > 0 {
+		plan.UpdateMachine = &config.RemoteMachineConfig{}
+		for _, m := range d.MachineChanged {
+			switch m.Key {
+			case "ComputerName":
+				plan.UpdateMachine.ComputerName = m.RemoteValue
+			case "LocalHostName":
+				plan.UpdateMachine.LocalHostName = m.RemoteValue
+			case "HostName":
+				plan.UpdateMachine.HostName = m.RemoteValue
+			}
+		}
+	}
+
 	if len(d.MacOSChanged) > 0 {
 		for _, p := range d.MacOSChanged {
 			plan.UpdateMacOSPrefs = append(plan.UpdateMacOSPrefs, config.RemoteMacOSPref{
@@ -139,6 +250,91 @@ func buildInstallPlan(d *syncpkg.SyncDiff, rc *config.RemoteConfig) *syncpkg.Syn
 	return plan
 }
 
+// approveSyncCode is the sync side of the installer's code gate: a silent
+// run approves only when --allow-post-install was passed, and an
+// interactive one previews the commands and asks.
+func approveSyncCode(silent, allow bool, what, header, prompt, preview string) (bool, error) {
+	if silent || !system.HasTTY() {
+		if !allow {
+			ui.Warn(fmt.Sprintf("Skipping %s in silent mode (use --allow-post-install to enable)", what))
+		}
+		return allow, nil
+	}
+	ui.Info(header)
+	ui.Println()
+	ui.PrintScriptPreview(preview)
+	ui.Println()
+	apply, err := ui.Confirm(prompt, true)
+	if err != nil {
+		return false, fmt.Errorf("confirm %s: %w", what, err)
+	}
+	if !apply {
+		ui.Muted("Skipping " + what)
+	}
+	return apply, nil
+}
+
+// gateGitCommands keeps the git settings that run commands in plan only
+// if the user approves them, the same opt-in post_install needs.
+func gateGitCommands(plan *syncpkg.SyncPlan, silent, allow bool) error {
+	keys := plan.UpdateGit.CommandSettings()
+	if len(keys) == 0 {
+		return nil
+	}
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("git config --global %s %q", k, plan.UpdateGit.Settings[k])
+	}
+	apply, err := approveSyncCode(silent, allow, "git settings that run commands",
+		fmt.Sprintf("Git settings that run commands (%d):", len(keys)),
+		"Apply these git settings?", strings.Join(lines, "\n"))
+	if err != nil {
+		return err
+	}
+	if !apply {
+		plan.UpdateGit = plan.UpdateGit.WithoutCommandSettings()
+	}
+	return nil
+}
+
+// gateStarshipConfig keeps the starship config in plan only if the user
+// approves it: its custom modules run commands at every prompt.
+func gateStarshipConfig(plan *syncpkg.SyncPlan, silent, allow bool) error {
+	if !plan.UpdateShell || !plan.Starship || plan.StarshipConfig == "" {
+		return nil
+	}
+	apply, err := approveSyncCode(silent, allow, "starship config",
+		"Starship config (custom modules run commands at every prompt):",
+		"Write this starship config?", plan.StarshipConfig)
+	if err != nil {
+		return err
+	}
+	if !apply {
+		plan.StarshipConfig = ""
+	}
+	return nil
+}
+
+// gateShellSnippets keeps a snippets update in plan only if the user
+// approves it: env vars and aliases run in every new shell. Removing the
+// block needs no approval.
+func gateShellSnippets(plan *syncpkg.SyncPlan, silent, allow bool) error {
+	if !plan.UpdateShellSnippets || plan.ShellSnippets.Empty() {
+		return nil
+	}
+	sh := (&config.RemoteShellConfig{Shell: plan.ShellName, Framework: plan.ShellFramework, OhMyZsh: plan.ShellOhMyZsh}).EffectiveShell()
+	apply, err := approveSyncCode(silent, allow, "shell snippets",
+		fmt.Sprintf("Shell snippets for %s (%s):", config.ShellRCFile(sh), plan.ShellSnippets.Summary()),
+		"Write these shell snippets?", plan.ShellSnippets.Render(sh))
+	if err != nil {
+		return err
+	}
+	if !apply {
+		plan.UpdateShellSnippets = false
+	}
+	return nil
+}
+
 // sourceLabel returns a human-readable label for a sync source, preferring
 // @username/slug form when available.
 func sourceLabel(source *syncpkg.SyncSource) string {
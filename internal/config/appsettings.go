@@ -0,0 +1,294 @@
+package config
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed data/app-settings.yaml
+var appSettingsYAML embed.FS
+
+// AppSettingsSpec is one app in the embedded app_settings registry: the
+// files and defaults domains that hold its configuration, and the cask
+// that installs it.
+type AppSettingsSpec struct {
+	ID      string   `yaml:"id"`
+	Name    string   `yaml:"name"`
+	Cask    string   `yaml:"cask"`
+	Files   []string `yaml:"files"`   // ~/-relative paths
+	Domains []string `yaml:"domains"` // defaults domains
+}
+
+type appSettingsData struct {
+	Apps []AppSettingsSpec `yaml:"apps"`
+}
+
+// AppSettingsRegistry returns every app whose settings openboot knows how
+// to capture, in registry order.
+func AppSettingsRegistry() []AppSettingsSpec {
+	data, err := appSettingsYAML.ReadFile("data/app-settings.yaml")
+	if err != nil {
+		log.Printf("Warning: failed to read app-settings.yaml: %v", err)
+		return nil
+	}
+
+	var asd appSettingsData
+	if err := yaml.Unmarshal(data, &asd); err != nil {
+		log.Printf("Warning: failed to parse app-settings.yaml: %v", err)
+		return nil
+	}
+
+	return asd.Apps
+}
+
+// LookupAppSettings returns the registry entry for id.
+func LookupAppSettings(id string) (AppSettingsSpec, bool) {
+	for _, s := range AppSettingsRegistry() {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return AppSettingsSpec{}, false
+}
+
+// AppSettings is the app_settings section of a snapshot or config. Each
+// captured file or domain refers to its content by digest, and the content
+// itself is stored once in Blobs, base64-encoded.
+type AppSettings struct {
+	Apps  []AppSettingsApp  `json:"apps"`
+	Blobs map[string]string `json:"blobs"` // "sha256:<hex>" → base64 content
+}
+
+// AppSettingsApp is the captured settings of one registry app.
+type AppSettingsApp struct {
+	App     string           `json:"app"` // registry id, e.g. "vscode"
+	Files   []AppSettingsRef `json:"files,omitempty"`
+	Domains []AppSettingsRef `json:"domains,omitempty"`
+}
+
+// AppSettingsRef points a file path or defaults domain, spelled as in the
+// registry, at the blob holding its content.
+type AppSettingsRef struct {
+	Target string `json:"target"`
+	Blob   string `json:"blob"`
+}
+
+// AppSettingsPath resolves a registry file path, written with a leading
+// ~/, against home.
+func AppSettingsPath(target, home string) string {
+	if rest, ok := strings.CutPrefix(target, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return target
+}
+
+// BlobDigest returns the content address AppSettings stores data under.
+func BlobDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Empty reports whether s carries no app settings.
+func (s *AppSettings) Empty() bool {
+	return s == nil || len(s.Apps) == 0
+}
+
+// Put stores data as a blob and returns its digest. Identical content is
+// stored once.
+func (s *AppSettings) Put(data []byte) string {
+	if s.Blobs == nil {
+		s.Blobs = map[string]string{}
+	}
+	digest := BlobDigest(data)
+	s.Blobs[digest] = base64.StdEncoding.EncodeToString(data)
+	return digest
+}
+
+// Blob returns the content stored under digest, checking that it still
+// hashes to digest.
+func (s *AppSettings) Blob(digest string) ([]byte, error) {
+	enc, ok := s.Blobs[digest]
+	if !ok {
+		return nil, fmt.Errorf("blob %s is missing", digest)
+	}
+	data, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("blob %s: %w", digest, err)
+	}
+	if BlobDigest(data) != digest {
+		return nil, fmt.Errorf("blob %s does not match its content", digest)
+	}
+	return data, nil
+}
+
+// AppIDs returns the ids of the apps s carries, in order.
+func (s *AppSettings) AppIDs() []string {
+	if s == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(s.Apps))
+	for _, a := range s.Apps {
+		ids = append(ids, a.App)
+	}
+	return ids
+}
+
+// Validate checks that every app is in the registry and listed once, that
+// it only names files and domains the registry lists for it, and that
+// every blob it refers to is present and intact. Restore writes nothing
+// the registry does not name, so a config cannot use app_settings to drop
+// arbitrary files.
+func (s *AppSettings) Validate() error {
+	if s == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(s.Apps))
+	for _, a := range s.Apps {
+		spec, ok := LookupAppSettings(a.App)
+		if !ok {
+			return fmt.Errorf("app settings: unknown app %q", a.App)
+		}
+		if seen[a.App] {
+			return fmt.Errorf("app settings: %s is listed twice", a.App)
+		}
+		seen[a.App] = true
+		for _, f := range a.Files {
+			if !slices.Contains(spec.Files, f.Target) {
+				return fmt.Errorf("app settings %s: %q is not one of its settings files", a.App, f.Target)
+			}
+			if _, err := s.Blob(f.Blob); err != nil {
+				return fmt.Errorf("app settings %s: %s: %w", a.App, f.Target, err)
+			}
+		}
+		for _, d := range a.Domains {
+			if !slices.Contains(spec.Domains, d.Target) {
+				return fmt.Errorf("app settings %s: %q is not one of its defaults domains", a.App, d.Target)
+			}
+			if _, err := s.Blob(d.Blob); err != nil {
+				return fmt.Errorf("app settings %s: %s: %w", a.App, d.Target, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateAppSettingsIDs checks that every id names a registry app.
+func ValidateAppSettingsIDs(ids []string) error {
+	var unknown []string
+	for _, id := range ids {
+		if _, ok := LookupAppSettings(id); !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown app(s): %s (see 'openboot app-settings list')", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// appSettingsOptIn is the file at AppSettingsOptInPath.
+type appSettingsOptIn struct {
+	Apps []string `json:"apps"`
+}
+
+// AppSettingsOptInPath returns the path of the list of apps whose settings
+// snapshots capture. Nothing is captured for an app until it is listed.
+func AppSettingsOptInPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".openboot", "app_settings.json"), nil
+}
+
+// EnabledAppSettings returns the ids of the apps opted in to capture,
+// sorted. A missing file means none; ids no longer in the registry are
+// dropped.
+func EnabledAppSettings() ([]string, error) {
+	path, err := AppSettingsOptInPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read app settings opt-in: %w", err)
+	}
+	var o appSettingsOptIn
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	ids := make([]string, 0, len(o.Apps))
+	for _, id := range o.Apps {
+		if _, ok := LookupAppSettings(id); ok && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// EnableAppSettings opts ids in to capture. Every id must be in the
+// registry.
+func EnableAppSettings(ids ...string) error {
+	if err := ValidateAppSettingsIDs(ids); err != nil {
+		return err
+	}
+	cur, err := EnabledAppSettings()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !slices.Contains(cur, id) {
+			cur = append(cur, id)
+		}
+	}
+	return saveAppSettingsOptIn(cur)
+}
+
+// DisableAppSettings opts ids out of capture. Ids that were not enabled
+// are ignored.
+func DisableAppSettings(ids ...string) error {
+	cur, err := EnabledAppSettings()
+	if err != nil {
+		return err
+	}
+	cur = slices.DeleteFunc(cur, func(id string) bool { return slices.Contains(ids, id) })
+	return saveAppSettingsOptIn(cur)
+}
+
+func saveAppSettingsOptIn(ids []string) error {
+	path, err := AppSettingsOptInPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create openboot dir: %w", err)
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	sort.Strings(ids)
+	data, err := json.MarshalIndent(appSettingsOptIn{Apps: ids}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal app settings opt-in: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write app settings opt-in: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppSettingsRegistry(t *testing.T) {
+	reg := AppSettingsRegistry()
+	require.NotEmpty(t, reg)
+	seen := map[string]bool{}
+	for _, s := range reg {
+		assert.False(t, seen[s.ID], "duplicate id %s", s.ID)
+		seen[s.ID] = true
+		assert.NotEmpty(t, s.Name, s.ID)
+		assert.NotEmpty(t, s.Cask, s.ID)
+		assert.NotEmpty(t, len(s.Files)+len(s.Domains), s.ID)
+		for _, f := range s.Files {
+			assert.True(t, strings.HasPrefix(f, "~/"), "%s: %s", s.ID, f)
+		}
+	}
+	for _, id := range []string{"vscode", "iterm2", "warp", "raycast", "rectangle"} {
+		_, ok := LookupAppSettings(id)
+		assert.True(t, ok, id)
+	}
+}
+
+func TestAppSettings_BlobRoundTrip(t *testing.T) {
+	s := &AppSettings{}
+	d := s.Put([]byte(`{"editor.fontSize": 14}`))
+	assert.True(t, strings.HasPrefix(d, "sha256:"))
+	assert.Equal(t, d, s.Put([]byte(`{"editor.fontSize": 14}`)))
+	assert.Len(t, s.Blobs, 1)
+
+	data, err := s.Blob(d)
+	require.NoError(t, err)
+	assert.Equal(t, `{"editor.fontSize": 14}`, string(data))
+
+	s.Blobs[d] = base64.StdEncoding.EncodeToString([]byte("edited"))
+	_, err = s.Blob(d)
+	assert.ErrorContains(t, err, "does not match")
+	_, err = s.Blob("sha256:00")
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestAppSettings_Validate(t *testing.T) {
+	s := &AppSettings{}
+	settings := s.Put([]byte("{}"))
+	s.Apps = []AppSettingsApp{{App: "vscode", Files: []AppSettingsRef{{Target: "~/Library/Application Support/Code/User/settings.json", Blob: settings}}}}
+	require.NoError(t, s.Validate())
+	assert.NoError(t, (*AppSettings)(nil).Validate())
+
+	bad := func(apps ...AppSettingsApp) *AppSettings { return &AppSettings{Apps: apps, Blobs: s.Blobs} }
+	for name, a := range map[string]*AppSettings{
+		"unknown app":    bad(AppSettingsApp{App: "notepad"}),
+		"listed twice":   bad(AppSettingsApp{App: "vscode"}, AppSettingsApp{App: "vscode"}),
+		"foreign file":   bad(AppSettingsApp{App: "vscode", Files: []AppSettingsRef{{Target: "~/.zshrc", Blob: settings}}}),
+		"foreign domain": bad(AppSettingsApp{App: "iterm2", Domains: []AppSettingsRef{{Target: "com.apple.dock", Blob: settings}}}),
+		"missing blob":   bad(AppSettingsApp{App: "iterm2", Domains: []AppSettingsRef{{Target: "com.googlecode.iterm2", Blob: "sha256:00"}}}),
+	} {
+		assert.Error(t, a.Validate(), name)
+	}
+}
+
+func TestAppSettingsOptIn(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	ids, err := EnabledAppSettings()
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	require.NoError(t, EnableAppSettings("vscode", "iterm2"))
+	require.NoError(t, EnableAppSettings("vscode"))
+	ids, err = EnabledAppSettings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"iterm2", "vscode"}, ids)
+
+	assert.ErrorContains(t, EnableAppSettings("notepad"), "unknown app")
+
+	require.NoError(t, DisableAppSettings("iterm2", "warp"))
+	ids, err = EnabledAppSettings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vscode"}, ids)
+
+	// Ids dropped from the registry are ignored rather than failing capture.
+	path := filepath.Join(home, ".openboot", "app_settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"apps":["vscode","retired-app"]}`), 0600))
+	ids, err = EnabledAppSettings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vscode"}, ids)
+}
+
+func TestLoadSnapshotAsRemoteConfig_AppSettings(t *testing.T) {
+	s := &AppSettings{}
+	d := s.Put([]byte("<plist/>"))
+	s.Apps = []AppSettingsApp{{App: "rectangle", Domains: []AppSettingsRef{{Target: "com.knollsoft.Rectangle", Blob: d}}}}
+	enc := base64.StdEncoding.EncodeToString([]byte("<plist/>"))
+
+	rc, err := loadSnapshotAsRemoteConfig([]byte(`{
+		"packages": {"formulae": [], "casks": ["rectangle"], "taps": [], "npm": []},
+		"app_settings": {"apps": [{"app": "rectangle", "domains": [{"target": "com.knollsoft.Rectangle", "blob": "` + d + `"}]}],
+			"blobs": {"` + d + `": "` + enc + `"}}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, s, rc.AppSettings)
+
+	_, err = loadSnapshotAsRemoteConfig([]byte(`{
+		"packages": {"formulae": [], "casks": [], "taps": [], "npm": []},
+		"app_settings": {"apps": [{"app": "rectangle", "files": [{"target": "~/.ssh/authorized_keys", "blob": "` + d + `"}]}],
+			"blobs": {"` + d + `": "` + enc + `"}}
+	}`))
+	assert.ErrorContains(t, err, "not one of its settings files")
+}
@@ -6,7 +6,7 @@
 //   - options.go    — Config conversion methods (ToInstallOptions, ToInstallState, ApplyState)
 //   - validate.go   — ValidateDotfilesURL and RemoteConfig.Validate
 //   - presets.go    — embedded presets.yaml, GetPreset, GetPresetNames
-//   - remote.go     — HTTP client, FetchRemoteConfig, UnmarshalRemoteConfigFlexible, LoadRemoteConfigFromFile, GetScreenRecordingPackages
+//   - remote.go     — HTTP client, FetchRemoteConfig, UnmarshalRemoteConfigFlexible, LoadRemoteConfigFromFile, CheckSignature, GetScreenRecordingPackages
 //   - packages.go   — embedded packages.yaml, Categories, package lookup helpers
 //   - packages_remote.go — remote package refresh and cache
 package config
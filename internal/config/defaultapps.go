@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultApp makes the app with BundleID the default handler for one
+// content type, file extension or URL scheme. Exactly one of UTI,
+// Extension and URLScheme is set.
+type DefaultApp struct {
+	UTI       string `json:"uti,omitempty"`        // e.g. "public.json"
+	Extension string `json:"extension,omitempty"`  // e.g. "md", without the dot
+	URLScheme string `json:"url_scheme,omitempty"` // e.g. "https"
+	BundleID  string `json:"bundle_id"`            // e.g. "com.microsoft.VSCode"
+}
+
+// Target returns what a is the default for, as duti spells it: the UTI,
+// the extension with a leading dot, or the URL scheme.
+func (a DefaultApp) Target() string {
+	switch {
+	case a.UTI != "":
+		return a.UTI
+	case a.Extension != "":
+		return "." + a.Extension
+	default:
+		return a.URLScheme
+	}
+}
+
+// IsURLScheme reports whether a maps a URL scheme rather than a file type.
+func (a DefaultApp) IsURLScheme() bool {
+	return a.URLScheme != ""
+}
+
+var (
+	defaultAppUTIRe       = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+	defaultAppExtensionRe = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+	defaultAppSchemeRe    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+	bundleIDRe            = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+)
+
+// ValidateDefaultApps checks that each entry maps exactly one well-formed
+// UTI, extension or URL scheme to a bundle ID, and that no target is
+// mapped twice.
+func ValidateDefaultApps(apps []DefaultApp) error {
+	seen := make(map[string]bool, len(apps))
+	for _, a := range apps {
+		set := 0
+		for _, v := range []string{a.UTI, a.Extension, a.URLScheme} {
+			if v != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("default app %s: set exactly one of uti, extension or url_scheme", a.BundleID)
+		}
+		switch {
+		case a.UTI != "" && !defaultAppUTIRe.MatchString(a.UTI):
+			return fmt.Errorf("default app: invalid uti %q", a.UTI)
+		case a.Extension != "" && !defaultAppExtensionRe.MatchString(a.Extension):
+			return fmt.Errorf("default app: invalid extension %q (no leading dot)", a.Extension)
+		case a.URLScheme != "" && !defaultAppSchemeRe.MatchString(a.URLScheme):
+			return fmt.Errorf("default app: invalid url_scheme %q", a.URLScheme)
+		case !bundleIDRe.MatchString(a.BundleID):
+			return fmt.Errorf("default app for %s: invalid bundle_id %q", a.Target(), a.BundleID)
+		}
+		key := strings.ToLower(a.Target())
+		if seen[key] {
+			return fmt.Errorf("default app for %s is set twice", a.Target())
+		}
+		seen[key] = true
+	}
+	return nil
+}
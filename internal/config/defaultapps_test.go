@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultAppTarget(t *testing.T) {
+	assert.Equal(t, "public.json", DefaultApp{UTI: "public.json"}.Target())
+	assert.Equal(t, ".md", DefaultApp{Extension: "md"}.Target())
+	assert.Equal(t, "https", DefaultApp{URLScheme: "https"}.Target())
+	assert.True(t, DefaultApp{URLScheme: "https"}.IsURLScheme())
+	assert.False(t, DefaultApp{Extension: "md"}.IsURLScheme())
+}
+
+func TestValidateDefaultApps(t *testing.T) {
+	valid := []DefaultApp{
+		{UTI: "public.json", BundleID: "com.microsoft.VSCode"},
+		{Extension: "md", BundleID: "com.microsoft.VSCode"},
+		{URLScheme: "https", BundleID: "com.google.Chrome"},
+		{URLScheme: "x-github-client", BundleID: "com.github.GitHubClient"},
+	}
+	assert.NoError(t, ValidateDefaultApps(valid))
+	assert.NoError(t, ValidateDefaultApps(nil))
+
+	invalid := [][]DefaultApp{
+		{{BundleID: "com.microsoft.VSCode"}},
+		{{UTI: "public.json", Extension: "json", BundleID: "com.microsoft.VSCode"}},
+		{{Extension: ".md", BundleID: "com.microsoft.VSCode"}},
+		{{UTI: "json", BundleID: "com.microsoft.VSCode"}},
+		{{URLScheme: "https://", BundleID: "com.google.Chrome"}},
+		{{URLScheme: "https", BundleID: "Chrome"}},
+		{{URLScheme: "https", BundleID: "com.google.Chrome; rm -rf ~"}},
+		{{Extension: "md", BundleID: "a.b"}, {Extension: "MD", BundleID: "c.d"}},
+	}
+	for _, apps := range invalid {
+		assert.Error(t, ValidateDefaultApps(apps), "%+v", apps)
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dock tile types. App and file tiles point at a file, folder tiles at a
+// directory; spacers have no path.
+const (
+	DockTileApp         = "app"
+	DockTileFile        = "file"
+	DockTileFolder      = "folder"
+	DockTileSpacer      = "spacer"
+	DockTileSmallSpacer = "small-spacer"
+	DockTileFlexSpacer  = "flex-spacer"
+)
+
+// DockFolderDisplays, DockFolderViews and DockFolderSorts are the folder
+// tile options, in the order of the Dock's own numbering for them.
+var (
+	DockFolderDisplays = []string{"stack", "folder"}
+	DockFolderViews    = []string{"auto", "fan", "grid", "list"}
+	DockFolderSorts    = []string{"name", "added", "modified", "created", "kind"}
+)
+
+// DockOrientations are the screen edges the Dock can sit on.
+var DockOrientations = []string{"bottom", "left", "right"}
+
+// DockLayout is the whole Dock: the app side, the folder side (persistent
+// others) and its settings. Restoring a layout replaces both sides.
+type DockLayout struct {
+	Apps     []DockTile    `json:"apps"`
+	Others   []DockTile    `json:"others,omitempty"`
+	Settings *DockSettings `json:"settings,omitempty"`
+}
+
+// DockTile is one Dock tile. Path is set for app, file and folder tiles;
+// Display, View and Sort only for folders, where empty means the Dock's
+// default (stack, auto, added).
+type DockTile struct {
+	Type    string `json:"type"`
+	Path    string `json:"path,omitempty"`
+	Display string `json:"display,omitempty"`
+	View    string `json:"view,omitempty"`
+	Sort    string `json:"sort,omitempty"`
+}
+
+// DockSettings are the com.apple.dock preferences a layout carries. Unset
+// fields are left as they are.
+type DockSettings struct {
+	Orientation   string `json:"orientation,omitempty"`
+	Autohide      *bool  `json:"autohide,omitempty"`
+	Magnification *bool  `json:"magnification,omitempty"`
+	TileSize      int    `json:"tile_size,omitempty"`
+	LargeSize     int    `json:"large_size,omitempty"`
+	ShowRecents   *bool  `json:"show_recents,omitempty"`
+}
+
+// AppPaths returns the paths of the app tiles, in order.
+func (l *DockLayout) AppPaths() []string {
+	if l == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(l.Apps))
+	for _, t := range l.Apps {
+		if t.Type == DockTileApp {
+			paths = append(paths, t.Path)
+		}
+	}
+	return paths
+}
+
+// IsSpacer reports whether t is one of the spacer tiles.
+func (t DockTile) IsSpacer() bool {
+	return t.Type == DockTileSpacer || t.Type == DockTileSmallSpacer || t.Type == DockTileFlexSpacer
+}
+
+// Empty reports whether s sets nothing. A nil s is empty.
+func (s *DockSettings) Empty() bool {
+	return s == nil || *s == DockSettings{}
+}
+
+// Validate checks tile types, that path tiles have an absolute or
+// home-relative path, that folder options are known and only on folders,
+// and that settings are in range.
+func (l *DockLayout) Validate() error {
+	if l == nil {
+		return nil
+	}
+	for _, side := range []struct {
+		name  string
+		tiles []DockTile
+	}{{"apps", l.Apps}, {"others", l.Others}} {
+		for _, t := range side.tiles {
+			if err := t.Validate(); err != nil {
+				return fmt.Errorf("dock %s: %w", side.name, err)
+			}
+		}
+	}
+	return l.Settings.Validate()
+}
+
+// Validate checks t; see DockLayout.Validate.
+func (t DockTile) Validate() error {
+	switch t.Type {
+	case DockTileApp, DockTileFile, DockTileFolder:
+		if !strings.HasPrefix(t.Path, "/") && !strings.HasPrefix(t.Path, "~/") {
+			return fmt.Errorf("%s tile %q: path must be absolute or start with ~/", t.Type, t.Path)
+		}
+		if strings.ContainsAny(t.Path, "\n\r\x00") {
+			return fmt.Errorf("%s tile: path must be on one line", t.Type)
+		}
+	case DockTileSpacer, DockTileSmallSpacer, DockTileFlexSpacer:
+		if t.Path != "" {
+			return fmt.Errorf("%s tile: spacers have no path", t.Type)
+		}
+	default:
+		return fmt.Errorf("unknown tile type %q", t.Type)
+	}
+	if t.Type != DockTileFolder {
+		if t.Display != "" || t.View != "" || t.Sort != "" {
+			return fmt.Errorf("%s tile %s: display, view and sort are for folders", t.Type, t.Path)
+		}
+		return nil
+	}
+	for _, opt := range []struct {
+		name, v string
+		allowed []string
+	}{{"display", t.Display, DockFolderDisplays}, {"view", t.View, DockFolderViews}, {"sort", t.Sort, DockFolderSorts}} {
+		if opt.v != "" && !slices.Contains(opt.allowed, opt.v) {
+			return fmt.Errorf("folder tile %s: %s must be one of %s", t.Path, opt.name, strings.Join(opt.allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// Validate checks the orientation is known and the sizes are within the
+// Dock's 16–128 point range.
+func (s *DockSettings) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.Orientation != "" && !slices.Contains(DockOrientations, s.Orientation) {
+		return fmt.Errorf("dock orientation must be one of %s", strings.Join(DockOrientations, ", "))
+	}
+	for _, size := range []struct {
+		name string
+		v    int
+	}{{"tile_size", s.TileSize}, {"large_size", s.LargeSize}} {
+		if size.v != 0 && (size.v < 16 || size.v > 128) {
+			return fmt.Errorf("dock %s %d out of range (16-128)", size.name, size.v)
+		}
+	}
+	return nil
+}
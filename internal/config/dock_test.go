@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockLayout_Validate(t *testing.T) {
+	yes := true
+	valid := &DockLayout{
+		Apps: []DockTile{
+			{Type: DockTileApp, Path: "/Applications/Safari.app"},
+			{Type: DockTileSmallSpacer},
+			{Type: DockTileApp, Path: "~/Applications/Zed.app"},
+		},
+		Others: []DockTile{
+			{Type: DockTileFolder, Path: "~/Downloads", Display: "stack", View: "fan", Sort: "added"},
+			{Type: DockTileFile, Path: "/Users/Shared/notes.txt"},
+		},
+		Settings: &DockSettings{Orientation: "left", Autohide: &yes, TileSize: 48},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, (*DockLayout)(nil).Validate())
+
+	for name, l := range map[string]*DockLayout{
+		"unknown type":      {Apps: []DockTile{{Type: "widget"}}},
+		"relative path":     {Apps: []DockTile{{Type: DockTileApp, Path: "Safari.app"}}},
+		"spacer with path":  {Apps: []DockTile{{Type: DockTileSpacer, Path: "/Applications"}}},
+		"bad view":          {Others: []DockTile{{Type: DockTileFolder, Path: "~/Downloads", View: "carousel"}}},
+		"view on app":       {Apps: []DockTile{{Type: DockTileApp, Path: "/Applications/Mail.app", View: "grid"}}},
+		"bad orientation":   {Settings: &DockSettings{Orientation: "top"}},
+		"tile size too big": {Settings: &DockSettings{TileSize: 512}},
+	} {
+		assert.Error(t, l.Validate(), name)
+	}
+}
+
+func TestDockLayout_AppPaths(t *testing.T) {
+	l := &DockLayout{Apps: []DockTile{
+		{Type: DockTileApp, Path: "/Applications/Safari.app"},
+		{Type: DockTileSpacer},
+		{Type: DockTileApp, Path: "/Applications/Mail.app"},
+	}}
+	assert.Equal(t, []string{"/Applications/Safari.app", "/Applications/Mail.app"}, l.AppPaths())
+	assert.Nil(t, (*DockLayout)(nil).AppPaths())
+}
+
+func TestLoadSnapshotAsRemoteConfig_Dock(t *testing.T) {
+	rc, err := loadSnapshotAsRemoteConfig([]byte(`{
+		"packages": {"formulae": [], "casks": [], "taps": [], "npm": []},
+		"dock": {"apps": [{"type": "app", "path": "/Applications/Safari.app"}, {"type": "spacer"}],
+		         "others": [{"type": "folder", "path": "~/Downloads", "view": "grid"}],
+		         "settings": {"orientation": "bottom", "tile_size": 40}}
+	}`))
+	assert.NoError(t, err)
+	if assert.NotNil(t, rc.Dock) {
+		assert.Len(t, rc.Dock.Apps, 2)
+		assert.Equal(t, "grid", rc.Dock.Others[0].View)
+		assert.Equal(t, 40, rc.Dock.Settings.TileSize)
+	}
+
+	_, err = loadSnapshotAsRemoteConfig([]byte(`{"dock": {"apps": [{"type": "app", "path": "Safari.app"}]}}`))
+	assert.Error(t, err)
+}
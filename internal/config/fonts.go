@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Font is one entry of the fonts section: a Homebrew font cask, or a font
+// archive (.zip) or file (.ttf, .otf, .ttc) downloaded from URL and checked
+// against SHA256 before its fonts are copied to ~/Library/Fonts.
+//
+// Capture also lists fonts it cannot map to a cask, with the Files it
+// found and no URL; those are reported but not installed.
+type Font struct {
+	Cask   string   `json:"cask,omitempty"`   // e.g. "font-jetbrains-mono"
+	Name   string   `json:"name,omitempty"`   // e.g. "Berkeley Mono"
+	URL    string   `json:"url,omitempty"`    // https only
+	SHA256 string   `json:"sha256,omitempty"` // hex digest of the download
+	Files  []string `json:"files,omitempty"`  // captured file names, for fonts without a cask
+}
+
+// Label returns the cask or the name, for messages.
+func (f Font) Label() string {
+	if f.Cask != "" {
+		return f.Cask
+	}
+	return f.Name
+}
+
+// Downloadable reports whether f is installed from its URL.
+func (f Font) Downloadable() bool {
+	return f.Cask == "" && f.URL != ""
+}
+
+// FontFileExtensions are the font file types installed from archives and
+// read by capture.
+var FontFileExtensions = []string{".ttf", ".otf", ".ttc", ".dfont"}
+
+// IsFontFile reports whether name has a font file extension.
+func IsFontFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range FontFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	fontCaskRe = regexp.MustCompile(`^font-[a-z0-9-]+$`)
+	sha256Re   = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+)
+
+// ValidateFonts checks that each entry is either a font-* cask or a named
+// font with an https URL pinned by a sha256 digest (or, as capture writes
+// it, neither), and that no cask or name appears twice.
+func ValidateFonts(fonts []Font) error {
+	seen := make(map[string]bool, len(fonts))
+	for _, f := range fonts {
+		switch {
+		case f.Cask != "":
+			if !fontCaskRe.MatchString(f.Cask) {
+				return fmt.Errorf("font: invalid cask %q (font casks are named font-*)", f.Cask)
+			}
+			if f.URL != "" || f.SHA256 != "" {
+				return fmt.Errorf("font %s: set either cask or url, not both", f.Cask)
+			}
+		case strings.TrimSpace(f.Name) == "":
+			return fmt.Errorf("font: set cask, or name with url and sha256")
+		case f.URL == "" && f.SHA256 == "":
+			// Captured font without a source: listed, not installed.
+		default:
+			u, err := url.Parse(f.URL)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return fmt.Errorf("font %s: url must be https", f.Name)
+			}
+			if !sha256Re.MatchString(f.SHA256) {
+				return fmt.Errorf("font %s: sha256 must be a 64-character hex digest", f.Name)
+			}
+		}
+		for _, file := range f.Files {
+			if file != filepath.Base(file) || !IsFontFile(file) {
+				return fmt.Errorf("font %s: %q is not a font file name", f.Label(), file)
+			}
+		}
+		key := strings.ToLower(f.Label())
+		if seen[key] {
+			return fmt.Errorf("font %s is listed twice", f.Label())
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// FontCaskForFile returns the catalog font cask whose files a font file
+// with this name belongs to, or "" when the catalog does not know it. The
+// longest matching prefix wins, so "JetBrainsMonoNerdFont-Bold.ttf" maps to
+// the Nerd Font cask rather than plain JetBrains Mono.
+func FontCaskForFile(name string) string {
+	categoriesMu.RLock()
+	defer categoriesMu.RUnlock()
+	cask, best := "", 0
+	for _, cat := range Categories {
+		for _, pkg := range cat.Packages {
+			if pkg.Font != "" && len(pkg.Font) > best && strings.HasPrefix(name, pkg.Font) {
+				cask, best = pkg.Name, len(pkg.Font)
+			}
+		}
+	}
+	return cask
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFonts(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	assert.NoError(t, ValidateFonts([]Font{
+		{Cask: "font-jetbrains-mono-nerd-font"},
+		{Name: "Berkeley Mono", URL: "https://example.com/berkeley.zip", SHA256: sum},
+		{Name: "MyFont", Files: []string{"MyFont-Regular.otf"}},
+	}))
+	assert.NoError(t, ValidateFonts(nil))
+
+	for name, f := range map[string]Font{
+		"cask not font-":  {Cask: "iterm2"},
+		"cask and url":    {Cask: "font-inter", URL: "https://example.com/inter.zip", SHA256: sum},
+		"empty":           {},
+		"http url":        {Name: "X", URL: "http://example.com/x.zip", SHA256: sum},
+		"url without sum": {Name: "X", URL: "https://example.com/x.zip"},
+		"short sum":       {Name: "X", URL: "https://example.com/x.zip", SHA256: "abc"},
+		"path in files":   {Name: "X", Files: []string{"../X.ttf"}},
+		"not a font file": {Name: "X", Files: []string{"X.txt"}},
+	} {
+		assert.Error(t, ValidateFonts([]Font{f}), name)
+	}
+	assert.Error(t, ValidateFonts([]Font{{Cask: "font-inter"}, {Cask: "font-inter"}}))
+}
+
+func TestFontCaskForFile(t *testing.T) {
+	assert.Equal(t, "font-jetbrains-mono", FontCaskForFile("JetBrainsMono-Bold.ttf"))
+	assert.Equal(t, "font-jetbrains-mono-nerd-font", FontCaskForFile("JetBrainsMonoNerdFont-Regular.ttf"))
+	assert.Equal(t, "font-fira-code", FontCaskForFile("FiraCode-Retina.ttf"))
+	assert.Empty(t, FontCaskForFile("BerkeleyMono-Regular.otf"))
+}
+
+func TestLoadSnapshotAsRemoteConfig_Fonts(t *testing.T) {
+	rc, err := loadSnapshotAsRemoteConfig([]byte(`{
+		"packages": {"formulae": [], "casks": [], "taps": [], "npm": []},
+		"fonts": [{"cask": "font-inter"}, {"name": "MyFont", "files": ["MyFont-Regular.otf"]}]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Font{{Cask: "font-inter"}, {Name: "MyFont", Files: []string{"MyFont-Regular.otf"}}}, rc.Fonts)
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeyboardGlobalDomain in AppShortcuts holds menu shortcuts for every app.
+const KeyboardGlobalDomain = "NSGlobalDomain"
+
+// RemoteKeyboardConfig is the keyboard section of a config: app menu
+// shortcuts, system shortcut overrides and hidutil key remapping. Nil or
+// empty parts are left as they are.
+type RemoteKeyboardConfig struct {
+	// AppShortcuts maps a bundle ID (or NSGlobalDomain) to menu item titles
+	// and their key equivalents, as stored in NSUserKeyEquivalents: "@"
+	// Command, "~" Option, "^" Control, "$" Shift, then the key, e.g.
+	// {"com.apple.Safari": {"Show All Tabs": "@~t"}}.
+	AppShortcuts map[string]map[string]string `json:"app_shortcuts,omitempty"`
+	// SymbolicHotKeys override entries of com.apple.symbolichotkeys, the
+	// system shortcuts in Keyboard settings (Spotlight, Mission Control,
+	// input sources, screenshots).
+	SymbolicHotKeys []SymbolicHotKey `json:"symbolic_hotkeys,omitempty"`
+	// KeyMappings remap keys with hidutil's UserKeyMapping, persisted
+	// across logins by a LaunchAgent.
+	KeyMappings []KeyMapping `json:"key_mappings,omitempty"`
+}
+
+// SymbolicHotKey is one AppleSymbolicHotKeys entry. ID is the system's
+// number for the shortcut, e.g. 64 for Spotlight search.
+type SymbolicHotKey struct {
+	ID      int  `json:"id"`
+	Enabled bool `json:"enabled"`
+	// Parameters are the character code (65535 for none), the virtual key
+	// code and the modifier mask. Empty keeps the current key and only
+	// sets Enabled.
+	Parameters []int `json:"parameters,omitempty"`
+}
+
+// KeyMapping remaps one key. From and To are key names from KeyUsages, or
+// HID usages written in hex ("0x700000039").
+type KeyMapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// KeyUsages maps the key names a KeyMapping accepts to their HID usage
+// (usage page 7, keyboard).
+var KeyUsages = map[string]uint64{
+	"caps_lock":     0x700000039,
+	"escape":        0x700000029,
+	"return":        0x700000028,
+	"tab":           0x70000002B,
+	"delete":        0x70000002A,
+	"spacebar":      0x70000002C,
+	"grave":         0x700000035,
+	"section":       0x700000064,
+	"left_control":  0x7000000E0,
+	"left_shift":    0x7000000E1,
+	"left_option":   0x7000000E2,
+	"left_command":  0x7000000E3,
+	"right_control": 0x7000000E4,
+	"right_shift":   0x7000000E5,
+	"right_option":  0x7000000E6,
+	"right_command": 0x7000000E7,
+	"f13":           0x700000068,
+	"f14":           0x700000069,
+	"f15":           0x70000006A,
+	"f16":           0x70000006B,
+	"f17":           0x70000006C,
+	"f18":           0x70000006D,
+	"f19":           0x70000006E,
+}
+
+// Empty reports whether k sets nothing. A nil k is empty.
+func (k *RemoteKeyboardConfig) Empty() bool {
+	return k == nil || (len(k.AppShortcuts) == 0 && len(k.SymbolicHotKeys) == 0 && len(k.KeyMappings) == 0)
+}
+
+// Domains returns the AppShortcuts domains in sorted order.
+func (k *RemoteKeyboardConfig) Domains() []string {
+	if k == nil {
+		return nil
+	}
+	domains := make([]string, 0, len(k.AppShortcuts))
+	for d := range k.AppShortcuts {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// KeyUsage returns the HID usage name stands for: a KeyUsages name or a
+// hex usage.
+func KeyUsage(name string) (uint64, error) {
+	if u, ok := KeyUsages[strings.ToLower(name)]; ok {
+		return u, nil
+	}
+	if strings.HasPrefix(name, "0x") || strings.HasPrefix(name, "0X") {
+		if u, err := strconv.ParseUint(name[2:], 16, 64); err == nil && u > 0 {
+			return u, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key %q (use a name like caps_lock or a hex HID usage)", name)
+}
+
+// KeyName returns the KeyUsages name for usage, or its hex form when it
+// has none.
+func KeyName(usage uint64) string {
+	for name, u := range KeyUsages {
+		if u == usage {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%X", usage)
+}
+
+// UserKeyMapping returns the hidutil property JSON for k's key mappings,
+// e.g. {"UserKeyMapping":[{"HIDKeyboardModifierMappingSrc":30064771129,...}]}.
+// An empty list clears the mapping. Validate k first.
+func (k *RemoteKeyboardConfig) UserKeyMapping() string {
+	var entries []string
+	if k != nil {
+		for _, m := range k.KeyMappings {
+			src, _ := KeyUsage(m.From)
+			dst, _ := KeyUsage(m.To)
+			entries = append(entries, fmt.Sprintf(`{"HIDKeyboardModifierMappingSrc":%d,"HIDKeyboardModifierMappingDst":%d}`, src, dst))
+		}
+	}
+	return `{"UserKeyMapping":[` + strings.Join(entries, ",") + `]}`
+}
+
+// Matches reports whether have, the machine's entry for the same ID,
+// already is what h sets. Without Parameters only Enabled is compared.
+func (h SymbolicHotKey) Matches(have SymbolicHotKey) bool {
+	return h.Enabled == have.Enabled && (len(h.Parameters) == 0 || slices.Equal(h.Parameters, have.Parameters))
+}
+
+// KeyMappingsEqual reports whether a and b map the same keys to the same
+// keys, in any order and however the keys are spelled.
+func KeyMappingsEqual(a, b []KeyMapping) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	usages := func(ms []KeyMapping) map[uint64]uint64 {
+		m := make(map[uint64]uint64, len(ms))
+		for _, km := range ms {
+			src, _ := KeyUsage(km.From)
+			dst, _ := KeyUsage(km.To)
+			m[src] = dst
+		}
+		return m
+	}
+	ua, ub := usages(a), usages(b)
+	for src, dst := range ua {
+		if d, ok := ub[src]; !ok || d != dst {
+			return false
+		}
+	}
+	return true
+}
+
+var keyboardDomainRe = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+
+// Validate checks that shortcut domains are bundle IDs, that titles and
+// key equivalents are single lines, that hotkeys are distinct with three
+// parameters, and that mapped keys are known and mapped once.
+func (k *RemoteKeyboardConfig) Validate() error {
+	if k == nil {
+		return nil
+	}
+	for _, d := range k.Domains() {
+		if d != KeyboardGlobalDomain && !keyboardDomainRe.MatchString(d) {
+			return fmt.Errorf("keyboard shortcuts: %q is not a bundle ID", d)
+		}
+		for title, equiv := range k.AppShortcuts[d] {
+			if strings.TrimSpace(title) == "" || strings.ContainsAny(title, "\n\r\x00") {
+				return fmt.Errorf("keyboard shortcuts for %s: menu title must be one non-empty line", d)
+			}
+			if equiv == "" || strings.ContainsAny(equiv, "\n\r\x00") {
+				return fmt.Errorf("keyboard shortcut %s in %s: key equivalent must be one non-empty line", title, d)
+			}
+		}
+	}
+	ids := make(map[int]bool, len(k.SymbolicHotKeys))
+	for _, h := range k.SymbolicHotKeys {
+		if h.ID < 0 {
+			return fmt.Errorf("symbolic hotkey %d: invalid id", h.ID)
+		}
+		if ids[h.ID] {
+			return fmt.Errorf("symbolic hotkey %d is set twice", h.ID)
+		}
+		ids[h.ID] = true
+		if len(h.Parameters) != 0 && len(h.Parameters) != 3 {
+			return fmt.Errorf("symbolic hotkey %d: want 3 parameters (char, key code, modifiers), got %d", h.ID, len(h.Parameters))
+		}
+	}
+	from := make(map[uint64]bool, len(k.KeyMappings))
+	for _, m := range k.KeyMappings {
+		src, err := KeyUsage(m.From)
+		if err != nil {
+			return fmt.Errorf("key mapping: %w", err)
+		}
+		if _, err := KeyUsage(m.To); err != nil {
+			return fmt.Errorf("key mapping: %w", err)
+		}
+		if from[src] {
+			return fmt.Errorf("key mapping: %s is mapped twice", m.From)
+		}
+		from[src] = true
+	}
+	return nil
+}
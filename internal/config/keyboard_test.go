@@ -0,0 +1,82 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyUsage(t *testing.T) {
+	u, err := KeyUsage("Caps_Lock")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x700000039), u)
+
+	u, err = KeyUsage("0x7000000E3")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x7000000E3), u)
+
+	_, err = KeyUsage("hyper")
+	assert.Error(t, err)
+
+	assert.Equal(t, "left_command", KeyName(0x7000000E3))
+	assert.Equal(t, "0xFF00000003", KeyName(0xFF00000003))
+}
+
+func TestRemoteKeyboardConfig_UserKeyMapping(t *testing.T) {
+	k := &RemoteKeyboardConfig{KeyMappings: []KeyMapping{{From: "caps_lock", To: "escape"}}}
+	assert.Equal(t,
+		`{"UserKeyMapping":[{"HIDKeyboardModifierMappingSrc":30064771129,"HIDKeyboardModifierMappingDst":30064771113}]}`,
+		k.UserKeyMapping())
+	assert.Equal(t, `{"UserKeyMapping":[]}`, (&RemoteKeyboardConfig{}).UserKeyMapping())
+}
+
+func TestRemoteKeyboardConfig_Validate(t *testing.T) {
+	valid := &RemoteKeyboardConfig{
+		AppShortcuts: map[string]map[string]string{
+			KeyboardGlobalDomain: {"Minimize": "@m"},
+			"com.apple.Safari":   {"Show All Tabs": "@~t"},
+		},
+		SymbolicHotKeys: []SymbolicHotKey{{ID: 64}, {ID: 65, Enabled: true, Parameters: []int{65535, 49, 1572864}}},
+		KeyMappings:     []KeyMapping{{From: "caps_lock", To: "escape"}},
+	}
+	require.NoError(t, valid.Validate())
+	var nilCfg *RemoteKeyboardConfig
+	require.NoError(t, nilCfg.Validate())
+
+	tests := []struct {
+		name string
+		cfg  RemoteKeyboardConfig
+		want string
+	}{
+		{"bad domain", RemoteKeyboardConfig{AppShortcuts: map[string]map[string]string{"Safari": {"x": "@x"}}}, "not a bundle ID"},
+		{"empty title", RemoteKeyboardConfig{AppShortcuts: map[string]map[string]string{"com.a.b": {" ": "@x"}}}, "menu title"},
+		{"multiline equivalent", RemoteKeyboardConfig{AppShortcuts: map[string]map[string]string{"com.a.b": {"Go": "@\nx"}}}, "key equivalent"},
+		{"duplicate hotkey", RemoteKeyboardConfig{SymbolicHotKeys: []SymbolicHotKey{{ID: 64}, {ID: 64}}}, "set twice"},
+		{"hotkey parameters", RemoteKeyboardConfig{SymbolicHotKeys: []SymbolicHotKey{{ID: 64, Parameters: []int{1}}}}, "want 3 parameters"},
+		{"unknown key", RemoteKeyboardConfig{KeyMappings: []KeyMapping{{From: "hyper", To: "escape"}}}, "unknown key"},
+		{"key mapped twice", RemoteKeyboardConfig{KeyMappings: []KeyMapping{{From: "caps_lock", To: "escape"}, {From: "0x700000039", To: "left_control"}}}, "mapped twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestSymbolicHotKey_Matches(t *testing.T) {
+	have := SymbolicHotKey{ID: 64, Enabled: true, Parameters: []int{32, 49, 1048576}}
+	assert.True(t, SymbolicHotKey{ID: 64, Enabled: true}.Matches(have), "no parameters compares only enabled")
+	assert.False(t, SymbolicHotKey{ID: 64, Enabled: false}.Matches(have))
+	assert.False(t, SymbolicHotKey{ID: 64, Enabled: true, Parameters: []int{32, 49, 524288}}.Matches(have))
+}
+
+func TestKeyMappingsEqual(t *testing.T) {
+	a := []KeyMapping{{From: "caps_lock", To: "escape"}, {From: "right_option", To: "right_control"}}
+	b := []KeyMapping{{From: "0x7000000E6", To: "right_control"}, {From: "caps_lock", To: "0x700000029"}}
+	assert.True(t, KeyMappingsEqual(a, b))
+	assert.False(t, KeyMappingsEqual(a, a[:1]))
+	assert.False(t, KeyMappingsEqual(a[:1], []KeyMapping{{From: "caps_lock", To: "left_control"}}))
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/plist"
+)
+
+// LaunchAgentMarker is the comment openboot writes into the LaunchAgent
+// plists it generates. Capture reads only agents that carry it.
+const LaunchAgentMarker = "Managed by openboot"
+
+// LaunchAgent is a user LaunchAgent in ~/Library/LaunchAgents/<Label>.plist.
+// Paths may start with ~/, which is expanded when the plist is written.
+type LaunchAgent struct {
+	Label string `json:"label"` // e.g. "com.example.colima"
+	// ProgramArguments is the command and its arguments. launchd does not
+	// search PATH, so the command is an absolute path.
+	ProgramArguments []string          `json:"program_arguments"`
+	Environment      map[string]string `json:"environment,omitempty"`
+	RunAtLoad        bool              `json:"run_at_load,omitempty"`
+	// StartInterval runs the agent every so many seconds; 0 = never.
+	StartInterval     int    `json:"start_interval,omitempty"`
+	StandardOutPath   string `json:"stdout_path,omitempty"`
+	StandardErrorPath string `json:"stderr_path,omitempty"`
+}
+
+// File returns the agent's plist file name.
+func (a LaunchAgent) File() string {
+	return a.Label + ".plist"
+}
+
+// Plist renders a as a launchd property list carrying LaunchAgentMarker,
+// with ~/ in paths expanded against home. Environment keys are sorted so
+// the output is stable.
+func (a LaunchAgent) Plist(home string) []byte {
+	expand := func(p string) string {
+		if rest, ok := strings.CutPrefix(p, "~/"); ok {
+			return filepath.Join(home, rest)
+		}
+		return p
+	}
+	esc := func(s string) string {
+		var b bytes.Buffer
+		_ = xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<!-- ` + LaunchAgentMarker + ` -->
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + esc(a.Label) + `</string>
+	<key>ProgramArguments</key>
+	<array>
+`)
+	for i, arg := range a.ProgramArguments {
+		if i == 0 {
+			arg = expand(arg)
+		}
+		sb.WriteString("\t\t<string>" + esc(arg) + "</string>\n")
+	}
+	sb.WriteString("\t</array>\n")
+	if len(a.Environment) > 0 {
+		keys := make([]string, 0, len(a.Environment))
+		for k := range a.Environment {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, k := range keys {
+			sb.WriteString("\t\t<key>" + esc(k) + "</key>\n\t\t<string>" + esc(a.Environment[k]) + "</string>\n")
+		}
+		sb.WriteString("\t</dict>\n")
+	}
+	if a.RunAtLoad {
+		sb.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	}
+	if a.StartInterval > 0 {
+		sb.WriteString("\t<key>StartInterval</key>\n\t<integer>" + strconv.Itoa(a.StartInterval) + "</integer>\n")
+	}
+	if a.StandardOutPath != "" {
+		sb.WriteString("\t<key>StandardOutPath</key>\n\t<string>" + esc(expand(a.StandardOutPath)) + "</string>\n")
+	}
+	if a.StandardErrorPath != "" {
+		sb.WriteString("\t<key>StandardErrorPath</key>\n\t<string>" + esc(expand(a.StandardErrorPath)) + "</string>\n")
+	}
+	sb.WriteString("</dict>\n</plist>\n")
+	return []byte(sb.String())
+}
+
+// ParseLaunchAgent reads back a plist Plist wrote. It returns false when
+// data does not carry LaunchAgentMarker or cannot be read, so hand-written
+// agents are left alone. Paths under home are written as ~/ again.
+func ParseLaunchAgent(data []byte, home string) (LaunchAgent, bool) {
+	if !bytes.Contains(data, []byte("<!-- "+LaunchAgentMarker+" -->")) {
+		return LaunchAgent{}, false
+	}
+	d, err := plist.ParseDict(data)
+	if err != nil {
+		return LaunchAgent{}, false
+	}
+	contract := func(p string) string {
+		if rest, ok := strings.CutPrefix(p, strings.TrimRight(home, "/")+"/"); ok && home != "" {
+			return "~/" + rest
+		}
+		return p
+	}
+
+	a := LaunchAgent{}
+	a.Label, _ = d["Label"].(string)
+	args, _ := d["ProgramArguments"].([]any)
+	for i, v := range args {
+		arg, _ := v.(string)
+		if i == 0 {
+			arg = contract(arg)
+		}
+		a.ProgramArguments = append(a.ProgramArguments, arg)
+	}
+	if env, ok := d["EnvironmentVariables"].(plist.Dict); ok && len(env) > 0 {
+		a.Environment = make(map[string]string, len(env))
+		for k, v := range env {
+			a.Environment[k], _ = v.(string)
+		}
+	}
+	a.RunAtLoad, _ = d["RunAtLoad"].(bool)
+	if v, ok := d["StartInterval"].(string); ok {
+		a.StartInterval, _ = strconv.Atoi(v)
+	}
+	if v, ok := d["StandardOutPath"].(string); ok {
+		a.StandardOutPath = contract(v)
+	}
+	if v, ok := d["StandardErrorPath"].(string); ok {
+		a.StandardErrorPath = contract(v)
+	}
+	if a.Label == "" || len(a.ProgramArguments) == 0 {
+		return LaunchAgent{}, false
+	}
+	return a, true
+}
+
+var launchAgentLabelRe = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)+$`)
+
+// ValidateLaunchAgents checks that each agent has a reverse-DNS label used
+// once, an absolute command, well-formed environment names, a
+// non-negative interval and absolute log paths.
+func ValidateLaunchAgents(agents []LaunchAgent) error {
+	seen := make(map[string]bool, len(agents))
+	for _, a := range agents {
+		if !launchAgentLabelRe.MatchString(a.Label) {
+			return fmt.Errorf("launch agent: invalid label %q (use reverse-DNS, e.g. com.example.sync)", a.Label)
+		}
+		if seen[a.Label] {
+			return fmt.Errorf("launch agent %s is declared twice", a.Label)
+		}
+		seen[a.Label] = true
+		if len(a.ProgramArguments) == 0 {
+			return fmt.Errorf("launch agent %s: program_arguments is empty", a.Label)
+		}
+		if !isAbsOrHome(a.ProgramArguments[0]) {
+			return fmt.Errorf("launch agent %s: program %q must be an absolute path or start with ~/", a.Label, a.ProgramArguments[0])
+		}
+		for _, arg := range a.ProgramArguments {
+			if strings.ContainsRune(arg, 0) {
+				return fmt.Errorf("launch agent %s: argument contains a NUL byte", a.Label)
+			}
+		}
+		for k := range a.Environment {
+			if !envNameRe.MatchString(k) {
+				return fmt.Errorf("launch agent %s: invalid environment variable name %q", a.Label, k)
+			}
+		}
+		if a.StartInterval < 0 {
+			return fmt.Errorf("launch agent %s: start_interval must not be negative", a.Label)
+		}
+		for _, p := range []string{a.StandardOutPath, a.StandardErrorPath} {
+			if p != "" && !isAbsOrHome(p) {
+				return fmt.Errorf("launch agent %s: log path %q must be absolute or start with ~/", a.Label, p)
+			}
+		}
+	}
+	return nil
+}
+
+func isAbsOrHome(p string) bool {
+	return strings.HasPrefix(p, "/") || strings.HasPrefix(p, "~/")
+}
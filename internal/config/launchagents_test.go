@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleAgent = LaunchAgent{
+	Label:             "com.example.sync",
+	ProgramArguments:  []string{"~/bin/sync", "--once", "a&b"},
+	Environment:       map[string]string{"SYNC_DIR": "~/Sync", "LANG": "en_US.UTF-8"},
+	RunAtLoad:         true,
+	StartInterval:     300,
+	StandardOutPath:   "~/Library/Logs/sync.log",
+	StandardErrorPath: "/tmp/sync.err",
+}
+
+func TestLaunchAgent_PlistRoundTrip(t *testing.T) {
+	data := sampleAgent.Plist("/Users/alice")
+	s := string(data)
+	assert.Contains(t, s, "<!-- Managed by openboot -->")
+	assert.Contains(t, s, "<string>/Users/alice/bin/sync</string>")
+	assert.Contains(t, s, "<string>a&amp;b</string>")
+	assert.Contains(t, s, "<string>/Users/alice/Library/Logs/sync.log</string>")
+	assert.Less(t, strings.Index(s, "LANG"), strings.Index(s, "SYNC_DIR"), "environment is sorted")
+
+	got, ok := ParseLaunchAgent(data, "/Users/alice")
+	require.True(t, ok)
+	assert.Equal(t, sampleAgent, got)
+
+	minimal := LaunchAgent{Label: "com.example.colima", ProgramArguments: []string{"/opt/homebrew/bin/colima", "start"}}
+	got, ok = ParseLaunchAgent(minimal.Plist("/Users/alice"), "/Users/alice")
+	require.True(t, ok)
+	assert.Equal(t, minimal, got)
+}
+
+func TestParseLaunchAgent_UnmanagedIgnored(t *testing.T) {
+	unmarked := strings.Replace(string(sampleAgent.Plist("/Users/alice")), "<!-- Managed by openboot -->\n", "", 1)
+	_, ok := ParseLaunchAgent([]byte(unmarked), "/Users/alice")
+	assert.False(t, ok)
+
+	_, ok = ParseLaunchAgent([]byte("<!-- Managed by openboot --> not a plist"), "/Users/alice")
+	assert.False(t, ok)
+}
+
+func TestValidateLaunchAgents(t *testing.T) {
+	assert.NoError(t, ValidateLaunchAgents([]LaunchAgent{sampleAgent}))
+	assert.NoError(t, ValidateLaunchAgents(nil))
+
+	for name, a := range map[string]LaunchAgent{
+		"bad label":         {Label: "sync", ProgramArguments: []string{"/bin/true"}},
+		"no program":        {Label: "com.example.sync"},
+		"relative program":  {Label: "com.example.sync", ProgramArguments: []string{"colima", "start"}},
+		"bad env name":      {Label: "com.example.sync", ProgramArguments: []string{"/bin/true"}, Environment: map[string]string{"A-B": "x"}},
+		"negative interval": {Label: "com.example.sync", ProgramArguments: []string{"/bin/true"}, StartInterval: -1},
+		"relative log":      {Label: "com.example.sync", ProgramArguments: []string{"/bin/true"}, StandardOutPath: "sync.log"},
+	} {
+		assert.Error(t, ValidateLaunchAgents([]LaunchAgent{a}), name)
+	}
+	assert.Error(t, ValidateLaunchAgents([]LaunchAgent{sampleAgent, sampleAgent}), "duplicate label")
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MachineUserPlaceholder in a machine name is replaced by the login name of
+// the user applying the config.
+const MachineUserPlaceholder = "{{user}}"
+
+// RemoteMachineConfig names the machine. Each field is a template that may
+// contain {{user}}; empty fields are left as they are. They map to the
+// scutil preferences of the same name: ComputerName is the name shown in
+// Finder and sharing, LocalHostName the Bonjour name (<name>.local) and
+// HostName the name the shell and fleet tooling report.
+type RemoteMachineConfig struct {
+	ComputerName  string `json:"computer_name,omitempty"`
+	LocalHostName string `json:"local_host_name,omitempty"`
+	HostName      string `json:"host_name,omitempty"`
+}
+
+// MachineName is one scutil name and its value.
+type MachineName struct {
+	Key   string // ComputerName, LocalHostName or HostName
+	Value string
+}
+
+// Empty reports whether m names nothing. A nil m is empty.
+func (m *RemoteMachineConfig) Empty() bool {
+	return m == nil || (m.ComputerName == "" && m.LocalHostName == "" && m.HostName == "")
+}
+
+// Names returns the names m sets, in the order scutil should apply them.
+func (m *RemoteMachineConfig) Names() []MachineName {
+	if m == nil {
+		return nil
+	}
+	var names []MachineName
+	for _, n := range []MachineName{
+		{"ComputerName", m.ComputerName},
+		{"LocalHostName", m.LocalHostName},
+		{"HostName", m.HostName},
+	} {
+		if n.Value != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// Resolve returns m with {{user}} replaced by user. In LocalHostName and
+// HostName the user is first reduced to a DNS label: lowercased, with
+// anything but letters, digits and hyphens turned into hyphens.
+func (m *RemoteMachineConfig) Resolve(user string) *RemoteMachineConfig {
+	if m == nil {
+		return nil
+	}
+	label := hostLabelUser(user)
+	return &RemoteMachineConfig{
+		ComputerName:  strings.ReplaceAll(m.ComputerName, MachineUserPlaceholder, user),
+		LocalHostName: strings.ReplaceAll(m.LocalHostName, MachineUserPlaceholder, label),
+		HostName:      strings.ReplaceAll(m.HostName, MachineUserPlaceholder, label),
+	}
+}
+
+func hostLabelUser(user string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(user) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('-')
+		}
+	}
+	return strings.Trim(sb.String(), "-")
+}
+
+var (
+	machinePlaceholderRe = regexp.MustCompile(`\{\{[^}]*\}\}`)
+	localHostNameRe      = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+	hostNameRe           = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+)
+
+// maxComputerNameLen is the longest ComputerName the Sharing pane accepts.
+const maxComputerNameLen = 63
+
+// Validate checks that every template uses only {{user}}, and that with a
+// sample user substituted ComputerName is short printable text,
+// LocalHostName a single DNS label and HostName a DNS name.
+func (m *RemoteMachineConfig) Validate() error {
+	if m == nil {
+		return nil
+	}
+	for _, n := range m.Names() {
+		for _, p := range machinePlaceholderRe.FindAllString(n.Value, -1) {
+			if p != MachineUserPlaceholder {
+				return fmt.Errorf("machine %s: unknown placeholder %s (only %s is supported)", n.Key, p, MachineUserPlaceholder)
+			}
+		}
+	}
+	r := m.Resolve("user")
+	if len(r.ComputerName) > maxComputerNameLen {
+		return fmt.Errorf("machine ComputerName too long (max %d characters)", maxComputerNameLen)
+	}
+	for _, c := range r.ComputerName {
+		if unicode.IsControl(c) {
+			return fmt.Errorf("machine ComputerName must be printable text")
+		}
+	}
+	if r.LocalHostName != "" && !localHostNameRe.MatchString(r.LocalHostName) {
+		return fmt.Errorf("machine LocalHostName %q must be letters, digits and hyphens", m.LocalHostName)
+	}
+	if r.HostName != "" && (len(r.HostName) > 253 || !hostNameRe.MatchString(r.HostName)) {
+		return fmt.Errorf("machine HostName %q must be a DNS name", m.HostName)
+	}
+	return nil
+}
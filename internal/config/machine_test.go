@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteMachineConfigResolve(t *testing.T) {
+	m := &RemoteMachineConfig{ComputerName: "{{user}}'s MacBook", LocalHostName: "{{user}}-mbp", HostName: "{{user}}-mbp.corp.example.com"}
+
+	got := m.Resolve("Jane.Doe")
+	assert.Equal(t, "Jane.Doe's MacBook", got.ComputerName)
+	assert.Equal(t, "jane-doe-mbp", got.LocalHostName, "user reduced to a DNS label")
+	assert.Equal(t, "jane-doe-mbp.corp.example.com", got.HostName)
+	assert.Equal(t, []MachineName{
+		{"ComputerName", "Jane.Doe's MacBook"},
+		{"LocalHostName", "jane-doe-mbp"},
+		{"HostName", "jane-doe-mbp.corp.example.com"},
+	}, got.Names())
+
+	assert.Equal(t, []MachineName{{"HostName", "build-01"}}, (&RemoteMachineConfig{HostName: "build-01"}).Names())
+	assert.Nil(t, (*RemoteMachineConfig)(nil).Resolve("jane"))
+}
+
+func TestRemoteMachineConfigValidate(t *testing.T) {
+	valid := []*RemoteMachineConfig{
+		nil,
+		{ComputerName: "Jane’s MacBook Pro"},
+		{LocalHostName: "{{user}}-mbp", HostName: "{{user}}-mbp.local"},
+	}
+	for _, m := range valid {
+		assert.NoError(t, m.Validate(), "%+v", m)
+	}
+
+	invalid := []*RemoteMachineConfig{
+		{ComputerName: "{{serial}}"},
+		{ComputerName: "line\nbreak"},
+		{ComputerName: "a very long computer name that goes on and on well past sixty-three chars"},
+		{LocalHostName: "jane mbp"},
+		{LocalHostName: "jane.mbp"},
+		{LocalHostName: "-mbp"},
+		{HostName: "bad_host"},
+		{HostName: "trailing.dot."},
+	}
+	for _, m := range invalid {
+		assert.Error(t, m.Validate(), "%+v", m)
+	}
+}
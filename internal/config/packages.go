@@ -15,6 +15,9 @@ type Package struct {
 	Description string `yaml:"desc"`
 	IsCask      bool   `yaml:"cask"`
 	IsNpm       bool   `yaml:"npm"`
+	// Font is the file name prefix of a font cask's files in
+	// ~/Library/Fonts, e.g. "JetBrainsMono"; see FontCaskForFile.
+	Font string `yaml:"font"`
 }
 
 type Category struct {
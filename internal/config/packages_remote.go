@@ -165,6 +165,7 @@ var categoryMap = map[string]struct {
 	"development":  {Name: "Development", Icon: "🛠"},
 	"productivity": {Name: "Productivity", Icon: "🚀"},
 	"optional":     {Name: "Optional", Icon: "📦"},
+	"fonts":        {Name: "Fonts", Icon: "🔤"},
 }
 
 // mergeRemotePackages converts remote packages into Categories format and
@@ -210,6 +211,8 @@ func mergeRemotePackages(pkgs []remotePackage) {
 			catIndex["productivity"] = i
 		case "NPM Global":
 			catIndex["development"] = i // npm goes with development
+		case "Fonts":
+			catIndex["fonts"] = i
 		}
 	}
 
@@ -15,7 +15,11 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/openbootdotdev/openboot/internal/envelope"
 	"github.com/openbootdotdev/openboot/internal/httputil"
+	"github.com/openbootdotdev/openboot/internal/redact"
+	"github.com/openbootdotdev/openboot/internal/signing"
+	"github.com/openbootdotdev/openboot/internal/snapshot/schema"
 	"github.com/openbootdotdev/openboot/internal/system"
 )
 
@@ -44,6 +48,26 @@ var clientVersion = "dev"
 // SetClientVersion sets the version string sent in X-OpenBoot-Version headers.
 func SetClientVersion(v string) { clientVersion = v }
 
+// redactedPrompt asks for a field a published snapshot had redacted. The
+// CLI sets it via SetRedactedPrompt; without it redacted fields are
+// cleared, so a placeholder is never applied as a value.
+var redactedPrompt = func(path, value string) (string, error) { return "", nil }
+
+// SetRedactedPrompt sets the prompt for redacted fields in loaded configs.
+func SetRedactedPrompt(fn func(path, value string) (string, error)) { redactedPrompt = fn }
+
+// CheckSignature verifies data's signature, if it has one. A signature
+// that does not match its content is an error; whether an unsigned config
+// or an untrusted key is acceptable is the caller's policy, applied to the
+// result with signing.Policy.Accept.
+func CheckSignature(data []byte) (signing.Result, error) {
+	res, err := signing.Inspect(data)
+	if err != nil {
+		return signing.Result{}, fmt.Errorf("signature check: %w", err)
+	}
+	return res, nil
+}
+
 // versionTransport wraps http.DefaultTransport to inject the version header.
 type versionTransport struct{ base http.RoundTripper }
 
@@ -128,6 +152,9 @@ func LoadRemoteConfigFromFile(path string) (*RemoteConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read config file: %w", err)
 	}
+	if data, err = envelope.Unseal(data); err != nil {
+		return nil, fmt.Errorf("decrypt config file: %w", err)
+	}
 
 	// Detect format: snapshot files have "captured_at" and nested "packages".
 	var probe struct {
@@ -161,37 +188,70 @@ type snapshotFile struct {
 		Taps     []string         `json:"taps"`
 		Npm      PackageEntryList `json:"npm"`
 	} `json:"packages"`
-	Shell struct {
-		OhMyZsh bool     `json:"oh_my_zsh"`
-		Theme   string   `json:"theme"`
-		Plugins []string `json:"plugins"`
-	} `json:"shell"`
-	MacOSPrefs []RemoteMacOSPref `json:"macos_prefs"`
+	Shell        RemoteShellConfig     `json:"shell"`
+	Git          RemoteGitConfig       `json:"git"`
+	SSH          *RemoteSSHConfig      `json:"ssh"`
+	DefaultApps  []DefaultApp          `json:"default_apps"`
+	Keyboard     *RemoteKeyboardConfig `json:"keyboard"`
+	Dock         *DockLayout           `json:"dock"`
+	LaunchAgents []LaunchAgent         `json:"launch_agents"`
+	Fonts        []Font                `json:"fonts"`
+	MacOSPrefs   []RemoteMacOSPref     `json:"macos_prefs"`
+	AppSettings  *AppSettings          `json:"app_settings"`
+	Services     []string              `json:"services"`
 }
 
 func loadSnapshotAsRemoteConfig(data []byte) (*RemoteConfig, error) {
+	sig, err := CheckSignature(data)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err = schema.Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+	data, err = redact.RestoreJSON(data, redactedPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("restore redacted fields: %w", err)
+	}
 	var snap snapshotFile
 	if err := json.Unmarshal(data, &snap); err != nil {
 		return nil, fmt.Errorf("parse snapshot file: %w", err)
 	}
 
 	rc := &RemoteConfig{
-		Packages:   snap.Packages.Formulae,
-		Casks:      snap.Packages.Casks,
-		Taps:       snap.Packages.Taps,
-		Npm:        snap.Packages.Npm,
-		MacOSPrefs: snap.MacOSPrefs,
-	}
-	if snap.Shell.OhMyZsh {
-		rc.Shell = &RemoteShellConfig{
-			OhMyZsh: true,
-			Theme:   snap.Shell.Theme,
-			Plugins: snap.Shell.Plugins,
-		}
+		Packages:     snap.Packages.Formulae,
+		Casks:        snap.Packages.Casks,
+		Taps:         snap.Packages.Taps,
+		Npm:          snap.Packages.Npm,
+		MacOSPrefs:   snap.MacOSPrefs,
+		DefaultApps:  snap.DefaultApps,
+		LaunchAgents: snap.LaunchAgents,
+		Fonts:        snap.Fonts,
+		Services:     snap.Services,
+	}
+	if snap.Shell.Managed() || !snap.Shell.Snippets.Empty() {
+		shell := snap.Shell
+		rc.Shell = &shell
+	}
+	if !snap.Git.Empty() {
+		git := snap.Git
+		rc.Git = &git
+	}
+	if !snap.SSH.Empty() {
+		rc.SSH = snap.SSH
+	}
+	if !snap.Keyboard.Empty() {
+		rc.Keyboard = snap.Keyboard
+	}
+	rc.Dock = snap.Dock
+	if !snap.AppSettings.Empty() {
+		rc.AppSettings = snap.AppSettings
 	}
 	if err := rc.Validate(); err != nil {
 		return nil, fmt.Errorf("snapshot contains invalid data: %w", err)
 	}
+	rc.Signature = sig
 	// Note: snapshot files do not contain dotfiles_repo or post_install.
 	// Those fields must be set manually on openboot.dev after upload.
 	return rc, nil
@@ -272,13 +332,24 @@ func decodeRemoteConfig(r io.Reader) (*RemoteConfig, error) {
 
 // UnmarshalRemoteConfigFlexible parses JSON into a RemoteConfig, accepting
 // packages in either flat string array format (["git","curl"]) or typed
-// object array format ([{"name":"git","type":"formula"}]).
+// object array format ([{"name":"git","type":"formula"}]). The signature
+// is checked before redacted fields are restored, since it covers them as
+// published.
 func UnmarshalRemoteConfigFlexible(data []byte) (*RemoteConfig, error) {
+	sig, err := CheckSignature(data)
+	if err != nil {
+		return nil, err
+	}
+	data, err = redact.RestoreJSON(data, redactedPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("restore redacted fields: %w", err)
+	}
 	// Try direct unmarshal first (flat string arrays).
 	var rc RemoteConfig
 	if err := json.Unmarshal(data, &rc); err == nil {
 		normalizeRemoteConfig(&rc)
 		backfillMacOSPrefsFromSnapshot(&rc, data)
+		rc.Signature = sig
 		return &rc, nil
 	}
 
@@ -346,6 +417,7 @@ func UnmarshalRemoteConfigFlexible(data []byte) (*RemoteConfig, error) {
 	}
 	normalizeRemoteConfig(&result)
 	backfillMacOSPrefsFromSnapshot(&result, data)
+	result.Signature = sig
 	return &result, nil
 }
 
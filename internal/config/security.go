@@ -0,0 +1,40 @@
+package config
+
+// Security hardening controls a config can turn on. Each key is a field of
+// RemoteSecurityConfig; internal/doctor holds the check and apply side.
+const (
+	SecurityTouchIDSudo = "touch_id_sudo"
+	SecurityFirewall    = "firewall"
+	SecurityStealthMode = "stealth_mode"
+)
+
+// RemoteSecurityConfig opts in to hardening steps, one switch each. A false
+// switch leaves the control as it is; openboot never turns one off.
+type RemoteSecurityConfig struct {
+	// TouchIDSudo enables pam_tid.so in /etc/pam.d/sudo_local, which
+	// survives macOS updates (macOS 14+).
+	TouchIDSudo bool `json:"touch_id_sudo,omitempty"`
+	// Firewall turns on the application firewall.
+	Firewall bool `json:"firewall,omitempty"`
+	// StealthMode stops the machine answering pings and probes of closed
+	// ports.
+	StealthMode bool `json:"stealth_mode,omitempty"`
+}
+
+// Enabled returns the keys of the controls s turns on, in apply order.
+func (s *RemoteSecurityConfig) Enabled() []string {
+	if s == nil {
+		return nil
+	}
+	var keys []string
+	if s.TouchIDSudo {
+		keys = append(keys, SecurityTouchIDSudo)
+	}
+	if s.Firewall {
+		keys = append(keys, SecurityFirewall)
+	}
+	if s.StealthMode {
+		keys = append(keys, SecurityStealthMode)
+	}
+	return keys
+}
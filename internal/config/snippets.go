@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SnippetsBlockName names the managed rc-file block that holds shell
+// snippets. internal/shell writes it; internal/snapshot reads it back.
+const SnippetsBlockName = "Snippets"
+
+// ManagedBlockMarkers returns the comment lines that fence the OpenBoot
+// block called name in an rc file. The same markers work in zsh, bash and
+// fish.
+func ManagedBlockMarkers(name string) (start, end string) {
+	return "# >>> OpenBoot-" + name, "# <<< OpenBoot-" + name
+}
+
+// ShellEnvVar is an exported environment variable. Value is double-quoted
+// when rendered, so $VAR references expand; command substitution is refused
+// by Validate.
+type ShellEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ShellAlias is a shell alias. Command is single-quoted when rendered and
+// runs exactly as written.
+type ShellAlias struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// ShellSnippets are small pieces of shell setup a config can carry without
+// a dotfiles repo. They are rendered into one managed block in the rc file
+// of the config's shell, in a fixed order: env vars, aliases, then PATH.
+type ShellSnippets struct {
+	Env     []ShellEnvVar `json:"env,omitempty"`
+	Aliases []ShellAlias  `json:"aliases,omitempty"`
+	// Path entries are prepended to PATH, first entry first.
+	Path []string `json:"path,omitempty"`
+}
+
+// Empty reports whether s sets nothing. A nil s is empty.
+func (s *ShellSnippets) Empty() bool {
+	return s == nil || (len(s.Env) == 0 && len(s.Aliases) == 0 && len(s.Path) == 0)
+}
+
+// Equal reports whether s and o render the same block. Nil and empty are
+// equal.
+func (s *ShellSnippets) Equal(o *ShellSnippets) bool {
+	if s.Empty() || o.Empty() {
+		return s.Empty() && o.Empty()
+	}
+	if len(s.Env) != len(o.Env) || len(s.Aliases) != len(o.Aliases) || len(s.Path) != len(o.Path) {
+		return false
+	}
+	for i := range s.Env {
+		if s.Env[i] != o.Env[i] {
+			return false
+		}
+	}
+	for i := range s.Aliases {
+		if s.Aliases[i] != o.Aliases[i] {
+			return false
+		}
+	}
+	for i := range s.Path {
+		if s.Path[i] != o.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary describes s in a few words for diff output, e.g.
+// "2 env var(s), 1 alias(es), 1 PATH entry(ies)", or "(none)".
+func (s *ShellSnippets) Summary() string {
+	if s.Empty() {
+		return "(none)"
+	}
+	var parts []string
+	if n := len(s.Env); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d env var(s)", n))
+	}
+	if n := len(s.Aliases); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d alias(es)", n))
+	}
+	if n := len(s.Path); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d PATH entry(ies)", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Render returns the block body for sh, one line per snippet. Validate s
+// first; Render does not re-check it.
+func (s *ShellSnippets) Render(sh string) string {
+	if s.Empty() {
+		return ""
+	}
+	var sb strings.Builder
+	fish := sh == ShellFish
+	for _, e := range s.Env {
+		if fish {
+			fmt.Fprintf(&sb, "set -gx %s \"%s\"\n", e.Name, escapeDoubleQuoted(e.Value))
+		} else {
+			fmt.Fprintf(&sb, "export %s=\"%s\"\n", e.Name, escapeDoubleQuoted(e.Value))
+		}
+	}
+	for _, a := range s.Aliases {
+		if fish {
+			cmd := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(a.Command)
+			fmt.Fprintf(&sb, "alias %s '%s'\n", a.Name, cmd)
+		} else {
+			fmt.Fprintf(&sb, "alias %s='%s'\n", a.Name, strings.ReplaceAll(a.Command, `'`, `'\''`))
+		}
+	}
+	if len(s.Path) > 0 {
+		if fish {
+			fmt.Fprintf(&sb, "set -gx PATH \"%s\" $PATH\n", strings.Join(s.Path, `" "`))
+		} else {
+			fmt.Fprintf(&sb, "export PATH=\"%s:$PATH\"\n", strings.Join(s.Path, ":"))
+		}
+	}
+	return sb.String()
+}
+
+var (
+	snippetPosixPathRe  = regexp.MustCompile(`^export PATH="(.*):\$PATH"$`)
+	snippetPosixEnvRe   = regexp.MustCompile(`^export ([A-Za-z_][A-Za-z0-9_]*)="(.*)"$`)
+	snippetPosixAliasRe = regexp.MustCompile(`^alias ([^=\s]+)='(.*)'$`)
+	snippetFishPathRe   = regexp.MustCompile(`^set -gx PATH ((?:"[^"]*" )+)\$PATH$`)
+	snippetFishEnvRe    = regexp.MustCompile(`^set -gx ([A-Za-z_][A-Za-z0-9_]*) "(.*)"$`)
+	snippetFishAliasRe  = regexp.MustCompile(`^alias (\S+) '(.*)'$`)
+	snippetQuotedRe     = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// ParseShellSnippets reads back the snippets in rc, the contents of sh's rc
+// file. It returns nil when rc has no snippets block. Lines in the block
+// that Render would not have produced are ignored.
+func ParseShellSnippets(sh, rc string) *ShellSnippets {
+	start, end := ManagedBlockMarkers(SnippetsBlockName)
+	i := strings.Index(rc, start+"\n")
+	if i < 0 {
+		return nil
+	}
+	body := rc[i+len(start)+1:]
+	j := strings.Index(body, end)
+	if j < 0 {
+		return nil
+	}
+
+	s := &ShellSnippets{}
+	fish := sh == ShellFish
+	for _, line := range strings.Split(body[:j], "\n") {
+		line = strings.TrimSpace(line)
+		if fish {
+			switch {
+			case snippetFishPathRe.MatchString(line):
+				for _, m := range snippetQuotedRe.FindAllStringSubmatch(snippetFishPathRe.FindStringSubmatch(line)[1], -1) {
+					s.Path = append(s.Path, m[1])
+				}
+			case snippetFishEnvRe.MatchString(line):
+				m := snippetFishEnvRe.FindStringSubmatch(line)
+				s.Env = append(s.Env, ShellEnvVar{Name: m[1], Value: unescapeBackslashes(m[2])})
+			case snippetFishAliasRe.MatchString(line):
+				m := snippetFishAliasRe.FindStringSubmatch(line)
+				s.Aliases = append(s.Aliases, ShellAlias{Name: m[1], Command: unescapeBackslashes(m[2])})
+			}
+			continue
+		}
+		switch {
+		case snippetPosixPathRe.MatchString(line):
+			s.Path = append(s.Path, strings.Split(snippetPosixPathRe.FindStringSubmatch(line)[1], ":")...)
+		case snippetPosixEnvRe.MatchString(line):
+			m := snippetPosixEnvRe.FindStringSubmatch(line)
+			s.Env = append(s.Env, ShellEnvVar{Name: m[1], Value: unescapeBackslashes(m[2])})
+		case snippetPosixAliasRe.MatchString(line):
+			m := snippetPosixAliasRe.FindStringSubmatch(line)
+			s.Aliases = append(s.Aliases, ShellAlias{Name: m[1], Command: strings.ReplaceAll(m[2], `'\''`, `'`)})
+		}
+	}
+	return s
+}
+
+// escapeDoubleQuoted escapes the characters that end or alter a
+// double-quoted string in zsh, bash and fish, leaving $VAR expansion intact.
+func escapeDoubleQuoted(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v)
+}
+
+// unescapeBackslashes undoes escapeDoubleQuoted and fish's single-quote
+// escaping: a backslash followed by any character yields that character.
+func unescapeBackslashes(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var sb strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+1 < len(v) {
+			i++
+		}
+		sb.WriteByte(v[i])
+	}
+	return sb.String()
+}
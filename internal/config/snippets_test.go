@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleSnippets() *ShellSnippets {
+	return &ShellSnippets{
+		Env: []ShellEnvVar{
+			{Name: "GOPRIVATE", Value: "github.com/acme/*"},
+			{Name: "GREETING", Value: `say "hi" \ bye`},
+		},
+		Aliases: []ShellAlias{
+			{Name: "k", Command: "kubectl"},
+			{Name: "gl", Command: `git log --format='%h %s'`},
+		},
+		Path: []string{"$HOME/go/bin", "/opt/acme/bin"},
+	}
+}
+
+func TestShellSnippetsRender(t *testing.T) {
+	s := sampleSnippets()
+
+	assert.Equal(t, `export GOPRIVATE="github.com/acme/*"
+export GREETING="say \"hi\" \\ bye"
+alias k='kubectl'
+alias gl='git log --format='\''%h %s'\'''
+export PATH="$HOME/go/bin:/opt/acme/bin:$PATH"
+`, s.Render(ShellZsh))
+	assert.Equal(t, s.Render(ShellZsh), s.Render(ShellBash))
+
+	assert.Equal(t, `set -gx GOPRIVATE "github.com/acme/*"
+set -gx GREETING "say \"hi\" \\ bye"
+alias k 'kubectl'
+alias gl 'git log --format=\'%h %s\''
+set -gx PATH "$HOME/go/bin" "/opt/acme/bin" $PATH
+`, s.Render(ShellFish))
+
+	assert.Empty(t, (*ShellSnippets)(nil).Render(ShellZsh))
+}
+
+func TestParseShellSnippetsRoundTrip(t *testing.T) {
+	for _, sh := range []string{ShellZsh, ShellBash, ShellFish} {
+		t.Run(sh, func(t *testing.T) {
+			start, end := ManagedBlockMarkers(SnippetsBlockName)
+			rc := "export EDITOR=vim\n" + start + "\n" + sampleSnippets().Render(sh) + end + "\nalias ll='ls -l'\n"
+
+			got := ParseShellSnippets(sh, rc)
+			require.NotNil(t, got)
+			assert.Equal(t, sampleSnippets(), got)
+		})
+	}
+}
+
+func TestParseShellSnippetsNoBlock(t *testing.T) {
+	assert.Nil(t, ParseShellSnippets(ShellZsh, "export A=1\n"))
+	start, _ := ManagedBlockMarkers(SnippetsBlockName)
+	assert.Nil(t, ParseShellSnippets(ShellZsh, start+"\nexport A=\"1\"\n"), "unterminated block")
+}
+
+func TestShellSnippetsEqual(t *testing.T) {
+	var none *ShellSnippets
+	assert.True(t, none.Equal(&ShellSnippets{}))
+	assert.True(t, sampleSnippets().Equal(sampleSnippets()))
+
+	other := sampleSnippets()
+	other.Path = other.Path[:1]
+	assert.False(t, sampleSnippets().Equal(other))
+	assert.False(t, sampleSnippets().Equal(nil))
+
+	assert.Equal(t, "(none)", none.Summary())
+	assert.Equal(t, "2 env var(s), 2 alias(es), 2 PATH entry(ies)", sampleSnippets().Summary())
+}
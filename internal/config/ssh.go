@@ -0,0 +1,352 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SSHBlockName names the managed block in ~/.ssh/config. internal/sshconfig
+// writes it; internal/snapshot reads it back.
+const SSHBlockName = "SSH"
+
+// sshKeysMarker heads the Host * section that loads the declared keys into
+// the agent, so ParseSSHBlock can tell it from a user-declared Host *.
+const sshKeysMarker = "# Keys, added to the agent and the macOS keychain"
+
+// RemoteSSHConfig is the ssh section of a config: keys to generate, Host
+// blocks for ~/.ssh/config and known_hosts lines to pin. It never carries
+// key material beyond public host keys.
+type RemoteSSHConfig struct {
+	Keys  []SSHKey  `json:"keys,omitempty"`
+	Hosts []SSHHost `json:"hosts,omitempty"`
+	// KnownHosts are known_hosts lines ("<host> <type> <base64 key>") added
+	// to ~/.ssh/known_hosts, e.g. github.com's published keys.
+	KnownHosts []string `json:"known_hosts,omitempty"`
+}
+
+// SSHKey is an ed25519 key pair under ~/.ssh, generated when missing.
+type SSHKey struct {
+	// Name is the private key's file name under ~/.ssh, e.g. "id_ed25519".
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+	// Passphrase asks for a passphrase when the key is generated. Without
+	// it the key is generated unencrypted.
+	Passphrase bool `json:"passphrase,omitempty"`
+}
+
+// SSHHost is one Host block in ~/.ssh/config. Options holds any further
+// keywords, rendered in sorted order after the named fields.
+type SSHHost struct {
+	Host         string            `json:"host"`
+	HostName     string            `json:"hostname,omitempty"`
+	User         string            `json:"user,omitempty"`
+	Port         int               `json:"port,omitempty"`
+	IdentityFile string            `json:"identity_file,omitempty"`
+	Options      map[string]string `json:"options,omitempty"`
+}
+
+// Empty reports whether s declares nothing. A nil s is empty.
+func (s *RemoteSSHConfig) Empty() bool {
+	return s == nil || (len(s.Keys) == 0 && len(s.Hosts) == 0 && len(s.KnownHosts) == 0)
+}
+
+// KeyPath returns where k's private key lives, relative to the home
+// directory.
+func (k SSHKey) KeyPath() string {
+	return path.Join(".ssh", k.Name)
+}
+
+// Render returns the ~/.ssh/config block body: the Host blocks in order,
+// then a Host * section that adds the declared keys to the agent and the
+// macOS keychain. It is empty when there are no hosts or keys. Validate s
+// first; Render does not re-check it.
+func (s *RemoteSSHConfig) Render() string {
+	if s == nil {
+		return ""
+	}
+	var sections []string
+	for _, h := range s.Hosts {
+		sections = append(sections, h.render())
+	}
+	if len(s.Keys) > 0 {
+		var sb strings.Builder
+		sb.WriteString(sshKeysMarker + "\n")
+		sb.WriteString("Host *\n")
+		// UseKeychain is Apple-only; IgnoreUnknown keeps other ssh builds
+		// from rejecting the file.
+		sb.WriteString("  IgnoreUnknown UseKeychain\n")
+		sb.WriteString("  AddKeysToAgent yes\n")
+		sb.WriteString("  UseKeychain yes\n")
+		for _, k := range s.Keys {
+			fmt.Fprintf(&sb, "  IdentityFile ~/%s\n", k.KeyPath())
+		}
+		sections = append(sections, sb.String())
+	}
+	return strings.Join(sections, "\n")
+}
+
+func (h SSHHost) render() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Host %s\n", h.Host)
+	if h.HostName != "" {
+		fmt.Fprintf(&sb, "  HostName %s\n", h.HostName)
+	}
+	if h.User != "" {
+		fmt.Fprintf(&sb, "  User %s\n", h.User)
+	}
+	if h.Port != 0 {
+		fmt.Fprintf(&sb, "  Port %d\n", h.Port)
+	}
+	if h.IdentityFile != "" {
+		fmt.Fprintf(&sb, "  IdentityFile %s\n", h.IdentityFile)
+	}
+	keys := make([]string, 0, len(h.Options))
+	for k := range h.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "  %s %s\n", k, h.Options[k])
+	}
+	return sb.String()
+}
+
+// ParseSSHBlock reads back the hosts and keys in the managed block of
+// content, the contents of ~/.ssh/config. It returns nil when there is no
+// block. Key comments and passphrase settings are not in the file, so
+// parsed keys have neither.
+func ParseSSHBlock(content string) *RemoteSSHConfig {
+	start, end := ManagedBlockMarkers(SSHBlockName)
+	i := strings.Index(content, start+"\n")
+	if i < 0 {
+		return nil
+	}
+	body := content[i+len(start)+1:]
+	j := strings.Index(body, end)
+	if j < 0 {
+		return nil
+	}
+
+	s := &RemoteSSHConfig{}
+	var host *SSHHost
+	inKeys, nextIsKeys := false, false
+	flush := func() {
+		if host != nil {
+			s.Hosts = append(s.Hosts, *host)
+			host = nil
+		}
+	}
+	for _, line := range strings.Split(body[:j], "\n") {
+		line = strings.TrimSpace(line)
+		if line == sshKeysMarker {
+			nextIsKeys = true
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kw, val, _ := strings.Cut(line, " ")
+		val = strings.TrimSpace(val)
+		if strings.EqualFold(kw, "Host") {
+			flush()
+			inKeys, nextIsKeys = nextIsKeys, false
+			if !inKeys {
+				host = &SSHHost{Host: val}
+			}
+			continue
+		}
+		switch {
+		case inKeys:
+			if strings.EqualFold(kw, "IdentityFile") && strings.HasPrefix(val, "~/.ssh/") {
+				s.Keys = append(s.Keys, SSHKey{Name: strings.TrimPrefix(val, "~/.ssh/")})
+			}
+		case host != nil:
+			host.set(kw, val)
+		}
+	}
+	flush()
+	return s
+}
+
+func (h *SSHHost) set(kw, val string) {
+	switch strings.ToLower(kw) {
+	case "hostname":
+		h.HostName = val
+	case "user":
+		h.User = val
+	case "port":
+		if n, err := strconv.Atoi(val); err == nil {
+			h.Port = n
+			return
+		}
+		h.setOption(kw, val)
+	case "identityfile":
+		h.IdentityFile = val
+	default:
+		h.setOption(kw, val)
+	}
+}
+
+func (h *SSHHost) setOption(kw, val string) {
+	if !sshOptionAllowed(kw, val) {
+		// Hand-edited into the block; Validate would refuse to carry it.
+		return
+	}
+	if h.Options == nil {
+		h.Options = make(map[string]string)
+	}
+	h.Options[kw] = val
+}
+
+var (
+	sshKeyNameRe  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	sshKeywordRe  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+	sshHostKeyRe  = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+	sshHostTypeRe = regexp.MustCompile(`^(ssh-ed25519|ssh-rsa|ecdsa-sha2-nistp(256|384|521)|sk-ssh-ed25519@openssh\.com|sk-ecdsa-sha2-nistp256@openssh\.com)$`)
+)
+
+// sshAllowedOptions are the Host block options a config may set, lowercased
+// as ssh matches them, each with the values it may take (nil: any one-line
+// value). Anything else is refused: options that run commands
+// (ProxyCommand, LocalCommand, KnownHostsCommand), load a library
+// (PKCS11Provider, SecurityKeyProvider), pull in other files (Include,
+// Match), move known_hosts (UserKnownHostsFile) or hand local ports to the
+// remote end (RemoteForward) are all outside the list, and host key
+// checking and agent forwarding may only be made stricter.
+var sshAllowedOptions = map[string][]string{
+	"addkeystoagent":           nil,
+	"usekeychain":              nil,
+	"identitiesonly":           nil,
+	"identityagent":            nil,
+	"certificatefile":          nil,
+	"preferredauthentications": nil,
+	"pubkeyauthentication":     nil,
+	"passwordauthentication":   nil,
+	"serveraliveinterval":      nil,
+	"serveralivecountmax":      nil,
+	"tcpkeepalive":             nil,
+	"connecttimeout":           nil,
+	"connectionattempts":       nil,
+	"compression":              nil,
+	"controlmaster":            nil,
+	"controlpath":              nil,
+	"controlpersist":           nil,
+	"proxyjump":                nil,
+	"requesttty":               nil,
+	"loglevel":                 nil,
+	"hashknownhosts":           nil,
+	"visualhostkey":            nil,
+	"addressfamily":            nil,
+	"batchmode":                nil,
+	"stricthostkeychecking":    {"yes", "ask", "accept-new"},
+	"forwardagent":             {"no"},
+	"forwardx11":               {"no"},
+}
+
+// sshOptionAllowed reports whether a config may set option kw to val.
+func sshOptionAllowed(kw, val string) bool {
+	values, ok := sshAllowedOptions[strings.ToLower(kw)]
+	if !ok {
+		return false
+	}
+	return values == nil || slices.Contains(values, strings.ToLower(strings.TrimSpace(val)))
+}
+
+// sshNamedOptions are set through SSHHost fields, not Options.
+var sshNamedOptions = map[string]bool{
+	"hostname":     true,
+	"user":         true,
+	"port":         true,
+	"identityfile": true,
+}
+
+// Validate checks that key names are file-safe and distinct, that host
+// blocks hold single-line values and no command-running options, and that
+// known_hosts lines are plain, well-formed host keys.
+func (s *RemoteSSHConfig) Validate() error {
+	if s == nil {
+		return nil
+	}
+	names := make(map[string]bool, len(s.Keys))
+	for _, k := range s.Keys {
+		if !sshKeyNameRe.MatchString(k.Name) || strings.HasSuffix(k.Name, ".pub") {
+			return fmt.Errorf("ssh key name %q must be a file name under ~/.ssh (letters, digits, . _ -)", k.Name)
+		}
+		if names[k.Name] {
+			return fmt.Errorf("ssh key %q is defined twice", k.Name)
+		}
+		names[k.Name] = true
+		if strings.ContainsAny(k.Comment, "\n\r\x00") {
+			return fmt.Errorf("ssh key %s: comment must be on one line", k.Name)
+		}
+	}
+	for _, h := range s.Hosts {
+		if err := h.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, line := range s.KnownHosts {
+		if err := validateKnownHost(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that h names a host pattern, that every value is a
+// single token-safe line, and that every option is allow-listed.
+func (h SSHHost) Validate() error {
+	if strings.TrimSpace(h.Host) == "" {
+		return fmt.Errorf("ssh host: host pattern is required")
+	}
+	if strings.ContainsAny(h.Host, "\n\r\x00\"") {
+		return fmt.Errorf("ssh host %q: invalid host pattern", h.Host)
+	}
+	for _, f := range []struct{ name, v string }{{"hostname", h.HostName}, {"user", h.User}, {"identity_file", h.IdentityFile}} {
+		if strings.ContainsAny(f.v, " \t\n\r\x00\"") {
+			return fmt.Errorf("ssh host %s: %s must be a single word", h.Host, f.name)
+		}
+	}
+	if h.Port < 0 || h.Port > 65535 {
+		return fmt.Errorf("ssh host %s: port %d out of range", h.Host, h.Port)
+	}
+	for k, v := range h.Options {
+		lk := strings.ToLower(k)
+		switch {
+		case !sshKeywordRe.MatchString(k):
+			return fmt.Errorf("ssh host %s: invalid option %q", h.Host, k)
+		case sshNamedOptions[lk]:
+			return fmt.Errorf("ssh host %s: set %s through its own field, not options", h.Host, k)
+		case strings.TrimSpace(v) == "" || strings.ContainsAny(v, "\n\r\x00"):
+			return fmt.Errorf("ssh host %s: option %s needs a one-line value", h.Host, k)
+		case !sshOptionAllowed(k, v):
+			return fmt.Errorf("ssh host %s: option %s %s is not allowed", h.Host, k, v)
+		}
+	}
+	return nil
+}
+
+// validateKnownHost checks line is "<hosts> <key type> <base64 key>" with
+// an optional comment, and no @cert-authority or @revoked marker.
+func validateKnownHost(line string) error {
+	if strings.ContainsAny(line, "\n\r\x00") {
+		return fmt.Errorf("known_hosts entry must be on one line")
+	}
+	f := strings.Fields(line)
+	switch {
+	case len(f) < 3:
+		return fmt.Errorf("known_hosts entry %q: want \"<host> <key type> <key>\"", line)
+	case strings.HasPrefix(f[0], "@"):
+		return fmt.Errorf("known_hosts entry for %s: markers like %s are not supported", f[1], f[0])
+	case !sshHostTypeRe.MatchString(f[1]):
+		return fmt.Errorf("known_hosts entry for %s: unsupported key type %q", f[0], f[1])
+	case !sshHostKeyRe.MatchString(f[2]):
+		return fmt.Errorf("known_hosts entry for %s: key is not base64", f[0])
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const githubEd25519 = "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+
+func sampleSSH() *RemoteSSHConfig {
+	return &RemoteSSHConfig{
+		Keys: []SSHKey{{Name: "id_ed25519"}, {Name: "id_work"}},
+		Hosts: []SSHHost{
+			{Host: "github.com", User: "git", IdentityFile: "~/.ssh/id_ed25519"},
+			{Host: "box *.lan", HostName: "10.0.0.2", Port: 2222, Options: map[string]string{"ServerAliveInterval": "60", "ForwardAgent": "no"}},
+		},
+	}
+}
+
+func TestRemoteSSHConfigRender(t *testing.T) {
+	want := `Host github.com
+  User git
+  IdentityFile ~/.ssh/id_ed25519
+
+Host box *.lan
+  HostName 10.0.0.2
+  Port 2222
+  ForwardAgent no
+  ServerAliveInterval 60
+
+# Keys, added to the agent and the macOS keychain
+Host *
+  IgnoreUnknown UseKeychain
+  AddKeysToAgent yes
+  UseKeychain yes
+  IdentityFile ~/.ssh/id_ed25519
+  IdentityFile ~/.ssh/id_work
+`
+	assert.Equal(t, want, sampleSSH().Render())
+	assert.Empty(t, (&RemoteSSHConfig{KnownHosts: []string{githubEd25519}}).Render())
+	assert.Empty(t, (*RemoteSSHConfig)(nil).Render())
+}
+
+func TestParseSSHBlockRoundTrip(t *testing.T) {
+	start, end := ManagedBlockMarkers(SSHBlockName)
+	content := start + "\n" + sampleSSH().Render() + end + "\n\nHost *\n  ServerAliveInterval 30\n"
+
+	got := ParseSSHBlock(content)
+	require.NotNil(t, got)
+	assert.Equal(t, sampleSSH(), got)
+
+	assert.Nil(t, ParseSSHBlock("Host github.com\n  User git\n"))
+}
+
+func TestParseSSHBlockDropsCommandOptions(t *testing.T) {
+	start, end := ManagedBlockMarkers(SSHBlockName)
+	got := ParseSSHBlock(start + "\nHost jump\n  ProxyCommand nc %h %p\n  User me\n" + end + "\n")
+	require.NotNil(t, got)
+	assert.Equal(t, []SSHHost{{Host: "jump", User: "me"}}, got.Hosts)
+	assert.NoError(t, got.Validate())
+}
+
+func TestParseSSHBlockDropsOptionsOutsideAllowList(t *testing.T) {
+	start, end := ManagedBlockMarkers(SSHBlockName)
+	got := ParseSSHBlock(start + "\nHost x\n  StrictHostKeyChecking no\n  UserKnownHostsFile /dev/null\n  PKCS11Provider /tmp/p.dylib\n  StrictHostKeyChecking accept-new\n" + end + "\n")
+	require.NotNil(t, got)
+	assert.Equal(t, []SSHHost{{Host: "x", Options: map[string]string{"StrictHostKeyChecking": "accept-new"}}}, got.Hosts)
+	assert.NoError(t, got.Validate())
+}
+
+func TestRemoteSSHConfigValidate(t *testing.T) {
+	valid := sampleSSH()
+	valid.KnownHosts = []string{githubEd25519}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, (*RemoteSSHConfig)(nil).Validate())
+
+	invalid := []*RemoteSSHConfig{
+		{Keys: []SSHKey{{Name: "../id_rsa"}}},
+		{Keys: []SSHKey{{Name: "id_ed25519.pub"}}},
+		{Keys: []SSHKey{{Name: "a"}, {Name: "a"}}},
+		{Keys: []SSHKey{{Name: "a", Comment: "x\ny"}}},
+		{Hosts: []SSHHost{{Host: ""}}},
+		{Hosts: []SSHHost{{Host: "x", User: "a b"}}},
+		{Hosts: []SSHHost{{Host: "x", Port: 70000}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"ProxyCommand": "sh -c evil"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"localcommand": "evil"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"User": "git"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"PKCS11Provider": "/tmp/evil.dylib"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"SecurityKeyProvider": "/tmp/evil.dylib"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"StrictHostKeyChecking": "no"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"UserKnownHostsFile": "/dev/null"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"ForwardAgent": "yes"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"Include": "/tmp/other"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"SomeFutureOption": "yes"}}}},
+		{Hosts: []SSHHost{{Host: "x", Options: map[string]string{"Compression": "yes\nHost *"}}}},
+		{KnownHosts: []string{"github.com ssh-ed25519"}},
+		{KnownHosts: []string{"@revoked github.com ssh-ed25519 AAAA"}},
+		{KnownHosts: []string{"github.com ssh-dss AAAA"}},
+		{KnownHosts: []string{"github.com ssh-ed25519 not*base64"}},
+	}
+	for i, s := range invalid {
+		assert.Error(t, s.Validate(), "case %d", i)
+	}
+}
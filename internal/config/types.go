@@ -3,6 +3,8 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/openbootdotdev/openboot/internal/signing"
 )
 
 // InstallOptions holds user-supplied inputs set from CLI flags and environment
@@ -22,6 +24,7 @@ type InstallOptions struct {
 	GitEmail         string // OPENBOOT_GIT_EMAIL (silent mode)
 	PostInstall      string // --post-install
 	AllowPostInstall bool   // --allow-post-install
+	RequireSignature bool   // --require-signature
 	DotfilesURL      string // from remote config
 }
 
@@ -38,6 +41,20 @@ type InstallState struct {
 	SnapshotShellOhMyZsh bool               // from snapshot capture
 	SnapshotShellTheme   string             // from snapshot capture
 	SnapshotShellPlugins []string           // from snapshot capture
+	// Non-oh-my-zsh shell setup from snapshot capture; see RemoteShellConfig.
+	SnapshotShellName      string
+	SnapshotShellFramework string
+	SnapshotStarship       bool
+	SnapshotStarshipConfig string
+	SnapshotShellSnippets  *ShellSnippets
+	SnapshotSSH            *RemoteSSHConfig      // managed ~/.ssh/config block from snapshot capture
+	SnapshotDefaultApps    []DefaultApp          // from snapshot capture
+	SnapshotKeyboard       *RemoteKeyboardConfig // from snapshot capture
+	SnapshotDock           *DockLayout           // from snapshot capture
+	SnapshotLaunchAgents   []LaunchAgent         // openboot-managed agents from snapshot capture
+	SnapshotFonts          []Font                // from snapshot capture
+	SnapshotAppSettings    *AppSettings          // opted-in app settings from snapshot capture
+	SnapshotServices       []string              // running brew services from snapshot capture
 }
 
 // Config holds all configuration for a single openboot run.
@@ -52,6 +69,8 @@ type Config struct {
 type SnapshotGitConfig struct {
 	UserName  string
 	UserEmail string
+	// Config is the captured global git configuration beyond identity.
+	Config *RemoteGitConfig
 }
 
 // PackageEntry represents a package with an optional description.
@@ -134,26 +153,125 @@ type LoginItem struct {
 }
 
 type RemoteConfig struct {
-	Username     string             `json:"username"`
-	Slug         string             `json:"slug"`
-	Name         string             `json:"name"`
-	Preset       string             `json:"preset"`
-	Packages     PackageEntryList   `json:"packages"`
-	Casks        PackageEntryList   `json:"casks"`
-	Taps         []string           `json:"taps"`
-	Npm          PackageEntryList   `json:"npm"`
-	DotfilesRepo string             `json:"dotfiles_repo"`
-	PostInstall  []string           `json:"post_install"`
-	Shell        *RemoteShellConfig `json:"shell"`
-	MacOSPrefs   []RemoteMacOSPref  `json:"macos_prefs"`
-	DockApps     []string           `json:"dock_apps,omitempty"`
-	LoginItems   []LoginItem        `json:"login_items,omitempty"`
+	Username     string                `json:"username"`
+	Slug         string                `json:"slug"`
+	Name         string                `json:"name"`
+	Preset       string                `json:"preset"`
+	Packages     PackageEntryList      `json:"packages"`
+	Casks        PackageEntryList      `json:"casks"`
+	Taps         []string              `json:"taps"`
+	Npm          PackageEntryList      `json:"npm"`
+	DotfilesRepo string                `json:"dotfiles_repo"`
+	PostInstall  []string              `json:"post_install"`
+	Shell        *RemoteShellConfig    `json:"shell"`
+	Git          *RemoteGitConfig      `json:"git,omitempty"`
+	SSH          *RemoteSSHConfig      `json:"ssh,omitempty"`
+	Machine      *RemoteMachineConfig  `json:"machine,omitempty"`
+	MacOSPrefs   []RemoteMacOSPref     `json:"macos_prefs"`
+	DockApps     []string              `json:"dock_apps,omitempty"`
+	Dock         *DockLayout           `json:"dock,omitempty"`
+	LoginItems   []LoginItem           `json:"login_items,omitempty"`
+	LaunchAgents []LaunchAgent         `json:"launch_agents,omitempty"`
+	Fonts        []Font                `json:"fonts,omitempty"`
+	DefaultApps  []DefaultApp          `json:"default_apps,omitempty"`
+	Security     *RemoteSecurityConfig `json:"security,omitempty"`
+	Keyboard     *RemoteKeyboardConfig `json:"keyboard,omitempty"`
+	AppSettings  *AppSettings          `json:"app_settings,omitempty"`
+	Services     []string              `json:"services,omitempty"` // brew services to start
+
+	// Signature is what CheckSignature found when the config was loaded;
+	// the CLI checks it against the trusted keys.
+	Signature signing.Result `json:"-"`
+}
+
+// Shells and shell frameworks a RemoteShellConfig can describe.
+const (
+	ShellZsh  = "zsh"
+	ShellBash = "bash"
+	ShellFish = "fish"
+
+	FrameworkOhMyZsh = "oh-my-zsh"
+	FrameworkZinit   = "zinit"
+	FrameworkPrezto  = "prezto"
+	FrameworkFisher  = "fisher"
+)
+
+// StarshipConfigFile is where starship reads its config, relative to the
+// home directory.
+const StarshipConfigFile = ".config/starship.toml"
+
+// ShellRCFile returns the rc file openboot reads and writes for sh, relative
+// to the home directory.
+func ShellRCFile(sh string) string {
+	switch sh {
+	case ShellBash:
+		return ".bashrc"
+	case ShellFish:
+		return ".config/fish/config.fish"
+	default:
+		return ".zshrc"
+	}
+}
+
+// frameworkShell maps each supported framework to the shell it runs under.
+var frameworkShell = map[string]string{
+	FrameworkOhMyZsh: ShellZsh,
+	FrameworkZinit:   ShellZsh,
+	FrameworkPrezto:  ShellZsh,
+	FrameworkFisher:  ShellFish,
 }
 
 type RemoteShellConfig struct {
-	OhMyZsh bool     `json:"oh_my_zsh"`
-	Theme   string   `json:"theme"`
+	// Shell is the shell the config targets: "zsh", "bash" or "fish".
+	// Empty means the shell implied by Framework, or zsh.
+	Shell string `json:"shell,omitempty"`
+	// Framework is the plugin manager: "oh-my-zsh", "zinit" or "prezto" for
+	// zsh, "fisher" for fish, or empty for none. Configs that predate it set
+	// only OhMyZsh, which still implies "oh-my-zsh".
+	Framework string `json:"framework,omitempty"`
+	OhMyZsh   bool   `json:"oh_my_zsh"`
+	// Theme is ZSH_THEME for oh-my-zsh and the prompt theme for prezto.
+	Theme string `json:"theme"`
+	// Plugins are oh-my-zsh plugin names, prezto module names, or
+	// owner/repo[@ref] references for zinit and fisher.
 	Plugins []string `json:"plugins"`
+	// Starship enables the starship prompt for Shell. StarshipConfig, when
+	// set, is the full contents of ~/.config/starship.toml.
+	Starship       bool   `json:"starship,omitempty"`
+	StarshipConfig string `json:"starship_config,omitempty"`
+	// Snippets are env vars, aliases and PATH entries written to a managed
+	// block in Shell's rc file. See ShellSnippets.
+	Snippets *ShellSnippets `json:"snippets,omitempty"`
+}
+
+// EffectiveFramework returns Framework, falling back to "oh-my-zsh" when only
+// the legacy OhMyZsh flag is set.
+func (s *RemoteShellConfig) EffectiveFramework() string {
+	if s == nil {
+		return ""
+	}
+	if s.Framework == "" && s.OhMyZsh {
+		return FrameworkOhMyZsh
+	}
+	return s.Framework
+}
+
+// EffectiveShell returns Shell, or the shell the framework runs under, or zsh.
+func (s *RemoteShellConfig) EffectiveShell() string {
+	if s != nil && s.Shell != "" {
+		return s.Shell
+	}
+	if sh, ok := frameworkShell[s.EffectiveFramework()]; ok {
+		return sh
+	}
+	return ShellZsh
+}
+
+// Managed reports whether the section asks openboot to set anything up:
+// a framework, the starship prompt, or both. Snippets are applied separately
+// and do not count.
+func (s *RemoteShellConfig) Managed() bool {
+	return s != nil && (s.EffectiveFramework() != "" || s.Starship)
 }
 
 type RemoteMacOSPref struct {
@@ -1,10 +1,15 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/secrets"
 )
 
 const (
@@ -12,6 +17,9 @@ const (
 	maxPostInstallCmdLen = 4096
 )
 
+// MaxStarshipConfigLen caps the starship.toml a shell config may carry.
+const MaxStarshipConfigLen = 64 * 1024
+
 var (
 	pkgNameRe = regexp.MustCompile(`^[a-zA-Z0-9@/_.+-]+$`)
 	tapNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+/[a-zA-Z0-9_-]+$`)
@@ -22,8 +30,34 @@ var (
 	// dotfilesPathRe validates the path component: one or more segments of
 	// alphanumeric, dash, underscore, or dot characters separated by slashes.
 	dotfilesPathRe = regexp.MustCompile(`^/[a-zA-Z0-9._-]+(/[a-zA-Z0-9._-]+)*$`)
+
+	// shellNameRe matches oh-my-zsh theme/plugin names and prezto
+	// theme/module names — they are written unquoted into rc files.
+	shellNameRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	// zinitPluginRe matches the owner/repo references zinit loads.
+	zinitPluginRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+$`)
+	// fisherPluginRe matches fisher's owner/repo[@ref] references.
+	fisherPluginRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+(@[a-zA-Z0-9_./-]+)?$`)
+
+	// envNameRe and aliasNameRe match the names shell snippets may define.
+	envNameRe   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	aliasNameRe = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
 )
 
+// snippetExecEnvVars are variables a shell runs, sources or expands as a
+// prompt, or that make every program load a library. Snippets may not set
+// them: their value would run as a command from the rc file.
+var snippetExecEnvVars = map[string]bool{
+	"PROMPT_COMMAND": true, "BASH_ENV": true, "ENV": true, "ZDOTDIR": true,
+	"PS0": true, "PS1": true, "PS2": true, "PS3": true, "PS4": true,
+	"PROMPT": true, "PROMPT2": true, "PROMPT3": true, "PROMPT4": true, "RPROMPT": true, "RPS1": true, "RPS2": true,
+	"SHELLOPTS": true, "BASHOPTS": true, "IFS": true, "FPATH": true, "fish_function_path": true,
+}
+
+// snippetExecEnvPrefixes are prefixes of variable names snippets may not
+// set: dynamic loader settings and exported bash functions.
+var snippetExecEnvPrefixes = []string{"DYLD_", "LD_", "BASH_FUNC_"}
+
 // ValidateDotfilesURL checks that a dotfiles repo URL uses HTTPS, has a
 // valid path, max 500 chars, and no path traversal. Any HTTPS host is
 // accepted (including self-hosted GitLab, Gitea, etc.).
@@ -73,11 +107,161 @@ func (rc *RemoteConfig) Validate() error {
 	if err := validateMacOSPrefs(rc); err != nil {
 		return fmt.Errorf("validate macos prefs: %w", err)
 	}
+	if err := rc.Shell.Validate(); err != nil {
+		return fmt.Errorf("validate shell: %w", err)
+	}
+	if err := rc.Git.Validate(); err != nil {
+		return fmt.Errorf("validate git: %w", err)
+	}
+	if err := rc.SSH.Validate(); err != nil {
+		return fmt.Errorf("validate ssh: %w", err)
+	}
+	if err := rc.Machine.Validate(); err != nil {
+		return fmt.Errorf("validate machine: %w", err)
+	}
+	if err := ValidateDefaultApps(rc.DefaultApps); err != nil {
+		return fmt.Errorf("validate default apps: %w", err)
+	}
+	if err := rc.Keyboard.Validate(); err != nil {
+		return fmt.Errorf("validate keyboard: %w", err)
+	}
+	if err := rc.Dock.Validate(); err != nil {
+		return fmt.Errorf("validate dock: %w", err)
+	}
+	if err := ValidateLaunchAgents(rc.LaunchAgents); err != nil {
+		return fmt.Errorf("validate launch agents: %w", err)
+	}
+	if err := ValidateFonts(rc.Fonts); err != nil {
+		return fmt.Errorf("validate fonts: %w", err)
+	}
+	if err := rc.AppSettings.Validate(); err != nil {
+		return fmt.Errorf("validate app settings: %w", err)
+	}
+	if err := validateSecretReferences(rc); err != nil {
+		return err
+	}
 	return validatePostInstall(rc)
 }
 
-// validatePackageLists checks that all formulae, casks, npm packages, and
-// taps have valid names within the allowed length.
+// Validate checks that the shell section names a supported shell and
+// framework that belong together, and that the theme and plugins are in the
+// form that framework expects. Theme and plugins are only checked when a
+// framework is set; without one they are ignored, as they always were.
+func (s *RemoteShellConfig) Validate() error {
+	if s == nil {
+		return nil
+	}
+	switch s.Shell {
+	case "", ShellZsh, ShellBash, ShellFish:
+	default:
+		return fmt.Errorf("unsupported shell %q (allowed: zsh, bash, fish)", s.Shell)
+	}
+	if s.Framework != "" {
+		if _, ok := frameworkShell[s.Framework]; !ok {
+			return fmt.Errorf("unsupported framework %q (allowed: oh-my-zsh, zinit, prezto, fisher)", s.Framework)
+		}
+		if s.OhMyZsh && s.Framework != FrameworkOhMyZsh {
+			return fmt.Errorf("oh_my_zsh conflicts with framework %q", s.Framework)
+		}
+	}
+
+	fw := s.EffectiveFramework()
+	if fw != "" && s.Shell != "" && frameworkShell[fw] != s.Shell {
+		return fmt.Errorf("framework %q runs under %s, not %s", fw, frameworkShell[fw], s.Shell)
+	}
+
+	switch fw {
+	case FrameworkOhMyZsh, FrameworkPrezto:
+		if s.Theme != "" && !shellNameRe.MatchString(s.Theme) {
+			return fmt.Errorf("invalid theme %q (only alphanumerics, hyphens, underscores, dots allowed)", s.Theme)
+		}
+		for _, p := range s.Plugins {
+			if !shellNameRe.MatchString(p) {
+				return fmt.Errorf("invalid %s plugin %q (only alphanumerics, hyphens, underscores, dots allowed)", fw, p)
+			}
+		}
+	case FrameworkZinit, FrameworkFisher:
+		if s.Theme != "" {
+			return fmt.Errorf("%s has no theme setting; install a prompt as a plugin instead", fw)
+		}
+		re, want := zinitPluginRe, "owner/repo"
+		if fw == FrameworkFisher {
+			re, want = fisherPluginRe, "owner/repo or owner/repo@ref"
+		}
+		for _, p := range s.Plugins {
+			if !re.MatchString(p) {
+				return fmt.Errorf("invalid %s plugin %q (expected %s)", fw, p, want)
+			}
+		}
+	}
+
+	if s.StarshipConfig != "" {
+		if !s.Starship {
+			return fmt.Errorf("starship_config is set but starship is not enabled")
+		}
+		if len(s.StarshipConfig) > MaxStarshipConfigLen {
+			return fmt.Errorf("starship_config too long (%d bytes, max %d)", len(s.StarshipConfig), MaxStarshipConfigLen)
+		}
+		if strings.ContainsRune(s.StarshipConfig, 0) {
+			return fmt.Errorf("starship_config must not contain NUL bytes")
+		}
+	}
+	if err := s.Snippets.Validate(); err != nil {
+		return fmt.Errorf("snippets: %w", err)
+	}
+	return nil
+}
+
+// Validate checks that every snippet renders to exactly one line of the
+// form Render writes. Values are double-quoted, so $VAR references are
+// allowed but command substitution is not; alias commands are
+// single-quoted and may contain anything but a line break.
+func (s *ShellSnippets) Validate() error {
+	if s == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(s.Env))
+	for _, e := range s.Env {
+		if !envNameRe.MatchString(e.Name) {
+			return fmt.Errorf("invalid env var name %q", e.Name)
+		}
+		if e.Name == "PATH" {
+			return fmt.Errorf("set PATH with the path list, not env")
+		}
+		if snippetExecEnvVars[e.Name] || slices.ContainsFunc(snippetExecEnvPrefixes, func(p string) bool { return strings.HasPrefix(e.Name, p) }) {
+			return fmt.Errorf("env var %s runs commands from the shell and cannot be set by snippets", e.Name)
+		}
+		if seen[e.Name] {
+			return fmt.Errorf("env var %s is set twice", e.Name)
+		}
+		seen[e.Name] = true
+		if strings.ContainsAny(e.Value, "\n\r\x00`") || strings.Contains(e.Value, "$(") {
+			return fmt.Errorf("env var %s: value must not contain line breaks, NUL, backticks or $(", e.Name)
+		}
+	}
+	seen = make(map[string]bool, len(s.Aliases))
+	for _, a := range s.Aliases {
+		if !aliasNameRe.MatchString(a.Name) {
+			return fmt.Errorf("invalid alias name %q", a.Name)
+		}
+		if seen[a.Name] {
+			return fmt.Errorf("alias %s is defined twice", a.Name)
+		}
+		seen[a.Name] = true
+		if a.Command == "" || strings.ContainsAny(a.Command, "\n\r\x00") {
+			return fmt.Errorf("alias %s: command must be non-empty and on one line", a.Name)
+		}
+	}
+	for _, p := range s.Path {
+		if p == "" || strings.ContainsAny(p, "\n\r\x00`\"\\:") || strings.Contains(p, "$(") {
+			return fmt.Errorf("invalid PATH entry %q", p)
+		}
+	}
+	return nil
+}
+
+// validatePackageLists checks that all formulae, casks, npm packages, taps
+// and services have valid names within the allowed length.
 func validatePackageLists(rc *RemoteConfig) error {
 	for _, p := range rc.Packages {
 		if len(p.Name) > maxPackageNameLen {
@@ -111,6 +295,14 @@ func validatePackageLists(rc *RemoteConfig) error {
 			return fmt.Errorf("invalid tap name: %q (expected format: owner/repo)", t)
 		}
 	}
+	for _, s := range rc.Services {
+		if len(s) > maxPackageNameLen {
+			return fmt.Errorf("service name too long (%d chars, max %d): %q", len(s), maxPackageNameLen, s)
+		}
+		if !pkgNameRe.MatchString(s) {
+			return fmt.Errorf("invalid service name: %q", s)
+		}
+	}
 	return nil
 }
 
@@ -138,6 +330,34 @@ func validateMacOSPrefs(rc *RemoteConfig) error {
 	return nil
 }
 
+// validateSecretReferences refuses secret:// references outside
+// post_install, the only place they are resolved. Anywhere else the
+// literal reference would be cloned, written to a dotfile or passed to
+// brew as if it were the value.
+func validateSecretReferences(rc *RemoteConfig) error {
+	c := *rc
+	c.PostInstall = nil
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("validate secret references: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("validate secret references: %w", err)
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if refs := secrets.FindReferences(string(fields[k])); len(refs) > 0 {
+			return fmt.Errorf("%s: %s is not resolved here; secret references only work in post_install", k, refs[0])
+		}
+	}
+	return nil
+}
+
 // validatePostInstall checks that all post-install commands are non-empty,
 // NUL-free, and within the maximum length.
 func validatePostInstall(rc *RemoteConfig) error {
@@ -179,6 +179,16 @@ func TestValidatePackageLists_TapInvalidFormat(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid tap name")
 }
 
+func TestValidatePackageLists_Services(t *testing.T) {
+	rc := &RemoteConfig{Services: []string{"postgresql@16", "redis", "colima"}}
+	assert.NoError(t, validatePackageLists(rc))
+
+	rc = &RemoteConfig{Services: []string{"redis; rm -rf ~"}}
+	err := validatePackageLists(rc)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid service name")
+}
+
 func TestValidatePackageLists_PackageNameAtMaxLength(t *testing.T) {
 	rc := &RemoteConfig{
 		Packages: PackageEntryList{{Name: strings.Repeat("a", maxPackageNameLen)}},
@@ -307,3 +317,95 @@ func TestRemoteConfigValidate_InvalidDotfilesTriggersError(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid dotfiles_repo")
 }
+
+// ---- RemoteShellConfig.Validate ----
+
+func TestRemoteShellConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   *RemoteShellConfig
+		wantErr string
+	}{
+		{"nil", nil, ""},
+		{"legacy oh-my-zsh", &RemoteShellConfig{OhMyZsh: true, Theme: "agnoster", Plugins: []string{"git"}}, ""},
+		{"legacy fields ignored without framework", &RemoteShellConfig{Theme: "bad theme"}, ""},
+		{"prezto", &RemoteShellConfig{Framework: FrameworkPrezto, Theme: "sorin", Plugins: []string{"git", "syntax-highlighting"}}, ""},
+		{"zinit", &RemoteShellConfig{Framework: FrameworkZinit, Plugins: []string{"zsh-users/zsh-autosuggestions"}}, ""},
+		{"fisher with ref", &RemoteShellConfig{Shell: ShellFish, Framework: FrameworkFisher, Plugins: []string{"ilancosman/tide@v6"}}, ""},
+		{"bash with starship", &RemoteShellConfig{Shell: ShellBash, Starship: true, StarshipConfig: "add_newline = false\n"}, ""},
+		{"unknown shell", &RemoteShellConfig{Shell: "tcsh"}, "unsupported shell"},
+		{"unknown framework", &RemoteShellConfig{Framework: "antigen"}, "unsupported framework"},
+		{"oh_my_zsh with other framework", &RemoteShellConfig{OhMyZsh: true, Framework: FrameworkZinit}, "conflicts"},
+		{"framework on wrong shell", &RemoteShellConfig{Shell: ShellBash, Framework: FrameworkPrezto}, "runs under zsh"},
+		{"oh-my-zsh plugin injection", &RemoteShellConfig{OhMyZsh: true, Plugins: []string{"git); rm -rf ~"}}, "invalid oh-my-zsh plugin"},
+		{"zinit theme", &RemoteShellConfig{Framework: FrameworkZinit, Theme: "x"}, "no theme"},
+		{"zinit plugin ref", &RemoteShellConfig{Framework: FrameworkZinit, Plugins: []string{"a/b@v1"}}, "expected owner/repo"},
+		{"fisher bare name", &RemoteShellConfig{Framework: FrameworkFisher, Plugins: []string{"tide"}}, "invalid fisher plugin"},
+		{"starship config without starship", &RemoteShellConfig{StarshipConfig: "x"}, "starship is not enabled"},
+		{"starship config too long", &RemoteShellConfig{Starship: true, StarshipConfig: strings.Repeat("#", MaxStarshipConfigLen+1)}, "too long"},
+		{"snippets", &RemoteShellConfig{Snippets: &ShellSnippets{
+			Env:     []ShellEnvVar{{Name: "GOPRIVATE", Value: "github.com/acme/*"}, {Name: "GOPATH", Value: "$HOME/go"}},
+			Aliases: []ShellAlias{{Name: "k", Command: "kubectl --context 'prod'"}},
+			Path:    []string{"$HOME/go/bin", "/opt/acme/bin"},
+		}}, ""},
+		{"snippet env name", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "1X", Value: "y"}}}}, "invalid env var name"},
+		{"snippet env PATH", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "PATH", Value: "/x"}}}}, "path list"},
+		{"snippet env PROMPT_COMMAND", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "PROMPT_COMMAND", Value: "curl x | sh"}}}}, "runs commands"},
+		{"snippet env BASH_ENV", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "BASH_ENV", Value: "/tmp/x"}}}}, "runs commands"},
+		{"snippet env ENV", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "ENV", Value: "/tmp/x"}}}}, "runs commands"},
+		{"snippet env ZDOTDIR", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "ZDOTDIR", Value: "/tmp/x"}}}}, "runs commands"},
+		{"snippet env PS1", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "PS1", Value: "${x}"}}}}, "runs commands"},
+		{"snippet env DYLD", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "DYLD_INSERT_LIBRARIES", Value: "/tmp/x.dylib"}}}}, "runs commands"},
+		{"snippet env twice", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "A", Value: "1"}, {Name: "A", Value: "2"}}}}, "set twice"},
+		{"snippet env substitution", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "A", Value: "$(curl x)"}}}}, "must not contain"},
+		{"snippet env newline", &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "A", Value: "x\nrm -rf ~"}}}}, "must not contain"},
+		{"snippet alias name", &RemoteShellConfig{Snippets: &ShellSnippets{Aliases: []ShellAlias{{Name: "a b", Command: "ls"}}}}, "invalid alias name"},
+		{"snippet alias newline", &RemoteShellConfig{Snippets: &ShellSnippets{Aliases: []ShellAlias{{Name: "a", Command: "ls\nx"}}}}, "on one line"},
+		{"snippet path colon", &RemoteShellConfig{Snippets: &ShellSnippets{Path: []string{"/a:/b"}}}, "invalid PATH entry"},
+		{"snippet path quote", &RemoteShellConfig{Snippets: &ShellSnippets{Path: []string{`/a"`}}}, "invalid PATH entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.shell.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRemoteShellConfigEffective(t *testing.T) {
+	var nilShell *RemoteShellConfig
+	assert.Equal(t, "", nilShell.EffectiveFramework())
+	assert.Equal(t, ShellZsh, nilShell.EffectiveShell())
+	assert.False(t, nilShell.Managed())
+
+	legacy := &RemoteShellConfig{OhMyZsh: true}
+	assert.Equal(t, FrameworkOhMyZsh, legacy.EffectiveFramework())
+	assert.True(t, legacy.Managed())
+
+	fish := &RemoteShellConfig{Framework: FrameworkFisher}
+	assert.Equal(t, ShellFish, fish.EffectiveShell())
+
+	bash := &RemoteShellConfig{Shell: ShellBash}
+	assert.False(t, bash.Managed(), "a shell name alone sets nothing up")
+	bash.Starship = true
+	assert.True(t, bash.Managed())
+}
+
+// Secret references are only resolved in post_install; anywhere else they
+// would be used literally.
+func TestValidate_SecretReferencesOnlyInPostInstall(t *testing.T) {
+	rc := &RemoteConfig{Packages: PackageEntryList{{Name: "git"}}, PostInstall: []string{"npm config set //registry.npmjs.org/:_authToken secret://env/NPM_TOKEN"}}
+	assert.NoError(t, rc.Validate())
+
+	rc = &RemoteConfig{Shell: &RemoteShellConfig{Snippets: &ShellSnippets{Env: []ShellEnvVar{{Name: "NPM_TOKEN", Value: "secret://env/NPM_TOKEN"}}}}}
+	assert.ErrorContains(t, rc.Validate(), "secret references only work in post_install")
+
+	rc = &RemoteConfig{Git: &RemoteGitConfig{Settings: map[string]string{"alias.tok": "secret://keychain/github.com"}}}
+	err := rc.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git: secret://keychain/github.com is not resolved here")
+}
@@ -0,0 +1,89 @@
+// Package defaultapps sets default application handlers for content types,
+// file extensions and URL schemes with duti, installing it through
+// Homebrew the first time it is needed. Capture lives in internal/snapshot,
+// which reads the LaunchServices plist directly.
+package defaultapps
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/brew"
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// hasDuti, installDuti, runDuti and currentHandlers wrap the tools and the
+// LaunchServices read so tests can run without touching the real handlers.
+var (
+	hasDuti = func() bool {
+		_, err := exec.LookPath("duti")
+		return err == nil
+	}
+	installDuti = func(dryRun bool) error {
+		return brew.Install([]string{"duti"}, dryRun)
+	}
+	runDuti = func(args ...string) error {
+		out, err := system.RunCommandSilent("duti", args...)
+		if err != nil {
+			return fmt.Errorf("duti: %s: %w", out, err)
+		}
+		return nil
+	}
+	currentHandlers = snapshot.CaptureDefaultApps
+)
+
+// Apply makes each app the default handler for its target, skipping those
+// LaunchServices already maps to the same bundle ID. macOS asks the user to
+// confirm a new default web browser (http and https); duti cannot skip that
+// dialog. It returns the number of handlers changed.
+func Apply(apps []config.DefaultApp, dryRun bool) (int, error) {
+	if len(apps) == 0 {
+		return 0, nil
+	}
+	if err := config.ValidateDefaultApps(apps); err != nil {
+		return 0, fmt.Errorf("set default apps: %w", err)
+	}
+
+	current := make(map[string]string)
+	if have, err := currentHandlers(); err == nil {
+		for _, a := range have {
+			current[strings.ToLower(a.Target())] = strings.ToLower(a.BundleID)
+		}
+	}
+	var todo []config.DefaultApp
+	for _, a := range apps {
+		if current[strings.ToLower(a.Target())] != strings.ToLower(a.BundleID) {
+			todo = append(todo, a)
+		}
+	}
+	if len(todo) == 0 {
+		return 0, nil
+	}
+
+	if !hasDuti() {
+		if err := installDuti(dryRun); err != nil {
+			return 0, fmt.Errorf("install duti: %w", err)
+		}
+	}
+	changed := 0
+	for _, a := range todo {
+		args := []string{"-s", a.BundleID, a.Target()}
+		if !a.IsURLScheme() {
+			args = append(args, "all")
+		}
+		if dryRun {
+			ui.DryRunMsg("Would run: duti %s", strings.Join(args, " "))
+			changed++
+			continue
+		}
+		if err := runDuti(args...); err != nil {
+			return changed, fmt.Errorf("set default app for %s: %w", a.Target(), err)
+		}
+		changed++
+	}
+	return changed, nil
+}
@@ -0,0 +1,83 @@
+package defaultapps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+type fakeTools struct {
+	installed bool
+	installs  int
+	calls     [][]string
+}
+
+func stubTools(t *testing.T, current []config.DefaultApp, dutiInstalled bool) *fakeTools {
+	t.Helper()
+	f := &fakeTools{installed: dutiInstalled}
+	origHas, origInstall, origRun, origCurrent := hasDuti, installDuti, runDuti, currentHandlers
+	t.Cleanup(func() { hasDuti, installDuti, runDuti, currentHandlers = origHas, origInstall, origRun, origCurrent })
+	hasDuti = func() bool { return f.installed }
+	installDuti = func(dryRun bool) error {
+		f.installs++
+		f.installed = !dryRun
+		return nil
+	}
+	runDuti = func(args ...string) error {
+		f.calls = append(f.calls, args)
+		return nil
+	}
+	currentHandlers = func() ([]config.DefaultApp, error) { return current, nil }
+	return f
+}
+
+var sampleApps = []config.DefaultApp{
+	{Extension: "md", BundleID: "com.microsoft.VSCode"},
+	{UTI: "public.json", BundleID: "com.microsoft.VSCode"},
+	{URLScheme: "https", BundleID: "com.google.Chrome"},
+}
+
+func TestApply_InstallsDutiAndSetsMissingHandlers(t *testing.T) {
+	// LaunchServices stores bundle IDs lowercased.
+	f := stubTools(t, []config.DefaultApp{{UTI: "public.json", BundleID: "com.microsoft.vscode"}}, false)
+
+	n, err := Apply(sampleApps, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, f.installs)
+	assert.Equal(t, [][]string{
+		{"-s", "com.microsoft.VSCode", ".md", "all"},
+		{"-s", "com.google.Chrome", "https"},
+	}, f.calls, "url schemes take no role")
+}
+
+func TestApply_NothingToDo(t *testing.T) {
+	f := stubTools(t, []config.DefaultApp{
+		{Extension: "md", BundleID: "com.microsoft.vscode"},
+		{UTI: "public.json", BundleID: "com.microsoft.vscode"},
+		{URLScheme: "https", BundleID: "com.google.chrome"},
+	}, false)
+
+	n, err := Apply(sampleApps, false)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Zero(t, f.installs, "duti is only installed when needed")
+}
+
+func TestApply_DryRun(t *testing.T) {
+	f := stubTools(t, nil, true)
+
+	n, err := Apply(sampleApps, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Empty(t, f.calls)
+}
+
+func TestApply_Invalid(t *testing.T) {
+	stubTools(t, nil, true)
+	_, err := Apply([]config.DefaultApp{{Extension: "md", BundleID: "nope"}}, false)
+	assert.ErrorContains(t, err, "invalid bundle_id")
+}
@@ -1,24 +1,42 @@
 package diff
 
 import (
+	"maps"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/system"
 )
 
+// currentUser fills in {{user}} in a remote config's machine names. It is
+// a var so tests can pin the user.
+var currentUser = system.Username
+
+// captureAppSettingsFor reads the system's settings for the given apps. It
+// is a var so tests can compare without reading real settings.
+var captureAppSettingsFor = snapshot.CaptureAppSettingsFor
+
 // CompareSnapshots performs a full diff between the current system snapshot and a reference snapshot.
 func CompareSnapshots(system, reference *snapshot.Snapshot, source Source) *DiffResult {
 	return &DiffResult{
-		Source:   source,
-		Packages: diffPackages(system, reference),
-		MacOS:    diffMacOS(system.MacOSPrefs, reference.MacOSPrefs),
-		DevTools: diffDevTools(system.DevTools, reference.DevTools),
-		Dotfiles: diffDotfiles(system.Dotfiles.RepoURL, reference.Dotfiles.RepoURL),
+		Source:       source,
+		Packages:     diffPackages(system, reference),
+		MacOS:        diffMacOS(system.MacOSPrefs, reference.MacOSPrefs),
+		DevTools:     diffDevTools(system.DevTools, reference.DevTools),
+		Dotfiles:     diffDotfiles(system.Dotfiles.RepoURL, reference.Dotfiles.RepoURL),
+		Git:          CompareGit(&system.Git, reference.Git.GitConfig()),
+		SSH:          CompareSSH(system.SSH, reference.SSH),
+		Machine:      CompareMachine(system.Machine, reference.Machine, ""),
+		Keyboard:     CompareKeyboard(system.Keyboard, reference.Keyboard),
+		LaunchAgents: CompareLaunchAgents(system.LaunchAgents, reference.LaunchAgents),
+		AppSettings:  CompareAppSettings(system.AppSettings, reference.AppSettings),
 	}
 }
 
@@ -37,8 +55,8 @@ func CompareSnapshotToRemote(system *snapshot.Snapshot, remote *config.RemoteCon
 	result := &DiffResult{
 		Source: source,
 		Packages: PackageDiff{
-			Formulae: DiffLists(system.Packages.Formulae, formulaeOnly),
-			Casks:    DiffLists(system.Packages.Casks, remote.Casks.Names()),
+			Formulae: diffBrewList(system.Packages.Formulae, system.Packages.FormulaInfo, formulaeOnly, nil),
+			Casks:    diffBrewList(system.Packages.Casks, system.Packages.CaskInfo, remote.Casks.Names(), nil),
 			Npm:      DiffLists(system.Packages.Npm, remote.Npm.Names()),
 			Taps:     DiffLists(system.Packages.Taps, remote.Taps),
 		},
@@ -63,57 +81,350 @@ func CompareSnapshotToRemote(system *snapshot.Snapshot, remote *config.RemoteCon
 		result.MacOS = diffMacOS(system.MacOSPrefs, refPrefs)
 	}
 
-	// Shell configuration comparison — only when remote specifies oh-my-zsh
-	if remote.Shell != nil && remote.Shell.OhMyZsh {
-		result.Shell = diffShell(remote.Shell.Theme, remote.Shell.Plugins)
+	result.Git = CompareGit(&system.Git, remote.Git)
+	result.SSH = CompareSSH(system.SSH, remote.SSH)
+	if user, err := currentUser(); err == nil {
+		result.Machine = CompareMachine(system.Machine, remote.Machine, user)
+	}
+	result.Keyboard = CompareKeyboard(system.Keyboard, remote.Keyboard)
+	result.LaunchAgents = CompareLaunchAgents(system.LaunchAgents, remote.LaunchAgents)
+
+	// App settings are read fresh for the apps the remote carries: the
+	// system snapshot only holds the apps opted in to capture here.
+	if !remote.AppSettings.Empty() {
+		if local, err := captureAppSettingsFor(remote.AppSettings.AppIDs()); err == nil {
+			result.AppSettings = CompareAppSettings(local, remote.AppSettings)
+		}
+	}
+
+	// Shell configuration comparison. Captured even without a remote shell
+	// section: a local snippets block the remote no longer has is a change.
+	if local, err := snapshot.CaptureShell(); err == nil && local != nil {
+		result.Shell = CompareShell(local, remote.Shell)
 	}
 
 	return result
 }
 
-// diffShell captures the local shell state and compares it against reference values.
-func diffShell(refTheme string, refPlugins []string) *ShellDiff {
-	local, err := snapshot.CaptureShell()
-	if err != nil || local == nil {
+// CompareShell compares the local shell state against a reference config.
+// Only what the reference specifies is compared: an empty theme or plugin
+// list, or a starship entry without a config, means "no opinion". Snippets
+// are the exception — openboot owns the whole snippets block, so a local
+// block the reference lacks is a change. A nil ref means no shell section.
+func CompareShell(local *snapshot.ShellSnapshot, ref *config.RemoteShellConfig) *ShellDiff {
+	if ref == nil {
+		ref = &config.RemoteShellConfig{}
+	}
+	sd := &ShellDiff{
+		LocalFramework:     local.Framework,
+		ReferenceFramework: ref.EffectiveFramework(),
+		LocalTheme:         local.Theme,
+		ReferenceTheme:     ref.Theme,
+		LocalPlugins:       local.Plugins,
+		ReferencePlugins:   ref.Plugins,
+		LocalSnippets:      local.Snippets,
+		ReferenceSnippets:  ref.Snippets,
+	}
+	if sd.ReferenceFramework != "" {
+		sd.FrameworkChanged = sd.ReferenceFramework != local.Framework
+		sd.ThemeChanged = ref.Theme != "" && ref.Theme != local.Theme
+		sd.PluginsChanged = len(ref.Plugins) > 0 && !PluginsEqual(ref.Plugins, local.Plugins)
+	}
+	if ref.Starship {
+		sd.StarshipChanged = !local.Starship ||
+			(ref.StarshipConfig != "" && strings.TrimSpace(ref.StarshipConfig) != strings.TrimSpace(local.StarshipConfig))
+	}
+	sd.SnippetsChanged = !ref.Snippets.Equal(local.Snippets)
+	if !sd.FrameworkChanged && !sd.ThemeChanged && !sd.PluginsChanged && !sd.StarshipChanged && !sd.SnippetsChanged {
 		return nil
 	}
+	return sd
+}
 
-	var sd *ShellDiff
+// CompareGit compares the local global git configuration against a
+// reference. Settings are matched by canonical key, so "init.defaultbranch"
+// and "init.defaultBranch" are the same setting. Settings and files the
+// reference leaves out are not compared. Returns nil when nothing differs.
+func CompareGit(local *snapshot.GitSnapshot, ref *config.RemoteGitConfig) *GitDiff {
+	if ref.Empty() {
+		return nil
+	}
+	gd := &GitDiff{}
+	for _, key := range ref.SortedKeys() {
+		canon, ok := config.CanonicalGitKey(key)
+		if !ok {
+			continue
+		}
+		if want, have := ref.Settings[key], local.Settings[canon]; want != have {
+			gd.Changed = append(gd.Changed, GitSettingDelta{Key: canon, System: have, Reference: want})
+		}
+	}
+	gd.GitignoreChanged = ref.Gitignore != "" && strings.TrimSpace(ref.Gitignore) != strings.TrimSpace(local.Gitignore)
+	gd.GitattributesChanged = ref.Gitattributes != "" && strings.TrimSpace(ref.Gitattributes) != strings.TrimSpace(local.Gitattributes)
+	gd.SigningChanged = ref.Signing != nil && (local.Signing == nil || local.Signing.Format != ref.Signing.Format)
+	for _, p := range ref.Profiles {
+		if !hasGitProfile(local.Profiles, p) {
+			gd.ProfilesChanged = append(gd.ProfilesChanged, p.Name)
+		}
+	}
+	if gd.Count() == 0 {
+		return nil
+	}
+	return gd
+}
+
+// CompareSSH compares the local managed ~/.ssh/config block against a
+// reference ssh section. Hosts are matched by pattern and compared as
+// rendered; keys by name. Key comments, passphrases and known_hosts pins
+// are not in the block and are not compared. Returns nil when nothing
+// differs or ref is empty.
+func CompareSSH(local, ref *config.RemoteSSHConfig) *SSHDiff {
+	if ref.Empty() {
+		return nil
+	}
+	if local == nil {
+		local = &config.RemoteSSHConfig{}
+	}
+	localHosts := make(map[string]config.SSHHost, len(local.Hosts))
+	for _, h := range local.Hosts {
+		localHosts[h.Host] = h
+	}
+	localKeys := make(map[string]bool, len(local.Keys))
+	for _, k := range local.Keys {
+		localKeys[k.Name] = true
+	}
+
+	sd := &SSHDiff{}
+	refHosts := make(map[string]bool, len(ref.Hosts))
+	for _, h := range ref.Hosts {
+		refHosts[h.Host] = true
+		have, ok := localHosts[h.Host]
+		if !ok || !sshHostEqual(have, h) {
+			sd.HostsChanged = append(sd.HostsChanged, h.Host)
+		}
+	}
+	refKeys := make(map[string]bool, len(ref.Keys))
+	for _, k := range ref.Keys {
+		refKeys[k.Name] = true
+		if !localKeys[k.Name] {
+			sd.KeysMissing = append(sd.KeysMissing, k.Name)
+		}
+	}
+	for h := range localHosts {
+		if !refHosts[h] {
+			sd.Stale = true
+		}
+	}
+	for k := range localKeys {
+		if !refKeys[k] {
+			sd.Stale = true
+		}
+	}
+	if sd.Count() == 0 {
+		return nil
+	}
+	return sd
+}
 
-	if refTheme != "" && refTheme != local.Theme {
-		sd = &ShellDiff{
-			ThemeChanged:     true,
-			LocalTheme:       local.Theme,
-			ReferenceTheme:   refTheme,
-			LocalPlugins:     local.Plugins,
-			ReferencePlugins: refPlugins,
+// CompareMachine compares the local machine names against a reference,
+// with the reference's {{user}} placeholders filled in for user. Names the
+// reference leaves empty are not compared. Returns nil when nothing
+// differs.
+func CompareMachine(local, ref *config.RemoteMachineConfig, user string) *MachineDiff {
+	if ref.Empty() {
+		return nil
+	}
+	if local == nil {
+		local = &config.RemoteMachineConfig{}
+	}
+	have := make(map[string]string, 3)
+	for _, n := range local.Names() {
+		have[n.Key] = n.Value
+	}
+	md := &MachineDiff{}
+	for _, n := range ref.Resolve(user).Names() {
+		if have[n.Key] != n.Value {
+			md.Changed = append(md.Changed, MachineNameDelta{Key: n.Key, System: have[n.Key], Reference: n.Value})
 		}
 	}
+	if len(md.Changed) == 0 {
+		return nil
+	}
+	return md
+}
 
-	if len(refPlugins) > 0 && !PluginsEqual(refPlugins, local.Plugins) {
-		if sd == nil {
-			sd = &ShellDiff{
-				LocalTheme:       local.Theme,
-				ReferenceTheme:   refTheme,
-				LocalPlugins:     local.Plugins,
-				ReferencePlugins: refPlugins,
+// CompareKeyboard compares the local keyboard settings against a reference
+// keyboard section. App shortcuts and hotkeys the reference does not set
+// are not compared; key mappings are compared as a whole. Returns nil when
+// nothing differs or ref is empty.
+func CompareKeyboard(local, ref *config.RemoteKeyboardConfig) *KeyboardDiff {
+	if ref.Empty() {
+		return nil
+	}
+	if local == nil {
+		local = &config.RemoteKeyboardConfig{}
+	}
+	kd := &KeyboardDiff{}
+	for _, domain := range ref.Domains() {
+		titles := make([]string, 0, len(ref.AppShortcuts[domain]))
+		for title, equiv := range ref.AppShortcuts[domain] {
+			if local.AppShortcuts[domain][title] != equiv {
+				titles = append(titles, title)
 			}
 		}
-		sd.PluginsChanged = true
+		sort.Strings(titles)
+		for _, title := range titles {
+			kd.ShortcutsChanged = append(kd.ShortcutsChanged, domain+": "+title)
+		}
+	}
+	have := make(map[int]config.SymbolicHotKey, len(local.SymbolicHotKeys))
+	for _, h := range local.SymbolicHotKeys {
+		have[h.ID] = h
 	}
+	for _, h := range ref.SymbolicHotKeys {
+		if cur, ok := have[h.ID]; !ok || !h.Matches(cur) {
+			kd.HotKeysChanged = append(kd.HotKeysChanged, h.ID)
+		}
+	}
+	if len(ref.KeyMappings) > 0 && !config.KeyMappingsEqual(ref.KeyMappings, local.KeyMappings) {
+		kd.MappingChanged = true
+	}
+	if kd.Count() == 0 {
+		return nil
+	}
+	return kd
+}
 
-	return sd
+// CompareLaunchAgents compares the system's openboot-managed LaunchAgents
+// against a reference launch_agents section, by label. Returns nil when
+// nothing differs or ref is empty.
+func CompareLaunchAgents(local, ref []config.LaunchAgent) *LaunchAgentsDiff {
+	if len(ref) == 0 {
+		return nil
+	}
+	have := make(map[string]config.LaunchAgent, len(local))
+	for _, a := range local {
+		have[a.Label] = a
+	}
+	want := make(map[string]bool, len(ref))
+	ld := &LaunchAgentsDiff{}
+	for _, a := range ref {
+		want[a.Label] = true
+		cur, ok := have[a.Label]
+		if !ok {
+			ld.Missing = append(ld.Missing, a.Label)
+			continue
+		}
+		if fields := launchAgentFieldsChanged(cur, a); len(fields) > 0 {
+			ld.Changed = append(ld.Changed, LaunchAgentChanges{Label: a.Label, Fields: fields})
+		}
+	}
+	for _, a := range local {
+		if !want[a.Label] {
+			ld.Extra = append(ld.Extra, a.Label)
+		}
+	}
+	sort.Strings(ld.Missing)
+	sort.Strings(ld.Extra)
+	sort.Slice(ld.Changed, func(i, j int) bool { return ld.Changed[i].Label < ld.Changed[j].Label })
+	if len(ld.Missing)+len(ld.Extra)+len(ld.Changed) == 0 {
+		return nil
+	}
+	return ld
+}
+
+// launchAgentFieldsChanged returns the config names of the fields that
+// differ between a and b.
+func launchAgentFieldsChanged(a, b config.LaunchAgent) []string {
+	var fields []string
+	if !slices.Equal(a.ProgramArguments, b.ProgramArguments) {
+		fields = append(fields, "program_arguments")
+	}
+	if !maps.Equal(a.Environment, b.Environment) {
+		fields = append(fields, "environment")
+	}
+	if a.RunAtLoad != b.RunAtLoad {
+		fields = append(fields, "run_at_load")
+	}
+	if a.StartInterval != b.StartInterval {
+		fields = append(fields, "start_interval")
+	}
+	if a.StandardOutPath != b.StandardOutPath {
+		fields = append(fields, "stdout_path")
+	}
+	if a.StandardErrorPath != b.StandardErrorPath {
+		fields = append(fields, "stderr_path")
+	}
+	return fields
+}
+
+// sshHostEqual reports whether a and b render the same Host block.
+func sshHostEqual(a, b config.SSHHost) bool {
+	ra := (&config.RemoteSSHConfig{Hosts: []config.SSHHost{a}}).Render()
+	rb := (&config.RemoteSSHConfig{Hosts: []config.SSHHost{b}}).Render()
+	return ra == rb
+}
+
+// hasGitProfile reports whether profiles holds p's identity for p's
+// directory, under any name.
+func hasGitProfile(profiles []config.GitProfile, p config.GitProfile) bool {
+	for _, q := range profiles {
+		if q.SameIdentity(p) {
+			return true
+		}
+	}
+	return false
 }
 
 func diffPackages(system, reference *snapshot.Snapshot) PackageDiff {
+	sys, ref := system.Packages, reference.Packages
 	return PackageDiff{
-		Formulae: DiffLists(system.Packages.Formulae, reference.Packages.Formulae),
-		Casks:    DiffLists(system.Packages.Casks, reference.Packages.Casks),
+		Formulae: diffBrewList(sys.Formulae, sys.FormulaInfo, ref.Formulae, ref.FormulaInfo),
+		Casks:    diffBrewList(sys.Casks, sys.CaskInfo, ref.Casks, ref.CaskInfo),
 		Npm:      DiffLists(system.Packages.Npm, reference.Packages.Npm),
 		Taps:     DiffLists(system.Packages.Taps, reference.Packages.Taps),
 	}
 }
 
+// diffBrewList compares formula or cask lists, using the brew info each
+// side recorded when there is any. A reference package installed on the
+// system only as a dependency is not missing, a tapped package matches by
+// full or short name, and packages whose versions both sides know are
+// compared by version. Without system info it is DiffLists.
+func diffBrewList(system []string, systemInfo []snapshot.PackageInfo, reference []string, referenceInfo []snapshot.PackageInfo) ListDiff {
+	ld := DiffLists(system, reference)
+	if len(systemInfo) == 0 {
+		return ld
+	}
+
+	installed := snapshot.InstalledNames(systemInfo)
+	var missing []string
+	for _, name := range ld.Missing {
+		if installed[name] {
+			ld.Common++
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	ld.Missing = missing
+	refSet := ToSet(reference)
+	ld.Extra = slices.DeleteFunc(ld.Extra, func(name string) bool { return refSet[path.Base(name)] })
+
+	if len(referenceInfo) == 0 {
+		return ld
+	}
+	for _, name := range slices.Sorted(maps.Keys(refSet)) {
+		s, sok := snapshot.LookupPackageInfo(systemInfo, name)
+		r, rok := snapshot.LookupPackageInfo(referenceInfo, name)
+		if !sok || !rok || s.Version == "" || r.Version == "" || s.Version == r.Version {
+			continue
+		}
+		ld.Changed = append(ld.Changed, VersionChange{Name: name, System: s.Version, Reference: r.Version})
+		ld.Common--
+	}
+	return ld
+}
+
 func diffMacOS(system, reference []snapshot.MacOSPref) *MacOSDiff {
 	type prefKey struct {
 		Domain string
@@ -257,3 +568,74 @@ func diffDotfiles(systemURL, referenceURL string) *DotfilesDiff {
 
 	return dd
 }
+
+// CompareAppSettings compares the system's application settings against a
+// reference app_settings section, by app and then by file or domain
+// digest. Returns nil when nothing differs or ref is empty.
+func CompareAppSettings(local, ref *config.AppSettings) *AppSettingsDiff {
+	if ref.Empty() {
+		return nil
+	}
+	have := make(map[string]config.AppSettingsApp)
+	if local != nil {
+		for _, a := range local.Apps {
+			have[a.App] = a
+		}
+	}
+	want := make(map[string]bool, len(ref.Apps))
+	ad := &AppSettingsDiff{}
+	for _, a := range ref.Apps {
+		want[a.App] = true
+		cur, ok := have[a.App]
+		if !ok {
+			ad.Missing = append(ad.Missing, a.App)
+			continue
+		}
+		if targets := appSettingsTargetsChanged(cur, a); len(targets) > 0 {
+			ad.Changed = append(ad.Changed, AppSettingsChanges{App: a.App, Targets: targets})
+		}
+	}
+	if local != nil {
+		for _, a := range local.Apps {
+			if !want[a.App] {
+				ad.Extra = append(ad.Extra, a.App)
+			}
+		}
+	}
+	sort.Strings(ad.Missing)
+	sort.Strings(ad.Extra)
+	sort.Slice(ad.Changed, func(i, j int) bool { return ad.Changed[i].App < ad.Changed[j].App })
+	if len(ad.Missing)+len(ad.Extra)+len(ad.Changed) == 0 {
+		return nil
+	}
+	return ad
+}
+
+// appSettingsTargetsChanged returns, sorted, the files and domains whose
+// digest differs between a and b, including ones only one side has.
+func appSettingsTargetsChanged(a, b config.AppSettingsApp) []string {
+	digests := func(app config.AppSettingsApp) map[string]string {
+		m := make(map[string]string, len(app.Files)+len(app.Domains))
+		for _, r := range app.Files {
+			m[r.Target] = r.Blob
+		}
+		for _, r := range app.Domains {
+			m[r.Target] = r.Blob
+		}
+		return m
+	}
+	da, db := digests(a), digests(b)
+	var targets []string
+	for t, d := range db {
+		if da[t] != d {
+			targets = append(targets, t)
+		}
+	}
+	for t := range da {
+		if _, ok := db[t]; !ok {
+			targets = append(targets, t)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
@@ -74,6 +74,54 @@ func TestCompareSnapshots_PackageDifferences(t *testing.T) {
 	assert.Equal(t, 1, result.Packages.Casks.Common)
 }
 
+// With brew info on the system side, a reference formula installed only
+// as a dependency is present, not missing; with info on both sides,
+// versions are compared.
+func TestCompareSnapshots_PackageInfo(t *testing.T) {
+	isolateHome(t)
+	system := &snapshot.Snapshot{
+		Packages: snapshot.PackageSnapshot{
+			Formulae: []string{"git", "node"},
+			Casks:    []string{"firefox"},
+			FormulaInfo: []snapshot.PackageInfo{
+				{Name: "git", Version: "2.45.2", OnRequest: true},
+				{Name: "node", Version: "22.3.0", OnRequest: true},
+				{Name: "openssl@3", Version: "3.3.1"},
+			},
+			CaskInfo: []snapshot.PackageInfo{{Name: "firefox", Version: "127.0"}},
+		},
+	}
+	reference := &snapshot.Snapshot{
+		Packages: snapshot.PackageSnapshot{
+			Formulae: []string{"git", "node", "openssl@3", "ripgrep"},
+			Casks:    []string{"firefox"},
+			FormulaInfo: []snapshot.PackageInfo{
+				{Name: "git", Version: "2.45.2", OnRequest: true},
+				{Name: "node", Version: "20.11.0", OnRequest: true},
+				{Name: "openssl@3", Version: "3.3.1", OnRequest: true},
+				{Name: "ripgrep", Version: "14.1.0", OnRequest: true},
+			},
+			CaskInfo: []snapshot.PackageInfo{{Name: "firefox", Version: "128.0"}},
+		},
+	}
+
+	result := CompareSnapshots(system, reference, Source{Kind: "file", Path: "ref.json"})
+
+	assert.Equal(t, []string{"ripgrep"}, result.Packages.Formulae.Missing)
+	assert.Empty(t, result.Packages.Formulae.Extra)
+	assert.Equal(t, []VersionChange{{Name: "node", System: "22.3.0", Reference: "20.11.0"}}, result.Packages.Formulae.Changed)
+	assert.Equal(t, 2, result.Packages.Formulae.Common) // git, openssl@3
+	assert.Equal(t, []VersionChange{{Name: "firefox", System: "127.0", Reference: "128.0"}}, result.Packages.Casks.Changed)
+	assert.Equal(t, 2, result.TotalChanged())
+
+	// An older reference without info is compared by name only.
+	reference.Packages.FormulaInfo, reference.Packages.CaskInfo = nil, nil
+	result = CompareSnapshots(system, reference, Source{Kind: "file", Path: "ref.json"})
+	assert.Equal(t, []string{"ripgrep"}, result.Packages.Formulae.Missing)
+	assert.Empty(t, result.Packages.Formulae.Changed)
+	assert.Equal(t, 3, result.Packages.Formulae.Common)
+}
+
 func TestCompareSnapshots_MacOSDifferences(t *testing.T) {
 	isolateHome(t)
 	system := &snapshot.Snapshot{
@@ -187,6 +235,27 @@ func TestCompareSnapshotToRemote(t *testing.T) {
 	assert.Nil(t, result.DevTools)
 }
 
+func TestCompareSnapshotToRemote_DependencyIsNotMissing(t *testing.T) {
+	isolateHome(t)
+	system := &snapshot.Snapshot{
+		Packages: snapshot.PackageSnapshot{
+			Formulae: []string{"hashicorp/tap/terraform"},
+			FormulaInfo: []snapshot.PackageInfo{
+				{Name: "hashicorp/tap/terraform", OnRequest: true},
+				{Name: "openssl@3"},
+			},
+		},
+	}
+	remote := &config.RemoteConfig{
+		Packages: config.PackageEntryList{{Name: "openssl@3"}, {Name: "terraform"}},
+	}
+
+	result := CompareSnapshotToRemote(system, remote, Source{Kind: "remote", Path: "alice/my-config"})
+	assert.Empty(t, result.Packages.Formulae.Missing)
+	assert.Empty(t, result.Packages.Formulae.Extra)
+	assert.Equal(t, 2, result.Packages.Formulae.Common)
+}
+
 func TestCompareSnapshotToRemote_EmptyRemote(t *testing.T) {
 	isolateHome(t)
 	system := &snapshot.Snapshot{
@@ -300,3 +369,261 @@ func TestDiffDotfiles_EmptyReference(t *testing.T) {
 	result := diffDotfiles("https://github.com/user/dotfiles", "")
 	assert.Nil(t, result.RepoChanged)
 }
+
+func TestCompareShell(t *testing.T) {
+	local := &snapshot.ShellSnapshot{
+		Framework: config.FrameworkOhMyZsh,
+		OhMyZsh:   true,
+		Theme:     "robbyrussell",
+		Plugins:   []string{"git"},
+	}
+
+	assert.Nil(t, CompareShell(local, &config.RemoteShellConfig{OhMyZsh: true, Theme: "robbyrussell", Plugins: []string{"git"}}))
+
+	sd := CompareShell(local, &config.RemoteShellConfig{Framework: config.FrameworkZinit, Plugins: []string{"zsh-users/zsh-autosuggestions"}})
+	require.NotNil(t, sd)
+	assert.True(t, sd.FrameworkChanged)
+	assert.Equal(t, config.FrameworkOhMyZsh, sd.LocalFramework)
+	assert.Equal(t, config.FrameworkZinit, sd.ReferenceFramework)
+	assert.True(t, sd.PluginsChanged)
+	assert.False(t, sd.ThemeChanged)
+
+	sd = CompareShell(local, &config.RemoteShellConfig{OhMyZsh: true, Starship: true})
+	require.NotNil(t, sd)
+	assert.True(t, sd.StarshipChanged)
+	assert.False(t, sd.FrameworkChanged)
+
+	withStarship := &snapshot.ShellSnapshot{Starship: true, StarshipConfig: "add_newline = false\n"}
+	assert.Nil(t, CompareShell(withStarship, &config.RemoteShellConfig{Starship: true}), "no config means no opinion on starship.toml")
+	assert.Nil(t, CompareShell(withStarship, &config.RemoteShellConfig{Starship: true, StarshipConfig: "add_newline = false"}))
+	sd = CompareShell(withStarship, &config.RemoteShellConfig{Starship: true, StarshipConfig: "add_newline = true"})
+	require.NotNil(t, sd)
+	assert.True(t, sd.StarshipChanged)
+}
+
+func TestCompareShell_Snippets(t *testing.T) {
+	snippets := &config.ShellSnippets{Path: []string{"$HOME/bin"}}
+
+	assert.Nil(t, CompareShell(&snapshot.ShellSnapshot{Snippets: snippets}, &config.RemoteShellConfig{Snippets: snippets}))
+	assert.Nil(t, CompareShell(&snapshot.ShellSnapshot{}, nil), "no block and no section")
+
+	sd := CompareShell(&snapshot.ShellSnapshot{}, &config.RemoteShellConfig{Snippets: snippets})
+	require.NotNil(t, sd)
+	assert.True(t, sd.SnippetsChanged)
+	assert.False(t, sd.FrameworkChanged)
+
+	// A block the reference no longer carries is a change, so sync removes it.
+	sd = CompareShell(&snapshot.ShellSnapshot{Snippets: snippets}, nil)
+	require.NotNil(t, sd)
+	assert.True(t, sd.SnippetsChanged)
+	assert.Nil(t, sd.ReferenceSnippets)
+}
+
+func TestCompareGit(t *testing.T) {
+	local := &snapshot.GitSnapshot{
+		Settings:  map[string]string{"init.defaultBranch": "master", "alias.co": "checkout"},
+		Gitignore: ".DS_Store\n",
+	}
+
+	assert.Nil(t, CompareGit(local, nil))
+	assert.Nil(t, CompareGit(local, &config.RemoteGitConfig{
+		Settings:  map[string]string{"alias.co": "checkout"},
+		Gitignore: ".DS_Store",
+	}), "trailing whitespace in files is not a change")
+
+	gd := CompareGit(local, &config.RemoteGitConfig{
+		Settings:      map[string]string{"init.defaultbranch": "main", "pull.rebase": "true"},
+		Gitattributes: "*.png binary\n",
+	})
+	require.NotNil(t, gd)
+	assert.Equal(t, []GitSettingDelta{
+		{Key: "init.defaultBranch", System: "master", Reference: "main"},
+		{Key: "pull.rebase", System: "", Reference: "true"},
+	}, gd.Changed)
+	assert.False(t, gd.GitignoreChanged)
+	assert.True(t, gd.GitattributesChanged)
+	assert.Equal(t, 3, gd.Count())
+}
+
+func TestCompareGit_Profiles(t *testing.T) {
+	local := &snapshot.GitSnapshot{Profiles: []config.GitProfile{
+		{Name: "gitconfig-work", Directory: "~/work/", Email: "jane@acme.com"},
+	}}
+	ref := &config.RemoteGitConfig{Profiles: []config.GitProfile{
+		{Name: "work", Directory: "~/work", Email: "jane@acme.com"},
+	}}
+	assert.Nil(t, CompareGit(local, ref), "same identity under another name")
+
+	ref.Profiles = append(ref.Profiles, config.GitProfile{Name: "oss", Directory: "~/oss", Email: "jane@oss.dev"})
+	gd := CompareGit(local, ref)
+	require.NotNil(t, gd)
+	assert.Equal(t, []string{"oss"}, gd.ProfilesChanged)
+	assert.Equal(t, 1, gd.Count())
+}
+
+func TestCompareGit_Signing(t *testing.T) {
+	ref := &config.RemoteGitConfig{Signing: &config.GitSigning{Format: config.GitSigningSSH}}
+
+	gd := CompareGit(&snapshot.GitSnapshot{}, ref)
+	require.NotNil(t, gd)
+	assert.True(t, gd.SigningChanged)
+
+	gd = CompareGit(&snapshot.GitSnapshot{Signing: &config.GitSigning{Format: config.GitSigningGPG}}, ref)
+	require.NotNil(t, gd)
+	assert.True(t, gd.SigningChanged, "other format")
+
+	assert.Nil(t, CompareGit(&snapshot.GitSnapshot{Signing: &config.GitSigning{Format: config.GitSigningSSH}}, ref))
+}
+
+func TestCompareSSH(t *testing.T) {
+	ref := &config.RemoteSSHConfig{
+		Keys:  []config.SSHKey{{Name: "id_ed25519", Comment: "jane@acme.com"}},
+		Hosts: []config.SSHHost{{Host: "github.com", User: "git"}, {Host: "box", HostName: "10.0.0.2"}},
+	}
+
+	assert.Nil(t, CompareSSH(nil, nil))
+	assert.Nil(t, CompareSSH(&config.RemoteSSHConfig{Hosts: []config.SSHHost{{Host: "x"}}}, nil), "no ssh section, no opinion")
+
+	sd := CompareSSH(nil, ref)
+	require.NotNil(t, sd)
+	assert.Equal(t, []string{"github.com", "box"}, sd.HostsChanged)
+	assert.Equal(t, []string{"id_ed25519"}, sd.KeysMissing)
+	assert.False(t, sd.Stale)
+
+	local := &config.RemoteSSHConfig{
+		// Parsed keys carry no comment; only names are compared.
+		Keys:  []config.SSHKey{{Name: "id_ed25519"}},
+		Hosts: []config.SSHHost{{Host: "github.com", User: "git"}, {Host: "box", HostName: "10.0.0.3"}, {Host: "old"}},
+	}
+	sd = CompareSSH(local, ref)
+	require.NotNil(t, sd)
+	assert.Equal(t, []string{"box"}, sd.HostsChanged)
+	assert.Empty(t, sd.KeysMissing)
+	assert.True(t, sd.Stale)
+	assert.Equal(t, 2, sd.Count())
+
+	local.Hosts = ref.Hosts
+	assert.Nil(t, CompareSSH(local, ref))
+}
+
+func TestCompareMachine(t *testing.T) {
+	ref := &config.RemoteMachineConfig{LocalHostName: "{{user}}-mbp", HostName: "{{user}}-mbp"}
+
+	assert.Nil(t, CompareMachine(&config.RemoteMachineConfig{HostName: "x"}, nil, "jane"))
+
+	md := CompareMachine(&config.RemoteMachineConfig{ComputerName: "Anything", LocalHostName: "jane-mbp"}, ref, "jane")
+	require.NotNil(t, md)
+	assert.Equal(t, []MachineNameDelta{{Key: "HostName", System: "", Reference: "jane-mbp"}}, md.Changed,
+		"ComputerName is not in the reference, LocalHostName matches")
+
+	assert.Nil(t, CompareMachine(&config.RemoteMachineConfig{LocalHostName: "jane-mbp", HostName: "jane-mbp"}, ref, "jane"))
+}
+
+func TestCompareKeyboard(t *testing.T) {
+	ref := &config.RemoteKeyboardConfig{
+		AppShortcuts:    map[string]map[string]string{"com.apple.Safari": {"Show All Tabs": "@~t", "Pin Tab": "@$p"}},
+		SymbolicHotKeys: []config.SymbolicHotKey{{ID: 64, Enabled: false}},
+		KeyMappings:     []config.KeyMapping{{From: "caps_lock", To: "escape"}},
+	}
+	assert.Nil(t, CompareKeyboard(ref, nil))
+
+	local := &config.RemoteKeyboardConfig{
+		AppShortcuts:    map[string]map[string]string{"com.apple.Safari": {"Show All Tabs": "@~t", "Extra": "@e"}},
+		SymbolicHotKeys: []config.SymbolicHotKey{{ID: 64, Enabled: true, Parameters: []int{32, 49, 1048576}}, {ID: 60, Enabled: true}},
+	}
+	kd := CompareKeyboard(local, ref)
+	require.NotNil(t, kd)
+	assert.Equal(t, []string{"com.apple.Safari: Pin Tab"}, kd.ShortcutsChanged, "shortcuts only local are not compared")
+	assert.Equal(t, []int{64}, kd.HotKeysChanged)
+	assert.True(t, kd.MappingChanged)
+	assert.Equal(t, 3, kd.Count())
+
+	local.AppShortcuts["com.apple.Safari"]["Pin Tab"] = "@$p"
+	local.SymbolicHotKeys[0].Enabled = false
+	local.KeyMappings = []config.KeyMapping{{From: "0x700000039", To: "escape"}}
+	assert.Nil(t, CompareKeyboard(local, ref))
+}
+
+func TestCompareLaunchAgents(t *testing.T) {
+	sync := config.LaunchAgent{Label: "com.example.sync", ProgramArguments: []string{"/usr/local/bin/sync"}, StartInterval: 300}
+	colima := config.LaunchAgent{Label: "com.example.colima", ProgramArguments: []string{"/opt/homebrew/bin/colima", "start"}, RunAtLoad: true}
+	assert.Nil(t, CompareLaunchAgents([]config.LaunchAgent{sync}, nil))
+	assert.Nil(t, CompareLaunchAgents([]config.LaunchAgent{sync, colima}, []config.LaunchAgent{colima, sync}))
+
+	localSync := sync
+	localSync.StartInterval = 60
+	localSync.Environment = map[string]string{"DEBUG": "1"}
+	stale := config.LaunchAgent{Label: "com.example.old", ProgramArguments: []string{"/bin/true"}}
+	ld := CompareLaunchAgents([]config.LaunchAgent{localSync, stale}, []config.LaunchAgent{sync, colima})
+	require.NotNil(t, ld)
+	assert.Equal(t, []string{"com.example.colima"}, ld.Missing)
+	assert.Equal(t, []string{"com.example.old"}, ld.Extra)
+	assert.Equal(t, []LaunchAgentChanges{{Label: "com.example.sync", Fields: []string{"environment", "start_interval"}}}, ld.Changed)
+
+	r := &DiffResult{LaunchAgents: ld}
+	assert.Equal(t, 1, r.TotalMissing())
+	assert.Equal(t, 1, r.TotalExtra())
+	assert.Equal(t, 1, r.TotalChanged())
+}
+
+func TestCompareAppSettings(t *testing.T) {
+	const settings = "~/Library/Application Support/Code/User/settings.json"
+	const keybindings = "~/Library/Application Support/Code/User/keybindings.json"
+	build := func(files map[string]string, domains map[string]string, app string) config.AppSettingsApp {
+		a := config.AppSettingsApp{App: app}
+		for t, c := range files {
+			a.Files = append(a.Files, config.AppSettingsRef{Target: t, Blob: config.BlobDigest([]byte(c))})
+		}
+		for t, c := range domains {
+			a.Domains = append(a.Domains, config.AppSettingsRef{Target: t, Blob: config.BlobDigest([]byte(c))})
+		}
+		return a
+	}
+	ref := &config.AppSettings{Apps: []config.AppSettingsApp{
+		build(map[string]string{settings: "a", keybindings: "k"}, nil, "vscode"),
+		build(nil, map[string]string{"com.googlecode.iterm2": "p"}, "iterm2"),
+	}}
+	assert.Nil(t, CompareAppSettings(ref, nil))
+	assert.Nil(t, CompareAppSettings(ref, ref))
+
+	local := &config.AppSettings{Apps: []config.AppSettingsApp{
+		build(map[string]string{settings: "b"}, nil, "vscode"),
+		build(nil, map[string]string{"com.knollsoft.Rectangle": "r"}, "rectangle"),
+	}}
+	ad := CompareAppSettings(local, ref)
+	require.NotNil(t, ad)
+	assert.Equal(t, []string{"iterm2"}, ad.Missing)
+	assert.Equal(t, []string{"rectangle"}, ad.Extra)
+	assert.Equal(t, []AppSettingsChanges{{App: "vscode", Targets: []string{keybindings, settings}}}, ad.Changed)
+
+	r := &DiffResult{AppSettings: ad}
+	assert.Equal(t, 1, r.TotalMissing())
+	assert.Equal(t, 1, r.TotalExtra())
+	assert.Equal(t, 1, r.TotalChanged())
+
+	data, err := FormatJSON(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"app_settings"`)
+}
+
+// The remote side is compared against a fresh read of the apps it
+// carries, not the opted-in capture in the system snapshot.
+func TestCompareSnapshotToRemote_AppSettings(t *testing.T) {
+	ref := &config.AppSettings{}
+	ref.Apps = []config.AppSettingsApp{{App: "rectangle", Domains: []config.AppSettingsRef{{Target: "com.knollsoft.Rectangle", Blob: ref.Put([]byte("new"))}}}}
+
+	orig := captureAppSettingsFor
+	t.Cleanup(func() { captureAppSettingsFor = orig })
+	var asked []string
+	captureAppSettingsFor = func(ids []string) (*config.AppSettings, error) {
+		asked = ids
+		local := &config.AppSettings{}
+		local.Apps = []config.AppSettingsApp{{App: "rectangle", Domains: []config.AppSettingsRef{{Target: "com.knollsoft.Rectangle", Blob: local.Put([]byte("old"))}}}}
+		return local, nil
+	}
+
+	result := CompareSnapshotToRemote(&snapshot.Snapshot{}, &config.RemoteConfig{AppSettings: ref}, Source{Kind: "remote"})
+	assert.Equal(t, []string{"rectangle"}, asked)
+	require.NotNil(t, result.AppSettings)
+	assert.Equal(t, []AppSettingsChanges{{App: "rectangle", Targets: []string{"com.knollsoft.Rectangle"}}}, result.AppSettings.Changed)
+}
@@ -1,11 +1,15 @@
 package diff
 
-import "sort"
+import (
+	"sort"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
 
 // Source describes where the reference configuration came from.
 type Source struct {
-	Kind string // "file", "local", "remote"
-	Path string // file path, "~/.openboot/snapshot.json", or "user/slug"
+	Kind string // "file", "local", "remote", "history"
+	Path string // file path, "~/.openboot/snapshot.json", "user/slug", or "a → b"
 }
 
 // ListDiff is the result of comparing two string lists (e.g. formulae).
@@ -13,6 +17,16 @@ type ListDiff struct {
 	Missing []string // in reference but not in system
 	Extra   []string // in system but not in reference
 	Common  int      // count of items in both
+	// Changed lists packages in both at different versions, when both
+	// sides recorded versions. They are not counted in Common.
+	Changed []VersionChange `json:",omitempty"`
+}
+
+// VersionChange records a package installed at different versions.
+type VersionChange struct {
+	Name      string `json:"name"`
+	System    string `json:"system"`
+	Reference string `json:"reference"`
 }
 
 // ValueChange records a single scalar value that differs.
@@ -75,22 +89,160 @@ type DotfilesDiff struct {
 
 // ShellDiff holds shell configuration differences between system and reference.
 type ShellDiff struct {
-	ThemeChanged     bool
-	LocalTheme       string
-	ReferenceTheme   string
-	PluginsChanged   bool
-	LocalPlugins     []string
-	ReferencePlugins []string
+	FrameworkChanged   bool
+	LocalFramework     string
+	ReferenceFramework string
+	ThemeChanged       bool
+	LocalTheme         string
+	ReferenceTheme     string
+	PluginsChanged     bool
+	LocalPlugins       []string
+	ReferencePlugins   []string
+	StarshipChanged    bool // starship not set up locally, or starship.toml differs
+	SnippetsChanged    bool
+	LocalSnippets      *config.ShellSnippets
+	ReferenceSnippets  *config.ShellSnippets
+}
+
+// GitDiff holds global git configuration differences. Only what the
+// reference sets is compared.
+type GitDiff struct {
+	Changed              []GitSettingDelta `json:"changed,omitempty"`
+	GitignoreChanged     bool              `json:"gitignore_changed,omitempty"`
+	GitattributesChanged bool              `json:"gitattributes_changed,omitempty"`
+	// Names of reference profiles missing locally or with a different
+	// identity.
+	ProfilesChanged []string `json:"profiles_changed,omitempty"`
+	// SigningChanged is set when the reference signs commits and the system
+	// does not, or uses another format.
+	SigningChanged bool `json:"signing_changed,omitempty"`
+}
+
+// GitSettingDelta records a git setting whose value differs. System is ""
+// when the setting is unset locally.
+type GitSettingDelta struct {
+	Key       string `json:"key"`
+	System    string `json:"system"`
+	Reference string `json:"reference"`
+}
+
+// Count returns the number of differing settings, files and profiles.
+func (g *GitDiff) Count() int {
+	n := len(g.Changed) + len(g.ProfilesChanged)
+	if g.GitignoreChanged {
+		n++
+	}
+	if g.GitattributesChanged {
+		n++
+	}
+	if g.SigningChanged {
+		n++
+	}
+	return n
+}
+
+// SSHDiff holds differences in the managed ~/.ssh/config block. Only a
+// reference with an ssh section is compared.
+type SSHDiff struct {
+	// Host patterns whose block is missing locally or differs.
+	HostsChanged []string `json:"hosts_changed,omitempty"`
+	// Names of reference keys the local block does not load.
+	KeysMissing []string `json:"keys_missing,omitempty"`
+	// Stale is set when the local block has hosts or keys the reference
+	// does not; openboot owns the block, so they would be removed.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// Count returns the number of differing hosts and keys, counting a stale
+// block as one.
+func (s *SSHDiff) Count() int {
+	n := len(s.HostsChanged) + len(s.KeysMissing)
+	if s.Stale {
+		n++
+	}
+	return n
+}
+
+// MachineDiff holds machine names that differ. Only the names the
+// reference sets are compared, after filling in its {{user}} placeholders.
+type MachineDiff struct {
+	Changed []MachineNameDelta `json:"changed"`
+}
+
+// MachineNameDelta records one scutil name that differs. System is "" when
+// the name is unset locally.
+type MachineNameDelta struct {
+	Key       string `json:"key"`
+	System    string `json:"system"`
+	Reference string `json:"reference"`
+}
+
+// KeyboardDiff holds keyboard settings that differ. Only what the
+// reference sets is compared.
+type KeyboardDiff struct {
+	// App shortcuts missing or different locally, as "<domain>: <title>".
+	ShortcutsChanged []string `json:"shortcuts_changed,omitempty"`
+	// IDs of symbolic hotkeys that differ.
+	HotKeysChanged []int `json:"hotkeys_changed,omitempty"`
+	// MappingChanged is set when the hidutil key mapping differs.
+	MappingChanged bool `json:"mapping_changed,omitempty"`
+}
+
+// Count returns the number of differing shortcuts and hotkeys, counting a
+// changed key mapping as one.
+func (k *KeyboardDiff) Count() int {
+	n := len(k.ShortcutsChanged) + len(k.HotKeysChanged)
+	if k.MappingChanged {
+		n++
+	}
+	return n
+}
+
+// LaunchAgentsDiff holds differences in openboot-managed LaunchAgents.
+// Only a reference with a launch_agents section is compared.
+type LaunchAgentsDiff struct {
+	Missing []string             `json:"missing,omitempty"` // labels in reference but not on the system
+	Extra   []string             `json:"extra,omitempty"`   // managed labels on the system but not in reference
+	Changed []LaunchAgentChanges `json:"changed,omitempty"`
+}
+
+// LaunchAgentChanges names the fields of one agent that differ, as they
+// are spelled in the config (e.g. "program_arguments").
+type LaunchAgentChanges struct {
+	Label  string   `json:"label"`
+	Fields []string `json:"fields"`
+}
+
+// AppSettingsDiff holds differences in captured application settings, by
+// registry app id. Only a reference with an app_settings section is
+// compared.
+type AppSettingsDiff struct {
+	Missing []string             `json:"missing,omitempty"` // apps with settings in reference but none on the system
+	Extra   []string             `json:"extra,omitempty"`   // apps with settings on the system but not in reference
+	Changed []AppSettingsChanges `json:"changed,omitempty"`
+}
+
+// AppSettingsChanges names the files and defaults domains of one app whose
+// content differs, spelled as in the registry.
+type AppSettingsChanges struct {
+	App     string   `json:"app"`
+	Targets []string `json:"targets"`
 }
 
 // DiffResult is the top-level diff output.
 type DiffResult struct {
-	Source   Source
-	Packages PackageDiff
-	MacOS    *MacOSDiff    // nil when not compared
-	DevTools *DevToolDiff  // nil when not compared
-	Dotfiles *DotfilesDiff // nil when not compared
-	Shell    *ShellDiff    // nil when not compared
+	Source       Source
+	Packages     PackageDiff
+	MacOS        *MacOSDiff        // nil when not compared
+	DevTools     *DevToolDiff      // nil when not compared
+	Dotfiles     *DotfilesDiff     // nil when not compared
+	Shell        *ShellDiff        // nil when not compared
+	Git          *GitDiff          // nil when not compared or identical
+	SSH          *SSHDiff          // nil when not compared or identical
+	Machine      *MachineDiff      // nil when not compared or identical
+	Keyboard     *KeyboardDiff     // nil when not compared or identical
+	LaunchAgents *LaunchAgentsDiff // nil when not compared or identical
+	AppSettings  *AppSettingsDiff  // nil when not compared or identical
 }
 
 // DiffLists computes a bidirectional set diff between system and reference string slices.
@@ -138,6 +290,18 @@ func (r *DiffResult) HasChanges() bool {
 	if r.Shell != nil {
 		return true
 	}
+	if r.Git != nil {
+		return true
+	}
+	if r.SSH != nil {
+		return true
+	}
+	if r.Machine != nil {
+		return true
+	}
+	if r.Keyboard != nil {
+		return true
+	}
 	return false
 }
 
@@ -154,6 +318,12 @@ func (r *DiffResult) TotalMissing() int {
 	if r.DevTools != nil {
 		n += len(r.DevTools.Missing)
 	}
+	if r.LaunchAgents != nil {
+		n += len(r.LaunchAgents.Missing)
+	}
+	if r.AppSettings != nil {
+		n += len(r.AppSettings.Missing)
+	}
 	return n
 }
 
@@ -170,12 +340,18 @@ func (r *DiffResult) TotalExtra() int {
 	if r.DevTools != nil {
 		n += len(r.DevTools.Extra)
 	}
+	if r.LaunchAgents != nil {
+		n += len(r.LaunchAgents.Extra)
+	}
+	if r.AppSettings != nil {
+		n += len(r.AppSettings.Extra)
+	}
 	return n
 }
 
 // TotalChanged returns the count of values that differ between system and reference.
 func (r *DiffResult) TotalChanged() int {
-	n := 0
+	n := len(r.Packages.Formulae.Changed) + len(r.Packages.Casks.Changed)
 	if r.MacOS != nil {
 		n += len(r.MacOS.Changed)
 	}
@@ -188,6 +364,24 @@ func (r *DiffResult) TotalChanged() int {
 	if r.Shell != nil {
 		n++
 	}
+	if r.Git != nil {
+		n += r.Git.Count()
+	}
+	if r.SSH != nil {
+		n += r.SSH.Count()
+	}
+	if r.Machine != nil {
+		n += len(r.Machine.Changed)
+	}
+	if r.Keyboard != nil {
+		n += r.Keyboard.Count()
+	}
+	if r.LaunchAgents != nil {
+		n += len(r.LaunchAgents.Changed)
+	}
+	if r.AppSettings != nil {
+		n += len(r.AppSettings.Changed)
+	}
 	return n
 }
 
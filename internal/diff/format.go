@@ -36,6 +36,24 @@ func FormatTerminal(result *DiffResult, packagesOnly bool) {
 		if result.Shell != nil {
 			printShellSection(result.Shell)
 		}
+		if result.Git != nil {
+			printGitSection(result.Git)
+		}
+		if result.SSH != nil {
+			printSSHSection(result.SSH)
+		}
+		if result.Machine != nil {
+			printMachineSection(result.Machine)
+		}
+		if result.Keyboard != nil {
+			printKeyboardSection(result.Keyboard)
+		}
+		if result.LaunchAgents != nil {
+			printLaunchAgentsSection(result.LaunchAgents)
+		}
+		if result.AppSettings != nil {
+			printAppSettingsSection(result.AppSettings)
+		}
 	}
 
 	printSummary(result)
@@ -44,12 +62,18 @@ func FormatTerminal(result *DiffResult, packagesOnly bool) {
 // FormatJSON returns the diff result as indented JSON.
 func FormatJSON(result *DiffResult) ([]byte, error) {
 	out := jsonOutput{
-		Source:   result.Source,
-		Packages: result.Packages,
-		Dotfiles: result.Dotfiles,
-		MacOS:    result.MacOS,
-		DevTools: result.DevTools,
-		Shell:    result.Shell,
+		Source:       result.Source,
+		Packages:     result.Packages,
+		Dotfiles:     result.Dotfiles,
+		MacOS:        result.MacOS,
+		DevTools:     result.DevTools,
+		Shell:        result.Shell,
+		Git:          result.Git,
+		SSH:          result.SSH,
+		Machine:      result.Machine,
+		Keyboard:     result.Keyboard,
+		LaunchAgents: result.LaunchAgents,
+		AppSettings:  result.AppSettings,
 		Summary: jsonSummary{
 			Missing: result.TotalMissing(),
 			Extra:   result.TotalExtra(),
@@ -61,13 +85,19 @@ func FormatJSON(result *DiffResult) ([]byte, error) {
 }
 
 type jsonOutput struct {
-	Source   Source        `json:"source"`
-	Packages PackageDiff   `json:"packages"`
-	Dotfiles *DotfilesDiff `json:"dotfiles,omitempty"`
-	MacOS    *MacOSDiff    `json:"macos,omitempty"`
-	DevTools *DevToolDiff  `json:"dev_tools,omitempty"`
-	Shell    *ShellDiff    `json:"shell,omitempty"`
-	Summary  jsonSummary   `json:"summary"`
+	Source       Source            `json:"source"`
+	Packages     PackageDiff       `json:"packages"`
+	Dotfiles     *DotfilesDiff     `json:"dotfiles,omitempty"`
+	MacOS        *MacOSDiff        `json:"macos,omitempty"`
+	DevTools     *DevToolDiff      `json:"dev_tools,omitempty"`
+	Shell        *ShellDiff        `json:"shell,omitempty"`
+	Git          *GitDiff          `json:"git,omitempty"`
+	SSH          *SSHDiff          `json:"ssh,omitempty"`
+	Machine      *MachineDiff      `json:"machine,omitempty"`
+	Keyboard     *KeyboardDiff     `json:"keyboard,omitempty"`
+	LaunchAgents *LaunchAgentsDiff `json:"launch_agents,omitempty"`
+	AppSettings  *AppSettingsDiff  `json:"app_settings,omitempty"`
+	Summary      jsonSummary       `json:"summary"`
 }
 
 type jsonSummary struct {
@@ -84,17 +114,23 @@ func printSource(source Source) {
 		ui.Info(fmt.Sprintf("Comparing: system vs snapshot file (%s)", source.Path))
 	case "remote":
 		ui.Info(fmt.Sprintf("Comparing: system vs remote config (%s)", source.Path))
+	case "history":
+		ui.Info(fmt.Sprintf("Comparing: snapshot history %s (+ added, - removed)", source.Path))
 	default:
 		ui.Info(fmt.Sprintf("Comparing: system vs %s", source.Path))
 	}
 }
 
 func printListSection(name string, ld ListDiff) {
-	if len(ld.Missing) == 0 && len(ld.Extra) == 0 && ld.Common == 0 {
+	if len(ld.Missing) == 0 && len(ld.Extra) == 0 && len(ld.Changed) == 0 && ld.Common == 0 {
 		return
 	}
 
 	ui.Printf("  %s:\n", name)
+	for _, c := range ld.Changed {
+		ui.Printf("    %s %s: %s %s %s\n",
+			ui.Yellow("~"), c.Name, c.System, ui.Yellow("\u2192"), c.Reference)
+	}
 	for _, item := range ld.Missing {
 		ui.Printf("    %s %-28s %s\n", ui.Green("+"), item, ui.Green("(missing)"))
 	}
@@ -170,10 +206,18 @@ func printDevToolsSection(dd *DevToolDiff) {
 }
 
 func printShellSection(sd *ShellDiff) {
-	if !sd.ThemeChanged && !sd.PluginsChanged {
+	if !sd.FrameworkChanged && !sd.ThemeChanged && !sd.PluginsChanged && !sd.StarshipChanged && !sd.SnippetsChanged {
 		return
 	}
 	ui.Printf("  Shell:\n")
+	if sd.FrameworkChanged {
+		local := sd.LocalFramework
+		if local == "" {
+			local = "(none)"
+		}
+		ui.Printf("    %s framework: %s %s %s\n",
+			ui.Yellow("~"), local, ui.Yellow("\u2192"), sd.ReferenceFramework)
+	}
 	if sd.ThemeChanged {
 		local := sd.LocalTheme
 		if local == "" {
@@ -190,6 +234,107 @@ func printShellSection(sd *ShellDiff) {
 		ui.Printf("    %s plugins: %s %s %s\n",
 			ui.Yellow("~"), local, ui.Yellow("\u2192"), strings.Join(sd.ReferencePlugins, ", "))
 	}
+	if sd.StarshipChanged {
+		ui.Printf("    %s starship prompt or starship.toml differs\n", ui.Yellow("~"))
+	}
+	if sd.SnippetsChanged {
+		ui.Printf("    %s snippets: %s %s %s\n",
+			ui.Yellow("~"), sd.LocalSnippets.Summary(), ui.Yellow("\u2192"), sd.ReferenceSnippets.Summary())
+	}
+	ui.Println()
+}
+
+func printGitSection(gd *GitDiff) {
+	ui.Printf("  Git:\n")
+	for _, c := range gd.Changed {
+		system := c.System
+		if system == "" {
+			system = "(unset)"
+		}
+		ui.Printf("    %s %s: %s %s %s\n",
+			ui.Yellow("~"), c.Key, system, ui.Yellow("\u2192"), c.Reference)
+	}
+	if gd.GitignoreChanged {
+		ui.Printf("    %s global gitignore differs\n", ui.Yellow("~"))
+	}
+	if gd.GitattributesChanged {
+		ui.Printf("    %s global gitattributes differs\n", ui.Yellow("~"))
+	}
+	if gd.SigningChanged {
+		ui.Printf("    %s commit signing differs\n", ui.Yellow("~"))
+	}
+	for _, name := range gd.ProfilesChanged {
+		ui.Printf("    %s profile %s differs\n", ui.Yellow("~"), name)
+	}
+	ui.Println()
+}
+
+func printSSHSection(sd *SSHDiff) {
+	ui.Printf("  SSH:\n")
+	for _, h := range sd.HostsChanged {
+		ui.Printf("    %s Host %s differs\n", ui.Yellow("~"), h)
+	}
+	for _, k := range sd.KeysMissing {
+		ui.Printf("    %s key %s not loaded\n", ui.Yellow("~"), k)
+	}
+	if sd.Stale {
+		ui.Printf("    %s managed block has hosts or keys the reference does not\n", ui.Yellow("~"))
+	}
+	ui.Println()
+}
+
+func printMachineSection(md *MachineDiff) {
+	ui.Printf("  Machine:\n")
+	for _, c := range md.Changed {
+		system := c.System
+		if system == "" {
+			system = "(unset)"
+		}
+		ui.Printf("    %s %s: %s %s %s\n",
+			ui.Yellow("~"), c.Key, system, ui.Yellow("\u2192"), c.Reference)
+	}
+	ui.Println()
+}
+
+func printKeyboardSection(kd *KeyboardDiff) {
+	ui.Printf("  Keyboard:\n")
+	for _, s := range kd.ShortcutsChanged {
+		ui.Printf("    %s shortcut %s differs\n", ui.Yellow("~"), s)
+	}
+	for _, id := range kd.HotKeysChanged {
+		ui.Printf("    %s symbolic hotkey %d differs\n", ui.Yellow("~"), id)
+	}
+	if kd.MappingChanged {
+		ui.Printf("    %s key mapping differs\n", ui.Yellow("~"))
+	}
+	ui.Println()
+}
+
+func printLaunchAgentsSection(ld *LaunchAgentsDiff) {
+	ui.Printf("  Launch agents:\n")
+	for _, label := range ld.Missing {
+		ui.Printf("    %s %s\n", ui.Green("+"), label)
+	}
+	for _, label := range ld.Extra {
+		ui.Printf("    %s %s\n", ui.Red("-"), label)
+	}
+	for _, c := range ld.Changed {
+		ui.Printf("    %s %s: %s\n", ui.Yellow("~"), c.Label, strings.Join(c.Fields, ", "))
+	}
+	ui.Println()
+}
+
+func printAppSettingsSection(ad *AppSettingsDiff) {
+	ui.Printf("  App settings:\n")
+	for _, app := range ad.Missing {
+		ui.Printf("    %s %s\n", ui.Green("+"), app)
+	}
+	for _, app := range ad.Extra {
+		ui.Printf("    %s %s\n", ui.Red("-"), app)
+	}
+	for _, c := range ad.Changed {
+		ui.Printf("    %s %s: %s\n", ui.Yellow("~"), c.App, strings.Join(c.Targets, ", "))
+	}
 	ui.Println()
 }
 
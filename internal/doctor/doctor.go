@@ -35,12 +35,16 @@ type Summary struct {
 }
 
 // CommandRunner abstracts subprocess execution for testability.
-// Production code wires this to system.RunCommandSilent / system.RunCommandOutput.
+// Production code wires this to system.RunCommandSilent / system.RunCommandOutput /
+// system.RunCommandSilentInput.
 type CommandRunner interface {
 	// RunSilent runs a command and returns combined stdout+stderr.
 	RunSilent(name string, args ...string) (string, error)
 	// RunOutput runs a command and returns stdout only.
 	RunOutput(name string, args ...string) (string, error)
+	// RunInput runs a command with input on its stdin and returns combined
+	// stdout+stderr.
+	RunInput(input string, name string, args ...string) (string, error)
 }
 
 // defaultRunner delegates to the system package wrappers.
@@ -54,6 +58,10 @@ func (defaultRunner) RunOutput(name string, args ...string) (string, error) {
 	return system.RunCommandOutput(name, args...)
 }
 
+func (defaultRunner) RunInput(input string, name string, args ...string) (string, error) {
+	return system.RunCommandSilentInput(input, name, args...)
+}
+
 // Doctor holds configuration for running diagnostic checks.
 type Doctor struct {
 	Runner  CommandRunner
@@ -86,6 +94,7 @@ func (d *Doctor) RunAll() Summary {
 		d.CheckOpenBootState(),
 		d.CheckPATH(),
 	}
+	results = append(results, d.CheckSecurity()...)
 
 	ui.Muted("") // blank line before summary
 	var s Summary
@@ -55,6 +55,10 @@ func (f *fakeRunner) RunOutput(name string, args ...string) (string, error) {
 	return "", nil
 }
 
+func (f *fakeRunner) RunInput(_ string, name string, args ...string) (string, error) {
+	return f.RunSilent(name, args...)
+}
+
 func TestCheckGit_Configured(t *testing.T) {
 	runner := newFakeRunner()
 	runner.silentResults["git config --global user.name"] = fakeResult{out: "Alice"}
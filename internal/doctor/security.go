@@ -0,0 +1,221 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// Paths the security controls read and write.
+const (
+	sudoLocalPath     = "/etc/pam.d/sudo_local"
+	sudoLocalTemplate = "/etc/pam.d/sudo_local.template"
+	socketFilterFW    = "/usr/libexec/ApplicationFirewall/socketfilterfw"
+)
+
+// pamTIDLine is the sudo_local line that lets Touch ID answer sudo.
+const pamTIDLine = "auth       sufficient     pam_tid.so"
+
+// SecurityControl is one opt-in hardening step. Check reports whether the
+// control is already on; Apply turns it on and may assume sudo has a
+// cached credential. Both go through the runner so tests never touch the
+// machine.
+type SecurityControl struct {
+	Key   string // the config.RemoteSecurityConfig JSON key
+	Name  string
+	Check func(r CommandRunner) (bool, error)
+	Apply func(r CommandRunner) error
+}
+
+// SecurityControls lists every control in the order they are applied.
+var SecurityControls = []SecurityControl{
+	{Key: config.SecurityTouchIDSudo, Name: "Touch ID for sudo", Check: touchIDSudoEnabled, Apply: enableTouchIDSudo},
+	{Key: config.SecurityFirewall, Name: "Firewall", Check: firewallEnabled, Apply: enableFirewall},
+	{Key: config.SecurityStealthMode, Name: "Firewall stealth mode", Check: stealthModeEnabled, Apply: enableStealthMode},
+}
+
+// sudoValidate asks for the sudo password once, on the terminal, so the
+// controls can then run `sudo -n`. It is a var so tests can skip it.
+var sudoValidate = func() error {
+	return system.RunCommandWithTTY("sudo", "-v")
+}
+
+// ApplySecurity turns on each control cfg enables that is not on yet,
+// prompting for sudo at most once. It returns the number of controls
+// changed.
+func ApplySecurity(r CommandRunner, cfg *config.RemoteSecurityConfig, dryRun bool) (int, error) {
+	var pending []SecurityControl
+	for _, key := range cfg.Enabled() {
+		c, err := securityControl(key)
+		if err != nil {
+			return 0, err
+		}
+		on, err := c.Check(r)
+		if err != nil {
+			return 0, fmt.Errorf("check %s: %w", c.Name, err)
+		}
+		if !on {
+			pending = append(pending, c)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	if dryRun {
+		for _, c := range pending {
+			ui.DryRunMsg("Would enable %s", c.Name)
+		}
+		return len(pending), nil
+	}
+	if os.Geteuid() != 0 {
+		if err := sudoValidate(); err != nil {
+			return 0, fmt.Errorf("sudo: %w", err)
+		}
+	}
+	for i, c := range pending {
+		if err := c.Apply(r); err != nil {
+			return i, fmt.Errorf("enable %s: %w", c.Name, err)
+		}
+	}
+	return len(pending), nil
+}
+
+// ApplySecurityDefault is ApplySecurity with the system runner.
+func ApplySecurityDefault(cfg *config.RemoteSecurityConfig, dryRun bool) (int, error) {
+	return ApplySecurity(defaultRunner{}, cfg, dryRun)
+}
+
+func securityControl(key string) (SecurityControl, error) {
+	for _, c := range SecurityControls {
+		if c.Key == key {
+			return c, nil
+		}
+	}
+	return SecurityControl{}, fmt.Errorf("unknown security control %q", key)
+}
+
+// CheckSecurity reports the state of every security control. A control
+// that is off is a warning: all of them are opt-in.
+func (d *Doctor) CheckSecurity() []Result {
+	results := make([]Result, 0, len(SecurityControls))
+	for _, c := range SecurityControls {
+		on, err := c.Check(d.Runner)
+		switch {
+		case err != nil:
+			results = append(results, warn(fmt.Sprintf("Could not check %s: %v", c.Name, err)))
+		case on:
+			results = append(results, ok(c.Name+" enabled"))
+		default:
+			results = append(results, warn(fmt.Sprintf("%s off (enable with security.%s)", c.Name, c.Key)))
+		}
+	}
+	return results
+}
+
+// ── Touch ID for sudo ────────────────────────────────────────────────────────
+
+// touchIDSudoEnabled reports whether sudo_local has an active pam_tid.so
+// line. A missing sudo_local means it is off.
+func touchIDSudoEnabled(r CommandRunner) (bool, error) {
+	content, err := r.RunOutput("cat", sudoLocalPath)
+	if err != nil {
+		return false, nil
+	}
+	return hasPamTID(content), nil
+}
+
+// enableTouchIDSudo writes sudo_local with pam_tid.so enabled, starting from
+// the current file or, on a fresh machine, the template macOS ships. The
+// content goes to `sudo tee` on stdin, so nothing is staged on disk.
+func enableTouchIDSudo(r CommandRunner) error {
+	content, err := r.RunOutput("cat", sudoLocalPath)
+	if err != nil {
+		content, err = r.RunOutput("cat", sudoLocalTemplate)
+		if err != nil {
+			return fmt.Errorf("no %s or %s (needs macOS 14 or later)", sudoLocalPath, sudoLocalTemplate)
+		}
+	}
+
+	// tee keeps the file's mode and owner when it exists and creates it
+	// root-owned when it does not; set both to what macOS ships either way.
+	if _, err := r.RunInput(withPamTID(content), "sudo", "-n", "tee", sudoLocalPath); err != nil {
+		return fmt.Errorf("write %s: %w", sudoLocalPath, err)
+	}
+	if _, err := r.RunSilent("sudo", "-n", "chown", "root:wheel", sudoLocalPath); err != nil {
+		return fmt.Errorf("chown %s: %w", sudoLocalPath, err)
+	}
+	if _, err := r.RunSilent("sudo", "-n", "chmod", "444", sudoLocalPath); err != nil {
+		return fmt.Errorf("chmod %s: %w", sudoLocalPath, err)
+	}
+	return nil
+}
+
+// hasPamTID reports whether content, a PAM file, has an uncommented auth
+// line for pam_tid.so.
+func hasPamTID(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		f := strings.Fields(line)
+		if len(f) >= 3 && f[0] == "auth" && f[2] == "pam_tid.so" {
+			return true
+		}
+	}
+	return false
+}
+
+// withPamTID returns content with pam_tid.so enabled: a commented-out
+// pam_tid.so line, as in the template, is uncommented; otherwise the line
+// is appended.
+func withPamTID(content string) string {
+	if hasPamTID(content) {
+		return content
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for i, line := range lines {
+		uncommented := strings.TrimLeft(strings.TrimSpace(line), "# ")
+		if strings.HasPrefix(strings.TrimSpace(line), "#") && hasPamTID(uncommented) {
+			lines[i] = uncommented
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	return strings.Join(append(lines, pamTIDLine), "\n") + "\n"
+}
+
+// ── Application firewall ─────────────────────────────────────────────────────
+
+func firewallEnabled(r CommandRunner) (bool, error) {
+	out, err := r.RunOutput(socketFilterFW, "--getglobalstate")
+	if err != nil {
+		return false, err
+	}
+	// "Firewall is enabled. (State = 1)"; State = 2 blocks everything,
+	// which is on as well.
+	return strings.Contains(out, "State = 1") || strings.Contains(out, "State = 2"), nil
+}
+
+func enableFirewall(r CommandRunner) error {
+	_, err := r.RunSilent("sudo", "-n", socketFilterFW, "--setglobalstate", "on")
+	return err
+}
+
+func stealthModeEnabled(r CommandRunner) (bool, error) {
+	out, err := r.RunOutput(socketFilterFW, "--getstealthmode")
+	if err != nil {
+		return false, err
+	}
+	// Older releases print "Stealth mode enabled", newer ones
+	// "Firewall stealth mode is on".
+	out = strings.ToLower(out)
+	return strings.Contains(out, "mode enabled") || strings.Contains(out, "mode is on"), nil
+}
+
+func enableStealthMode(r CommandRunner) error {
+	_, err := r.RunSilent("sudo", "-n", socketFilterFW, "--setstealthmode", "on")
+	return err
+}
@@ -0,0 +1,203 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+const sudoLocalTemplateContent = `# sudo_local: local config file which survives system update and is included for sudo
+# uncomment following line to enable Touch ID for sudo
+#auth       sufficient     pam_tid.so
+`
+
+// recordingRunner is a fakeRunner that also records the RunSilent and
+// RunInput calls, and what sudo_local would have been written with.
+type recordingRunner struct {
+	*fakeRunner
+	calls     []string
+	installed string
+}
+
+func (r *recordingRunner) RunSilent(name string, args ...string) (string, error) {
+	r.calls = append(r.calls, cmdKey(name, args...))
+	return r.fakeRunner.RunSilent(name, args...)
+}
+
+func (r *recordingRunner) RunInput(input string, name string, args ...string) (string, error) {
+	r.calls = append(r.calls, cmdKey(name, args...))
+	if name == "sudo" && len(args) > 1 && args[1] == "tee" {
+		r.installed = input
+	}
+	return r.fakeRunner.RunSilent(name, args...)
+}
+
+func newSecurityRunner() *recordingRunner {
+	r := &recordingRunner{fakeRunner: newFakeRunner()}
+	r.outputResults["cat "+sudoLocalPath] = fakeResult{err: errors.New("no such file")}
+	r.outputResults["cat "+sudoLocalTemplate] = fakeResult{out: sudoLocalTemplateContent}
+	r.outputResults[socketFilterFW+" --getglobalstate"] = fakeResult{out: "Firewall is disabled. (State = 0)"}
+	r.outputResults[socketFilterFW+" --getstealthmode"] = fakeResult{out: "Firewall stealth mode is off"}
+	return r
+}
+
+func stubSudoValidate(t *testing.T) *int {
+	t.Helper()
+	orig := sudoValidate
+	t.Cleanup(func() { sudoValidate = orig })
+	prompts := 0
+	sudoValidate = func() error { prompts++; return nil }
+	return &prompts
+}
+
+func TestWithPamTID(t *testing.T) {
+	t.Run("uncomments the template line", func(t *testing.T) {
+		got := withPamTID(sudoLocalTemplateContent)
+		assert.True(t, hasPamTID(got))
+		assert.Contains(t, got, "\nauth       sufficient     pam_tid.so\n")
+		assert.Contains(t, got, "# uncomment following line")
+	})
+	t.Run("appends when there is no line", func(t *testing.T) {
+		got := withPamTID("auth       optional       /opt/homebrew/lib/pam/pam_reattach.so\n")
+		assert.Equal(t, "auth       optional       /opt/homebrew/lib/pam/pam_reattach.so\n"+pamTIDLine+"\n", got)
+	})
+	t.Run("empty file", func(t *testing.T) {
+		assert.Equal(t, pamTIDLine+"\n", withPamTID(""))
+	})
+	t.Run("already enabled is unchanged", func(t *testing.T) {
+		in := "auth sufficient pam_tid.so\n"
+		assert.Equal(t, in, withPamTID(in))
+	})
+}
+
+func TestHasPamTID_IgnoresComments(t *testing.T) {
+	assert.False(t, hasPamTID(sudoLocalTemplateContent))
+	assert.False(t, hasPamTID("# auth sufficient pam_tid.so"))
+	assert.True(t, hasPamTID("  auth   sufficient   pam_tid.so  "))
+}
+
+func TestApplySecurity_PromptsOnceAndAppliesPending(t *testing.T) {
+	prompts := stubSudoValidate(t)
+	r := newSecurityRunner()
+
+	n, err := ApplySecurity(r, &config.RemoteSecurityConfig{TouchIDSudo: true, Firewall: true, StealthMode: true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	if os.Geteuid() != 0 {
+		assert.Equal(t, 1, *prompts)
+	}
+
+	assert.Equal(t, []string{
+		"sudo -n tee " + sudoLocalPath,
+		"sudo -n chown root:wheel " + sudoLocalPath,
+		"sudo -n chmod 444 " + sudoLocalPath,
+		"sudo -n " + socketFilterFW + " --setglobalstate on",
+		"sudo -n " + socketFilterFW + " --setstealthmode on",
+	}, r.calls)
+	assert.True(t, hasPamTID(r.installed))
+}
+
+func TestApplySecurity_SkipsControlsAlreadyOn(t *testing.T) {
+	prompts := stubSudoValidate(t)
+	r := newSecurityRunner()
+	r.outputResults["cat "+sudoLocalPath] = fakeResult{out: pamTIDLine + "\n"}
+	r.outputResults[socketFilterFW+" --getglobalstate"] = fakeResult{out: "Firewall is enabled. (State = 1)"}
+
+	n, err := ApplySecurity(r, &config.RemoteSecurityConfig{TouchIDSudo: true, Firewall: true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 0, *prompts)
+	assert.Empty(t, r.calls)
+}
+
+func TestApplySecurity_OnlyEnabledControls(t *testing.T) {
+	stubSudoValidate(t)
+	r := newSecurityRunner()
+
+	n, err := ApplySecurity(r, &config.RemoteSecurityConfig{StealthMode: true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"sudo -n " + socketFilterFW + " --setstealthmode on"}, r.calls)
+}
+
+func TestApplySecurity_DryRun(t *testing.T) {
+	prompts := stubSudoValidate(t)
+	r := newSecurityRunner()
+
+	n, err := ApplySecurity(r, &config.RemoteSecurityConfig{TouchIDSudo: true, Firewall: true}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 0, *prompts)
+	assert.Empty(t, r.calls)
+}
+
+func TestApplySecurity_NilConfig(t *testing.T) {
+	n, err := ApplySecurity(newSecurityRunner(), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestApplySecurity_NoSudoLocalTemplate(t *testing.T) {
+	stubSudoValidate(t)
+	r := newSecurityRunner()
+	r.outputResults["cat "+sudoLocalTemplate] = fakeResult{err: errors.New("no such file")}
+
+	_, err := ApplySecurity(r, &config.RemoteSecurityConfig{TouchIDSudo: true}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "macOS 14")
+}
+
+func TestApplySecurity_CheckError(t *testing.T) {
+	stubSudoValidate(t)
+	r := newSecurityRunner()
+	r.outputResults[socketFilterFW+" --getglobalstate"] = fakeResult{err: errors.New("not found")}
+
+	_, err := ApplySecurity(r, &config.RemoteSecurityConfig{Firewall: true}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "check Firewall")
+}
+
+func TestCheckSecurity(t *testing.T) {
+	r := newSecurityRunner()
+	r.outputResults["cat "+sudoLocalPath] = fakeResult{out: pamTIDLine + "\n"}
+	r.outputResults[socketFilterFW+" --getstealthmode"] = fakeResult{err: errors.New("not found")}
+	d := &Doctor{Runner: r}
+
+	results := d.CheckSecurity()
+	require.Len(t, results, 3)
+	assert.Equal(t, StatusOK, results[0].Status)
+	assert.Contains(t, results[0].Message, "Touch ID for sudo enabled")
+	assert.Equal(t, StatusWarn, results[1].Status)
+	assert.Contains(t, results[1].Message, "security.firewall")
+	assert.Equal(t, StatusWarn, results[2].Status)
+	assert.Contains(t, results[2].Message, "Could not check")
+}
+
+func TestStealthModeEnabled_OutputFormats(t *testing.T) {
+	for out, want := range map[string]bool{
+		"Stealth mode enabled":         true,
+		"Firewall stealth mode is on":  true,
+		"Stealth mode disabled":        false,
+		"Firewall stealth mode is off": false,
+	} {
+		r := newFakeRunner()
+		r.outputResults[socketFilterFW+" --getstealthmode"] = fakeResult{out: out}
+		got, err := stealthModeEnabled(r)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, out)
+	}
+}
+
+func TestSecurityControl_UnknownKey(t *testing.T) {
+	_, err := securityControl("auto_lock")
+	assert.ErrorContains(t, err, `unknown security control "auto_lock"`)
+
+	c, err := securityControl(config.SecurityFirewall)
+	require.NoError(t, err)
+	assert.Equal(t, "Firewall", c.Name)
+}
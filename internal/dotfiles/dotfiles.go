@@ -124,7 +124,7 @@ func backupForReclone(dotfilesPath, repoURL, currentURL string, dryRun bool) (ne
 		ui.DryRunMsg("Would backup %s and re-clone from %s", dotfilesPath, repoURL)
 		return false, nil
 	}
-	backupPath := dotfilesPath + ".openboot.bak"
+	backupPath := dotfilesPath + backupSuffix
 	// Remove stale backup from a previous remote change to avoid rename failure.
 	if _, err := os.Stat(backupPath); err == nil {
 		if err := os.RemoveAll(backupPath); err != nil {
@@ -415,7 +415,7 @@ func backupConflicts(pkgDir, targetDir string, dryRun bool) ([][2]string, error)
 			return nil
 		}
 
-		backupPath := target + ".openboot.bak"
+		backupPath := target + backupSuffix
 		if bErr := backupFile(target, backupPath, false); bErr != nil {
 			return fmt.Errorf("backup %s: %w", target, bErr)
 		}
@@ -509,7 +509,7 @@ func linkDirect(dotfilesPath string, dryRun bool) error {
 	for _, entry := range entries {
 		name := entry.Name()
 		// Only link dotfiles (entries starting with "."), skip git metadata.
-		if !strings.HasPrefix(name, ".") || name == ".git" || name == ".gitignore" || name == ".gitmodules" || name == ".gitattributes" {
+		if !isDirectLinkName(name) {
 			continue
 		}
 
@@ -527,7 +527,7 @@ func linkDirect(dotfilesPath string, dryRun bool) error {
 		}
 
 		if _, err := os.Lstat(dst); err == nil {
-			backupPath := dst + ".openboot.bak"
+			backupPath := dst + backupSuffix
 			if err := os.Rename(dst, backupPath); err != nil {
 				ui.Warn(fmt.Sprintf("failed to backup %s: %v", dst, err))
 				continue
@@ -0,0 +1,308 @@
+package dotfiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// LinkState classifies one dotfile target under $HOME.
+type LinkState string
+
+const (
+	// StateLinked: the target resolves to the file in the dotfiles repo.
+	StateLinked LinkState = "linked"
+	// StateMissing: nothing exists at the target path.
+	StateMissing LinkState = "missing"
+	// StateConflicting: a regular file or directory sits where the link
+	// should be; linking would back it up (or stow would refuse).
+	StateConflicting LinkState = "conflicting"
+	// StateBroken: the target is a symlink whose destination no longer exists.
+	StateBroken LinkState = "broken"
+	// StateForeign: the target is a symlink to somewhere other than this repo.
+	StateForeign LinkState = "foreign"
+)
+
+// backupSuffix is appended to a file moved aside so a dotfile can be linked
+// in its place.
+const backupSuffix = ".openboot.bak"
+
+// Layout names how the dotfiles repo is linked into $HOME.
+const (
+	LayoutMake   = "make"
+	LayoutStow   = "stow"
+	LayoutDirect = "direct"
+)
+
+// FileStatus is the link health of one file the repo provides.
+type FileStatus struct {
+	Package  string    `json:"package,omitempty"`
+	Target   string    `json:"target"`
+	Source   string    `json:"source"`
+	State    LinkState `json:"state"`
+	LinkDest string    `json:"link_dest,omitempty"`
+	Backup   string    `json:"backup,omitempty"`
+}
+
+// RepoStatus describes the state of the ~/.dotfiles clone.
+type RepoStatus struct {
+	RemoteURL  string `json:"remote_url,omitempty"`
+	Branch     string `json:"branch"`
+	Dirty      bool   `json:"dirty"`
+	DirtyFiles int    `json:"dirty_files,omitempty"`
+	Ahead      int    `json:"ahead"`
+	Behind     int    `json:"behind"`
+	// CompareError is set when ahead/behind could not be computed (no
+	// upstream branch, fetch failed). Ahead and Behind are zero then.
+	CompareError string `json:"compare_error,omitempty"`
+}
+
+// Status is the full report produced by GetStatus.
+type Status struct {
+	Path   string       `json:"path"`
+	Layout string       `json:"layout"`
+	Repo   *RepoStatus  `json:"repo,omitempty"`
+	Files  []FileStatus `json:"files"`
+}
+
+// Counts tallies Files by state.
+func (s *Status) Counts() map[LinkState]int {
+	counts := make(map[LinkState]int)
+	for _, f := range s.Files {
+		counts[f.State]++
+	}
+	return counts
+}
+
+// Healthy reports whether every file is linked and the clone is clean and
+// up to date with its remote.
+func (s *Status) Healthy() bool {
+	for _, f := range s.Files {
+		if f.State != StateLinked {
+			return false
+		}
+	}
+	return s.Repo == nil || (!s.Repo.Dirty && s.Repo.Behind == 0)
+}
+
+// linkPair is one source file in the repo and the $HOME path it links to.
+type linkPair struct {
+	pkg    string
+	source string
+	target string
+}
+
+// GetStatus inspects ~/.dotfiles and classifies every file it would link.
+// With fetch set, `git fetch origin` runs first so Behind reflects the
+// remote rather than the last fetch; otherwise the check touches nothing.
+func GetStatus(fetch bool) (*Status, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("dotfiles status: %w", err)
+	}
+	dotfilesPath := filepath.Join(home, defaultDotfilesDir)
+	if _, err := os.Stat(dotfilesPath); err != nil {
+		return nil, fmt.Errorf("dotfiles directory not found: %s", dotfilesPath)
+	}
+
+	layout, pairs, err := collectLinkPairs(dotfilesPath, home)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &Status{Path: dotfilesPath, Layout: layout, Files: make([]FileStatus, 0, len(pairs))}
+	for _, p := range pairs {
+		st.Files = append(st.Files, classifyLink(p))
+	}
+
+	if _, err := os.Stat(filepath.Join(dotfilesPath, ".git")); err == nil {
+		st.Repo = repoStatus(dotfilesPath, fetch)
+	}
+	return st, nil
+}
+
+// collectLinkPairs lists the files Link would place in home, mirroring its
+// layout detection. A Makefile-driven repo is reported per stow package when
+// it has them (the common case: the Makefile calls stow), otherwise as a
+// direct-link set.
+func collectLinkPairs(dotfilesPath, home string) (string, []linkPair, error) {
+	stow := hasStowPackages(dotfilesPath)
+	layout := LayoutDirect
+	switch {
+	case hasMakefile(dotfilesPath):
+		layout = LayoutMake
+	case stow:
+		layout = LayoutStow
+	}
+
+	if stow {
+		pairs, err := stowLinkPairs(dotfilesPath, home)
+		return layout, pairs, err
+	}
+	pairs, err := directLinkPairs(dotfilesPath, home)
+	return layout, pairs, err
+}
+
+func directLinkPairs(dotfilesPath, home string) ([]linkPair, error) {
+	entries, err := os.ReadDir(dotfilesPath)
+	if err != nil {
+		return nil, fmt.Errorf("read dotfiles dir: %w", err)
+	}
+	var pairs []linkPair
+	for _, entry := range entries {
+		name := entry.Name()
+		if !isDirectLinkName(name) {
+			continue
+		}
+		pairs = append(pairs, linkPair{
+			source: filepath.Join(dotfilesPath, name),
+			target: filepath.Join(home, name),
+		})
+	}
+	return pairs, nil
+}
+
+// isDirectLinkName mirrors linkDirect's filter: only dotfiles, never git
+// metadata.
+func isDirectLinkName(name string) bool {
+	if !strings.HasPrefix(name, ".") {
+		return false
+	}
+	switch name {
+	case ".git", ".gitignore", ".gitmodules", ".gitattributes":
+		return false
+	}
+	return true
+}
+
+func stowLinkPairs(dotfilesPath, home string) ([]linkPair, error) {
+	entries, err := os.ReadDir(dotfilesPath)
+	if err != nil {
+		return nil, fmt.Errorf("read dotfiles dir: %w", err)
+	}
+	var pairs []linkPair
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		pkg := entry.Name()
+		pkgDir := filepath.Join(dotfilesPath, pkg)
+		err := filepath.WalkDir(pkgDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Name() == ".DS_Store" || d.Name() == ".stow-local-ignore" {
+				return nil
+			}
+			rel, relErr := filepath.Rel(pkgDir, path)
+			if relErr != nil {
+				return relErr
+			}
+			pairs = append(pairs, linkPair{pkg: pkg, source: path, target: filepath.Join(home, rel)})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk package %s: %w", pkg, err)
+		}
+	}
+	return pairs, nil
+}
+
+// classifyLink works out the state of one target. Resolution goes through
+// filepath.EvalSymlinks so a file inside a directory stow folded into a
+// single symlink (~/.config/nvim -> .dotfiles/nvim/.config/nvim) counts as
+// linked even though the file path itself is not a symlink.
+func classifyLink(p linkPair) FileStatus {
+	fs := FileStatus{Package: p.pkg, Target: p.target, Source: p.source}
+	if _, err := os.Lstat(p.target + backupSuffix); err == nil {
+		fs.Backup = p.target + backupSuffix
+	}
+
+	info, err := os.Lstat(p.target)
+	if err != nil {
+		fs.State = StateMissing
+		return fs
+	}
+	isLink := info.Mode()&os.ModeSymlink != 0
+	if isLink {
+		fs.LinkDest = readLinkAbs(p.target)
+	}
+
+	resolved, err := filepath.EvalSymlinks(p.target)
+	if err != nil {
+		fs.State = StateBroken
+		return fs
+	}
+	if srcResolved, err := filepath.EvalSymlinks(p.source); err == nil && resolved == srcResolved {
+		fs.State = StateLinked
+		return fs
+	}
+	if isLink {
+		fs.State = StateForeign
+		return fs
+	}
+	fs.State = StateConflicting
+	return fs
+}
+
+// readLinkAbs returns the symlink destination of path as an absolute path.
+// Stow writes relative links, which are relative to the link's directory.
+func readLinkAbs(path string) string {
+	dest, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(path), dest)
+	}
+	return filepath.Clean(dest)
+}
+
+// repoStatus reports dirtiness and ahead/behind counts against
+// origin/<branch>, using the same branch detection as sync.
+func repoStatus(dotfilesPath string, fetch bool) *RepoStatus {
+	rs := &RepoStatus{}
+	if out, err := gitOutputFunc([]string{"-C", dotfilesPath, "remote", "get-url", "origin"}); err == nil {
+		rs.RemoteURL = strings.TrimSpace(string(out))
+	}
+
+	if out, err := gitOutputFunc([]string{"-C", dotfilesPath, "status", "--porcelain"}); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			if strings.TrimSpace(line) != "" {
+				rs.DirtyFiles++
+			}
+		}
+		rs.Dirty = rs.DirtyFiles > 0
+	}
+
+	if fetch {
+		if err := gitExecFunc([]string{"-C", dotfilesPath, "fetch", "origin"}); err != nil {
+			rs.CompareError = fmt.Sprintf("fetch failed: %v", err)
+		}
+	}
+
+	rs.Branch = resolveBranch(dotfilesPath)
+	out, err := gitOutputFunc([]string{"-C", dotfilesPath, "rev-list", "--left-right", "--count", "HEAD...origin/" + rs.Branch})
+	if err != nil {
+		if rs.CompareError == "" {
+			rs.CompareError = fmt.Sprintf("cannot compare with origin/%s", rs.Branch)
+		}
+		return rs
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 2 {
+		rs.Ahead, _ = strconv.Atoi(fields[0])
+		rs.Behind, _ = strconv.Atoi(fields[1])
+	}
+	return rs
+}
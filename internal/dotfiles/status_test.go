@@ -0,0 +1,173 @@
+package dotfiles
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGit replaces gitOutputFunc/gitExecFunc with canned responses keyed by
+// the git subcommand args (without the leading "-C <path>").
+func stubGit(t *testing.T, outputs map[string]string) *[]string {
+	t.Helper()
+	var execCalls []string
+	origOut, origExec := gitOutputFunc, gitExecFunc
+	gitOutputFunc = func(args []string) ([]byte, error) {
+		key := strings.Join(args[2:], " ")
+		if out, ok := outputs[key]; ok {
+			return []byte(out), nil
+		}
+		return nil, errors.New("unexpected git call: " + key)
+	}
+	gitExecFunc = func(args []string) error {
+		execCalls = append(execCalls, strings.Join(args[2:], " "))
+		return nil
+	}
+	t.Cleanup(func() { gitOutputFunc, gitExecFunc = origOut, origExec })
+	return &execCalls
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func statesByTarget(st *Status, home string) map[string]LinkState {
+	out := make(map[string]LinkState)
+	for _, f := range st.Files {
+		rel, _ := filepath.Rel(home, f.Target)
+		out[rel] = f.State
+	}
+	return out
+}
+
+func TestGetStatus_StowClassification(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	df := filepath.Join(home, ".dotfiles")
+
+	writeFile(t, filepath.Join(df, "zsh", ".zshrc"), "zsh")
+	writeFile(t, filepath.Join(df, "git", ".gitconfig"), "git")
+	writeFile(t, filepath.Join(df, "vim", ".vimrc"), "vim")
+	writeFile(t, filepath.Join(df, "tmux", ".tmux.conf"), "tmux")
+	writeFile(t, filepath.Join(df, "nvim", ".config", "nvim", "init.lua"), "nvim")
+	writeFile(t, filepath.Join(df, "ssh", ".sshrc"), "ssh")
+
+	// linked (relative, as stow writes it)
+	require.NoError(t, os.Symlink(filepath.Join(".dotfiles", "zsh", ".zshrc"), filepath.Join(home, ".zshrc")))
+	// conflicting, with a backup left by an earlier link
+	writeFile(t, filepath.Join(home, ".gitconfig"), "local")
+	writeFile(t, filepath.Join(home, ".gitconfig.openboot.bak"), "older")
+	// broken
+	require.NoError(t, os.Symlink(filepath.Join(df, "vim", ".vimrc.gone"), filepath.Join(home, ".vimrc")))
+	// foreign
+	writeFile(t, filepath.Join(home, "elsewhere", ".tmux.conf"), "other")
+	require.NoError(t, os.Symlink(filepath.Join(home, "elsewhere", ".tmux.conf"), filepath.Join(home, ".tmux.conf")))
+	// linked through a folded directory
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config"), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(df, "nvim", ".config", "nvim"), filepath.Join(home, ".config", "nvim")))
+	// .sshrc left missing
+
+	st, err := GetStatus(false)
+	require.NoError(t, err)
+	assert.Equal(t, LayoutStow, st.Layout)
+	assert.Nil(t, st.Repo, "no .git means no repo status")
+
+	assert.Equal(t, map[string]LinkState{
+		".zshrc":                StateLinked,
+		".gitconfig":            StateConflicting,
+		".vimrc":                StateBroken,
+		".tmux.conf":            StateForeign,
+		".config/nvim/init.lua": StateLinked,
+		".sshrc":                StateMissing,
+	}, statesByTarget(st, home))
+
+	for _, f := range st.Files {
+		switch filepath.Base(f.Target) {
+		case ".gitconfig":
+			assert.Equal(t, filepath.Join(home, ".gitconfig.openboot.bak"), f.Backup)
+			assert.Equal(t, "git", f.Package)
+		case ".tmux.conf":
+			assert.Equal(t, filepath.Join(home, "elsewhere", ".tmux.conf"), f.LinkDest)
+		case ".zshrc":
+			assert.Equal(t, filepath.Join(df, "zsh", ".zshrc"), f.LinkDest)
+		}
+	}
+	assert.False(t, st.Healthy())
+	assert.Equal(t, 2, st.Counts()[StateLinked])
+}
+
+func TestGetStatus_DirectLayout(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	df := filepath.Join(home, ".dotfiles")
+	writeFile(t, filepath.Join(df, ".zshrc"), "zsh")
+	writeFile(t, filepath.Join(df, ".gitignore"), "ignored")
+	writeFile(t, filepath.Join(df, "README.md"), "readme")
+	require.NoError(t, os.Symlink(filepath.Join(df, ".zshrc"), filepath.Join(home, ".zshrc")))
+
+	st, err := GetStatus(false)
+	require.NoError(t, err)
+	assert.Equal(t, LayoutDirect, st.Layout)
+	assert.Equal(t, map[string]LinkState{".zshrc": StateLinked}, statesByTarget(st, home))
+	assert.True(t, st.Healthy())
+}
+
+func TestGetStatus_RepoDirtyAndBehind(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	df := filepath.Join(home, ".dotfiles")
+	writeFile(t, filepath.Join(df, ".zshrc"), "zsh")
+	require.NoError(t, os.MkdirAll(filepath.Join(df, ".git"), 0755))
+
+	execCalls := stubGit(t, map[string]string{
+		"remote get-url origin":                            "https://github.com/alice/dotfiles\n",
+		"status --porcelain":                               " M .zshrc\n?? new\n",
+		"rev-parse --abbrev-ref HEAD":                      "main\n",
+		"rev-list --left-right --count HEAD...origin/main": "1\t3\n",
+	})
+
+	st, err := GetStatus(true)
+	require.NoError(t, err)
+	require.NotNil(t, st.Repo)
+	assert.Equal(t, &RepoStatus{
+		RemoteURL:  "https://github.com/alice/dotfiles",
+		Branch:     "main",
+		Dirty:      true,
+		DirtyFiles: 2,
+		Ahead:      1,
+		Behind:     3,
+	}, st.Repo)
+	assert.Equal(t, []string{"fetch origin"}, *execCalls)
+	assert.False(t, st.Healthy())
+}
+
+func TestGetStatus_NoUpstream(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	df := filepath.Join(home, ".dotfiles")
+	require.NoError(t, os.MkdirAll(filepath.Join(df, ".git"), 0755))
+
+	execCalls := stubGit(t, map[string]string{
+		"status --porcelain":          "",
+		"rev-parse --abbrev-ref HEAD": "main\n",
+	})
+
+	st, err := GetStatus(false)
+	require.NoError(t, err)
+	assert.Empty(t, *execCalls, "no fetch without the flag")
+	assert.Contains(t, st.Repo.CompareError, "origin/main")
+	assert.False(t, st.Repo.Dirty)
+}
+
+func TestGetStatus_NoDotfilesDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	_, err := GetStatus(false)
+	assert.ErrorContains(t, err, "dotfiles directory not found")
+}
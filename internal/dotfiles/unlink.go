@@ -0,0 +1,266 @@
+package dotfiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// UnlinkOptions controls Unlink.
+type UnlinkOptions struct {
+	// RemoveClone deletes ~/.dotfiles after unlinking. Refused when the
+	// clone has uncommitted or unpushed work.
+	RemoveClone bool
+	DryRun      bool
+}
+
+// RemovedLink is a symlink into the repo that Unlink removed.
+type RemovedLink struct {
+	Target   string `json:"target"`
+	LinkDest string `json:"link_dest"`
+}
+
+// RestoredFile is a pre-openboot file moved back from its backup.
+type RestoredFile struct {
+	Backup string `json:"backup"`
+	Target string `json:"target"`
+}
+
+// SkippedFile is a backup Unlink left alone, with the reason.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// UnlinkManifest records what Unlink did (or, in a dry run, would do).
+// Outside a dry run it is also written to ~/.openboot so the restore can be
+// audited after the fact.
+type UnlinkManifest struct {
+	Path         string         `json:"path"`
+	UnlinkedAt   time.Time      `json:"unlinked_at"`
+	DryRun       bool           `json:"dry_run"`
+	Removed      []RemovedLink  `json:"removed"`
+	Restored     []RestoredFile `json:"restored"`
+	Skipped      []SkippedFile  `json:"skipped,omitempty"`
+	CloneRemoved bool           `json:"clone_removed"`
+	// ManifestPath is where this manifest was saved; empty in a dry run.
+	ManifestPath string `json:"-"`
+}
+
+// unlinkNow is the manifest clock. Tests replace it.
+var unlinkNow = time.Now
+
+// Unlink removes every symlink in $HOME that points into ~/.dotfiles and
+// moves the .openboot.bak backups made while linking back into place.
+//
+// Symlinks are found two ways: by walking the files the repo provides
+// (including stow-folded parent directories), and by scanning the top level
+// of $HOME, which catches links left dangling by files since deleted from the
+// repo. A backup is only restored when its original path is free after the
+// links are gone; a backup shadowed by some other file is reported as
+// skipped rather than overwriting anything.
+func Unlink(opts UnlinkOptions) (*UnlinkManifest, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("unlink dotfiles: %w", err)
+	}
+	dotfilesPath := filepath.Join(home, defaultDotfilesDir)
+	if _, err := os.Stat(dotfilesPath); err != nil {
+		return nil, fmt.Errorf("dotfiles directory not found: %s", dotfilesPath)
+	}
+
+	if opts.RemoveClone {
+		if err := checkCloneDisposable(dotfilesPath); err != nil {
+			return nil, err
+		}
+	}
+
+	_, pairs, err := collectLinkPairs(dotfilesPath, home)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &UnlinkManifest{
+		Path:       dotfilesPath,
+		UnlinkedAt: unlinkNow().UTC(),
+		DryRun:     opts.DryRun,
+		Removed:    []RemovedLink{},
+		Restored:   []RestoredFile{},
+	}
+
+	links := findRepoLinks(dotfilesPath, home, pairs)
+	removed := make(map[string]bool, len(links))
+	var errs []error
+	for _, l := range links {
+		if !opts.DryRun {
+			if err := os.Remove(l.Target); err != nil {
+				errs = append(errs, fmt.Errorf("remove link %s: %w", l.Target, err))
+				continue
+			}
+		}
+		removed[l.Target] = true
+		m.Removed = append(m.Removed, l)
+	}
+
+	for _, backup := range findBackups(dotfilesPath, pairs, links) {
+		target := strings.TrimSuffix(backup, backupSuffix)
+		if _, err := os.Lstat(target); err == nil && !removed[target] {
+			m.Skipped = append(m.Skipped, SkippedFile{Path: backup, Reason: "original path is occupied by a file openboot did not link"})
+			continue
+		}
+		if !opts.DryRun {
+			if err := os.Rename(backup, target); err != nil {
+				errs = append(errs, fmt.Errorf("restore %s: %w", target, err))
+				continue
+			}
+		}
+		m.Restored = append(m.Restored, RestoredFile{Backup: backup, Target: target})
+	}
+
+	if opts.RemoveClone && len(errs) == 0 {
+		if !opts.DryRun {
+			if err := os.RemoveAll(dotfilesPath); err != nil {
+				errs = append(errs, fmt.Errorf("remove clone: %w", err))
+			}
+		}
+		m.CloneRemoved = len(errs) == 0
+	}
+
+	if !opts.DryRun {
+		path, err := saveUnlinkManifest(home, m, opts.DryRun)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		m.ManifestPath = path
+	}
+
+	return m, errors.Join(errs...)
+}
+
+// findRepoLinks returns every symlink under home that resolves into
+// dotfilesPath, sorted by path. Each repo file's path is walked from the top
+// down so a stow-folded directory is removed as the single link it is; the
+// walk stops at the first symlink so it never descends into the repo (or
+// anywhere else) through one.
+func findRepoLinks(dotfilesPath, home string, pairs []linkPair) []RemovedLink {
+	seen := make(map[string]bool)
+	var links []RemovedLink
+	// consider records path if it is a symlink into the repo and reports
+	// whether path is a symlink at all.
+	consider := func(path string) bool {
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return false
+		}
+		if seen[path] {
+			return true
+		}
+		seen[path] = true
+		dest := readLinkAbs(path)
+		if dest == dotfilesPath || strings.HasPrefix(dest, dotfilesPath+string(filepath.Separator)) {
+			links = append(links, RemovedLink{Target: path, LinkDest: dest})
+		}
+		return true
+	}
+
+	for _, p := range pairs {
+		rel, err := filepath.Rel(home, p.target)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		path := home
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			path = filepath.Join(path, part)
+			if consider(path) {
+				break
+			}
+		}
+	}
+	if entries, err := os.ReadDir(home); err == nil {
+		for _, e := range entries {
+			consider(filepath.Join(home, e.Name()))
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool { return links[i].Target < links[j].Target })
+	return links
+}
+
+// findBackups returns the .openboot.bak files and directories next to every
+// path the repo links, plus next to every removed symlink, sorted and
+// de-duplicated. linkDirect backs up a real directory such as ~/.config the
+// same way it backs up a file. The backup of the clone itself, left by a
+// re-clone after the remote changed, is never a link target's and is skipped.
+func findBackups(dotfilesPath string, pairs []linkPair, links []RemovedLink) []string {
+	seen := map[string]bool{dotfilesPath + backupSuffix: true}
+	var out []string
+	add := func(target string) {
+		backup := target + backupSuffix
+		if seen[backup] {
+			return
+		}
+		seen[backup] = true
+		if _, err := os.Lstat(backup); err == nil {
+			out = append(out, backup)
+		}
+	}
+	for _, p := range pairs {
+		add(p.target)
+	}
+	for _, l := range links {
+		add(l.Target)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// checkCloneDisposable refuses to delete a clone holding work that exists
+// nowhere else.
+func checkCloneDisposable(dotfilesPath string) error {
+	if _, err := os.Stat(filepath.Join(dotfilesPath, ".git")); err != nil {
+		return fmt.Errorf("%s is not a git clone; remove it manually if you no longer need it", dotfilesPath)
+	}
+	rs := repoStatus(dotfilesPath, false)
+	if rs.Dirty {
+		return fmt.Errorf("%s has %d uncommitted change(s); commit and push them, or remove the clone manually", dotfilesPath, rs.DirtyFiles)
+	}
+	if rs.CompareError != "" {
+		return fmt.Errorf("cannot confirm %s is pushed (%s); remove the clone manually", dotfilesPath, rs.CompareError)
+	}
+	if rs.Ahead > 0 {
+		return fmt.Errorf("%s has %d unpushed commit(s) on %s; push them, or remove the clone manually", dotfilesPath, rs.Ahead, rs.Branch)
+	}
+	return nil
+}
+
+// saveUnlinkManifest writes m to ~/.openboot/dotfiles-unlink-<timestamp>.json.
+func saveUnlinkManifest(home string, m *UnlinkManifest, dryRun bool) (string, error) {
+	if dryRun {
+		return "", nil
+	}
+	dir := filepath.Join(home, ".openboot")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create manifest dir: %w", err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal unlink manifest: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("dotfiles-unlink-%s.json", m.UnlinkedAt.Format("20060102-150405")))
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return "", fmt.Errorf("write unlink manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("rename unlink manifest: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,162 @@
+package dotfiles
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLinkedStowHome builds a stow-layout repo with one plain link, one
+// folded directory link, a link whose repo file was deleted, a backup to
+// restore, and a backup shadowed by an unrelated file.
+func setupLinkedStowHome(t *testing.T) (home, df string) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	df = filepath.Join(home, ".dotfiles")
+
+	writeFile(t, filepath.Join(df, "zsh", ".zshrc"), "repo zshrc")
+	writeFile(t, filepath.Join(df, "nvim", ".config", "nvim", "init.lua"), "repo nvim")
+	writeFile(t, filepath.Join(df, "git", ".gitconfig"), "repo gitconfig")
+
+	require.NoError(t, os.Symlink(filepath.Join(".dotfiles", "zsh", ".zshrc"), filepath.Join(home, ".zshrc")))
+	writeFile(t, filepath.Join(home, ".zshrc.openboot.bak"), "original zshrc")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config"), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(df, "nvim", ".config", "nvim"), filepath.Join(home, ".config", "nvim")))
+
+	// Dangling link to a file no longer in the repo.
+	require.NoError(t, os.Symlink(filepath.Join(df, "old", ".oldrc"), filepath.Join(home, ".oldrc")))
+
+	// User replaced the link with their own file; the backup must stay put.
+	writeFile(t, filepath.Join(home, ".gitconfig"), "user gitconfig")
+	writeFile(t, filepath.Join(home, ".gitconfig.openboot.bak"), "pre-openboot gitconfig")
+
+	// Unrelated symlink must survive.
+	writeFile(t, filepath.Join(home, "notes", ".notesrc"), "notes")
+	require.NoError(t, os.Symlink(filepath.Join(home, "notes", ".notesrc"), filepath.Join(home, ".notesrc")))
+
+	orig := unlinkNow
+	unlinkNow = func() time.Time { return time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC) }
+	t.Cleanup(func() { unlinkNow = orig })
+	return home, df
+}
+
+func TestUnlink_RemovesRepoLinksAndRestoresBackups(t *testing.T) {
+	home, df := setupLinkedStowHome(t)
+
+	m, err := Unlink(UnlinkOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []RemovedLink{
+		{Target: filepath.Join(home, ".config", "nvim"), LinkDest: filepath.Join(df, "nvim", ".config", "nvim")},
+		{Target: filepath.Join(home, ".oldrc"), LinkDest: filepath.Join(df, "old", ".oldrc")},
+		{Target: filepath.Join(home, ".zshrc"), LinkDest: filepath.Join(df, "zsh", ".zshrc")},
+	}, m.Removed)
+	assert.Equal(t, []RestoredFile{
+		{Backup: filepath.Join(home, ".zshrc.openboot.bak"), Target: filepath.Join(home, ".zshrc")},
+	}, m.Restored)
+	require.Len(t, m.Skipped, 1)
+	assert.Equal(t, filepath.Join(home, ".gitconfig.openboot.bak"), m.Skipped[0].Path)
+
+	data, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, "original zshrc", string(data))
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc.openboot.bak"))
+	assert.NoDirExists(t, filepath.Join(home, ".config", "nvim"))
+	assert.FileExists(t, filepath.Join(df, "nvim", ".config", "nvim", "init.lua"), "repo contents untouched")
+	assert.FileExists(t, filepath.Join(home, ".gitconfig.openboot.bak"))
+	_, err = os.Lstat(filepath.Join(home, ".notesrc"))
+	assert.NoError(t, err, "unrelated symlink kept")
+	assert.DirExists(t, df, "clone kept without RemoveClone")
+
+	// Manifest written to ~/.openboot.
+	require.Equal(t, filepath.Join(home, ".openboot", "dotfiles-unlink-20261018-093000.json"), m.ManifestPath)
+	raw, err := os.ReadFile(m.ManifestPath)
+	require.NoError(t, err)
+	var saved UnlinkManifest
+	require.NoError(t, json.Unmarshal(raw, &saved))
+	assert.Equal(t, m.Restored, saved.Restored)
+	info, err := os.Stat(m.ManifestPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestUnlink_RestoresDirectoryBackup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	df := filepath.Join(home, ".dotfiles")
+	writeFile(t, filepath.Join(df, ".vim", "vimrc"), "repo vimrc")
+	writeFile(t, filepath.Join(home, ".vim", "vimrc"), "original vimrc")
+	// Left by a re-clone after the remote changed; not a link's backup.
+	writeFile(t, filepath.Join(home, ".dotfiles.openboot.bak", "old"), "old clone")
+
+	require.NoError(t, linkDirect(df, false))
+	require.DirExists(t, filepath.Join(home, ".vim.openboot.bak"))
+
+	m, err := Unlink(UnlinkOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []RestoredFile{
+		{Backup: filepath.Join(home, ".vim.openboot.bak"), Target: filepath.Join(home, ".vim")},
+	}, m.Restored)
+
+	info, err := os.Lstat(filepath.Join(home, ".vim"))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir(), "real directory restored in place of the link")
+	data, err := os.ReadFile(filepath.Join(home, ".vim", "vimrc"))
+	require.NoError(t, err)
+	assert.Equal(t, "original vimrc", string(data))
+	assert.DirExists(t, filepath.Join(home, ".dotfiles.openboot.bak"))
+}
+
+func TestUnlink_DryRunChangesNothing(t *testing.T) {
+	home, _ := setupLinkedStowHome(t)
+
+	m, err := Unlink(UnlinkOptions{DryRun: true, RemoveClone: false})
+	require.NoError(t, err)
+	assert.True(t, m.DryRun)
+	assert.Len(t, m.Removed, 3)
+	assert.Len(t, m.Restored, 1)
+	assert.Empty(t, m.ManifestPath)
+
+	target, err := os.Readlink(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(".dotfiles", "zsh", ".zshrc"), target)
+	assert.FileExists(t, filepath.Join(home, ".zshrc.openboot.bak"))
+	assert.NoDirExists(t, filepath.Join(home, ".openboot"))
+}
+
+func TestUnlink_RemoveClone(t *testing.T) {
+	home, df := setupLinkedStowHome(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(df, ".git"), 0755))
+	stubGit(t, map[string]string{
+		"status --porcelain":                               "",
+		"rev-parse --abbrev-ref HEAD":                      "main\n",
+		"rev-list --left-right --count HEAD...origin/main": "0\t0\n",
+	})
+
+	m, err := Unlink(UnlinkOptions{RemoveClone: true})
+	require.NoError(t, err)
+	assert.True(t, m.CloneRemoved)
+	assert.NoDirExists(t, df)
+	assert.FileExists(t, filepath.Join(home, ".zshrc"))
+}
+
+func TestUnlink_RemoveCloneRefusesUnpushedWork(t *testing.T) {
+	_, df := setupLinkedStowHome(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(df, ".git"), 0755))
+	stubGit(t, map[string]string{
+		"status --porcelain":                               " M zsh/.zshrc\n",
+		"rev-parse --abbrev-ref HEAD":                      "main\n",
+		"rev-list --left-right --count HEAD...origin/main": "0\t0\n",
+	})
+
+	_, err := Unlink(UnlinkOptions{RemoveClone: true})
+	assert.ErrorContains(t, err, "uncommitted change")
+	assert.DirExists(t, df)
+}
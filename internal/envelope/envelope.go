@@ -0,0 +1,180 @@
+// Package envelope encrypts snapshot and config files with a passphrase,
+// so a copy carried over AirDrop or a USB stick is unreadable without it.
+//
+// A sealed file is a small JSON document naming the format and version,
+// the key derivation (scrypt) with its parameters and salt, and the cipher
+// (XChaCha20-Poly1305) with its nonce and the ciphertext. The header
+// fields are authenticated along with the payload. Readers detect the
+// format with IsSealed, so sealed and plain files load through the same
+// paths.
+package envelope
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Format identifies a sealed file.
+	Format = "openboot-sealed"
+	// Version is the envelope version this build writes.
+	Version = 1
+
+	kdfScrypt     = "scrypt"
+	cipherXChaCha = "xchacha20-poly1305"
+	saltSize      = 16
+)
+
+// scrypt cost parameters for new envelopes: about 100ms and 32 MB on a
+// current Mac. Opening reads them from the header.
+var (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Upper bounds on the scrypt parameters Open accepts, a little above what
+// Seal writes: at most 256 MB and a few seconds to derive a key, whatever
+// a crafted header asks for.
+const (
+	maxScryptN = 1 << 18
+	maxScryptR = 8
+	maxScryptP = 4
+)
+
+// PassphraseEnv names the environment variable Unseal reads a passphrase
+// from before it prompts, for scripted restores.
+const PassphraseEnv = "OPENBOOT_PASSPHRASE"
+
+// ErrPassphrase is returned when a sealed file does not open with the
+// passphrase given.
+var ErrPassphrase = errors.New("wrong passphrase or corrupted file")
+
+type envelope struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Cipher     string `json:"cipher"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// additionalData binds the header to the ciphertext, so its parameters
+// cannot be swapped without failing authentication.
+func (e *envelope) additionalData() []byte {
+	return fmt.Appendf(nil, "%s/%d/%s/%d/%d/%d/%s", e.Format, e.Version, e.KDF, e.N, e.R, e.P, e.Cipher)
+}
+
+// IsSealed reports whether data is a sealed envelope.
+func IsSealed(data []byte) bool {
+	var probe struct {
+		Format string `json:"format"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Format == Format
+}
+
+// Seal encrypts plaintext with a key derived from passphrase.
+func Seal(plaintext, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("seal: empty passphrase")
+	}
+	e := &envelope{
+		Format: Format, Version: Version,
+		KDF: kdfScrypt, N: scryptN, R: scryptR, P: scryptP,
+		Salt:   make([]byte, saltSize),
+		Cipher: cipherXChaCha,
+		Nonce:  make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	if _, err := rand.Read(e.Salt); err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	if _, err := rand.Read(e.Nonce); err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	key, err := scrypt.Key(passphrase, e.Salt, e.N, e.R, e.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("seal: derive key: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	e.Ciphertext = aead.Seal(nil, e.Nonce, plaintext, e.additionalData())
+	return json.MarshalIndent(e, "", "  ")
+}
+
+// Open decrypts a sealed envelope. It returns ErrPassphrase when the
+// passphrase is wrong or the file was altered.
+func Open(data, passphrase []byte) ([]byte, error) {
+	var e envelope
+	if err := json.Unmarshal(data, &e); err != nil || e.Format != Format {
+		return nil, errors.New("open: not a sealed openboot file")
+	}
+	if e.Version > Version {
+		return nil, fmt.Errorf("open: envelope version %d is newer than this openboot supports (%d); upgrade openboot", e.Version, Version)
+	}
+	if e.KDF != kdfScrypt || e.Cipher != cipherXChaCha {
+		return nil, fmt.Errorf("open: unsupported kdf %q or cipher %q", e.KDF, e.Cipher)
+	}
+	// Bound the cost a crafted header can demand.
+	if e.N < 2 || e.N > maxScryptN || e.N&(e.N-1) != 0 || e.R < 1 || e.R > maxScryptR || e.P < 1 || e.P > maxScryptP || len(e.Nonce) != chacha20poly1305.NonceSizeX {
+		return nil, errors.New("open: invalid envelope parameters")
+	}
+	key, err := scrypt.Key(passphrase, e.Salt, e.N, e.R, e.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("open: derive key: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	plaintext, err := aead.Open(nil, e.Nonce, e.Ciphertext, e.additionalData())
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	return plaintext, nil
+}
+
+// passphrasePrompt asks for the passphrase of a sealed file. The CLI sets
+// it via SetPassphrasePrompt; without it only PassphraseEnv is used.
+var passphrasePrompt func() ([]byte, error)
+
+// SetPassphrasePrompt sets how Unseal asks for a passphrase.
+func SetPassphrasePrompt(fn func() ([]byte, error)) { passphrasePrompt = fn }
+
+// maxAttempts is how many times Unseal prompts before giving up.
+const maxAttempts = 3
+
+// Unseal returns data as is when it is not sealed, and otherwise opens it
+// with the passphrase from PassphraseEnv or, failing that, the prompt.
+func Unseal(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if pass := os.Getenv(PassphraseEnv); pass != "" {
+		return Open(data, []byte(pass))
+	}
+	if passphrasePrompt == nil {
+		return nil, fmt.Errorf("file is encrypted; set %s to its passphrase", PassphraseEnv)
+	}
+	for attempt := 1; ; attempt++ {
+		pass, err := passphrasePrompt()
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		out, err := Open(data, pass)
+		if !errors.Is(err, ErrPassphrase) || attempt == maxAttempts {
+			return out, err
+		}
+	}
+}
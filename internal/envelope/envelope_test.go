@@ -0,0 +1,123 @@
+package envelope
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cheapScrypt(t *testing.T) {
+	t.Helper()
+	orig := scryptN
+	t.Cleanup(func() { scryptN = orig })
+	scryptN = 1 << 10
+}
+
+func TestSealOpen(t *testing.T) {
+	cheapScrypt(t)
+	plain := []byte(`{"hostname":"mbp","packages":{"formulae":["git"]}}`)
+
+	sealed, err := Seal(plain, []byte("correct horse"))
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.False(t, IsSealed(plain))
+	assert.NotContains(t, string(sealed), "git")
+
+	out, err := Open(sealed, []byte("correct horse"))
+	require.NoError(t, err)
+	assert.Equal(t, plain, out)
+
+	_, err = Open(sealed, []byte("wrong horse"))
+	assert.ErrorIs(t, err, ErrPassphrase)
+
+	_, err = Seal(plain, nil)
+	assert.Error(t, err)
+}
+
+func TestOpen_RejectsAlteredHeader(t *testing.T) {
+	cheapScrypt(t)
+	sealed, err := Seal([]byte("{}"), []byte("passphrase"))
+	require.NoError(t, err)
+
+	alter := func(f func(e *envelope)) []byte {
+		var e envelope
+		require.NoError(t, json.Unmarshal(sealed, &e))
+		f(&e)
+		data, err := json.Marshal(e)
+		require.NoError(t, err)
+		return data
+	}
+
+	_, err = Open(alter(func(e *envelope) { e.R = 4 }), []byte("passphrase"))
+	assert.ErrorIs(t, err, ErrPassphrase, "header is authenticated")
+
+	_, err = Open(alter(func(e *envelope) { e.Version = Version + 1 }), []byte("passphrase"))
+	assert.ErrorContains(t, err, "newer")
+
+	_, err = Open(alter(func(e *envelope) { e.Cipher = "rot13" }), []byte("passphrase"))
+	assert.ErrorContains(t, err, "unsupported")
+
+	_, err = Open(alter(func(e *envelope) { e.N = 1 << 30 }), []byte("passphrase"))
+	assert.ErrorContains(t, err, "invalid envelope parameters")
+	for _, bad := range []func(e *envelope){
+		func(e *envelope) { e.N = 1 << 19 },
+		func(e *envelope) { e.N = 3 << 10 }, // not a power of two
+		func(e *envelope) { e.R = 9 },
+		func(e *envelope) { e.P = 5 },
+	} {
+		_, err = Open(alter(bad), []byte("passphrase"))
+		assert.ErrorContains(t, err, "invalid envelope parameters")
+	}
+}
+
+func TestUnseal(t *testing.T) {
+	cheapScrypt(t)
+	t.Setenv(PassphraseEnv, "")
+	orig := passphrasePrompt
+	t.Cleanup(func() { passphrasePrompt = orig })
+
+	plain := []byte(`{"version":1}`)
+	out, err := Unseal(plain)
+	require.NoError(t, err)
+	assert.Equal(t, plain, out)
+
+	sealed, err := Seal(plain, []byte("passphrase"))
+	require.NoError(t, err)
+
+	passphrasePrompt = nil
+	_, err = Unseal(sealed)
+	assert.ErrorContains(t, err, PassphraseEnv)
+
+	// Wrong, then right.
+	answers := []string{"nope", "passphrase"}
+	SetPassphrasePrompt(func() ([]byte, error) {
+		a := answers[0]
+		answers = answers[1:]
+		return []byte(a), nil
+	})
+	out, err = Unseal(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plain, out)
+
+	// Gives up after maxAttempts.
+	calls := 0
+	SetPassphrasePrompt(func() ([]byte, error) {
+		calls++
+		return []byte("nope"), nil
+	})
+	_, err = Unseal(sealed)
+	assert.ErrorIs(t, err, ErrPassphrase)
+	assert.Equal(t, maxAttempts, calls)
+
+	SetPassphrasePrompt(func() ([]byte, error) { return nil, errors.New("aborted") })
+	_, err = Unseal(sealed)
+	assert.ErrorContains(t, err, "aborted")
+
+	t.Setenv(PassphraseEnv, "passphrase")
+	out, err = Unseal(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plain, out)
+}
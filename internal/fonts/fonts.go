@@ -0,0 +1,266 @@
+// Package fonts applies the fonts section of a config: it installs font
+// casks with Homebrew and downloads pinned font archives into
+// ~/Library/Fonts. Capture lives in internal/snapshot.
+package fonts
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openbootdotdev/openboot/internal/brew"
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/httputil"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// maxDownloadSize caps a font download; font families with every weight
+// and a Nerd Font patch stay well under it.
+const maxDownloadSize = 256 << 20
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// installedCasks, installCasks and download wrap Homebrew and the network
+// so tests can run without either.
+var (
+	installedCasks = func() (map[string]bool, error) {
+		_, casks, err := brew.GetInstalledPackages()
+		return casks, err
+	}
+	installCasks = brew.InstallCask
+	download     = func(url string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := httputil.Do(httpClient, req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close() //nolint:errcheck // read-only body
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", url, err)
+		}
+		if len(data) > maxDownloadSize {
+			return nil, fmt.Errorf("%s is larger than %d MB", url, maxDownloadSize>>20)
+		}
+		return data, nil
+	}
+)
+
+// manifestFile records, per sha256, the font files a download installed,
+// so a font already in place is not fetched again.
+const manifestFile = "fonts.json"
+
+// Apply installs missing font casks and downloads the fonts that are not
+// installed yet. Captured fonts without a URL are reported and skipped.
+// It returns the number of fonts installed.
+func Apply(fonts []config.Font, dryRun bool) (int, error) {
+	if len(fonts) == 0 {
+		return 0, nil
+	}
+	if err := config.ValidateFonts(fonts); err != nil {
+		return 0, fmt.Errorf("apply fonts: %w", err)
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return 0, err
+	}
+
+	var casks []string
+	var downloads []config.Font
+	for _, f := range fonts {
+		switch {
+		case f.Cask != "":
+			casks = append(casks, f.Cask)
+		case f.Downloadable():
+			downloads = append(downloads, f)
+		default:
+			ui.Warn(fmt.Sprintf("Font %s has no cask or url; install it by hand", f.Name))
+		}
+	}
+
+	changed := 0
+	var errs []error
+	if len(casks) > 0 {
+		n, err := applyCasks(casks, dryRun)
+		changed += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(downloads) > 0 {
+		n, err := applyDownloads(downloads, home, dryRun)
+		changed += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return changed, errors.Join(errs...)
+}
+
+func applyCasks(casks []string, dryRun bool) (int, error) {
+	have, err := installedCasks()
+	if err != nil {
+		return 0, fmt.Errorf("list installed casks: %w", err)
+	}
+	var missing []string
+	for _, c := range casks {
+		if !have[c] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if err := installCasks(missing, dryRun); err != nil {
+		return 0, fmt.Errorf("install font casks: %w", err)
+	}
+	return len(missing), nil
+}
+
+func applyDownloads(fonts []config.Font, home string, dryRun bool) (int, error) {
+	dir := filepath.Join(home, snapshot.FontsDir)
+	manifestPath := filepath.Join(home, ".openboot", manifestFile)
+	manifest := readManifest(manifestPath)
+
+	changed := 0
+	var errs []error
+	for _, f := range fonts {
+		sum := strings.ToLower(f.SHA256)
+		if installed(dir, manifest[sum]) {
+			continue
+		}
+		if dryRun {
+			ui.DryRunMsg("Would download %s (sha256 %s…) and install its fonts into ~/%s", f.URL, sum[:12], snapshot.FontsDir)
+			changed++
+			continue
+		}
+		fontFiles, err := fetchFonts(f)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("font %s: %w", f.Name, err))
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return changed, fmt.Errorf("create %s: %w", dir, err)
+		}
+		names := make([]string, 0, len(fontFiles))
+		for name, body := range fontFiles {
+			if err := os.WriteFile(filepath.Join(dir, name), body, 0644); err != nil { //nolint:gosec // fonts are world-readable
+				errs = append(errs, fmt.Errorf("font %s: write %s: %w", f.Name, name, err))
+				continue
+			}
+			names = append(names, name)
+		}
+		manifest[sum] = names
+		changed++
+	}
+	if dryRun || changed == 0 {
+		return changed, errors.Join(errs...)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return changed, fmt.Errorf("marshal font manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0700); err != nil {
+		errs = append(errs, fmt.Errorf("create %s: %w", filepath.Dir(manifestPath), err))
+	} else if err := os.WriteFile(manifestPath, data, 0600); err != nil {
+		errs = append(errs, fmt.Errorf("write font manifest: %w", err))
+	}
+	return changed, errors.Join(errs...)
+}
+
+// fetchFonts downloads f, checks its digest and returns its font files
+// keyed by name.
+func fetchFonts(f config.Font) (map[string][]byte, error) {
+	data, err := download(f.URL)
+	if err != nil {
+		return nil, err
+	}
+	got := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(got[:]), f.SHA256) {
+		return nil, fmt.Errorf("sha256 mismatch for %s: got %x", f.URL, got)
+	}
+
+	fontFiles, err := extractFonts(f.URL, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(fontFiles) == 0 {
+		return nil, fmt.Errorf("%s contains no .ttf, .otf or .ttc files", f.URL)
+	}
+	return fontFiles, nil
+}
+
+// extractFonts returns the font files in data keyed by base name: every
+// font in a zip archive, flattened, or data itself when the URL names a
+// font file.
+func extractFonts(url string, data []byte) (map[string][]byte, error) {
+	base := filepath.Base(strings.SplitN(url, "?", 2)[0])
+	if config.IsFontFile(base) {
+		return map[string][]byte{base: data}, nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a font file nor a zip archive: %w", url, err)
+	}
+	out := map[string][]byte{}
+	for _, zf := range zr.File {
+		name := filepath.Base(zf.Name)
+		// Skip macOS resource forks (__MACOSX/._Font.ttf).
+		if zf.FileInfo().IsDir() || strings.HasPrefix(name, "._") || !config.IsFontFile(name) {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", zf.Name, err)
+		}
+		body, err := io.ReadAll(io.LimitReader(rc, maxDownloadSize))
+		rc.Close() //nolint:errcheck,gosec // read-only
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", zf.Name, err)
+		}
+		out[name] = body
+	}
+	return out, nil
+}
+
+func installed(dir string, files []string) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func readManifest(path string) map[string][]string {
+	m := map[string][]string{}
+	data, err := os.ReadFile(path) //nolint:gosec // openboot's own state file
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(data, &m)
+	return m
+}
@@ -0,0 +1,157 @@
+package fonts
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+type stubs struct {
+	home      string
+	installed map[string]bool
+	casks     [][]string
+	downloads []string
+	files     map[string][]byte
+}
+
+func stubFonts(t *testing.T) *stubs {
+	t.Helper()
+	s := &stubs{home: t.TempDir(), installed: map[string]bool{}, files: map[string][]byte{}}
+	t.Setenv("HOME", s.home)
+	origInstalled, origInstall, origDownload := installedCasks, installCasks, download
+	t.Cleanup(func() { installedCasks, installCasks, download = origInstalled, origInstall, origDownload })
+	installedCasks = func() (map[string]bool, error) { return s.installed, nil }
+	installCasks = func(pkgs []string, dryRun bool) error {
+		s.casks = append(s.casks, pkgs)
+		return nil
+	}
+	download = func(url string) ([]byte, error) {
+		s.downloads = append(s.downloads, url)
+		return s.files[url], nil
+	}
+	return s
+}
+
+func zipOf(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func sumOf(data []byte) string {
+	s := sha256.Sum256(data)
+	return hex.EncodeToString(s[:])
+}
+
+func TestApply_InstallsMissingCasks(t *testing.T) {
+	s := stubFonts(t)
+	s.installed["font-inter"] = true
+
+	n, err := Apply([]config.Font{{Cask: "font-inter"}, {Cask: "font-fira-code"}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, [][]string{{"font-fira-code"}}, s.casks)
+}
+
+func TestApply_DownloadsArchive(t *testing.T) {
+	s := stubFonts(t)
+	const url = "https://example.com/berkeley.zip"
+	s.files[url] = zipOf(t, map[string]string{
+		"berkeley/BerkeleyMono-Regular.otf":         "regular",
+		"berkeley/BerkeleyMono-Bold.otf":            "bold",
+		"berkeley/LICENSE.txt":                      "license",
+		"__MACOSX/berkeley/._BerkeleyMono-Bold.otf": "fork",
+	})
+	font := config.Font{Name: "Berkeley Mono", URL: url, SHA256: sumOf(s.files[url])}
+
+	n, err := Apply([]config.Font{font}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	dir := filepath.Join(s.home, "Library/Fonts")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"BerkeleyMono-Regular.otf", "BerkeleyMono-Bold.otf"}, names)
+
+	// Installed: not downloaded again.
+	n, err = Apply([]config.Font{font}, false)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Len(t, s.downloads, 1)
+
+	// A removed file brings the download back.
+	require.NoError(t, os.Remove(filepath.Join(dir, "BerkeleyMono-Bold.otf")))
+	n, err = Apply([]config.Font{font}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.FileExists(t, filepath.Join(dir, "BerkeleyMono-Bold.otf"))
+}
+
+func TestApply_DownloadsFontFile(t *testing.T) {
+	s := stubFonts(t)
+	const url = "https://example.com/fonts/Mono-Regular.ttf?raw=1"
+	s.files[url] = []byte("ttf")
+
+	n, err := Apply([]config.Font{{Name: "Mono", URL: url, SHA256: sumOf(s.files[url])}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.FileExists(t, filepath.Join(s.home, "Library/Fonts/Mono-Regular.ttf"))
+}
+
+func TestApply_ChecksumMismatch(t *testing.T) {
+	s := stubFonts(t)
+	const url = "https://example.com/berkeley.zip"
+	s.files[url] = zipOf(t, map[string]string{"BerkeleyMono-Regular.otf": "tampered"})
+
+	n, err := Apply([]config.Font{{Name: "Berkeley Mono", URL: url, SHA256: sumOf([]byte("original"))}}, false)
+	assert.ErrorContains(t, err, "sha256 mismatch")
+	assert.Zero(t, n)
+	assert.NoDirExists(t, filepath.Join(s.home, "Library/Fonts"))
+}
+
+func TestApply_DryRun(t *testing.T) {
+	s := stubFonts(t)
+	var dryRunCasks []bool
+	installCasks = func(pkgs []string, dryRun bool) error {
+		dryRunCasks = append(dryRunCasks, dryRun)
+		return nil
+	}
+
+	n, err := Apply([]config.Font{
+		{Cask: "font-inter"},
+		{Name: "Berkeley Mono", URL: "https://example.com/berkeley.zip", SHA256: sumOf([]byte("x"))},
+		{Name: "MyFont", Files: []string{"MyFont-Regular.otf"}},
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []bool{true}, dryRunCasks)
+	assert.Empty(t, s.downloads)
+	assert.NoDirExists(t, filepath.Join(s.home, "Library/Fonts"))
+}
+
+func TestApply_Invalid(t *testing.T) {
+	s := stubFonts(t)
+	_, err := Apply([]config.Font{{Name: "X", URL: "http://example.com/x.zip", SHA256: sumOf(nil)}}, false)
+	assert.Error(t, err)
+	assert.Empty(t, s.downloads)
+}
@@ -0,0 +1,144 @@
+// Package gitconfig applies the global git configuration a config or
+// snapshot carries: allow-listed `git config --global` settings, the global
+// gitignore and gitattributes files, and per-directory identity profiles. Capture lives in
+// internal/snapshot; what is allowed, and what counts as a secret, lives in
+// internal/config.
+package gitconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// getGlobal reads a global git setting, returning "" when unset. setGlobal
+// writes one, replacing every existing value so multi-valued keys like
+// url.<base>.insteadOf end up with exactly the configured one. Both are vars
+// so tests can run without touching the real ~/.gitconfig.
+var (
+	getGlobal = func(key string) string {
+		out, err := system.RunCommandOutput("git", "config", "--global", "--get", key)
+		if err != nil {
+			return ""
+		}
+		return out
+	}
+	setGlobal = func(key, value string) error {
+		return system.RunCommand("git", "config", "--global", "--replace-all", key, value)
+	}
+)
+
+// Apply brings the global git configuration in line with cfg. Only the
+// settings cfg names are written, and only when they differ; the gitignore
+// and gitattributes files are replaced when cfg sets them and their content
+// differs. Each profile gets its file in config.GitProfilesDir and an
+// includeIf rule; profiles cfg does not name are left alone. Signing is set
+// up separately, by SetupSigning. It returns the number of settings, files
+// and profiles changed.
+func Apply(cfg *config.RemoteGitConfig, dryRun bool) (int, error) {
+	if cfg.Empty() {
+		return 0, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return 0, fmt.Errorf("apply git config: %w", err)
+	}
+
+	changed := 0
+	for _, key := range cfg.SortedKeys() {
+		ok, err := setIfChanged(key, cfg.Settings[key], dryRun)
+		if err != nil {
+			return changed, err
+		}
+		if ok {
+			changed++
+		}
+	}
+
+	home, err := system.HomeDir()
+	if err != nil {
+		return changed, fmt.Errorf("apply git config: %w", err)
+	}
+	files := []struct {
+		content, setting, fallback string
+	}{
+		{cfg.Gitignore, "core.excludesFile", config.GitignoreFile},
+		{cfg.Gitattributes, "core.attributesFile", config.GitattributesFile},
+	}
+	for _, f := range files {
+		if f.content == "" {
+			continue
+		}
+		path := config.GitFilePath(home, getGlobal(f.setting), f.fallback)
+		if current, err := os.ReadFile(path); err == nil && string(current) == f.content { //nolint:gosec // the user's own global git file
+			continue
+		}
+		changed++
+		if err := writeGitFile(path, f.content, dryRun); err != nil {
+			return changed - 1, err
+		}
+	}
+
+	for _, p := range cfg.Profiles {
+		ok, err := applyProfile(home, p, dryRun)
+		if err != nil {
+			return changed, err
+		}
+		if ok {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// applyProfile writes p's file and points an includeIf rule for its
+// directory at it. It reports whether either needed a change.
+func applyProfile(home string, p config.GitProfile, dryRun bool) (bool, error) {
+	file := p.Name + ".gitconfig"
+	path := filepath.Join(home, config.GitProfilesDir, file)
+	content := p.Render()
+	changed := false
+	if current, err := os.ReadFile(path); err != nil || string(current) != content { //nolint:gosec // path is under the user's own git config dir
+		changed = true
+		if err := writeGitFile(path, content, dryRun); err != nil {
+			return false, fmt.Errorf("git profile %s: %w", p.Name, err)
+		}
+	}
+
+	key := "includeIf.gitdir:" + config.GitProfileDir(p.Directory) + ".path"
+	value := "~/" + config.GitProfilesDir + "/" + file
+	if getGlobal(key) == value {
+		return changed, nil
+	}
+	if dryRun {
+		ui.DryRunMsg("Would use git profile %s for %s", p.Name, config.GitProfileDir(p.Directory))
+		return true, nil
+	}
+	if err := setGlobal(key, value); err != nil {
+		return false, fmt.Errorf("git profile %s: set %s: %w", p.Name, key, err)
+	}
+	return true, nil
+}
+
+// writeGitFile replaces path atomically, creating its directory if needed.
+func writeGitFile(path, content string, dryRun bool) error {
+	if dryRun {
+		ui.DryRunMsg("Would write %s", path)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("rename %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// allowedSignersFile is where SSH signing trusts its own key, relative to the
+// home directory. git reads it through gpg.ssh.allowedSignersFile to verify
+// signatures locally.
+const allowedSignersFile = ".config/git/allowed_signers"
+
+// defaultSSHSigningKey is the key reused, or generated, when the config names
+// none and git has no SSH signing key yet.
+const defaultSSHSigningKey = ".ssh/id_ed25519"
+
+// sshKeygen, gpgRun and gpgLookPath wrap the key tools so tests can run
+// without touching ~/.ssh or a real keyring.
+var (
+	sshKeygen = func(args ...string) (string, error) {
+		out, err := system.RunCommandSilent("ssh-keygen", args...)
+		if err != nil {
+			return "", fmt.Errorf("ssh-keygen: %s: %w", out, err)
+		}
+		return out, nil
+	}
+	gpgRun = func(args ...string) (string, error) {
+		return system.RunCommandOutput("gpg", append([]string{"--batch"}, args...)...)
+	}
+	gpgLookPath = func() error {
+		_, err := exec.LookPath("gpg")
+		return err
+	}
+)
+
+// SigningResult describes the signing setup SetupSigning left in place.
+type SigningResult struct {
+	// PublicKey is the key to upload to the git host: an OpenSSH public key
+	// line or an armored GPG key. Empty in a dry run that would generate one.
+	PublicKey string
+	// Key is what git signs with: the public key file for SSH, the
+	// fingerprint for OpenPGP.
+	Key string
+	// Generated reports whether a new key was (or would be) created. A
+	// generated key has no passphrase.
+	Generated bool
+	// Changed counts git settings and files written.
+	Changed int
+}
+
+// SetupSigning makes git sign commits as email. It reuses the configured or
+// existing key — generating one only when there is none — then sets
+// gpg.format, user.signingkey and commit.gpgsign, and for SSH trusts the key
+// in the allowed signers file. Running it again changes nothing.
+func SetupSigning(s *config.GitSigning, name, email string, dryRun bool) (*SigningResult, error) {
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("set up commit signing: %w", err)
+	}
+	if s == nil {
+		return &SigningResult{}, nil
+	}
+	if email == "" {
+		return nil, errors.New("set up commit signing: no git email configured")
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("set up commit signing: %w", err)
+	}
+
+	var res *SigningResult
+	var signingKey string
+	switch s.Format {
+	case config.GitSigningSSH:
+		res, signingKey, err = sshSigningKey(home, s.Key, email, dryRun)
+	default:
+		res, signingKey, err = gpgSigningKey(s.Key, name, email, dryRun)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set up commit signing: %w", err)
+	}
+	res.Key = signingKey
+
+	settings := [][2]string{
+		{"gpg.format", s.GPGFormat()},
+		{"user.signingkey", signingKey},
+		{"commit.gpgsign", "true"},
+	}
+	if s.Format == config.GitSigningSSH {
+		signers := filepath.Join(home, allowedSignersFile)
+		settings = append(settings, [2]string{"gpg.ssh.allowedSignersFile", signers})
+		ok, err := trustSigner(signers, email, res.PublicKey, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("set up commit signing: %w", err)
+		}
+		if ok {
+			res.Changed++
+		}
+	}
+	for _, kv := range settings {
+		ok, err := setIfChanged(kv[0], kv[1], dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("set up commit signing: %w", err)
+		}
+		if ok {
+			res.Changed++
+		}
+	}
+	return res, nil
+}
+
+// sshSigningKey returns the public key file git should sign with: key when
+// set, else git's current SSH signing key, else ~/.ssh/id_ed25519 —
+// generated when missing.
+func sshSigningKey(home, key, email string, dryRun bool) (*SigningResult, string, error) {
+	path := config.GitFilePath(home, key, defaultSSHSigningKey)
+	if key == "" && getGlobal("gpg.format") == config.GitSigningSSH {
+		if cur := getGlobal("user.signingkey"); cur != "" && !strings.HasPrefix(cur, "key::") {
+			if _, err := os.Stat(config.GitFilePath(home, cur, "")); err == nil {
+				path = config.GitFilePath(home, cur, "")
+			}
+		}
+	}
+	priv := strings.TrimSuffix(path, ".pub")
+	pub := priv + ".pub"
+	res := &SigningResult{}
+
+	if _, err := os.Stat(pub); err != nil {
+		switch _, privErr := os.Stat(priv); {
+		case privErr == nil:
+			// A private key without its .pub: derive it.
+			if dryRun {
+				ui.DryRunMsg("Would write %s from %s", pub, priv)
+				return res, pub, nil
+			}
+			out, err := sshKeygen("-y", "-f", priv)
+			if err != nil {
+				return nil, "", err
+			}
+			if err := writeGitFile(pub, out+"\n", false); err != nil {
+				return nil, "", err
+			}
+		case key != "":
+			return nil, "", fmt.Errorf("ssh key %s not found", priv)
+		default:
+			res.Generated = true
+			if dryRun {
+				ui.DryRunMsg("Would generate SSH signing key %s", priv)
+				return res, pub, nil
+			}
+			if err := os.MkdirAll(filepath.Dir(priv), 0700); err != nil {
+				return nil, "", fmt.Errorf("create %s: %w", filepath.Dir(priv), err)
+			}
+			if _, err := sshKeygen("-q", "-t", "ed25519", "-C", email, "-N", "", "-f", priv); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	data, err := os.ReadFile(pub) //nolint:gosec // the user's own public key
+	if err != nil {
+		return nil, "", fmt.Errorf("read %s: %w", pub, err)
+	}
+	res.PublicKey = strings.TrimSpace(string(data))
+	return res, pub, nil
+}
+
+// gpgSigningKey returns the fingerprint git should sign with: key when set,
+// else git's current OpenPGP signing key, else the first secret key for
+// email — generated when there is none.
+func gpgSigningKey(key, name, email string, dryRun bool) (*SigningResult, string, error) {
+	if err := gpgLookPath(); err != nil {
+		return nil, "", errors.New("gpg not found; install gnupg first (brew install gnupg)")
+	}
+	res := &SigningResult{}
+
+	fpr := ""
+	switch {
+	case key != "":
+		if fpr = gpgFingerprint(key); fpr == "" {
+			return nil, "", fmt.Errorf("gpg secret key %s not found", key)
+		}
+	default:
+		if f := getGlobal("gpg.format"); f == "" || f == "openpgp" {
+			if cur := getGlobal("user.signingkey"); cur != "" {
+				fpr = gpgFingerprint(cur)
+			}
+		}
+		if fpr == "" {
+			fpr = gpgFingerprint(email)
+		}
+	}
+
+	if fpr == "" {
+		res.Generated = true
+		uid := email
+		if name != "" {
+			uid = name + " <" + email + ">"
+		}
+		if dryRun {
+			ui.DryRunMsg("Would generate GPG signing key for %s", uid)
+			return res, "<new key>", nil
+		}
+		if _, err := gpgRun("--passphrase", "", "--quick-generate-key", uid, "ed25519", "sign", "2y"); err != nil {
+			return nil, "", fmt.Errorf("generate gpg key: %w", err)
+		}
+		if fpr = gpgFingerprint(email); fpr == "" {
+			return nil, "", errors.New("generated gpg key not found in keyring")
+		}
+	}
+
+	pub, err := gpgRun("--armor", "--export", fpr)
+	if err != nil {
+		return nil, "", fmt.Errorf("export gpg key %s: %w", fpr, err)
+	}
+	res.PublicKey = pub
+	return res, fpr, nil
+}
+
+// gpgFingerprint returns the fingerprint of the first secret key matching
+// query (a key id, fingerprint or email), or "" when there is none.
+func gpgFingerprint(query string) string {
+	out, err := gpgRun("--with-colons", "--list-secret-keys", query)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Split(line, ":"); len(fields) > 9 && fields[0] == "fpr" {
+			return fields[9]
+		}
+	}
+	return ""
+}
+
+// trustSigner adds "<email> namespaces="git" <key>" to the allowed signers
+// file unless it is already there. It reports whether the file changed.
+func trustSigner(path, email, publicKey string, dryRun bool) (bool, error) {
+	current, err := os.ReadFile(path) //nolint:gosec // path is under the user's own git config dir
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("read %s: %w", path, err)
+	}
+	if publicKey == "" {
+		// Dry run with a key still to be generated.
+		ui.DryRunMsg("Would trust the new key in %s", path)
+		return true, nil
+	}
+	line := email + ` namespaces="git" ` + publicKey
+	for _, l := range strings.Split(string(current), "\n") {
+		if strings.TrimSpace(l) == line {
+			return false, nil
+		}
+	}
+	content := string(current)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return true, writeGitFile(path, content+line+"\n", dryRun)
+}
+
+// setIfChanged sets a global git setting unless it already has value. It
+// reports whether the setting changed.
+func setIfChanged(key, value string, dryRun bool) (bool, error) {
+	if getGlobal(key) == value {
+		return false, nil
+	}
+	if dryRun {
+		ui.DryRunMsg("Would set git %s = %s", key, value)
+		return true, nil
+	}
+	if err := setGlobal(key, value); err != nil {
+		return false, fmt.Errorf("set git %s: %w", key, err)
+	}
+	return true, nil
+}
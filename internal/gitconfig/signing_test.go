@@ -0,0 +1,158 @@
+package gitconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+// fakeSSHKeygen replaces ssh-keygen: generating writes a key pair, -y
+// prints a public key. It records each call.
+func fakeSSHKeygen(t *testing.T) *[][]string {
+	t.Helper()
+	var calls [][]string
+	orig := sshKeygen
+	t.Cleanup(func() { sshKeygen = orig })
+	sshKeygen = func(args ...string) (string, error) {
+		calls = append(calls, args)
+		if args[0] == "-y" {
+			return "ssh-ed25519 DERIVED", nil
+		}
+		path := args[len(args)-1]
+		if err := os.WriteFile(path, []byte("PRIVATE"), 0600); err != nil {
+			return "", err
+		}
+		return "", os.WriteFile(path+".pub", []byte("ssh-ed25519 AAAAGENERATED jane@acme.com\n"), 0600)
+	}
+	return &calls
+}
+
+func TestSetupSigning_SSHGeneratesOnceAndIsIdempotent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	global := map[string]string{}
+	fakeGit(t, global)
+	calls := fakeSSHKeygen(t)
+
+	s := &config.GitSigning{Format: config.GitSigningSSH}
+	res, err := SetupSigning(s, "Jane", "jane@acme.com", false)
+	require.NoError(t, err)
+	assert.True(t, res.Generated)
+	assert.Equal(t, "ssh-ed25519 AAAAGENERATED jane@acme.com", res.PublicKey)
+	assert.Equal(t, 5, res.Changed, "four settings and the allowed signers file")
+
+	pub := filepath.Join(home, ".ssh", "id_ed25519.pub")
+	signers := filepath.Join(home, ".config", "git", "allowed_signers")
+	assert.Equal(t, "ssh", global["gpg.format"])
+	assert.Equal(t, pub, global["user.signingkey"])
+	assert.Equal(t, "true", global["commit.gpgsign"])
+	assert.Equal(t, signers, global["gpg.ssh.allowedSignersFile"])
+	data, err := os.ReadFile(signers)
+	require.NoError(t, err)
+	assert.Equal(t, `jane@acme.com namespaces="git" ssh-ed25519 AAAAGENERATED jane@acme.com`+"\n", string(data))
+
+	res, err = SetupSigning(s, "Jane", "jane@acme.com", false)
+	require.NoError(t, err)
+	assert.False(t, res.Generated)
+	assert.Zero(t, res.Changed)
+	assert.Len(t, *calls, 1, "the key is generated once")
+}
+
+func TestSetupSigning_SSHReusesExistingKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	fakeGit(t, map[string]string{})
+	calls := fakeSSHKeygen(t)
+
+	// A private key without its .pub: the public half is derived, not a new
+	// key generated.
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".ssh"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".ssh", "signing"), []byte("PRIVATE"), 0600))
+
+	res, err := SetupSigning(&config.GitSigning{Format: config.GitSigningSSH, Key: "~/.ssh/signing"}, "", "jane@acme.com", false)
+	require.NoError(t, err)
+	assert.False(t, res.Generated)
+	assert.Equal(t, "ssh-ed25519 DERIVED", res.PublicKey)
+	require.Len(t, *calls, 1)
+	assert.Equal(t, "-y", (*calls)[0][0])
+
+	_, err = SetupSigning(&config.GitSigning{Format: config.GitSigningSSH, Key: "~/.ssh/missing"}, "", "jane@acme.com", false)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestSetupSigning_DryRunChangesNothing(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	global := map[string]string{}
+	fakeGit(t, global)
+	calls := fakeSSHKeygen(t)
+
+	res, err := SetupSigning(&config.GitSigning{Format: config.GitSigningSSH}, "", "jane@acme.com", true)
+	require.NoError(t, err)
+	assert.True(t, res.Generated)
+	assert.Positive(t, res.Changed)
+	assert.Empty(t, *calls)
+	assert.Empty(t, global)
+	assert.NoDirExists(t, filepath.Join(home, ".ssh"))
+	assert.NoFileExists(t, filepath.Join(home, ".config", "git", "allowed_signers"))
+}
+
+func TestSetupSigning_GPG(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	global := map[string]string{}
+	fakeGit(t, global)
+
+	origRun, origLook := gpgRun, gpgLookPath
+	t.Cleanup(func() { gpgRun, gpgLookPath = origRun, origLook })
+	gpgLookPath = func() error { return nil }
+	generated := false
+	var generateArgs []string
+	gpgRun = func(args ...string) (string, error) {
+		switch {
+		case args[0] == "--with-colons":
+			if !generated {
+				return "", errors.New("no secret key")
+			}
+			return "sec:u:255:22:KEYID:::::::scESC:\nfpr:::::::::ABCDEF0123456789:\n", nil
+		case args[0] == "--passphrase":
+			generated = true
+			generateArgs = args
+			return "", nil
+		case args[0] == "--armor":
+			return "-----BEGIN PGP PUBLIC KEY BLOCK-----", nil
+		}
+		return "", errors.New("unexpected gpg call: " + strings.Join(args, " "))
+	}
+
+	s := &config.GitSigning{Format: config.GitSigningGPG}
+	res, err := SetupSigning(s, "Jane Doe", "jane@acme.com", false)
+	require.NoError(t, err)
+	assert.True(t, res.Generated)
+	assert.Contains(t, generateArgs, "Jane Doe <jane@acme.com>")
+	assert.Equal(t, "-----BEGIN PGP PUBLIC KEY BLOCK-----", res.PublicKey)
+	assert.Equal(t, "openpgp", global["gpg.format"])
+	assert.Equal(t, "ABCDEF0123456789", global["user.signingkey"])
+	assert.Equal(t, "true", global["commit.gpgsign"])
+	assert.NotContains(t, global, "gpg.ssh.allowedSignersFile")
+
+	res, err = SetupSigning(s, "Jane Doe", "jane@acme.com", false)
+	require.NoError(t, err)
+	assert.False(t, res.Generated, "the existing key is reused")
+	assert.Zero(t, res.Changed)
+
+	gpgLookPath = func() error { return errors.New("not found") }
+	_, err = SetupSigning(s, "Jane Doe", "jane@acme.com", false)
+	assert.ErrorContains(t, err, "install gnupg")
+}
+
+func TestSetupSigning_NeedsEmail(t *testing.T) {
+	_, err := SetupSigning(&config.GitSigning{Format: config.GitSigningSSH}, "", "", false)
+	assert.ErrorContains(t, err, "no git email")
+}
@@ -117,6 +117,7 @@ func Apply(plan InstallPlan, r Reporter) error {
 
 func ApplyContext(ctx context.Context, plan InstallPlan, r Reporter) error {
 	steps := plannedSteps(plan)
+	plan.notes = &applyNotes{}
 	var softErrs []error
 
 	for i, s := range steps {
@@ -185,12 +186,24 @@ func noCtx(f func(InstallPlan, Reporter) error) func(context.Context, InstallPla
 func plannedSteps(plan InstallPlan) []applyStep {
 	sys := !plan.PackagesOnly
 	all := []applyStep{
+		{"Machine name", sys && !plan.Machine.Empty(), noCtx(applyMachine)},
+		{"Security", sys && len(plan.Security.Enabled()) > 0, noCtx(applySecurity)},
 		{"Git identity", sys && !plan.SkipGit, noCtx(applyGitConfig)},
+		{"Commit signing", sys && plan.GitConfig != nil && plan.GitConfig.Signing != nil, noCtx(applyGitSigning)},
+		{"Git settings", sys && !plan.GitConfig.Empty(), noCtx(applyGitSettings)},
+		{"SSH", sys && !plan.SSH.Empty(), noCtx(applySSH)},
 		{"Packages", len(plan.Formulae)+len(plan.Casks)+len(plan.Taps) > 0, applyPackages},
+		{"Fonts", len(plan.Fonts) > 0, noCtx(applyFonts)},
 		{"npm globals", len(plan.Npm) > 0, applyNpm},
-		{"Shell", sys && plan.InstallOhMyZsh, noCtx(applyShell)},
+		{"App settings", sys && !plan.AppSettings.Empty(), noCtx(applyAppSettings)},
+		{"Services", sys && len(plan.Services) > 0, noCtx(applyServices)},
+		{"Shell", sys && (plan.InstallOhMyZsh || plan.ShellFramework != "" || plan.Starship), noCtx(applyShell)},
 		{"Dotfiles", sys && plan.DotfilesURL != "", noCtx(applyDotfiles)},
-		{"macOS preferences", sys && (len(plan.MacOSPrefs) > 0 || plan.DockApps != nil || plan.LoginItems != nil), noCtx(applyMacOSPrefs)},
+		{"Shell snippets", sys && (!plan.ShellSnippets.Empty() || plan.RemoveShellSnippets), noCtx(applyShellSnippets)},
+		{"Default apps", sys && len(plan.DefaultApps) > 0, noCtx(applyDefaultApps)},
+		{"Keyboard", sys && !plan.Keyboard.Empty(), noCtx(applyKeyboard)},
+		{"macOS preferences", sys && (len(plan.MacOSPrefs) > 0 || plan.DockApps != nil || plan.Dock != nil || plan.LoginItems != nil), noCtx(applyMacOSPrefs)},
+		{"Launch agents", sys && len(plan.LaunchAgents) > 0, noCtx(applyLaunchAgents)},
 		{"Post-install script", sys && len(plan.PostInstall) > 0, noCtx(applyPostInstall)},
 	}
 	out := make([]applyStep, 0, len(all))
@@ -215,6 +228,49 @@ func abortWith(softErrs []error, cause error) error {
 	return errors.Join(append(softErrs, cause)...)
 }
 
+// keyNote is a public key the install set up. It is repeated in the
+// completion summary: printed only inside its step, it scrolled away long
+// before the install finished.
+type keyNote struct {
+	What      string
+	PublicKey string
+	// AddPassphrase is the command that adds a passphrase to a key generated
+	// without one; "" when the key was not generated unprotected.
+	AddPassphrase string
+}
+
+// applyNotes collects what steps want repeated in the completion summary.
+// A nil *applyNotes drops everything, so steps run outside ApplyContext
+// need not care.
+type applyNotes struct {
+	keys []keyNote
+}
+
+func (n *applyNotes) addKey(k keyNote) {
+	if n != nil {
+		n.keys = append(n.keys, k)
+	}
+}
+
+// showKeyNotes lists the public keys to upload and warns about the ones
+// generated without a passphrase.
+func showKeyNotes(n *applyNotes, r Reporter) {
+	if n == nil || len(n.keys) == 0 {
+		return
+	}
+	r.Info("Add these public keys to your git host:")
+	for _, k := range n.keys {
+		r.Info("  - " + k.What)
+		ui.Println(k.PublicKey)
+	}
+	for _, k := range n.keys {
+		if k.AddPassphrase != "" {
+			r.Warn(fmt.Sprintf("%s was generated without a passphrase; add one with: %s", k.What, k.AddPassphrase))
+		}
+	}
+	ui.Println()
+}
+
 func showCompletionFromPlan(plan InstallPlan, r Reporter, errCount int) {
 	ui.Println()
 	if errCount > 0 {
@@ -241,6 +297,7 @@ func showCompletionFromPlan(plan InstallPlan, r Reporter, errCount int) {
 	}
 	ui.Println()
 
+	showKeyNotes(plan.notes, r)
 	showScreenRecordingReminderFromPlan(plan)
 
 	r.Info("Next steps:")
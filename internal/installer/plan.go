@@ -23,7 +23,24 @@ type InstallPlan struct {
 	// Git
 	GitName  string
 	GitEmail string
-	SkipGit  bool // when true, Apply skips git configuration entirely
+	SkipGit  bool // when true, Apply skips the git identity step
+	// GitConfig is global git configuration beyond identity; nil = leave as-is.
+	GitConfig *config.RemoteGitConfig
+
+	// SSH keys, ~/.ssh/config hosts and known_hosts pins; nil = leave as-is.
+	SSH *config.RemoteSSHConfig
+
+	// Machine names (scutil), templates unresolved; nil = leave as-is.
+	Machine *config.RemoteMachineConfig
+
+	// Security hardening controls to turn on; nil = leave as-is.
+	Security *config.RemoteSecurityConfig
+
+	// DefaultApps are default handlers set once the apps are installed.
+	DefaultApps []config.DefaultApp
+
+	// Keyboard shortcuts and key remapping; nil = leave as-is.
+	Keyboard *config.RemoteKeyboardConfig
 
 	// Packages (fully resolved and categorized)
 	Formulae     []string
@@ -33,22 +50,50 @@ type InstallPlan struct {
 	SelectedPkgs map[string]bool // for showCompletion and screen-recording reminder
 	OnlinePkgs   []config.Package
 
+	// Fonts are font casks and pinned downloads into ~/Library/Fonts.
+	Fonts []config.Font
+
+	// AppSettings are restored for each app once its cask is installed;
+	// nil = leave as-is.
+	AppSettings *config.AppSettings
+
+	// Services are brew services to start once packages are installed.
+	Services []string
+
 	// Shell
 	InstallOhMyZsh bool
-	ShellTheme     string   // ZSH_THEME to restore; empty = leave as-is
-	ShellPlugins   []string // plugins=(...) to restore; nil = leave as-is
-	DotfilesURL    string   // "" = skip dotfiles entirely; any URL = use it (may be DefaultDotfilesURL)
+	ShellTheme     string   // ZSH_THEME (or prezto theme) to restore; empty = leave as-is
+	ShellPlugins   []string // plugins=(...) (or framework plugins) to restore; nil = leave as-is
+	ShellName      string   // shell the config targets; "" = implied by framework, else zsh
+	ShellFramework string   // zinit, prezto or fisher; oh-my-zsh is InstallOhMyZsh
+	Starship       bool
+	StarshipConfig string                // starship.toml contents; "" = leave as-is
+	ShellSnippets  *config.ShellSnippets // env vars, aliases, PATH; nil = leave as-is
+	DotfilesURL    string                // "" = skip dotfiles entirely; any URL = use it (may be DefaultDotfilesURL)
+
+	// RemoveShellSnippets is set when the config has no snippets but an
+	// earlier one left a snippets block: openboot owns the block, so the
+	// install removes it, as sync does.
+	RemoveShellSnippets bool
 
 	// macOS
 	MacOSPrefs []macos.Preference
 	DockApps   []string
+	Dock       *config.DockLayout // full layout; takes over from DockApps when set
 	LoginItems []macos.LoginItem
 
+	// LaunchAgents are written to ~/Library/LaunchAgents and loaded.
+	LaunchAgents []config.LaunchAgent
+
 	// Post-install
 	PostInstall []string
 
 	// Remote config reference (kept for completion display)
 	RemoteConfig *config.RemoteConfig
+
+	// notes collects what steps repeat in the completion summary; ApplyContext
+	// sets it.
+	notes *applyNotes
 }
 
 // Plan collects all user decisions and returns a ready-to-Apply InstallPlan.
@@ -92,15 +137,34 @@ func planFromRemoteConfig(opts *config.InstallOptions, st *config.InstallState,
 		plan.DotfilesURL = opts.DotfilesURL
 	}
 
-	if rc.Shell != nil && rc.Shell.OhMyZsh {
-		plan.InstallOhMyZsh = true
-		// Carry theme and plugins through so applyShell takes the restore path
-		// (writes plugins=() and git-clones external plugins). Dropping these
-		// silently downgraded remote-config installs to a bare OMZ install with
-		// no plugins cloned.
-		plan.ShellTheme = rc.Shell.Theme
-		plan.ShellPlugins = rc.Shell.Plugins
-	}
+	if rc.Shell != nil {
+		switch fw := rc.Shell.EffectiveFramework(); fw {
+		case config.FrameworkOhMyZsh:
+			plan.InstallOhMyZsh = true
+			// Carry theme and plugins through so applyShell takes the restore path
+			// (writes plugins=() and git-clones external plugins). Dropping these
+			// silently downgraded remote-config installs to a bare OMZ install with
+			// no plugins cloned.
+			plan.ShellTheme = rc.Shell.Theme
+			plan.ShellPlugins = rc.Shell.Plugins
+		case "":
+		default:
+			plan.ShellFramework = fw
+			plan.ShellTheme = rc.Shell.Theme
+			plan.ShellPlugins = rc.Shell.Plugins
+		}
+		plan.ShellName = rc.Shell.Shell
+		plan.Starship = rc.Shell.Starship
+		plan.StarshipConfig = rc.Shell.StarshipConfig
+		plan.ShellSnippets = rc.Shell.Snippets
+	}
+	plan.RemoveShellSnippets = plan.ShellSnippets.Empty() && hasSnippetsFunc()
+	plan.GitConfig = rc.Git
+	plan.SSH = rc.SSH
+	plan.Machine = rc.Machine
+	plan.Security = rc.Security
+	plan.DefaultApps = rc.DefaultApps
+	plan.Keyboard = rc.Keyboard
 
 	for _, p := range rc.MacOSPrefs {
 		prefType := p.Type
@@ -113,6 +177,11 @@ func planFromRemoteConfig(opts *config.InstallOptions, st *config.InstallState,
 	}
 
 	plan.DockApps = rc.DockApps
+	plan.Dock = rc.Dock
+	plan.LaunchAgents = rc.LaunchAgents
+	plan.Fonts = rc.Fonts
+	plan.AppSettings = rc.AppSettings
+	plan.Services = rc.Services
 
 	for _, li := range rc.LoginItems {
 		plan.LoginItems = append(plan.LoginItems, macos.LoginItem{
@@ -319,6 +388,14 @@ func PlanFromSelection(opts *config.InstallOptions, selected map[string]bool, on
 	} else {
 		plan.SkipGit = true
 	}
+	plan.SSH = st.SnapshotSSH
+	plan.DefaultApps = st.SnapshotDefaultApps
+	plan.Keyboard = st.SnapshotKeyboard
+	plan.Dock = st.SnapshotDock
+	plan.LaunchAgents = st.SnapshotLaunchAgents
+	plan.Fonts = st.SnapshotFonts
+	plan.AppSettings = st.SnapshotAppSettings
+	plan.Services = st.SnapshotServices
 
 	plan.InstallOhMyZsh = opts.Shell != "skip"
 
@@ -414,6 +491,7 @@ func PlanFromSnapshot(opts *config.InstallOptions, st *config.InstallState) Inst
 	if st.SnapshotGit != nil {
 		plan.GitName = st.SnapshotGit.UserName
 		plan.GitEmail = st.SnapshotGit.UserEmail
+		plan.GitConfig = st.SnapshotGit.Config
 	} else {
 		plan.SkipGit = true
 	}
@@ -424,10 +502,22 @@ func PlanFromSnapshot(opts *config.InstallOptions, st *config.InstallState) Inst
 	}
 
 	// Shell: restore exactly what the snapshot recorded; don't install OMZ if it wasn't there.
-	if opts.Shell != "skip" && st.SnapshotShellOhMyZsh {
-		plan.InstallOhMyZsh = true
-		plan.ShellTheme = st.SnapshotShellTheme
-		plan.ShellPlugins = st.SnapshotShellPlugins
+	if opts.Shell != "skip" {
+		switch {
+		case st.SnapshotShellOhMyZsh:
+			plan.InstallOhMyZsh = true
+			plan.ShellTheme = st.SnapshotShellTheme
+			plan.ShellPlugins = st.SnapshotShellPlugins
+		case st.SnapshotShellFramework != "" && st.SnapshotShellFramework != config.FrameworkOhMyZsh:
+			plan.ShellFramework = st.SnapshotShellFramework
+			plan.ShellTheme = st.SnapshotShellTheme
+			plan.ShellPlugins = st.SnapshotShellPlugins
+		}
+		plan.ShellName = st.SnapshotShellName
+		plan.Starship = st.SnapshotStarship
+		plan.StarshipConfig = st.SnapshotStarshipConfig
+		plan.ShellSnippets = st.SnapshotShellSnippets
+		plan.RemoveShellSnippets = plan.ShellSnippets.Empty() && hasSnippetsFunc()
 	}
 
 	// macOS: convert snapshot preferences to macos.Preference values, unless skipped via flag.
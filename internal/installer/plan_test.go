@@ -452,6 +452,66 @@ func TestPlan_RemoteConfig_ShellThemeAndPluginsRestored(t *testing.T) {
 	assert.Equal(t, []string{"git", "zsh-autosuggestions", "fast-syntax-highlighting"}, plan.ShellPlugins)
 }
 
+func TestPlan_RemoteConfig_OtherFrameworkAndStarship(t *testing.T) {
+	opts := &config.InstallOptions{DryRun: true, Silent: true}
+	st := &config.InstallState{
+		RemoteConfig: &config.RemoteConfig{
+			Shell: &config.RemoteShellConfig{
+				Shell:          config.ShellFish,
+				Framework:      config.FrameworkFisher,
+				Plugins:        []string{"ilancosman/tide@v6"},
+				Starship:       true,
+				StarshipConfig: "add_newline = false\n",
+				Snippets:       &config.ShellSnippets{Path: []string{"$HOME/bin"}},
+			},
+		},
+	}
+
+	plan, err := Plan(opts, st)
+	require.NoError(t, err)
+
+	assert.False(t, plan.InstallOhMyZsh)
+	assert.Equal(t, config.FrameworkFisher, plan.ShellFramework)
+	assert.Equal(t, config.ShellFish, plan.ShellName)
+	assert.Equal(t, []string{"ilancosman/tide@v6"}, plan.ShellPlugins)
+	assert.True(t, plan.Starship)
+	assert.Equal(t, "add_newline = false\n", plan.StarshipConfig)
+	assert.Equal(t, []string{"$HOME/bin"}, plan.ShellSnippets.Path)
+}
+
+func TestPlanFromSnapshot_OtherFrameworkRestored(t *testing.T) {
+	cfg := &config.Config{
+		InstallOptions: config.InstallOptions{DryRun: true, Silent: true, Macos: "skip", Dotfiles: "skip"},
+		InstallState: config.InstallState{
+			SnapshotShellName:      config.ShellZsh,
+			SnapshotShellFramework: config.FrameworkPrezto,
+			SnapshotShellTheme:     "sorin",
+			SnapshotShellPlugins:   []string{"git"},
+			SnapshotStarship:       true,
+			SnapshotShellSnippets:  &config.ShellSnippets{Aliases: []config.ShellAlias{{Name: "k", Command: "kubectl"}}},
+		},
+	}
+	plan := PlanFromSnapshot(cfg.ToInstallOptions(), cfg.ToInstallState())
+
+	assert.False(t, plan.InstallOhMyZsh)
+	assert.Equal(t, config.FrameworkPrezto, plan.ShellFramework)
+	assert.Equal(t, "sorin", plan.ShellTheme)
+	assert.True(t, plan.Starship)
+	require.NotNil(t, plan.ShellSnippets)
+	assert.Equal(t, "kubectl", plan.ShellSnippets.Aliases[0].Command)
+}
+
+func TestPlan_GitConfig(t *testing.T) {
+	opts := &config.InstallOptions{DryRun: true, Silent: true}
+	git := &config.RemoteGitConfig{Settings: map[string]string{"pull.rebase": "true"}}
+	plan, err := Plan(opts, &config.InstallState{RemoteConfig: &config.RemoteConfig{Git: git}})
+	require.NoError(t, err)
+	assert.Equal(t, git, plan.GitConfig)
+
+	plan = PlanFromSnapshot(opts, &config.InstallState{SnapshotGit: &config.SnapshotGitConfig{Config: git}})
+	assert.Equal(t, git, plan.GitConfig)
+}
+
 func TestPlanFromSnapshot_ShellSkipFlag(t *testing.T) {
 	cfg := &config.Config{
 		InstallOptions: config.InstallOptions{
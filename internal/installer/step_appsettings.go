@@ -0,0 +1,61 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/appsettings"
+	"github.com/openbootdotdev/openboot/internal/brew"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applyAppSettingsFunc and installedCasksFunc are vars so tests can observe
+// the app settings step without writing settings or asking brew.
+var (
+	applyAppSettingsFunc = appsettings.Apply
+	installedCasksFunc   = func() (map[string]bool, error) {
+		_, casks, err := brew.GetInstalledPackages()
+		return casks, err
+	}
+)
+
+// applyAppSettings runs after packages, so each app's cask is installed
+// before its settings are put in place. Apps that are not opted in on this
+// Mac, or whose cask is still missing, are skipped. In a dry run the planned casks count as installed.
+func applyAppSettings(plan InstallPlan, r Reporter) error {
+	installed, err := installedCasksFunc()
+	if err != nil {
+		r.Warn(fmt.Sprintf("Failed to check installed casks: %v", err))
+		installed = map[string]bool{}
+	}
+	if plan.DryRun {
+		for _, c := range plan.Casks {
+			installed[c] = true
+		}
+	}
+
+	res, err := applyAppSettingsFunc(plan.AppSettings, installed, plan.DryRun)
+	if len(res.NotOptedIn) > 0 {
+		r.Muted(fmt.Sprintf("Not opted in on this Mac, settings skipped: %s (openboot app-settings enable %s)",
+			strings.Join(res.NotOptedIn, ", "), strings.Join(res.NotOptedIn, " ")))
+	}
+	if len(res.Skipped) > 0 {
+		r.Muted("Not installed, settings skipped: " + strings.Join(res.Skipped, ", "))
+	}
+	for _, f := range res.Symlinked {
+		r.Muted(f + " is a symlink, left alone")
+	}
+	if err != nil {
+		return fmt.Errorf("app settings: %w", err)
+	}
+	if !plan.DryRun && len(res.Applied) > 0 {
+		if res.Changed == 0 {
+			r.Muted("App settings already up to date")
+		} else {
+			r.Success(fmt.Sprintf("App settings restored (%d changed)", res.Changed))
+			r.Muted("Restart " + strings.Join(res.Applied, ", ") + " to pick them up")
+		}
+	}
+	ui.Println()
+	return nil
+}
@@ -0,0 +1,29 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/openbootdotdev/openboot/internal/defaultapps"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applyDefaultAppsFunc is a var so tests can observe the default apps step
+// without changing the real handlers.
+var applyDefaultAppsFunc = defaultapps.Apply
+
+// applyDefaultApps runs after packages, so the apps it points at exist.
+func applyDefaultApps(plan InstallPlan, r Reporter) error {
+	n, err := applyDefaultAppsFunc(plan.DefaultApps, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("set default apps: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Default apps already set")
+		} else {
+			r.Success(fmt.Sprintf("Default apps set (%d changed)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
@@ -0,0 +1,30 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/openbootdotdev/openboot/internal/fonts"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applyFontsFunc is a var so tests can observe the fonts step without
+// running brew or downloading anything.
+var applyFontsFunc = fonts.Apply
+
+// applyFonts runs right after packages, so taps the font casks need are
+// already in place.
+func applyFonts(plan InstallPlan, r Reporter) error {
+	n, err := applyFontsFunc(plan.Fonts, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("fonts: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Fonts already installed")
+		} else {
+			r.Success(fmt.Sprintf("Fonts installed (%d changed)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
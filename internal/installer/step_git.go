@@ -2,11 +2,21 @@ package installer
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/gitconfig"
 	"github.com/openbootdotdev/openboot/internal/system"
 	"github.com/openbootdotdev/openboot/internal/ui"
 )
 
+// applyGitConfigFunc and setupSigningFunc are vars so tests can observe git
+// settings without touching the real ~/.gitconfig or generating keys.
+var (
+	applyGitConfigFunc = gitconfig.Apply
+	setupSigningFunc   = gitconfig.SetupSigning
+)
+
 func applyGitConfig(plan InstallPlan, r Reporter) error {
 	existingName, existingEmail := system.GetExistingGitConfig()
 	if existingName != "" && existingEmail != "" {
@@ -33,3 +43,95 @@ func applyGitConfig(plan InstallPlan, r Reporter) error {
 	ui.Println()
 	return nil
 }
+
+func applyGitSettings(plan InstallPlan, r Reporter) error {
+	git := plan.GitConfig
+	if keys := git.CommandSettings(); len(keys) > 0 {
+		run, err := approveCode(plan, r, codeGate{
+			What:    "git settings that run commands",
+			Header:  fmt.Sprintf("Git settings that run commands (%d):", len(keys)),
+			Prompt:  "Apply these git settings?",
+			Preview: gitCommandPreview(git, keys),
+		})
+		if err != nil {
+			return err
+		}
+		if !run {
+			git = git.WithoutCommandSettings()
+			if git.Empty() {
+				return nil
+			}
+		}
+	}
+
+	n, err := applyGitConfigFunc(git, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("apply git settings: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Git settings already up to date")
+		} else {
+			r.Success(fmt.Sprintf("Git settings applied (%d changed)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
+
+func applyGitSigning(plan InstallPlan, r Reporter) error {
+	// Sign as the identity the Git identity step leaves in place: an
+	// existing one wins over the plan's.
+	name, email := system.GetExistingGitConfig()
+	if name == "" || email == "" {
+		name, email = plan.GitName, plan.GitEmail
+	}
+	res, err := setupSigningFunc(plan.GitConfig.Signing, name, email, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("commit signing: %w", err)
+	}
+	if plan.DryRun {
+		ui.Println()
+		return nil
+	}
+	switch {
+	case res.Changed == 0:
+		r.Muted("Commit signing already configured")
+	case res.Generated:
+		r.Success(fmt.Sprintf("Generated a %s signing key; commits are now signed", plan.GitConfig.Signing.Format))
+	default:
+		r.Success(fmt.Sprintf("Commits are now signed with your %s key", plan.GitConfig.Signing.Format))
+	}
+	if res.Changed > 0 && res.PublicKey != "" {
+		note := keyNote{
+			What:      fmt.Sprintf("%s signing key (add it as a signing key)", plan.GitConfig.Signing.Format),
+			PublicKey: res.PublicKey,
+		}
+		if res.Generated {
+			note.AddPassphrase = signingPassphraseCommand(plan.GitConfig.Signing.Format, res.Key)
+		}
+		plan.notes.addKey(note)
+		r.Muted("Its public key is listed again at the end of the install")
+	}
+	ui.Println()
+	return nil
+}
+
+// signingPassphraseCommand returns the command that adds a passphrase to
+// the signing key key of the given format.
+func signingPassphraseCommand(format, key string) string {
+	if format == config.GitSigningSSH {
+		return "ssh-keygen -p -f " + strings.TrimSuffix(key, ".pub")
+	}
+	return "gpg --passwd " + key
+}
+
+// gitCommandPreview lists keys of g as the git config commands that set
+// them.
+func gitCommandPreview(g *config.RemoteGitConfig, keys []string) string {
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("git config --global %s %q", k, g.Settings[k])
+	}
+	return strings.Join(lines, "\n")
+}
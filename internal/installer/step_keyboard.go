@@ -0,0 +1,29 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/openbootdotdev/openboot/internal/keyboard"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applyKeyboardFunc is a var so tests can observe the keyboard step without
+// changing shortcuts or the key mapping.
+var applyKeyboardFunc = keyboard.Apply
+
+// applyKeyboard runs after packages, so the apps its shortcuts name exist.
+func applyKeyboard(plan InstallPlan, r Reporter) error {
+	n, err := applyKeyboardFunc(plan.Keyboard, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("keyboard: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Keyboard settings already applied")
+		} else {
+			r.Success(fmt.Sprintf("Keyboard settings applied (%d changed)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
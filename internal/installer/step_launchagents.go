@@ -0,0 +1,67 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/launchagents"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applyLaunchAgentsFunc is a var so tests can observe the launch agents
+// step without writing plists or calling launchctl.
+var applyLaunchAgentsFunc = launchagents.Apply
+
+// applyLaunchAgents runs after packages and dotfiles, so the programs the
+// agents start are installed when launchd first runs them. launchd keeps
+// running an agent at every login, so the step sits behind the same
+// opt-in as post_install.
+func applyLaunchAgents(plan InstallPlan, r Reporter) error {
+	run, err := approveCode(plan, r, codeGate{
+		What:    "launch agents",
+		Header:  fmt.Sprintf("Launch agents (%d):", len(plan.LaunchAgents)),
+		Prompt:  "Load these launch agents?",
+		Preview: launchAgentsPreview(plan.LaunchAgents),
+	})
+	if err != nil || !run {
+		return err
+	}
+
+	n, err := applyLaunchAgentsFunc(plan.LaunchAgents, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("launch agents: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Launch agents already up to date")
+		} else {
+			r.Success(fmt.Sprintf("Launch agents loaded (%d changed)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
+
+// launchAgentsPreview lists each agent's label, when launchd runs it, and
+// the command it runs.
+func launchAgentsPreview(agents []config.LaunchAgent) string {
+	var b strings.Builder
+	for i, a := range agents {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		var when []string
+		if a.RunAtLoad {
+			when = append(when, "at login")
+		}
+		if a.StartInterval > 0 {
+			when = append(when, fmt.Sprintf("every %ds", a.StartInterval))
+		}
+		if len(when) == 0 {
+			when = append(when, "on demand")
+		}
+		fmt.Fprintf(&b, "# %s (%s)\n%s", a.Label, strings.Join(when, ", "), strings.Join(a.ProgramArguments, " "))
+	}
+	return b.String()
+}
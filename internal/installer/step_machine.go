@@ -0,0 +1,30 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/openbootdotdev/openboot/internal/machine"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applyMachineFunc is a var so tests can observe the machine name step
+// without running scutil.
+var applyMachineFunc = machine.Apply
+
+// applyMachine runs first so its sudo prompt comes before the long
+// unattended package installs.
+func applyMachine(plan InstallPlan, r Reporter) error {
+	n, err := applyMachineFunc(plan.Machine, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("set machine name: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Machine name already set")
+		} else {
+			r.Success(fmt.Sprintf("Machine name set (%d changed)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
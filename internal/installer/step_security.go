@@ -0,0 +1,30 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/openbootdotdev/openboot/internal/doctor"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applySecurityFunc is a var so tests can observe the security step
+// without touching PAM or the firewall.
+var applySecurityFunc = doctor.ApplySecurityDefault
+
+// applySecurity runs early, next to the machine name, so its one sudo
+// prompt comes before the long unattended package installs.
+func applySecurity(plan InstallPlan, r Reporter) error {
+	n, err := applySecurityFunc(plan.Security, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("security: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Security controls already enabled")
+		} else {
+			r.Success(fmt.Sprintf("Security controls enabled (%d changed)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
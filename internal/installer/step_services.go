@@ -0,0 +1,30 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/openbootdotdev/openboot/internal/brew"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// startServicesFunc is a var so tests can observe the services step without
+// asking brew to start anything.
+var startServicesFunc = brew.StartServices
+
+// applyServices runs after packages, so each service's formula is installed
+// before it is started. Services already running are left alone.
+func applyServices(plan InstallPlan, r Reporter) error {
+	n, err := startServicesFunc(plan.Services, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("services: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("Services already running")
+		} else {
+			r.Success(fmt.Sprintf("Started %d service(s)", n))
+		}
+	}
+	ui.Println()
+	return nil
+}
@@ -3,6 +3,7 @@ package installer
 import (
 	"fmt"
 
+	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/dotfiles"
 	"github.com/openbootdotdev/openboot/internal/shell"
 	"github.com/openbootdotdev/openboot/internal/ui"
@@ -12,6 +13,17 @@ import (
 // (which downloads and runs the upstream script).
 var installOhMyZshFunc = shell.InstallOhMyZsh
 
+// restoreShellFunc is a var so tests can observe non-Oh-My-Zsh restores
+// without cloning frameworks.
+var restoreShellFunc = shell.Restore
+
+// applySnippetsFunc and hasSnippetsFunc are vars so tests can observe
+// snippet writes without a real rc file.
+var (
+	applySnippetsFunc = shell.ApplySnippets
+	hasSnippetsFunc   = shell.HasSnippets
+)
+
 func applyShell(plan InstallPlan, r Reporter) error {
 	if plan.InstallOhMyZsh {
 		if plan.ShellTheme != "" || len(plan.ShellPlugins) > 0 {
@@ -38,6 +50,37 @@ func applyShell(plan InstallPlan, r Reporter) error {
 		ui.Println()
 	}
 
+	// Other frameworks and the starship prompt go through the framework
+	// registry. Oh-My-Zsh was handled above, so it is not repeated here.
+	if plan.ShellFramework != "" || plan.Starship {
+		starshipConfig, err := approveStarshipConfig(plan, r)
+		if err != nil {
+			return err
+		}
+		spec := &config.RemoteShellConfig{
+			Shell:          plan.ShellName,
+			Framework:      plan.ShellFramework,
+			Starship:       plan.Starship,
+			StarshipConfig: starshipConfig,
+		}
+		if plan.ShellFramework != "" {
+			spec.Theme = plan.ShellTheme
+			spec.Plugins = plan.ShellPlugins
+		}
+		if err := restoreShellFunc(spec, plan.DryRun); err != nil {
+			return fmt.Errorf("restore shell config: %w", err)
+		}
+		if !plan.DryRun {
+			if plan.ShellFramework != "" {
+				r.Success(fmt.Sprintf("Shell restored (%s, %d plugins)", plan.ShellFramework, len(plan.ShellPlugins)))
+			}
+			if plan.Starship {
+				r.Success("Starship prompt configured")
+			}
+		}
+		ui.Println()
+	}
+
 	// Ensure brew shellenv in .zshrc only when user has no dotfiles managing it.
 	if plan.DotfilesURL == "" || plan.DotfilesURL == dotfiles.DefaultDotfilesURL {
 		if err := shell.EnsureBrewShellenv(plan.DryRun); err != nil {
@@ -47,6 +90,58 @@ func applyShell(plan InstallPlan, r Reporter) error {
 	return nil
 }
 
+// approveStarshipConfig returns the starship.toml to write: the plan's,
+// once approved, or "" to leave the file alone. Custom modules in it run
+// their command and when scripts at every prompt, so it takes the same
+// opt-in as post_install.
+func approveStarshipConfig(plan InstallPlan, r Reporter) (string, error) {
+	if !plan.Starship || plan.StarshipConfig == "" {
+		return "", nil
+	}
+	run, err := approveCode(plan, r, codeGate{
+		What:    "starship config",
+		Header:  "Starship config (custom modules run commands at every prompt):",
+		Prompt:  "Write this starship config?",
+		Preview: plan.StarshipConfig,
+	})
+	if err != nil || !run {
+		return "", err
+	}
+	return plan.StarshipConfig, nil
+}
+
+// applyShellSnippets writes the snippets block, or removes it when the
+// config has none. It runs after dotfiles so the block lands in the rc file
+// the dotfiles leave in place. Env vars and aliases run in every new shell,
+// so writing them takes the same opt-in as post_install; removing the
+// block does not.
+func applyShellSnippets(plan InstallPlan, r Reporter) error {
+	sh := (&config.RemoteShellConfig{Shell: plan.ShellName, Framework: plan.ShellFramework}).EffectiveShell()
+	if !plan.ShellSnippets.Empty() {
+		run, err := approveCode(plan, r, codeGate{
+			What:    "shell snippets",
+			Header:  fmt.Sprintf("Shell snippets for %s (%s):", config.ShellRCFile(sh), plan.ShellSnippets.Summary()),
+			Prompt:  "Write these shell snippets?",
+			Preview: plan.ShellSnippets.Render(sh),
+		})
+		if err != nil || !run {
+			return err
+		}
+	}
+	if err := applySnippetsFunc(sh, plan.ShellSnippets, plan.DryRun); err != nil {
+		return fmt.Errorf("apply shell snippets: %w", err)
+	}
+	switch {
+	case plan.DryRun:
+	case plan.ShellSnippets.Empty():
+		r.Success("Shell snippets removed (the config has none)")
+	default:
+		r.Success(fmt.Sprintf("Shell snippets written (%s)", plan.ShellSnippets.Summary()))
+	}
+	ui.Println()
+	return nil
+}
+
 func applyDotfiles(plan InstallPlan, r Reporter) error {
 	if plan.DotfilesURL == "" {
 		return nil // explicitly skipped via --dotfiles skip
@@ -0,0 +1,51 @@
+package installer
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/openbootdotdev/openboot/internal/sshconfig"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// applySSHFunc is a var so tests can observe the ssh step without touching
+// the real ~/.ssh or generating keys.
+var applySSHFunc = sshconfig.Apply
+
+func applySSH(plan InstallPlan, r Reporter) error {
+	missing := sshconfig.MissingKeys(plan.SSH)
+	n, err := applySSHFunc(plan.SSH, plan.DryRun)
+	if err != nil {
+		return fmt.Errorf("apply ssh config: %w", err)
+	}
+	if !plan.DryRun {
+		if n == 0 {
+			r.Muted("SSH config already up to date")
+		} else {
+			r.Success(fmt.Sprintf("SSH config applied (%d changed)", n))
+		}
+		noteGeneratedSSHKeys(plan, missing, r)
+	}
+	ui.Println()
+	return nil
+}
+
+// noteGeneratedSSHKeys queues the public keys of the keys in missing, which
+// Apply has just generated, for the completion summary.
+func noteGeneratedSSHKeys(plan InstallPlan, missing []string, r Reporter) {
+	for _, k := range plan.SSH.Keys {
+		if !slices.Contains(missing, k.Name) {
+			continue
+		}
+		pub, err := sshconfig.PublicKey(k)
+		if err != nil {
+			r.Warn(fmt.Sprintf("Generated SSH key %s, but could not read it back: %v", k.Name, err))
+			continue
+		}
+		note := keyNote{What: "SSH key ~/" + k.KeyPath(), PublicKey: pub}
+		if !k.Passphrase {
+			note.AddPassphrase = "ssh-keygen -p -f ~/" + k.KeyPath()
+		}
+		plan.notes.addKey(note)
+	}
+}
@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -8,13 +9,14 @@ import (
 	"strings"
 
 	"github.com/openbootdotdev/openboot/internal/macos"
+	"github.com/openbootdotdev/openboot/internal/secrets"
 	"github.com/openbootdotdev/openboot/internal/system"
 	"github.com/openbootdotdev/openboot/internal/ui"
 )
 
 func applyMacOSPrefs(plan InstallPlan, r Reporter) error {
 	hasPrefs := len(plan.MacOSPrefs) > 0
-	hasDock := plan.DockApps != nil
+	hasDock := plan.DockApps != nil || plan.Dock != nil
 	hasLogin := plan.LoginItems != nil
 	if !hasPrefs && !hasDock && !hasLogin {
 		return nil
@@ -62,6 +64,16 @@ func applyMacOSDefaultsSubtask(plan InstallPlan, r Reporter) error {
 }
 
 func applyDockSubtask(plan InstallPlan, r Reporter) error {
+	if plan.Dock != nil {
+		if err := macos.SetDockLayout(plan.Dock, plan.DryRun); err != nil {
+			return err
+		}
+		if !plan.DryRun {
+			r.Success(fmt.Sprintf("Dock configured (%d apps, %d others)", len(plan.Dock.Apps), len(plan.Dock.Others)))
+		}
+		ui.Println()
+		return nil
+	}
 	if err := macos.SetDockApps(plan.DockApps, plan.DryRun); err != nil {
 		return err
 	}
@@ -83,36 +95,237 @@ func applyLoginItemsSubtask(plan InstallPlan, r Reporter) error {
 	return nil
 }
 
-func applyPostInstall(plan InstallPlan, r Reporter) error {
-	if len(plan.PostInstall) == 0 {
+// secretResolver resolves secret:// references in post-install scripts.
+// Tests register a fake provider on a fresh resolver.
+var secretResolver = secrets.NewResolver()
+
+// resolveScriptSecrets replaces each secret:// reference in script with an
+// environment variable expansion and returns the variables to pass to the
+// shell. Resolved values travel through the child's environment rather than
+// its argv, so they never appear in `ps` output, the script preview, or logs.
+func resolveScriptSecrets(ctx context.Context, script string) (string, []string, error) {
+	if err := checkSecretQuoting(script); err != nil {
+		return "", nil, err
+	}
+	var env []string
+	out, err := secretResolver.Replace(ctx, script, func(_ secrets.Ref, value string) string {
+		name := fmt.Sprintf("OPENBOOT_SECRET_%d", len(env)+1)
+		env = append(env, name+"="+value)
+		return "${" + name + "}"
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return out, env, nil
+}
+
+// checkSecretQuoting refuses a secret reference where the shell would not
+// expand the "${OPENBOOT_SECRET_n}" resolveScriptSecrets puts in its place:
+// inside single quotes, $'...', or the body of a heredoc whose delimiter
+// is quoted. The script would get that text instead of the secret.
+func checkSecretQuoting(script string) error {
+	refs := secrets.FindReferences(script)
+	if len(refs) == 0 {
 		return nil
 	}
+	literal := literalRegions(script)
+	for _, ref := range refs {
+		for i := strings.Index(script, ref.String()); i >= 0; {
+			if where := literal[i]; where != "" {
+				return fmt.Errorf("%s is inside %s, where the shell does not expand it; move it outside the quotes", ref, where)
+			}
+			next := strings.Index(script[i+1:], ref.String())
+			if next < 0 {
+				break
+			}
+			i += 1 + next
+		}
+	}
+	return nil
+}
+
+// literalRegions returns, for each byte of a zsh script, "single quotes" or
+// "a quoted heredoc" when the shell takes it literally, and "" elsewhere.
+// It follows quoting, comments and heredocs; it does not parse command
+// substitutions, which is enough to tell where a reference will land.
+func literalRegions(script string) []string {
+	where := make([]string, len(script))
+	mark := func(from, to int, what string) {
+		for k := from; k < to && k < len(script); k++ {
+			where[k] = what
+		}
+	}
+	type heredoc struct {
+		delim          string
+		quoted, noTabs bool
+	}
+	var pending []heredoc
 
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == '\\':
+			i += 2
+		case c == '\'':
+			ansi := i > 0 && script[i-1] == '$'
+			j := i + 1
+			for j < len(script) && script[j] != '\'' {
+				if ansi && script[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			mark(i, j+1, "single quotes")
+			i = j + 1
+		case c == '"':
+			j := i + 1
+			for j < len(script) && script[j] != '"' {
+				if script[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = j + 1
+		case c == '#' && (i == 0 || strings.ContainsRune(" \t\n;", rune(script[i-1]))):
+			if j := strings.IndexByte(script[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(script)
+			}
+		case strings.HasPrefix(script[i:], "<<<"):
+			i += 3
+		case strings.HasPrefix(script[i:], "<<"):
+			h := heredoc{}
+			j := i + 2
+			if j < len(script) && script[j] == '-' {
+				h.noTabs = true
+				j++
+			}
+			for j < len(script) && (script[j] == ' ' || script[j] == '\t') {
+				j++
+			}
+			var delim strings.Builder
+			for j < len(script) && !strings.ContainsRune(" \t\n;|&<>()", rune(script[j])) {
+				switch script[j] {
+				case '\'', '"':
+					h.quoted = true
+					end := strings.IndexByte(script[j+1:], script[j])
+					if end < 0 {
+						end = len(script) - j - 1
+					}
+					delim.WriteString(script[j+1 : j+1+end])
+					j += end + 2
+				case '\\':
+					h.quoted = true
+					if j+1 < len(script) {
+						delim.WriteByte(script[j+1])
+					}
+					j += 2
+				default:
+					delim.WriteByte(script[j])
+					j++
+				}
+			}
+			h.delim = delim.String()
+			pending = append(pending, h)
+			i = j
+		case c == '\n':
+			i++
+			for _, h := range pending {
+				for i < len(script) {
+					end := strings.IndexByte(script[i:], '\n')
+					if end < 0 {
+						end = len(script) - i
+					}
+					line := script[i : i+end]
+					if h.noTabs {
+						line = strings.TrimLeft(line, "\t")
+					}
+					if line == h.delim {
+						i += end + 1
+						break
+					}
+					if h.quoted {
+						mark(i, i+end, "a quoted heredoc")
+					}
+					i += end + 1
+				}
+			}
+			pending = nil
+		default:
+			i++
+		}
+	}
+	return where
+}
+
+// codeGate describes a section that makes this Mac run commands a config
+// chose, for approveCode.
+type codeGate struct {
+	What    string // e.g. "post-install script", used in skip messages
+	Header  string
+	Prompt  string
+	Preview string
+}
+
+// approveCode is the gate for every section that runs a config's commands:
+// post_install, launch agents that launchd starts at login, git settings
+// git runs as commands, a starship config's custom modules and shell
+// snippets. In silent mode the section is skipped unless
+// --allow-post-install was passed; otherwise its commands are previewed
+// and, interactively, confirmed. A dry run shows the preview and approves.
+func approveCode(plan InstallPlan, r Reporter, g codeGate) (bool, error) {
 	if !plan.DryRun && (plan.Silent || !system.HasTTY()) && !plan.AllowPostInstall {
-		r.Warn("Skipping post-install script in silent mode (use --allow-post-install to enable)")
+		r.Warn(fmt.Sprintf("Skipping %s in silent mode (use --allow-post-install to enable)", g.What))
 		ui.Println()
-		return nil
+		return false, nil
 	}
 
-	script := strings.Join(plan.PostInstall, "\n")
-	r.Info(fmt.Sprintf("Post-install script (%d lines):", len(plan.PostInstall)))
+	r.Info(g.Header)
 	ui.Println()
-	ui.PrintScriptPreview(script)
+	ui.PrintScriptPreview(g.Preview)
 	ui.Println()
 
 	if !plan.DryRun && !plan.Silent && system.HasTTY() {
-		run, err := ui.Confirm("Run post-install script?", true)
+		run, err := ui.Confirm(g.Prompt, true)
 		if err != nil {
-			return fmt.Errorf("confirm post-install: %w", err)
+			return false, fmt.Errorf("confirm %s: %w", g.What, err)
 		}
 		if !run {
-			r.Muted("Skipping post-install script")
+			r.Muted("Skipping " + g.What)
 			ui.Println()
-			return nil
+			return false, nil
 		}
 	}
+	return true, nil
+}
+
+func applyPostInstall(plan InstallPlan, r Reporter) error {
+	if len(plan.PostInstall) == 0 {
+		return nil
+	}
+
+	script := strings.Join(plan.PostInstall, "\n")
+	run, err := approveCode(plan, r, codeGate{
+		What:    "post-install script",
+		Header:  fmt.Sprintf("Post-install script (%d lines):", len(plan.PostInstall)),
+		Prompt:  "Run post-install script?",
+		Preview: script,
+	})
+	if err != nil || !run {
+		return err
+	}
 
 	if plan.DryRun {
+		if err := secretResolver.Check(script); err != nil {
+			return fmt.Errorf("post-install: %w", err)
+		}
+		if err := checkSecretQuoting(script); err != nil {
+			return fmt.Errorf("post-install: %w", err)
+		}
+		if n := len(secrets.FindReferences(script)); n > 0 {
+			ui.DryRunMsg("Would resolve %d secret reference(s)", n)
+		}
 		ui.DryRunMsg("Would run the script above")
 		ui.Println()
 		return nil
@@ -123,10 +336,18 @@ func applyPostInstall(plan InstallPlan, r Reporter) error {
 		return fmt.Errorf("home dir: %w", err)
 	}
 
+	script, secretEnv, err := resolveScriptSecrets(context.Background(), script)
+	if err != nil {
+		return fmt.Errorf("post-install: %w", err)
+	}
+
 	c := exec.Command("/bin/zsh", "-c", script) //nolint:gosec // post-install scripts require explicit user opt-in (--allow-post-install flag)
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
 	c.Dir = home
+	if len(secretEnv) > 0 {
+		c.Env = append(os.Environ(), secretEnv...)
+	}
 	if err := c.Run(); err != nil {
 		ui.Println()
 		return fmt.Errorf("post-install: %w", err)
@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/openbootdotdev/openboot/internal/dotfiles"
 	"github.com/openbootdotdev/openboot/internal/macos"
+	"github.com/openbootdotdev/openboot/internal/secrets"
 )
 
 func requireZsh(t *testing.T) {
@@ -390,3 +392,105 @@ func TestApplyMacOSPrefs_DryRunAllThreeSubtasks(t *testing.T) {
 	err := applyMacOSPrefs(plan, NopReporter{})
 	assert.NoError(t, err)
 }
+
+func withFakeSecrets(t *testing.T, values map[string]string) *secrets.FakeProvider {
+	t.Helper()
+	orig := secretResolver
+	secretResolver = secrets.NewResolver()
+	fake := secrets.NewFakeProvider(values)
+	secretResolver.Register("fake", fake)
+	t.Cleanup(func() { secretResolver = orig })
+	return fake
+}
+
+// TestResolveScriptSecrets_PassesValuesViaEnv verifies that references are
+// swapped for variable expansions so the value never lands in the script text.
+func TestResolveScriptSecrets_PassesValuesViaEnv(t *testing.T) {
+	withFakeSecrets(t, map[string]string{"npm": "npm_tok_123"})
+
+	script, env, err := resolveScriptSecrets(context.Background(),
+		`npm config set //registry.npmjs.org/:_authToken "secret://fake/npm"`)
+	require.NoError(t, err)
+	assert.Equal(t, `npm config set //registry.npmjs.org/:_authToken "${OPENBOOT_SECRET_1}"`, script)
+	assert.Equal(t, []string{"OPENBOOT_SECRET_1=npm_tok_123"}, env)
+}
+
+// TestResolveScriptSecrets_RejectsSingleQuotes verifies that a reference the
+// shell would take literally is refused instead of passing "${...}" through.
+func TestResolveScriptSecrets_RejectsSingleQuotes(t *testing.T) {
+	fake := withFakeSecrets(t, map[string]string{"npm": "npm_tok_123"})
+
+	for _, script := range []string{
+		`echo 'token=secret://fake/npm'`,
+		`printf $'tok\'en secret://fake/npm\n'`,
+	} {
+		_, _, err := resolveScriptSecrets(context.Background(), script)
+		assert.ErrorContains(t, err, "secret://fake/npm is inside single quotes", script)
+	}
+	assert.Empty(t, fake.Lookups)
+}
+
+// TestResolveScriptSecrets_RejectsQuotedHeredoc verifies that a reference in
+// the body of a <<'EOF' heredoc is refused.
+func TestResolveScriptSecrets_RejectsQuotedHeredoc(t *testing.T) {
+	withFakeSecrets(t, map[string]string{"npm": "npm_tok_123"})
+
+	for _, script := range []string{
+		"cat > ~/.npmrc <<'EOF'\n_authToken=secret://fake/npm\nEOF",
+		"cat > ~/.npmrc <<-\"EOF\"\n\t_authToken=secret://fake/npm\n\tEOF",
+	} {
+		_, _, err := resolveScriptSecrets(context.Background(), script)
+		assert.ErrorContains(t, err, "secret://fake/npm is inside a quoted heredoc", script)
+	}
+}
+
+// TestResolveScriptSecrets_AllowsExpandingContexts verifies that double
+// quotes, unquoted heredocs and quotes elsewhere on the line still resolve.
+func TestResolveScriptSecrets_AllowsExpandingContexts(t *testing.T) {
+	withFakeSecrets(t, map[string]string{"npm": "npm_tok_123"})
+
+	for _, script := range []string{
+		`echo "it's secret://fake/npm"`,
+		`echo 'a' secret://fake/npm 'b' # 'secret://fake/npm'`,
+		"cat > ~/.npmrc <<EOF\n_authToken='secret://fake/npm'\nEOF\necho 'done'",
+		"cat <<'EOF'\nliteral\nEOF\necho secret://fake/npm",
+	} {
+		_, env, err := resolveScriptSecrets(context.Background(), script)
+		require.NoError(t, err, script)
+		assert.NotEmpty(t, env, script)
+	}
+}
+
+// TestApplyPostInstall_DryRun_DoesNotResolveSecrets verifies that dry-run
+// validates provider names without fetching any secret.
+func TestApplyPostInstall_DryRun_DoesNotResolveSecrets(t *testing.T) {
+	fake := withFakeSecrets(t, map[string]string{"npm": "npm_tok_123"})
+
+	plan := InstallPlan{DryRun: true, PostInstall: []string{"echo secret://fake/npm"}}
+	require.NoError(t, applyPostInstall(plan, NopReporter{}))
+	assert.Empty(t, fake.Lookups)
+
+	plan.PostInstall = []string{"echo secret://nosuch/npm"}
+	assert.ErrorContains(t, applyPostInstall(plan, NopReporter{}), "unknown secret provider")
+}
+
+// TestApplyPostInstall_ResolvesSecrets runs a script that writes a resolved
+// secret to a file, proving the value reaches the child shell.
+func TestApplyPostInstall_ResolvesSecrets(t *testing.T) {
+	requireZsh(t)
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	withFakeSecrets(t, map[string]string{"npm": "npm_tok_123"})
+
+	out := tmpDir + "/token"
+	plan := InstallPlan{
+		Silent:           true,
+		AllowPostInstall: true,
+		PostInstall:      []string{`printf %s secret://fake/npm > ` + out},
+	}
+	require.NoError(t, applyPostInstall(plan, NopReporter{}))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "npm_tok_123", string(data))
+}
@@ -1,12 +1,18 @@
 package installer
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/openbootdotdev/openboot/internal/appsettings"
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/gitconfig"
 	"github.com/openbootdotdev/openboot/internal/macos"
 )
 
@@ -81,3 +87,334 @@ func TestPlannedStepsOrder(t *testing.T) {
 			DotfilesURL: "u", MacOSPrefs: make([]macos.Preference, 1), PostInstall: []string{"x"},
 		}))
 }
+
+// Snippets get their own section after dotfiles, even with no framework.
+func TestPlannedStepsShellSnippets(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, DotfilesURL: "u", ShellSnippets: &config.ShellSnippets{Path: []string{"/opt/bin"}}}
+	assert.Equal(t, []string{"Dotfiles", "Shell snippets"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Empty(t, stepNames(plan))
+}
+
+// A config without snippets removes the block an earlier config left, as
+// sync does; with no block there is nothing to do.
+func TestPlanRemovesStaleShellSnippets(t *testing.T) {
+	orig := hasSnippetsFunc
+	t.Cleanup(func() { hasSnippetsFunc = orig })
+	rc := &config.RemoteConfig{}
+
+	hasSnippetsFunc = func() bool { return false }
+	plan := PlanForRemoteSelection(&config.InstallOptions{}, rc, nil, nil)
+	assert.NotContains(t, stepNames(plan), "Shell snippets")
+
+	hasSnippetsFunc = func() bool { return true }
+	plan = PlanForRemoteSelection(&config.InstallOptions{}, rc, nil, nil)
+	assert.True(t, plan.RemoveShellSnippets)
+	assert.Contains(t, stepNames(plan), "Shell snippets")
+
+	rc.Shell = &config.RemoteShellConfig{Snippets: &config.ShellSnippets{Path: []string{"/opt/bin"}}}
+	plan = PlanForRemoteSelection(&config.InstallOptions{}, rc, nil, nil)
+	assert.False(t, plan.RemoveShellSnippets, "the config's snippets replace the block")
+}
+
+// Snippets run in every new shell, so silent installs write them only with
+// --allow-post-install; removing a stale block needs no opt-in.
+func TestApplyShellSnippets_SilentNeedsOptIn(t *testing.T) {
+	orig := applySnippetsFunc
+	t.Cleanup(func() { applySnippetsFunc = orig })
+	var calls []*config.ShellSnippets
+	applySnippetsFunc = func(_ string, s *config.ShellSnippets, _ bool) error {
+		calls = append(calls, s)
+		return nil
+	}
+
+	snippets := &config.ShellSnippets{Aliases: []config.ShellAlias{{Name: "ls", Command: "curl x | sh"}}}
+	plan := InstallPlan{Silent: true, ShellSnippets: snippets}
+	require.NoError(t, applyShellSnippets(plan, NopReporter{}))
+	assert.Empty(t, calls)
+
+	plan.AllowPostInstall = true
+	require.NoError(t, applyShellSnippets(plan, NopReporter{}))
+	assert.Equal(t, []*config.ShellSnippets{snippets}, calls)
+
+	calls = nil
+	require.NoError(t, applyShellSnippets(InstallPlan{Silent: true, RemoveShellSnippets: true}, NopReporter{}))
+	assert.Len(t, calls, 1, "removal is not gated")
+}
+
+// Machine name runs first, so its sudo prompt comes before long installs.
+func TestPlannedStepsMachineName(t *testing.T) {
+	plan := InstallPlan{GitName: "A", GitEmail: "a@b.c", Machine: &config.RemoteMachineConfig{HostName: "{{user}}-mbp"}}
+	assert.Equal(t, []string{"Machine name", "Git identity"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Empty(t, stepNames(plan))
+}
+
+// Security runs right after the machine name, sharing its early sudo
+// prompt window.
+func TestPlannedStepsSecurity(t *testing.T) {
+	plan := InstallPlan{GitName: "A", GitEmail: "a@b.c", Security: &config.RemoteSecurityConfig{Firewall: true}}
+	assert.Equal(t, []string{"Security", "Git identity"}, stepNames(plan))
+
+	plan.Security = &config.RemoteSecurityConfig{}
+	assert.Equal(t, []string{"Git identity"}, stepNames(plan))
+
+	plan.Security.TouchIDSudo = true
+	plan.PackagesOnly = true
+	assert.Empty(t, stepNames(plan))
+}
+
+// Default apps run after packages, so the apps exist.
+func TestPlannedStepsDefaultApps(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, Casks: []string{"visual-studio-code"},
+		DefaultApps: []config.DefaultApp{{Extension: "md", BundleID: "com.microsoft.VSCode"}}}
+	assert.Equal(t, []string{"Packages", "Default apps"}, stepNames(plan))
+}
+
+// Keyboard shortcuts run after packages, so the apps they name exist.
+func TestPlannedStepsKeyboard(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, Casks: []string{"iterm2"},
+		Keyboard: &config.RemoteKeyboardConfig{KeyMappings: []config.KeyMapping{{From: "caps_lock", To: "escape"}}}}
+	assert.Equal(t, []string{"Packages", "Keyboard"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Equal(t, []string{"Packages"}, stepNames(plan))
+}
+
+func TestPlannedStepsDockLayout(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, Dock: &config.DockLayout{Apps: []config.DockTile{{Type: config.DockTileSpacer}}}}
+	assert.Equal(t, []string{"macOS preferences"}, stepNames(plan))
+}
+
+// Launch agents load after macOS preferences, once their programs exist.
+func TestPlannedStepsLaunchAgents(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, Casks: []string{"colima"},
+		LaunchAgents: []config.LaunchAgent{{Label: "com.example.colima", ProgramArguments: []string{"/opt/homebrew/bin/colima", "start"}}}}
+	assert.Equal(t, []string{"Packages", "Launch agents"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Equal(t, []string{"Packages"}, stepNames(plan))
+}
+
+// Launch agents run code at every login, so silent installs skip them
+// unless --allow-post-install was passed. Tests have no TTY.
+func TestApplyLaunchAgents_SilentNeedsOptIn(t *testing.T) {
+	orig := applyLaunchAgentsFunc
+	t.Cleanup(func() { applyLaunchAgentsFunc = orig })
+	var called bool
+	applyLaunchAgentsFunc = func([]config.LaunchAgent, bool) (int, error) {
+		called = true
+		return 1, nil
+	}
+
+	plan := InstallPlan{Silent: true,
+		LaunchAgents: []config.LaunchAgent{{Label: "com.example.colima", ProgramArguments: []string{"/opt/homebrew/bin/colima", "start"}, RunAtLoad: true}}}
+	require.NoError(t, applyLaunchAgents(plan, NopReporter{}))
+	assert.False(t, called)
+
+	plan.AllowPostInstall = true
+	require.NoError(t, applyLaunchAgents(plan, NopReporter{}))
+	assert.True(t, called)
+}
+
+func TestLaunchAgentsPreview(t *testing.T) {
+	preview := launchAgentsPreview([]config.LaunchAgent{
+		{Label: "com.example.colima", ProgramArguments: []string{"/opt/homebrew/bin/colima", "start"}, RunAtLoad: true},
+		{Label: "com.example.backup", ProgramArguments: []string{"/usr/local/bin/backup"}, StartInterval: 3600},
+	})
+	assert.Equal(t, "# com.example.colima (at login)\n/opt/homebrew/bin/colima start\n"+
+		"# com.example.backup (every 3600s)\n/usr/local/bin/backup", preview)
+}
+
+// A starship config's custom modules run commands at every prompt, so
+// silent installs drop it unless --allow-post-install was passed.
+func TestApplyShell_StarshipConfigNeedsOptIn(t *testing.T) {
+	orig := restoreShellFunc
+	t.Cleanup(func() { restoreShellFunc = orig })
+	var got *config.RemoteShellConfig
+	restoreShellFunc = func(spec *config.RemoteShellConfig, dryRun bool) error {
+		got = spec
+		return nil
+	}
+
+	plan := InstallPlan{Silent: true, Starship: true, DotfilesURL: "u",
+		StarshipConfig: "[custom.x]\ncommand = \"id\"\n"}
+	require.NoError(t, applyShell(plan, NopReporter{}))
+	require.NotNil(t, got)
+	assert.True(t, got.Starship)
+	assert.Empty(t, got.StarshipConfig)
+
+	plan.AllowPostInstall = true
+	require.NoError(t, applyShell(plan, NopReporter{}))
+	assert.Equal(t, plan.StarshipConfig, got.StarshipConfig)
+}
+
+// Git settings that run commands need the post-install opt-in in silent
+// mode; the rest are applied either way.
+func TestApplyGitSettings_CommandSettingsNeedOptIn(t *testing.T) {
+	orig := applyGitConfigFunc
+	t.Cleanup(func() { applyGitConfigFunc = orig })
+	var got *config.RemoteGitConfig
+	applyGitConfigFunc = func(g *config.RemoteGitConfig, dryRun bool) (int, error) {
+		got = g
+		return len(g.Settings), nil
+	}
+
+	plan := InstallPlan{Silent: true, GitConfig: &config.RemoteGitConfig{Settings: map[string]string{
+		"pull.rebase": "true", "core.editor": "vim", "alias.x": "!sh -c 'id'",
+	}}}
+	require.NoError(t, applyGitSettings(plan, NopReporter{}))
+	assert.Equal(t, map[string]string{"pull.rebase": "true"}, got.Settings)
+
+	plan.AllowPostInstall = true
+	require.NoError(t, applyGitSettings(plan, NopReporter{}))
+	assert.Len(t, got.Settings, 3)
+
+	got = nil
+	plan = InstallPlan{Silent: true, GitConfig: &config.RemoteGitConfig{Settings: map[string]string{"core.pager": "delta"}}}
+	require.NoError(t, applyGitSettings(plan, NopReporter{}))
+	assert.Nil(t, got, "nothing left to apply")
+}
+
+// Generated keys are listed again in the completion summary, with the
+// command to add a passphrase when they were generated without one.
+func TestApplyNotesGeneratedKeys(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	origSSH, origSigning := applySSHFunc, setupSigningFunc
+	t.Cleanup(func() { applySSHFunc, setupSigningFunc = origSSH, origSigning })
+	applySSHFunc = func(cfg *config.RemoteSSHConfig, dryRun bool) (int, error) {
+		require.NoError(t, os.MkdirAll(filepath.Join(home, ".ssh"), 0700))
+		for _, k := range cfg.Keys {
+			require.NoError(t, os.WriteFile(filepath.Join(home, k.KeyPath()), []byte("PRIVATE"), 0600))
+			require.NoError(t, os.WriteFile(filepath.Join(home, k.KeyPath()+".pub"), []byte("ssh-ed25519 AAAA"+k.Name+"\n"), 0600))
+		}
+		return len(cfg.Keys), nil
+	}
+	setupSigningFunc = func(*config.GitSigning, string, string, bool) (*gitconfig.SigningResult, error) {
+		return &gitconfig.SigningResult{PublicKey: "ssh-ed25519 AAAASIGN", Key: "/h/.ssh/id_sign.pub", Generated: true, Changed: 3}, nil
+	}
+
+	plan := InstallPlan{
+		GitEmail: "jane@acme.com", GitName: "Jane",
+		SSH:       &config.RemoteSSHConfig{Keys: []config.SSHKey{{Name: "id_work"}, {Name: "id_home", Passphrase: true}}},
+		GitConfig: &config.RemoteGitConfig{Signing: &config.GitSigning{Format: config.GitSigningSSH}},
+		notes:     &applyNotes{},
+	}
+	require.NoError(t, applySSH(plan, NopReporter{}))
+	require.NoError(t, applyGitSigning(plan, NopReporter{}))
+
+	assert.Equal(t, []keyNote{
+		{What: "SSH key ~/.ssh/id_work", PublicKey: "ssh-ed25519 AAAAid_work", AddPassphrase: "ssh-keygen -p -f ~/.ssh/id_work"},
+		{What: "SSH key ~/.ssh/id_home", PublicKey: "ssh-ed25519 AAAAid_home"},
+		{What: "ssh signing key (add it as a signing key)", PublicKey: "ssh-ed25519 AAAASIGN", AddPassphrase: "ssh-keygen -p -f /h/.ssh/id_sign"},
+	}, plan.notes.keys)
+
+	plan.notes = &applyNotes{}
+	require.NoError(t, applySSH(plan, NopReporter{}))
+	assert.Len(t, plan.notes.keys, 0, "keys that already existed are not listed")
+}
+
+// Fonts install right after packages, and also in packages-only runs.
+func TestPlannedStepsFonts(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, Casks: []string{"iterm2"}, Npm: []string{"typescript"},
+		Fonts: []config.Font{{Cask: "font-inter"}}}
+	assert.Equal(t, []string{"Packages", "Fonts", "npm globals"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Equal(t, []string{"Packages", "Fonts", "npm globals"}, stepNames(plan))
+}
+
+// SSH runs after the git steps, even when the identity step is skipped.
+func TestPlannedStepsSSH(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, SSH: &config.RemoteSSHConfig{Keys: []config.SSHKey{{Name: "id_ed25519"}}}}
+	assert.Equal(t, []string{"SSH"}, stepNames(plan))
+
+	plan.SSH = &config.RemoteSSHConfig{}
+	assert.Empty(t, stepNames(plan))
+}
+
+// Git settings follow the identity step but run even when it is skipped.
+func TestPlannedStepsGitSettings(t *testing.T) {
+	plan := InstallPlan{GitName: "A", GitEmail: "a@b.c", GitConfig: &config.RemoteGitConfig{Gitignore: ".DS_Store\n"}}
+	assert.Equal(t, []string{"Git identity", "Git settings"}, stepNames(plan))
+
+	plan.SkipGit = true
+	assert.Equal(t, []string{"Git settings"}, stepNames(plan))
+}
+
+// Commit signing runs right after the identity it signs as.
+func TestPlannedStepsCommitSigning(t *testing.T) {
+	plan := InstallPlan{GitName: "A", GitEmail: "a@b.c", GitConfig: &config.RemoteGitConfig{
+		Settings: map[string]string{"pull.rebase": "true"},
+		Signing:  &config.GitSigning{Format: config.GitSigningSSH},
+	}}
+	assert.Equal(t, []string{"Git identity", "Commit signing", "Git settings"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Empty(t, stepNames(plan))
+}
+
+// App settings are restored right after packages, so the apps' casks are
+// in place, and never in packages-only runs.
+func TestPlannedStepsAppSettings(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, Casks: []string{"rectangle"}, Npm: []string{"typescript"},
+		AppSettings: &config.AppSettings{Apps: []config.AppSettingsApp{{App: "rectangle"}}}}
+	assert.Equal(t, []string{"Packages", "npm globals", "App settings"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Equal(t, []string{"Packages", "npm globals"}, stepNames(plan))
+}
+
+// In a dry run the casks about to be installed count as installed, so the
+// preview shows their settings being restored.
+func TestApplyAppSettings_DryRunCountsPlannedCasks(t *testing.T) {
+	origApply, origInstalled := applyAppSettingsFunc, installedCasksFunc
+	t.Cleanup(func() { applyAppSettingsFunc, installedCasksFunc = origApply, origInstalled })
+	installedCasksFunc = func() (map[string]bool, error) { return map[string]bool{"iterm2": true}, nil }
+	var got map[string]bool
+	applyAppSettingsFunc = func(_ *config.AppSettings, installed map[string]bool, _ bool) (appsettings.Result, error) {
+		got = installed
+		return appsettings.Result{}, nil
+	}
+
+	plan := InstallPlan{DryRun: true, Casks: []string{"rectangle"}}
+	require.NoError(t, applyAppSettings(plan, NopReporter{}))
+	assert.Equal(t, map[string]bool{"iterm2": true, "rectangle": true}, got)
+
+	plan.DryRun = false
+	require.NoError(t, applyAppSettings(plan, NopReporter{}))
+	assert.Equal(t, map[string]bool{"iterm2": true}, got)
+}
+
+// Services start after packages and app settings, once their formulae are
+// installed, and never in packages-only runs.
+func TestPlannedStepsServices(t *testing.T) {
+	plan := InstallPlan{SkipGit: true, Formulae: []string{"redis"}, Services: []string{"redis"}}
+	assert.Equal(t, []string{"Packages", "Services"}, stepNames(plan))
+
+	plan.PackagesOnly = true
+	assert.Equal(t, []string{"Packages"}, stepNames(plan))
+}
+
+func TestApplyServices_PassesPlan(t *testing.T) {
+	orig := startServicesFunc
+	t.Cleanup(func() { startServicesFunc = orig })
+	var gotNames []string
+	var gotDryRun bool
+	startServicesFunc = func(names []string, dryRun bool) (int, error) {
+		gotNames, gotDryRun = names, dryRun
+		return len(names), nil
+	}
+
+	plan := InstallPlan{DryRun: true, Services: []string{"postgresql@16", "colima"}}
+	require.NoError(t, applyServices(plan, NopReporter{}))
+	assert.Equal(t, []string{"postgresql@16", "colima"}, gotNames)
+	assert.True(t, gotDryRun)
+
+	startServicesFunc = func([]string, bool) (int, error) { return 0, errors.New("1 service(s) failed to start") }
+	err := applyServices(InstallPlan{Services: []string{"redis"}}, NopReporter{})
+	assert.ErrorContains(t, err, "services: 1 service(s) failed to start")
+}
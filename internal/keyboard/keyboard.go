@@ -0,0 +1,247 @@
+// Package keyboard applies the keyboard section of a config: app menu
+// shortcuts (NSUserKeyEquivalents), system shortcut overrides
+// (com.apple.symbolichotkeys) and hidutil key remapping, which a
+// LaunchAgent re-applies at every login. Capture lives in internal/snapshot.
+package keyboard
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// AgentLabel names the LaunchAgent that re-applies the key mapping at login.
+const AgentLabel = "dev.openboot.keymapping"
+
+// agentFile is the LaunchAgent plist, relative to the home directory.
+const agentFile = "Library/LaunchAgents/" + AgentLabel + ".plist"
+
+// activateSettingsPath reloads symbolic hotkeys without logging out.
+const activateSettingsPath = "/System/Library/PrivateFrameworks/SystemAdministration.framework/Resources/activateSettings"
+
+// runDefaults, runHidutil, runLaunchctl, activateSettings and
+// currentKeyboard wrap the tools and the capture so tests can run without
+// changing the machine.
+var (
+	runDefaults = func(args ...string) error {
+		out, err := system.RunCommandSilent("defaults", args...)
+		if err != nil {
+			return fmt.Errorf("defaults %s: %s: %w", args[0], out, err)
+		}
+		return nil
+	}
+	runHidutil = func(args ...string) error {
+		out, err := system.RunCommandSilent("hidutil", args...)
+		if err != nil {
+			return fmt.Errorf("hidutil: %s: %w", out, err)
+		}
+		return nil
+	}
+	runLaunchctl = func(args ...string) error {
+		out, err := system.RunCommandSilent("launchctl", args...)
+		if err != nil {
+			return fmt.Errorf("launchctl %s: %s: %w", args[0], out, err)
+		}
+		return nil
+	}
+	activateSettings = func() {
+		// Best-effort: without it the hotkeys apply at next login.
+		_, _ = system.RunCommandSilent(activateSettingsPath, "-u")
+	}
+	currentKeyboard = snapshot.CaptureKeyboard
+)
+
+// Apply sets each shortcut, hotkey and key mapping cfg gives that differs
+// from the machine's. It returns the number of settings changed.
+func Apply(cfg *config.RemoteKeyboardConfig, dryRun bool) (int, error) {
+	if cfg.Empty() {
+		return 0, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return 0, fmt.Errorf("apply keyboard: %w", err)
+	}
+	current, err := currentKeyboard()
+	if err != nil || current == nil {
+		current = &config.RemoteKeyboardConfig{}
+	}
+
+	changed, err := applyAppShortcuts(cfg, current, dryRun)
+	if err != nil {
+		return changed, fmt.Errorf("apply keyboard: %w", err)
+	}
+	n, err := applySymbolicHotKeys(cfg.SymbolicHotKeys, current.SymbolicHotKeys, dryRun)
+	changed += n
+	if err != nil {
+		return changed, fmt.Errorf("apply keyboard: %w", err)
+	}
+	n, err = applyKeyMappings(cfg, current.KeyMappings, dryRun)
+	changed += n
+	if err != nil {
+		return changed, fmt.Errorf("apply keyboard: %w", err)
+	}
+	return changed, nil
+}
+
+func applyAppShortcuts(cfg, current *config.RemoteKeyboardConfig, dryRun bool) (int, error) {
+	changed := 0
+	for _, domain := range cfg.Domains() {
+		have := current.AppShortcuts[domain]
+		titles := make([]string, 0, len(cfg.AppShortcuts[domain]))
+		for t := range cfg.AppShortcuts[domain] {
+			titles = append(titles, t)
+		}
+		sort.Strings(titles)
+		for _, title := range titles {
+			equiv := cfg.AppShortcuts[domain][title]
+			if have[title] == equiv {
+				continue
+			}
+			changed++
+			if dryRun {
+				ui.DryRunMsg("Would set %s shortcut %q to %s", domain, title, equiv)
+				continue
+			}
+			if err := runDefaults("write", domain, "NSUserKeyEquivalents", "-dict-add", title, equiv); err != nil {
+				return changed - 1, fmt.Errorf("set %s shortcut %q: %w", domain, title, err)
+			}
+		}
+		if have == nil && domain != config.KeyboardGlobalDomain && !dryRun {
+			// Lists the app under App Shortcuts in Keyboard settings. Newer
+			// macOS needs Full Disk Access to write this domain; the
+			// shortcuts work without it.
+			_ = runDefaults("write", "com.apple.universalaccess", "com.apple.custommenu.apps", "-array-add", domain)
+		}
+	}
+	return changed, nil
+}
+
+func applySymbolicHotKeys(want, have []config.SymbolicHotKey, dryRun bool) (int, error) {
+	current := make(map[int]config.SymbolicHotKey, len(have))
+	for _, h := range have {
+		current[h.ID] = h
+	}
+	changed := 0
+	for _, h := range want {
+		cur, ok := current[h.ID]
+		if ok && h.Matches(cur) {
+			continue
+		}
+		if len(h.Parameters) == 0 {
+			// Only toggling: keep the key the machine has.
+			h.Parameters = cur.Parameters
+		}
+		changed++
+		if dryRun {
+			ui.DryRunMsg("Would set symbolic hotkey %d (enabled=%t)", h.ID, h.Enabled)
+			continue
+		}
+		if err := runDefaults("write", "com.apple.symbolichotkeys", "AppleSymbolicHotKeys",
+			"-dict-add", strconv.Itoa(h.ID), hotKeyPlist(h)); err != nil {
+			return changed - 1, fmt.Errorf("set symbolic hotkey %d: %w", h.ID, err)
+		}
+	}
+	if changed > 0 && !dryRun {
+		activateSettings()
+	}
+	return changed, nil
+}
+
+// hotKeyPlist renders h as the plist dictionary defaults -dict-add takes.
+func hotKeyPlist(h config.SymbolicHotKey) string {
+	var sb strings.Builder
+	sb.WriteString("<dict><key>enabled</key>")
+	if h.Enabled {
+		sb.WriteString("<true/>")
+	} else {
+		sb.WriteString("<false/>")
+	}
+	if len(h.Parameters) == 3 {
+		sb.WriteString("<key>value</key><dict><key>parameters</key><array>")
+		for _, p := range h.Parameters {
+			fmt.Fprintf(&sb, "<integer>%d</integer>", p)
+		}
+		sb.WriteString("</array><key>type</key><string>standard</string></dict>")
+	}
+	sb.WriteString("</dict>")
+	return sb.String()
+}
+
+// applyKeyMappings sets the hidutil mapping live when it differs and keeps
+// the LaunchAgent that restores it at login up to date and loaded.
+func applyKeyMappings(cfg *config.RemoteKeyboardConfig, have []config.KeyMapping, dryRun bool) (int, error) {
+	if len(cfg.KeyMappings) == 0 {
+		return 0, nil
+	}
+	changed := 0
+	if !config.KeyMappingsEqual(cfg.KeyMappings, have) {
+		changed++
+		if dryRun {
+			ui.DryRunMsg("Would run: hidutil property --set '%s'", cfg.UserKeyMapping())
+		} else if err := runHidutil("property", "--set", cfg.UserKeyMapping()); err != nil {
+			return 0, fmt.Errorf("set key mapping: %w", err)
+		}
+	}
+
+	home, err := system.HomeDir()
+	if err != nil {
+		return changed, err
+	}
+	path := filepath.Join(home, agentFile)
+	want := agentPlist(cfg.UserKeyMapping())
+	if cur, err := os.ReadFile(path); err == nil && bytes.Equal(cur, want) { //nolint:gosec // openboot's own LaunchAgent
+		return changed, nil
+	}
+	changed++
+	domain := fmt.Sprintf("gui/%d", os.Getuid())
+	if dryRun {
+		ui.DryRunMsg("Would write %s and run: launchctl bootstrap %s %s", path, domain, path)
+		return changed, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return changed - 1, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, want, 0644); err != nil { //nolint:gosec // LaunchAgents must be readable by launchd
+		return changed - 1, fmt.Errorf("write %s: %w", path, err)
+	}
+	// bootout fails when the agent is not loaded yet; that is fine.
+	_ = runLaunchctl("bootout", domain+"/"+AgentLabel)
+	if err := runLaunchctl("bootstrap", domain, path); err != nil {
+		return changed, fmt.Errorf("load %s: %w", AgentLabel, err)
+	}
+	return changed, nil
+}
+
+// agentPlist renders the LaunchAgent that runs hidutil with mapping at
+// login.
+func agentPlist(mapping string) []byte {
+	var arg bytes.Buffer
+	_ = xml.EscapeText(&arg, []byte(mapping))
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + AgentLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/usr/bin/hidutil</string>
+		<string>property</string>
+		<string>--set</string>
+		<string>` + arg.String() + `</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`)
+}
@@ -0,0 +1,148 @@
+package keyboard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+type fakeTools struct {
+	defaults  [][]string
+	hidutil   [][]string
+	launchctl [][]string
+	activated int
+}
+
+func stubTools(t *testing.T, current *config.RemoteKeyboardConfig) (*fakeTools, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	f := &fakeTools{}
+	origDefaults, origHidutil, origLaunchctl, origActivate, origCurrent := runDefaults, runHidutil, runLaunchctl, activateSettings, currentKeyboard
+	t.Cleanup(func() {
+		runDefaults, runHidutil, runLaunchctl, activateSettings, currentKeyboard = origDefaults, origHidutil, origLaunchctl, origActivate, origCurrent
+	})
+	runDefaults = func(args ...string) error {
+		f.defaults = append(f.defaults, args)
+		return nil
+	}
+	runHidutil = func(args ...string) error {
+		f.hidutil = append(f.hidutil, args)
+		return nil
+	}
+	runLaunchctl = func(args ...string) error {
+		f.launchctl = append(f.launchctl, args)
+		return nil
+	}
+	activateSettings = func() { f.activated++ }
+	currentKeyboard = func() (*config.RemoteKeyboardConfig, error) { return current, nil }
+	return f, home
+}
+
+var sampleKeyboard = &config.RemoteKeyboardConfig{
+	AppShortcuts: map[string]map[string]string{
+		"com.apple.Safari": {"Show All Tabs": "@~t", "Pin Tab": "@$p"},
+	},
+	SymbolicHotKeys: []config.SymbolicHotKey{
+		{ID: 64, Enabled: false},
+		{ID: 65, Enabled: true, Parameters: []int{65535, 49, 1572864}},
+	},
+	KeyMappings: []config.KeyMapping{{From: "caps_lock", To: "escape"}},
+}
+
+func TestApply_WritesWhatDiffers(t *testing.T) {
+	f, home := stubTools(t, &config.RemoteKeyboardConfig{
+		AppShortcuts:    map[string]map[string]string{"com.apple.Safari": {"Show All Tabs": "@~t"}},
+		SymbolicHotKeys: []config.SymbolicHotKey{{ID: 64, Enabled: true, Parameters: []int{32, 49, 1048576}}},
+	})
+
+	n, err := Apply(sampleKeyboard, false)
+	require.NoError(t, err)
+	// Pin Tab, hotkeys 64 and 65, the live mapping and the LaunchAgent.
+	assert.Equal(t, 5, n)
+
+	assert.Equal(t, [][]string{
+		{"write", "com.apple.Safari", "NSUserKeyEquivalents", "-dict-add", "Pin Tab", "@$p"},
+		{"write", "com.apple.symbolichotkeys", "AppleSymbolicHotKeys", "-dict-add", "64",
+			"<dict><key>enabled</key><false/><key>value</key><dict><key>parameters</key><array><integer>32</integer><integer>49</integer><integer>1048576</integer></array><key>type</key><string>standard</string></dict></dict>"},
+		{"write", "com.apple.symbolichotkeys", "AppleSymbolicHotKeys", "-dict-add", "65",
+			"<dict><key>enabled</key><true/><key>value</key><dict><key>parameters</key><array><integer>65535</integer><integer>49</integer><integer>1572864</integer></array><key>type</key><string>standard</string></dict></dict>"},
+	}, f.defaults, "toggling 64 keeps its current key; Safari is already listed")
+	assert.Equal(t, 1, f.activated)
+	assert.Equal(t, [][]string{{"property", "--set", sampleKeyboard.UserKeyMapping()}}, f.hidutil)
+
+	agent, err := os.ReadFile(filepath.Join(home, agentFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(agent), "<string>"+AgentLabel+"</string>")
+	assert.Contains(t, string(agent), "<string>/usr/bin/hidutil</string>")
+	assert.Contains(t, string(agent), "&#34;UserKeyMapping&#34;")
+	domain := fmt.Sprintf("gui/%d", os.Getuid())
+	assert.Equal(t, [][]string{
+		{"bootout", domain + "/" + AgentLabel},
+		{"bootstrap", domain, filepath.Join(home, agentFile)},
+	}, f.launchctl, "the agent is reloaded once written")
+}
+
+func TestApply_Idempotent(t *testing.T) {
+	f, _ := stubTools(t, sampleKeyboard)
+	_, err := Apply(sampleKeyboard, false)
+	require.NoError(t, err)
+	assert.Empty(t, f.defaults)
+	assert.Empty(t, f.hidutil)
+	assert.Zero(t, f.activated)
+
+	f.launchctl = nil
+	n, err := Apply(sampleKeyboard, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "the LaunchAgent is already written")
+	assert.Empty(t, f.launchctl, "an unchanged agent is not reloaded")
+}
+
+func TestApply_RegistersNewAppForKeyboardSettings(t *testing.T) {
+	f, _ := stubTools(t, nil)
+	_, err := Apply(&config.RemoteKeyboardConfig{
+		AppShortcuts: map[string]map[string]string{
+			config.KeyboardGlobalDomain: {"Minimize": "@m"},
+			"com.apple.mail":            {"Archive": "@^a"},
+		},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"write", "NSGlobalDomain", "NSUserKeyEquivalents", "-dict-add", "Minimize", "@m"},
+		{"write", "com.apple.mail", "NSUserKeyEquivalents", "-dict-add", "Archive", "@^a"},
+		{"write", "com.apple.universalaccess", "com.apple.custommenu.apps", "-array-add", "com.apple.mail"},
+	}, f.defaults)
+}
+
+func TestApply_DryRun(t *testing.T) {
+	f, home := stubTools(t, nil)
+	n, err := Apply(sampleKeyboard, true)
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Empty(t, f.defaults)
+	assert.Empty(t, f.hidutil)
+	assert.Empty(t, f.launchctl)
+	_, err = os.Stat(filepath.Join(home, agentFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestApply_InvalidConfig(t *testing.T) {
+	f, _ := stubTools(t, nil)
+	_, err := Apply(&config.RemoteKeyboardConfig{KeyMappings: []config.KeyMapping{{From: "hyper", To: "escape"}}}, false)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "unknown key"))
+	assert.Empty(t, f.hidutil)
+}
+
+func TestApply_Empty(t *testing.T) {
+	n, err := Apply(nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,111 @@
+// Package launchagents applies the launch_agents section of a config: it
+// writes each agent's plist to ~/Library/LaunchAgents and (re)loads it with
+// launchctl. Only agents openboot generated are ever replaced. Capture
+// lives in internal/snapshot.
+package launchagents
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// runLaunchctl and guiDomain wrap launchctl and the user's launchd domain
+// so tests can run without loading agents.
+var (
+	runLaunchctl = func(args ...string) error {
+		out, err := system.RunCommandSilent("launchctl", args...)
+		if err != nil {
+			return fmt.Errorf("launchctl %s: %s: %w", args[0], out, err)
+		}
+		return nil
+	}
+	guiDomain = func() string {
+		return fmt.Sprintf("gui/%d", os.Getuid())
+	}
+)
+
+// Apply writes and loads each agent whose plist differs from what is on
+// disk. It refuses to replace a plist openboot did not write. It returns
+// the number of agents changed.
+func Apply(agents []config.LaunchAgent, dryRun bool) (int, error) {
+	if len(agents) == 0 {
+		return 0, nil
+	}
+	if err := config.ValidateLaunchAgents(agents); err != nil {
+		return 0, fmt.Errorf("apply launch agents: %w", err)
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	var errs []error
+	for _, a := range agents {
+		ok, err := applyAgent(a, home, dryRun)
+		if ok {
+			changed++
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("launch agent %s: %w", a.Label, err))
+		}
+	}
+	return changed, errors.Join(errs...)
+}
+
+// applyAgent writes and reloads a when its plist is out of date. changed
+// is false when it already was current.
+func applyAgent(a config.LaunchAgent, home string, dryRun bool) (changed bool, err error) {
+	path := filepath.Join(home, snapshot.LaunchAgentsDir, a.File())
+	want := a.Plist(home)
+	cur, err := os.ReadFile(path) //nolint:gosec // path is built from a validated label
+	if err == nil {
+		if bytes.Equal(cur, want) {
+			return false, nil
+		}
+		if _, managed := config.ParseLaunchAgent(cur, home); !managed {
+			return false, fmt.Errorf("%s exists and was not written by openboot; remove it or rename the agent", path)
+		}
+	}
+
+	domain := guiDomain()
+	if dryRun {
+		ui.DryRunMsg("Would write %s and run: launchctl bootstrap %s %s", path, domain, path)
+		return true, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	// launchd creates log files but not their directories.
+	for _, p := range []string{a.StandardOutPath, a.StandardErrorPath} {
+		if p == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(p, "~/"); ok {
+			p = filepath.Join(home, rest)
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return false, fmt.Errorf("create log directory %s: %w", filepath.Dir(p), err)
+		}
+	}
+	if err := os.WriteFile(path, want, 0644); err != nil { //nolint:gosec // LaunchAgents must be readable by launchd
+		return false, fmt.Errorf("write %s: %w", path, err)
+	}
+
+	// bootout fails when the agent is not loaded yet; that is fine.
+	_ = runLaunchctl("bootout", domain+"/"+a.Label)
+	if err := runLaunchctl("bootstrap", domain, path); err != nil {
+		return true, err
+	}
+	return true, nil
+}
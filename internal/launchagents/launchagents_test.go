@@ -0,0 +1,98 @@
+package launchagents
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func stubLaunchctl(t *testing.T) (*[][]string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	var calls [][]string
+	origRun, origDomain := runLaunchctl, guiDomain
+	t.Cleanup(func() { runLaunchctl, guiDomain = origRun, origDomain })
+	runLaunchctl = func(args ...string) error {
+		calls = append(calls, args)
+		return nil
+	}
+	guiDomain = func() string { return "gui/501" }
+	return &calls, home
+}
+
+var colima = config.LaunchAgent{
+	Label:            "com.example.colima",
+	ProgramArguments: []string{"/opt/homebrew/bin/colima", "start"},
+	RunAtLoad:        true,
+	StandardOutPath:  "~/Library/Logs/colima/out.log",
+}
+
+func TestApply_WritesAndLoads(t *testing.T) {
+	calls, home := stubLaunchctl(t)
+	path := filepath.Join(home, "Library/LaunchAgents/com.example.colima.plist")
+
+	n, err := Apply([]config.LaunchAgent{colima}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, colima.Plist(home), data)
+	assert.DirExists(t, filepath.Join(home, "Library/Logs/colima"))
+	assert.Equal(t, [][]string{
+		{"bootout", "gui/501/com.example.colima"},
+		{"bootstrap", "gui/501", path},
+	}, *calls)
+
+	// Unchanged: nothing written or reloaded.
+	*calls = nil
+	n, err = Apply([]config.LaunchAgent{colima}, false)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Empty(t, *calls)
+
+	// A changed field rewrites and reloads.
+	changed := colima
+	changed.StartInterval = 3600
+	n, err = Apply([]config.LaunchAgent{changed}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, *calls, 2)
+}
+
+func TestApply_RefusesUnmanagedPlist(t *testing.T) {
+	calls, home := stubLaunchctl(t)
+	dir := filepath.Join(home, "Library/LaunchAgents")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	handWritten := []byte(`<plist version="1.0"><dict><key>Label</key><string>com.example.colima</string></dict></plist>`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, colima.File()), handWritten, 0644))
+
+	n, err := Apply([]config.LaunchAgent{colima}, false)
+	assert.ErrorContains(t, err, "not written by openboot")
+	assert.Zero(t, n)
+	assert.Empty(t, *calls)
+	data, _ := os.ReadFile(filepath.Join(dir, colima.File()))
+	assert.Equal(t, handWritten, data)
+}
+
+func TestApply_DryRun(t *testing.T) {
+	calls, home := stubLaunchctl(t)
+
+	n, err := Apply([]config.LaunchAgent{colima}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Empty(t, *calls)
+	assert.NoFileExists(t, filepath.Join(home, "Library/LaunchAgents", colima.File()))
+}
+
+func TestApply_Invalid(t *testing.T) {
+	calls, _ := stubLaunchctl(t)
+	_, err := Apply([]config.LaunchAgent{{Label: "colima", ProgramArguments: []string{"colima"}}}, false)
+	assert.Error(t, err)
+	assert.Empty(t, *calls)
+}
@@ -129,7 +129,7 @@ func Init(version string, verbose bool) (func(), error) {
 		}
 	}
 
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(newRedactHandler(handler)))
 	// os.Args is user-provided by design — this is an audit record of how the
 	// CLI was invoked. slog's JSON/text handlers quote attribute values, so
 	// there is no injection path into the surrounding log line.
@@ -214,13 +214,14 @@ var sensitiveFlagFragments = []string{"token", "password", "secret", "key", "cre
 // replaced by "<redacted>". Only the "--flag=value" inline form is redacted;
 // the space-separated form ("--flag value") is left alone because the value
 // at position i+1 has no flag context at this layer. This is a best-effort
-// hedge, not a guarantee.
+// hedge, not a guarantee. Any value registered via RegisterSecret is masked
+// regardless of position.
 func RedactArgs(args []string) []string {
 	out := make([]string, len(args))
 	for i, a := range args {
 		eq := strings.IndexByte(a, '=')
 		if eq <= 0 {
-			out[i] = a
+			out[i] = RedactString(a)
 			continue
 		}
 		name := strings.ToLower(a[:eq])
@@ -228,7 +229,7 @@ func RedactArgs(args []string) []string {
 			out[i] = a[:eq] + "=<redacted>"
 			continue
 		}
-		out[i] = a
+		out[i] = RedactString(a)
 	}
 	return out
 }
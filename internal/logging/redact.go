@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces any registered secret value wherever it appears in
+// log output.
+const redactedValue = "<redacted>"
+
+// minSecretLen is the shortest value RegisterSecret will track. Shorter
+// strings ("1", "yes") would cause collateral redaction of ordinary log text
+// while adding nothing: a secret that short is not a secret.
+const minSecretLen = 4
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = map[string]struct{}{}
+)
+
+// RegisterSecret records a resolved secret value so that every later slog
+// record and RedactArgs call replaces it with "<redacted>". The secrets
+// package calls this for every value it resolves; nothing else should need to.
+func RegisterSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLen {
+		return
+	}
+	secretsMu.Lock()
+	secrets[value] = struct{}{}
+	secretsMu.Unlock()
+}
+
+// resetSecrets forgets every registered secret. Test-only.
+func resetSecrets() {
+	secretsMu.Lock()
+	secrets = map[string]struct{}{}
+	secretsMu.Unlock()
+}
+
+// RedactString returns s with every registered secret value replaced by
+// "<redacted>". Longer secrets are replaced first so a secret that contains
+// another secret is not left half-masked.
+func RedactString(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	if len(secrets) == 0 || s == "" {
+		return s
+	}
+	values := make([]string, 0, len(secrets))
+	for v := range secrets {
+		values = append(values, v)
+	}
+	// Longest first; ties broken lexically so output is deterministic.
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, redactedValue)
+	}
+	return s
+}
+
+// redactHandler scrubs registered secret values from the message and every
+// attribute of a record before passing it on. It sits in front of both the
+// file and stderr sinks so neither ever sees a resolved secret.
+type redactHandler struct {
+	next slog.Handler
+}
+
+func newRedactHandler(next slog.Handler) *redactHandler {
+	return &redactHandler{next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, RedactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scrubbed[i] = redactAttr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(scrubbed)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr returns a with secret values scrubbed. Strings and string
+// slices are scrubbed in place, groups are walked recursively, and any other
+// KindAny value (errors, Stringers, maps, structs) is rendered with fmt and
+// scrubbed. Other kinds (ints, bools, durations) cannot carry a secret.
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		scrubbed := make([]any, len(group))
+		for i, g := range group {
+			scrubbed[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, scrubbed...)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case []string:
+			scrubbed := make([]string, len(x))
+			for i, s := range x {
+				scrubbed[i] = RedactString(s)
+			}
+			return slog.Any(a.Key, scrubbed)
+		case error:
+			return slog.String(a.Key, RedactString(x.Error()))
+		case interface{ String() string }:
+			return slog.String(a.Key, RedactString(x.String()))
+		case nil:
+		default:
+			return slog.String(a.Key, RedactString(fmt.Sprint(x)))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactString_RegisteredSecrets(t *testing.T) {
+	t.Cleanup(resetSecrets)
+	RegisterSecret("ghp_abcdef123456")
+	RegisterSecret("abc") // too short to track
+
+	assert.Equal(t, "token=<redacted> abc", RedactString("token=ghp_abcdef123456 abc"))
+	assert.Equal(t, "nothing here", RedactString("nothing here"))
+}
+
+func TestRedactString_LongestFirst(t *testing.T) {
+	t.Cleanup(resetSecrets)
+	RegisterSecret("secret")
+	RegisterSecret("secret-extended")
+
+	assert.Equal(t, "<redacted>", RedactString("secret-extended"))
+}
+
+func TestRedactArgs_RegisteredSecretInPositionalArg(t *testing.T) {
+	t.Cleanup(resetSecrets)
+	RegisterSecret("s3cr3t-value")
+
+	got := RedactArgs([]string{"openboot", "--token", "s3cr3t-value", "--name=s3cr3t-value"})
+	assert.Equal(t, []string{"openboot", "--token", "<redacted>", "--name=<redacted>"}, got)
+}
+
+func TestRedactHandler_ScrubsMessageAndAttrs(t *testing.T) {
+	t.Cleanup(resetSecrets)
+	RegisterSecret("hunter2hunter2")
+
+	var buf bytes.Buffer
+	logger := slog.New(newRedactHandler(slog.NewTextHandler(&buf, nil)))
+	logger.With("pre", "x hunter2hunter2").
+		WithGroup("g").
+		Info("msg hunter2hunter2",
+			"plain", "hunter2hunter2",
+			"err", errors.New("auth failed: hunter2hunter2"),
+			"list", []string{"a", "hunter2hunter2"},
+			"n", 42,
+		)
+
+	out := buf.String()
+	assert.NotContains(t, out, "hunter2hunter2")
+	assert.Contains(t, out, "<redacted>")
+	assert.Contains(t, out, "g.n=42")
+}
+
+func TestRedactHandler_ScrubsOtherAnyValues(t *testing.T) {
+	t.Cleanup(resetSecrets)
+	RegisterSecret("hunter2hunter2")
+
+	var buf bytes.Buffer
+	logger := slog.New(newRedactHandler(slog.NewJSONHandler(&buf, nil)))
+	logger.Info("msg",
+		"map", map[string]string{"token": "hunter2hunter2"},
+		"struct", struct{ Token string }{"hunter2hunter2"},
+		"list", []any{"a", "hunter2hunter2"},
+		"none", nil,
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "hunter2hunter2")
+	assert.Contains(t, out, `"map":"map[token:<redacted>]"`)
+	assert.Contains(t, out, `"none":null`)
+}
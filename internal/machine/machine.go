@@ -0,0 +1,72 @@
+// Package machine sets the machine's names — ComputerName, LocalHostName
+// and HostName — from the machine section of a config, through scutil.
+// Setting them needs root, so each change runs under sudo with the
+// terminal attached for the password prompt.
+package machine
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// getName, setName and username wrap scutil and the user lookup so tests
+// can run without changing the real machine.
+var (
+	getName = func(key string) string {
+		out, err := system.RunCommandOutput("scutil", "--get", key)
+		if err != nil {
+			// scutil errors when a name was never set.
+			return ""
+		}
+		return out
+	}
+	setName = func(key, value string) error {
+		if os.Geteuid() == 0 {
+			return system.RunCommand("scutil", "--set", key, value)
+		}
+		return system.RunCommandWithTTY("sudo", "scutil", "--set", key, value)
+	}
+	username = system.Username
+)
+
+// Apply sets each name cfg gives that differs from the machine's current
+// one. It returns the number of names changed.
+func Apply(cfg *config.RemoteMachineConfig, dryRun bool) (int, error) {
+	if cfg.Empty() {
+		return 0, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return 0, fmt.Errorf("set machine name: %w", err)
+	}
+	user, err := username()
+	if err != nil {
+		return 0, fmt.Errorf("set machine name: %w", err)
+	}
+	resolved := cfg.Resolve(user)
+	if len(resolved.Names()) != len(cfg.Names()) {
+		return 0, fmt.Errorf("set machine name for user %s: a name resolves to nothing", user)
+	}
+	if err := resolved.Validate(); err != nil {
+		return 0, fmt.Errorf("set machine name for user %s: %w", user, err)
+	}
+
+	changed := 0
+	for _, n := range resolved.Names() {
+		if getName(n.Key) == n.Value {
+			continue
+		}
+		changed++
+		if dryRun {
+			ui.DryRunMsg("Would run: sudo scutil --set %s %q", n.Key, n.Value)
+			continue
+		}
+		if err := setName(n.Key, n.Value); err != nil {
+			return changed - 1, fmt.Errorf("set %s: %w", n.Key, err)
+		}
+	}
+	return changed, nil
+}
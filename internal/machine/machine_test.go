@@ -0,0 +1,68 @@
+package machine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+// fakeScutil replaces scutil with an in-memory name table and pins the user.
+func fakeScutil(t *testing.T, names map[string]string) *[][2]string {
+	t.Helper()
+	var sets [][2]string
+	origGet, origSet, origUser := getName, setName, username
+	t.Cleanup(func() { getName, setName, username = origGet, origSet, origUser })
+	getName = func(key string) string { return names[key] }
+	setName = func(key, value string) error {
+		sets = append(sets, [2]string{key, value})
+		names[key] = value
+		return nil
+	}
+	username = func() (string, error) { return "jane", nil }
+	return &sets
+}
+
+func TestApply_SetsOnlyDifferingNames(t *testing.T) {
+	names := map[string]string{"ComputerName": "Jane's MacBook Pro", "LocalHostName": "Janes-MacBook-Pro"}
+	sets := fakeScutil(t, names)
+	cfg := &config.RemoteMachineConfig{ComputerName: "Jane's MacBook Pro", LocalHostName: "{{user}}-mbp", HostName: "{{user}}-mbp"}
+
+	n, err := Apply(cfg, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, [][2]string{{"LocalHostName", "jane-mbp"}, {"HostName", "jane-mbp"}}, *sets)
+
+	n, err = Apply(cfg, false)
+	require.NoError(t, err)
+	assert.Zero(t, n, "second run changes nothing")
+}
+
+func TestApply_DryRunSetsNothing(t *testing.T) {
+	sets := fakeScutil(t, map[string]string{})
+
+	n, err := Apply(&config.RemoteMachineConfig{HostName: "{{user}}-mbp"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Empty(t, *sets)
+}
+
+func TestApply_Errors(t *testing.T) {
+	fakeScutil(t, map[string]string{})
+
+	_, err := Apply(&config.RemoteMachineConfig{HostName: "{{serial}}"}, false)
+	assert.ErrorContains(t, err, "unknown placeholder")
+
+	username = func() (string, error) { return "___", nil }
+	_, err = Apply(&config.RemoteMachineConfig{LocalHostName: "{{user}}"}, false)
+	assert.ErrorContains(t, err, "for user ___", "resolved name re-checked")
+
+	username = func() (string, error) { return "jane", nil }
+	setName = func(string, string) error { return errors.New("sudo: a password is required") }
+	n, err := Apply(&config.RemoteMachineConfig{HostName: "box"}, false)
+	assert.ErrorContains(t, err, "set HostName")
+	assert.Zero(t, n)
+}
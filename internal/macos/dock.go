@@ -1,12 +1,31 @@
 package macos
 
 import (
+	"bytes"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/plist"
 	"github.com/openbootdotdev/openboot/internal/system"
 )
 
+// Dock plist tile-type values for each config.DockTile type.
+var dockTileTypes = map[string]string{
+	config.DockTileApp:         "file-tile",
+	config.DockTileFile:        "file-tile",
+	config.DockTileFolder:      "directory-tile",
+	config.DockTileSpacer:      "spacer-tile",
+	config.DockTileSmallSpacer: "small-spacer-tile",
+	config.DockTileFlexSpacer:  "flex-spacer-tile",
+}
+
 // SetDockApps replaces the Dock's pinned-apps list with the given
 // absolute paths in order. Missing apps are warned and skipped. After
 // success the Dock is restarted via `killall Dock` so the change is
@@ -19,7 +38,7 @@ func SetDockApps(apps []string, dryRun bool) error {
 		fmt.Println("[DRY-RUN] Would clear and rebuild Dock pinned apps:")
 		fmt.Println("[DRY-RUN]   defaults delete com.apple.dock persistent-apps")
 		for _, app := range apps {
-			if _, err := os.Stat(app); err != nil {
+			if _, err := os.Stat(expandHome(app)); err != nil {
 				fmt.Printf("[DRY-RUN]   (skip, not installed) %s\n", app)
 				continue
 			}
@@ -34,11 +53,11 @@ func SetDockApps(apps []string, dryRun bool) error {
 	_, _ = system.RunCommandSilent("defaults", "delete", "com.apple.dock", "persistent-apps")
 
 	for _, app := range apps {
-		if _, err := os.Stat(app); err != nil {
+		if _, err := os.Stat(expandHome(app)); err != nil {
 			fmt.Fprintf(os.Stderr, "⚠ Dock: skipping %s (not installed)\n", app)
 			continue
 		}
-		tile := dockTileFor(app)
+		tile := dockTileFor(config.DockTile{Type: config.DockTileApp, Path: app})
 		if _, err := system.RunCommandSilent("defaults", "write",
 			"com.apple.dock", "persistent-apps", "-array-add", tile); err != nil {
 			return fmt.Errorf("dock add %s: %w", app, err)
@@ -50,14 +69,325 @@ func SetDockApps(apps []string, dryRun bool) error {
 	return nil
 }
 
+// SetDockLayout replaces both sides of the Dock with layout's tiles, in
+// order, then writes its settings and restarts the Dock. Tiles whose path
+// does not exist are warned and skipped; a nil Others clears the folder
+// side like an empty one, so the result is fully declarative.
+func SetDockLayout(layout *config.DockLayout, dryRun bool) error {
+	if layout == nil {
+		return nil
+	}
+	if dryRun {
+		fmt.Println("[DRY-RUN] Would replace the Dock layout:")
+		for _, line := range DescribeDockLayout(layout) {
+			fmt.Printf("[DRY-RUN]   %s\n", line)
+		}
+		fmt.Println("[DRY-RUN]   killall Dock")
+		return nil
+	}
+
+	var errs []error
+	for _, side := range []struct {
+		key   string
+		tiles []config.DockTile
+	}{{"persistent-apps", layout.Apps}, {"persistent-others", layout.Others}} {
+		// Ignore error: key may not exist on a virgin machine.
+		_, _ = system.RunCommandSilent("defaults", "delete", "com.apple.dock", side.key)
+		for _, t := range side.tiles {
+			if !t.IsSpacer() {
+				if _, err := os.Stat(expandHome(t.Path)); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠ Dock: skipping %s (not found)\n", t.Path)
+					continue
+				}
+			}
+			if _, err := system.RunCommandSilent("defaults", "write",
+				"com.apple.dock", side.key, "-array-add", dockTileFor(t)); err != nil {
+				errs = append(errs, fmt.Errorf("dock add %s: %w", describeDockTile(t), err))
+			}
+		}
+	}
+	if err := Configure(dockSettingsPrefs(layout.Settings), false); err != nil {
+		errs = append(errs, err)
+	}
+
+	// killall is best-effort: Dock may not be running on some CI hosts.
+	_, _ = system.RunCommandSilent("killall", "Dock")
+	return errors.Join(errs...)
+}
+
+// DescribeDockLayout returns one line per tile, side by side, then one per
+// setting: what SetDockLayout would leave in the Dock.
+func DescribeDockLayout(layout *config.DockLayout) []string {
+	if layout == nil {
+		return nil
+	}
+	var lines []string
+	for _, side := range []struct {
+		name  string
+		tiles []config.DockTile
+	}{{"apps", layout.Apps}, {"others", layout.Others}} {
+		lines = append(lines, fmt.Sprintf("%s (%d):", side.name, len(side.tiles)))
+		for _, t := range side.tiles {
+			line := describeDockTile(t)
+			if !t.IsSpacer() {
+				if _, err := os.Stat(expandHome(t.Path)); err != nil {
+					line = "(skip, not found) " + line
+				}
+			}
+			lines = append(lines, "  "+line)
+		}
+	}
+	for _, p := range dockSettingsPrefs(layout.Settings) {
+		lines = append(lines, fmt.Sprintf("%s = %s", p.Key, p.Value))
+	}
+	return lines
+}
+
+// describeDockTile renders t for a preview, e.g. "folder ~/Downloads
+// (stack, fan, sorted by added)".
+func describeDockTile(t config.DockTile) string {
+	if t.IsSpacer() {
+		return t.Type
+	}
+	s := t.Type + " " + t.Path
+	var opts []string
+	for _, o := range []string{t.Display, t.View} {
+		if o != "" {
+			opts = append(opts, o)
+		}
+	}
+	if t.Sort != "" {
+		opts = append(opts, "sorted by "+t.Sort)
+	}
+	if len(opts) > 0 {
+		s += " (" + strings.Join(opts, ", ") + ")"
+	}
+	return s
+}
+
+// dockSettingsPrefs returns the com.apple.dock writes for s, in a fixed
+// order, leaving out unset fields.
+func dockSettingsPrefs(s *config.DockSettings) []Preference {
+	if s.Empty() {
+		return nil
+	}
+	var prefs []Preference
+	add := func(key, typ, value, desc string) {
+		prefs = append(prefs, Preference{Domain: "com.apple.dock", Key: key, Type: typ, Value: value, Desc: desc})
+	}
+	if s.Orientation != "" {
+		add("orientation", "string", s.Orientation, "Dock position")
+	}
+	if s.Autohide != nil {
+		add("autohide", "bool", strconv.FormatBool(*s.Autohide), "Dock auto-hide")
+	}
+	if s.Magnification != nil {
+		add("magnification", "bool", strconv.FormatBool(*s.Magnification), "Dock magnification")
+	}
+	if s.TileSize != 0 {
+		add("tilesize", "int", strconv.Itoa(s.TileSize), "Dock icon size")
+	}
+	if s.LargeSize != 0 {
+		add("largesize", "int", strconv.Itoa(s.LargeSize), "Dock magnified icon size")
+	}
+	if s.ShowRecents != nil {
+		add("show-recents", "bool", strconv.FormatBool(*s.ShowRecents), "Show recent apps in Dock")
+	}
+	return prefs
+}
+
 // dockTileFor returns the plist-XML <dict> blob that `defaults write
-// ... -array-add` expects for one app tile.
-func dockTileFor(appPath string) string {
-	return fmt.Sprintf(
-		`<dict><key>tile-data</key><dict><key>file-data</key><dict>`+
-			`<key>_CFURLString</key><string>%s</string>`+
-			`<key>_CFURLStringType</key><integer>0</integer>`+
-			`</dict></dict></dict>`,
-		appPath,
-	)
+// ... -array-add` expects for one tile. DockTileFromPlist reads it back.
+func dockTileFor(t config.DockTile) string {
+	var sb strings.Builder
+	sb.WriteString("<dict><key>tile-data</key><dict>")
+	if !t.IsSpacer() {
+		var path bytes.Buffer
+		_ = xml.EscapeText(&path, []byte(expandHome(t.Path)))
+		sb.WriteString(`<key>file-data</key><dict>` +
+			`<key>_CFURLString</key><string>` + path.String() + `</string>` +
+			`<key>_CFURLStringType</key><integer>0</integer>` +
+			`</dict>`)
+	}
+	if t.Type == config.DockTileFolder {
+		// The Dock numbers displayas and showas from 0, arrangement from 1.
+		for _, opt := range []struct {
+			key, v  string
+			allowed []string
+			base    int
+		}{
+			{"displayas", t.Display, config.DockFolderDisplays, 0},
+			{"showas", t.View, config.DockFolderViews, 0},
+			{"arrangement", t.Sort, config.DockFolderSorts, 1},
+		} {
+			if i := slices.Index(opt.allowed, opt.v); i >= 0 {
+				fmt.Fprintf(&sb, "<key>%s</key><integer>%d</integer>", opt.key, i+opt.base)
+			}
+		}
+	}
+	sb.WriteString("</dict><key>tile-type</key><string>" + dockTileTypes[t.Type] + "</string></dict>")
+	return sb.String()
+}
+
+// ParseDockLayout reads the layout from the com.apple.dock domain as
+// `defaults export com.apple.dock -` prints it. Tiles openboot cannot
+// restore (e.g. the Recents or Downloads smart stacks) are skipped, and
+// paths under home are written as ~/.
+func ParseDockLayout(prefs plist.Dict) *config.DockLayout {
+	layout := &config.DockLayout{
+		Apps:   DockTilesFromPlist(prefs["persistent-apps"], config.DockTileApp),
+		Others: DockTilesFromPlist(prefs["persistent-others"], config.DockTileFile),
+	}
+	if layout.Apps == nil {
+		layout.Apps = []config.DockTile{}
+	}
+	s := &config.DockSettings{}
+	if v, ok := prefs["orientation"].(string); ok && slices.Contains(config.DockOrientations, v) {
+		s.Orientation = v
+	}
+	s.Autohide = plistBoolPtr(prefs["autohide"])
+	s.Magnification = plistBoolPtr(prefs["magnification"])
+	s.TileSize = plistInt(prefs["tilesize"])
+	s.LargeSize = plistInt(prefs["largesize"])
+	s.ShowRecents = plistBoolPtr(prefs["show-recents"])
+	if !s.Empty() {
+		layout.Settings = s
+	}
+	return layout
+}
+
+// DockTilesFromPlist reads a persistent-apps or persistent-others array.
+// File tiles get fileType: the app side only holds apps, the folder side
+// only documents.
+func DockTilesFromPlist(v any, fileType string) []config.DockTile {
+	var raw []plist.Dict
+	switch arr := v.(type) {
+	case []plist.Dict:
+		raw = arr
+	case []any:
+		for _, e := range arr {
+			if d, ok := e.(plist.Dict); ok {
+				raw = append(raw, d)
+			}
+		}
+	}
+	var tiles []config.DockTile
+	for _, d := range raw {
+		if t, ok := DockTileFromPlist(d, fileType); ok {
+			tiles = append(tiles, t)
+		}
+	}
+	return tiles
+}
+
+// DockTileFromPlist reads one tile dictionary. ok is false for tile types
+// openboot does not restore and for file tiles without a file:// URL or
+// path.
+func DockTileFromPlist(d plist.Dict, fileType string) (config.DockTile, bool) {
+	tileType, _ := d["tile-type"].(string)
+	var t config.DockTile
+	for typ, pt := range dockTileTypes {
+		if pt == tileType && (pt != "file-tile" || typ == fileType) {
+			t.Type = typ
+		}
+	}
+	if t.Type == "" {
+		return t, false
+	}
+	if t.IsSpacer() {
+		return t, true
+	}
+
+	data, _ := d["tile-data"].(plist.Dict)
+	fileData, _ := data["file-data"].(plist.Dict)
+	raw, _ := fileData["_CFURLString"].(string)
+	path, err := dockURLToPath(raw)
+	if err != nil || path == "" {
+		return t, false
+	}
+	t.Path = contractHome(path)
+
+	if t.Type == config.DockTileFolder {
+		for _, opt := range []struct {
+			key     string
+			dst     *string
+			allowed []string
+			base    int
+		}{
+			{"displayas", &t.Display, config.DockFolderDisplays, 0},
+			{"showas", &t.View, config.DockFolderViews, 0},
+			{"arrangement", &t.Sort, config.DockFolderSorts, 1},
+		} {
+			if _, set := data[opt.key]; !set {
+				continue
+			}
+			if i := plistInt(data[opt.key]) - opt.base; i >= 0 && i < len(opt.allowed) {
+				*opt.dst = opt.allowed[i]
+			}
+		}
+	}
+	return t, true
+}
+
+// dockURLToPath converts a `file:///Applications/Foo.app/` URL into the
+// filesystem path `/Applications/Foo.app`. Plain paths, which tiles
+// written by dockTileFor hold, are returned as they are.
+func dockURLToPath(raw string) (string, error) {
+	if strings.HasPrefix(raw, "/") {
+		return strings.TrimRight(raw, "/"), nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("expected file:// url, got %q", u.Scheme)
+	}
+	p, err := url.PathUnescape(u.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(p, "/"), nil
+}
+
+// contractHome rewrites a path under the home directory as ~/..., so a
+// captured layout restores on a machine with another user name.
+func contractHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, strings.TrimRight(home, "/")+"/"); ok {
+		return "~/" + rest
+	}
+	return path
+}
+
+// plistBoolPtr reads a plist boolean, which defaults may also store as an
+// integer. It returns nil when v is unset.
+func plistBoolPtr(v any) *bool {
+	var b bool
+	switch x := v.(type) {
+	case bool:
+		b = x
+	case string:
+		n, err := strconv.ParseFloat(x, 64)
+		if err != nil {
+			return nil
+		}
+		b = n != 0
+	default:
+		return nil
+	}
+	return &b
+}
+
+// plistInt reads a plist integer or real, rounding reals; 0 when unset.
+func plistInt(v any) int {
+	s, _ := v.(string)
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int(n + 0.5)
 }
@@ -2,10 +2,14 @@ package macos
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/plist"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSetDockApps_DryRunDeleteThenAddThenKillall(t *testing.T) {
@@ -63,3 +67,175 @@ func TestSetDockApps_DryRunMissingAppPathSkippedWithWarn(t *testing.T) {
 	}
 	assert.Equal(t, 1, addLines, "only Real.app should be added")
 }
+
+// Every tile type survives dockTileFor → plist → DockTileFromPlist.
+func TestDockTileFor_RoundTrip(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	tiles := []struct {
+		side string
+		tile config.DockTile
+	}{
+		{config.DockTileApp, config.DockTile{Type: config.DockTileApp, Path: "/Applications/Safari.app"}},
+		{config.DockTileApp, config.DockTile{Type: config.DockTileApp, Path: "/Applications/R&D Tool.app"}},
+		{config.DockTileApp, config.DockTile{Type: config.DockTileSpacer}},
+		{config.DockTileApp, config.DockTile{Type: config.DockTileSmallSpacer}},
+		{config.DockTileApp, config.DockTile{Type: config.DockTileFlexSpacer}},
+		{config.DockTileFile, config.DockTile{Type: config.DockTileFile, Path: "/Users/Shared/notes.txt"}},
+		{config.DockTileFile, config.DockTile{Type: config.DockTileFolder, Path: "~/Downloads", Display: "stack", View: "fan", Sort: "added"}},
+		{config.DockTileFile, config.DockTile{Type: config.DockTileFolder, Path: "/Applications", Display: "folder", View: "grid", Sort: "name"}},
+		{config.DockTileFile, config.DockTile{Type: config.DockTileFolder, Path: "/Users/Shared", View: "list", Sort: "kind"}},
+		{config.DockTileFile, config.DockTile{Type: config.DockTileFolder, Path: "/tmp"}},
+	}
+	for _, tc := range tiles {
+		blob := dockTileFor(tc.tile)
+		if !tc.tile.IsSpacer() {
+			assert.NotContains(t, blob, "~", "paths are expanded before writing")
+		}
+		d, err := plist.ParseDict([]byte(blob))
+		require.NoError(t, err, blob)
+		got, ok := DockTileFromPlist(d, tc.side)
+		require.True(t, ok, blob)
+		assert.Equal(t, tc.tile, got, blob)
+	}
+	assert.Contains(t, dockTileFor(config.DockTile{Type: config.DockTileFolder, Path: "~/Downloads"}),
+		"<string>"+filepath.Join(home, "Downloads")+"</string>")
+}
+
+func TestParseDockLayout(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	export := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>autohide</key>
+	<true/>
+	<key>largesize</key>
+	<real>72.5</real>
+	<key>magnification</key>
+	<integer>0</integer>
+	<key>orientation</key>
+	<string>left</string>
+	<key>persistent-apps</key>
+	<array>
+		<dict>
+			<key>tile-data</key>
+			<dict>
+				<key>book</key>
+				<data>Ym9va0QCAAAAAA==</data>
+				<key>file-data</key>
+				<dict>
+					<key>_CFURLString</key>
+					<string>file:///Applications/Google%20Chrome.app/</string>
+					<key>_CFURLStringType</key>
+					<integer>15</integer>
+				</dict>
+			</dict>
+			<key>tile-type</key>
+			<string>file-tile</string>
+		</dict>
+		<dict>
+			<key>tile-data</key>
+			<dict/>
+			<key>tile-type</key>
+			<string>small-spacer-tile</string>
+		</dict>
+	</array>
+	<key>persistent-others</key>
+	<array>
+		<dict>
+			<key>tile-data</key>
+			<dict>
+				<key>arrangement</key>
+				<integer>2</integer>
+				<key>displayas</key>
+				<integer>0</integer>
+				<key>file-data</key>
+				<dict>
+					<key>_CFURLString</key>
+					<string>file://` + home + `/Downloads/</string>
+					<key>_CFURLStringType</key>
+					<integer>15</integer>
+				</dict>
+				<key>showas</key>
+				<integer>1</integer>
+			</dict>
+			<key>tile-type</key>
+			<string>directory-tile</string>
+		</dict>
+		<dict>
+			<key>tile-data</key>
+			<dict>
+				<key>list-type</key>
+				<integer>1</integer>
+			</dict>
+			<key>tile-type</key>
+			<string>recents-tile</string>
+		</dict>
+	</array>
+	<key>show-recents</key>
+	<false/>
+	<key>tilesize</key>
+	<integer>48</integer>
+</dict>
+</plist>`
+	prefs, err := plist.ParseDict([]byte(export))
+	require.NoError(t, err)
+
+	no, yes := false, true
+	assert.Equal(t, &config.DockLayout{
+		Apps: []config.DockTile{
+			{Type: config.DockTileApp, Path: "/Applications/Google Chrome.app"},
+			{Type: config.DockTileSmallSpacer},
+		},
+		Others: []config.DockTile{
+			{Type: config.DockTileFolder, Path: "~/Downloads", Display: "stack", View: "fan", Sort: "added"},
+		},
+		Settings: &config.DockSettings{
+			Orientation: "left", Autohide: &yes, Magnification: &no,
+			TileSize: 48, LargeSize: 73, ShowRecents: &no,
+		},
+	}, ParseDockLayout(prefs))
+
+	empty := ParseDockLayout(plist.Dict{})
+	assert.Equal(t, []config.DockTile{}, empty.Apps)
+	assert.Nil(t, empty.Settings)
+}
+
+func TestSetDockLayout_DryRunPreview(t *testing.T) {
+	dir := t.TempDir()
+	realApp := filepath.Join(dir, "Real.app")
+	require.NoError(t, os.MkdirAll(realApp, 0755))
+	autohide := true
+
+	out := captureStdout(t, func() {
+		err := SetDockLayout(&config.DockLayout{
+			Apps: []config.DockTile{
+				{Type: config.DockTileApp, Path: realApp},
+				{Type: config.DockTileSpacer},
+				{Type: config.DockTileApp, Path: "/Applications/DefinitelyDoesNotExist123.app"},
+			},
+			Others: []config.DockTile{
+				{Type: config.DockTileFolder, Path: dir, View: "grid", Sort: "name"},
+			},
+			Settings: &config.DockSettings{Orientation: "left", Autohide: &autohide},
+		}, true)
+		assert.NoError(t, err)
+	})
+	assert.Equal(t, `[DRY-RUN] Would replace the Dock layout:
+[DRY-RUN]   apps (3):
+[DRY-RUN]     app `+realApp+`
+[DRY-RUN]     spacer
+[DRY-RUN]     (skip, not found) app /Applications/DefinitelyDoesNotExist123.app
+[DRY-RUN]   others (1):
+[DRY-RUN]     folder `+dir+` (grid, sorted by name)
+[DRY-RUN]   orientation = left
+[DRY-RUN]   autohide = true
+[DRY-RUN]   killall Dock
+`, out)
+
+	assert.NoError(t, SetDockLayout(nil, true))
+}
@@ -0,0 +1,130 @@
+// Package managedblock edits the fenced sections openboot owns in files it
+// shares with the user — shell rc files, ~/.ssh/config:
+//
+//	# >>> OpenBoot-<name>
+//	...
+//	# <<< OpenBoot-<name>
+//
+// Everything outside the markers belongs to the user and is left alone, so
+// rewriting a block is idempotent and removing one restores the file.
+package managedblock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+func blockRe(name string) *regexp.Regexp {
+	start, end := config.ManagedBlockMarkers(name)
+	return regexp.MustCompile(`(?s)` + regexp.QuoteMeta(start) + `\n.*?` + regexp.QuoteMeta(end) + `\n?`)
+}
+
+// Render fences body with name's markers. The result always ends in a
+// newline, even for an empty body.
+func Render(name, body string) string {
+	start, end := config.ManagedBlockMarkers(name)
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return start + "\n" + body + end + "\n"
+}
+
+// Has reports whether content holds the named block.
+func Has(content, name string) bool {
+	return blockRe(name).MatchString(content)
+}
+
+// Strip returns content without the named block.
+func Strip(content, name string) string {
+	return blockRe(name).ReplaceAllString(content, "")
+}
+
+// Replace swaps the named block in content for block, or appends block when
+// there is none. An empty block removes it.
+func Replace(content, name, block string) string {
+	return replace(content, name, block, false)
+}
+
+func replace(content, name, block string, first bool) string {
+	re := blockRe(name)
+	if re.MatchString(content) {
+		return re.ReplaceAllLiteralString(content, block)
+	}
+	if block == "" {
+		return content
+	}
+	if first {
+		return block + content
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + block
+}
+
+// Upsert writes body as the named block in path, replacing an existing
+// block or appending a new one, and creating the file if needed. An empty
+// body removes the block. The write is atomic. When path is a symlink (a
+// file linked from a dotfiles repo) the link's target is updated, so the
+// link itself survives. It reports whether the file changed.
+func Upsert(path, name, body string, dryRun bool) (bool, error) {
+	return upsert(path, name, body, false, dryRun)
+}
+
+// UpsertFirst is Upsert for files where earlier lines win, like
+// ~/.ssh/config: a new block goes at the top of the file. An existing block
+// stays where it is.
+func UpsertFirst(path, name, body string, dryRun bool) (bool, error) {
+	return upsert(path, name, body, true, dryRun)
+}
+
+func upsert(path, name, body string, first, dryRun bool) (bool, error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("stat %s: %w", path, err)
+	}
+	raw, err := os.ReadFile(path) //nolint:gosec // paths are fixed locations under the home directory
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("read %s: %w", path, err)
+	}
+	content := string(raw)
+
+	block := ""
+	if body != "" {
+		block = Render(name, body)
+	}
+	updated := replace(content, name, block, first)
+	if updated == content {
+		return false, nil
+	}
+
+	if dryRun {
+		ui.DryRunMsg("Would update %s", path)
+		return true, nil
+	}
+	mode := os.FileMode(0600)
+	if info != nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(updated), mode); err != nil {
+		return false, fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return false, fmt.Errorf("rename %s: %w", path, err)
+	}
+	return true, nil
+}
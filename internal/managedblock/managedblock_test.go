@@ -0,0 +1,71 @@
+package managedblock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestUpsert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rc")
+	require.NoError(t, os.WriteFile(path, []byte("export A=1"), 0644))
+
+	changed, err := Upsert(path, "Test", "one", false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "export A=1\n# >>> OpenBoot-Test\none\n# <<< OpenBoot-Test\n", read(t, path))
+
+	changed, err = Upsert(path, "Test", "one\n", false)
+	require.NoError(t, err)
+	assert.False(t, changed, "same body is a no-op")
+
+	_, err = Upsert(path, "Test", "two\n", false)
+	require.NoError(t, err)
+	assert.Equal(t, "export A=1\n# >>> OpenBoot-Test\ntwo\n# <<< OpenBoot-Test\n", read(t, path))
+
+	_, err = Upsert(path, "Test", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, "export A=1\n", read(t, path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm(), "mode is kept")
+
+	missing := filepath.Join(t.TempDir(), "nested", "config.fish")
+	changed, err = Upsert(missing, "Test", "x", true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoFileExists(t, missing, "dry run writes nothing")
+}
+
+func TestUpsertFirst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("Host *\n  User me\n"), 0600))
+
+	_, err := UpsertFirst(path, "SSH", "Host a\n  User x", false)
+	require.NoError(t, err)
+	assert.Equal(t, "# >>> OpenBoot-SSH\nHost a\n  User x\n# <<< OpenBoot-SSH\nHost *\n  User me\n", read(t, path))
+
+	// The user moves the block below their own; an update keeps it there.
+	require.NoError(t, os.WriteFile(path, []byte("Host *\n  User me\n# >>> OpenBoot-SSH\nHost a\n# <<< OpenBoot-SSH\n"), 0600))
+	_, err = UpsertFirst(path, "SSH", "Host b", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Host *\n  User me\n# >>> OpenBoot-SSH\nHost b\n# <<< OpenBoot-SSH\n", read(t, path))
+}
+
+func TestStripAndHas(t *testing.T) {
+	content := "a\n" + Render("X", "b") + "c\n"
+	assert.True(t, Has(content, "X"))
+	assert.False(t, Has(content, "Y"))
+	assert.Equal(t, "a\nc\n", Strip(content, "X"))
+}
@@ -0,0 +1,203 @@
+// Package plist reads the XML property lists that `defaults export` and
+// `plutil -convert xml1` print. Values decode to strings (integers and
+// reals keep their text), bools, Dicts and []any; <data> blobs are replaced
+// by the string "<data>".
+package plist
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Dict is a map representation of a <dict> element.
+type Dict = map[string]any
+
+// ParseArray parses a plist XML document whose root element is <array>
+// of <dict> entries and returns them as a slice of Dict.
+func ParseArray(data []byte) ([]Dict, error) {
+	dec := xml.NewDecoder(strings.NewReader(string(data)))
+	// Advance past the XML declaration, DOCTYPE, and <plist> wrapper to reach
+	// the <array> element.
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("reading plist: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "array" {
+			break
+		}
+	}
+	values, err := readArray(dec)
+	if err != nil {
+		return nil, err
+	}
+	// Skip any non-dict entry (shouldn't appear in a dock plist, but be safe).
+	var result []Dict
+	for _, v := range values {
+		if d, ok := v.(Dict); ok {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// ParseDict parses a plist XML document whose root element is <dict>, such
+// as the output of `defaults export <domain> -`.
+func ParseDict(data []byte) (Dict, error) {
+	dec := xml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("reading plist: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "dict" {
+			break
+		}
+	}
+	return readDict(dec)
+}
+
+// readArray reads the contents of an already-opened <array> element and
+// returns its values in order.
+func readArray(dec *xml.Decoder) ([]any, error) {
+	var result []any
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("reading array: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := readValueElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				result = append(result, v)
+			}
+		case xml.EndElement:
+			// </array>
+			return result, nil
+		}
+	}
+}
+
+// readDict reads the contents of an already-opened <dict> element and
+// returns it as a Dict. Values may be strings, integers, dicts, arrays,
+// booleans, or data blobs. Data blobs are stored as a sentinel non-nil value
+// so callers can detect their presence without caring about the bytes.
+func readDict(dec *xml.Decoder) (Dict, error) {
+	d := make(Dict)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("reading dict: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "key" {
+				return nil, fmt.Errorf("expected <key>, got <%s>", t.Name.Local)
+			}
+			key, err := readCharData(dec)
+			if err != nil {
+				return nil, fmt.Errorf("reading key: %w", err)
+			}
+			val, err := readValue(dec)
+			if err != nil {
+				return nil, fmt.Errorf("reading value for key %q: %w", key, err)
+			}
+			d[key] = val
+		case xml.EndElement:
+			// </dict>
+			return d, nil
+		}
+	}
+}
+
+// readValue reads the next start element (the value following a <key>) and
+// returns a Go representation. The element and its children are consumed.
+func readValue(dec *xml.Decoder) (any, error) {
+	// Skip CharData (whitespace) before the value element.
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("reading value token: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			continue
+		case xml.StartElement:
+			return readValueElement(dec, t)
+		case xml.EndElement:
+			return nil, fmt.Errorf("unexpected end element <%s> while reading value", t.Name.Local)
+		}
+	}
+}
+
+// readValueElement reads a value given its opening StartElement (already consumed).
+func readValueElement(dec *xml.Decoder, se xml.StartElement) (any, error) {
+	switch se.Name.Local {
+	case "string", "integer", "real":
+		return readCharData(dec)
+	case "true":
+		if err := expectEnd(dec, "true"); err != nil {
+			return nil, err
+		}
+		return true, nil
+	case "false":
+		if err := expectEnd(dec, "false"); err != nil {
+			return nil, err
+		}
+		return false, nil
+	case "data":
+		// Consume and discard — we don't need the bytes.
+		if err := dec.Skip(); err != nil {
+			// dec.Skip() re-consumes including end element, but we already
+			// consumed the start element. On error just return a sentinel.
+			return "<data>", nil
+		}
+		// dec.Skip() consumed everything up to and including </data>.
+		return "<data>", nil
+	case "dict":
+		return readDict(dec)
+	case "array":
+		return readArray(dec)
+	default:
+		// Unknown element — skip it to stay robust.
+		if err := dec.Skip(); err != nil {
+			return nil, fmt.Errorf("skipping <%s>: %w", se.Name.Local, err)
+		}
+		return nil, nil
+	}
+}
+
+// readCharData reads character data up to the next end element and returns
+// it as a string. The end element is consumed.
+func readCharData(dec *xml.Decoder) (string, error) {
+	var buf strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("reading char data: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			buf.Write(t)
+		case xml.EndElement:
+			return buf.String(), nil
+		}
+	}
+}
+
+// expectEnd consumes tokens until the matching end element for name is found.
+func expectEnd(dec *xml.Decoder, name string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("expected </%s>: %w", name, err)
+	}
+	if ee, ok := tok.(xml.EndElement); ok && ee.Name.Local == name {
+		return nil
+	}
+	return fmt.Errorf("expected </%s>, got %T", name, tok)
+}
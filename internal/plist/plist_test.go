@@ -0,0 +1,43 @@
+package plist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDict(t *testing.T) {
+	d, err := ParseDict([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>name</key>
+	<string>Dock</string>
+	<key>size</key>
+	<integer>48</integer>
+	<key>on</key>
+	<true/>
+	<key>blob</key>
+	<data>AAAA</data>
+	<key>empty</key>
+	<dict/>
+	<key>tiles</key>
+	<array>
+		<dict><key>a</key><false/></dict>
+		<string>kept</string>
+	</array>
+</dict>
+</plist>`))
+	require.NoError(t, err)
+	assert.Equal(t, Dict{
+		"name":  "Dock",
+		"size":  "48",
+		"on":    true,
+		"blob":  "<data>",
+		"empty": Dict{},
+		"tiles": []any{Dict{"a": false}, "kept"},
+	}, d)
+
+	_, err = ParseDict([]byte(`<plist><array/></plist>`))
+	assert.Error(t, err)
+}
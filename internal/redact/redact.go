@@ -0,0 +1,402 @@
+// Package redact strips identifying data — hostnames, home directory
+// paths, email addresses, private repository URLs and user-defined
+// patterns — from a value before it leaves the machine, and fills the
+// placeholders it leaves back in when a redacted value is restored.
+//
+// Values are walked through their JSON form, so any type that round-trips
+// through encoding/json can be redacted, and findings are reported by
+// JSON path (e.g. "git.user_email", "login_items[2].path").
+package redact
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Built-in rule names. They double as placeholder kinds, and Config.Disable
+// takes them to switch a rule off.
+const (
+	RuleHostname = "hostname"
+	RuleHome     = "home"
+	RuleEmail    = "email"
+	RuleRepo     = "repo"
+)
+
+var builtinRules = []string{RuleHostname, RuleHome, RuleEmail, RuleRepo}
+
+// Placeholder returns the marker that replaces a value redacted by rule,
+// e.g. "<redacted:email>". RuleHome is the exception: home paths are
+// rewritten to ~, which restores on any machine without a prompt.
+func Placeholder(rule string) string {
+	return "<redacted:" + rule + ">"
+}
+
+var placeholderRe = regexp.MustCompile(`<redacted:[a-z0-9_-]+>`)
+
+// HasPlaceholder reports whether s holds a redaction placeholder.
+func HasPlaceholder(s string) bool {
+	return placeholderRe.MatchString(s)
+}
+
+// Config is the user's redaction config, read from ~/.openboot/redact.json.
+type Config struct {
+	// Disable lists built-in rules to skip (hostname, home, email, repo).
+	Disable []string `json:"disable,omitempty"`
+	// PrivateRepos lists host/owner prefixes (e.g. "github.com/acme") whose
+	// repository URLs are redacted even on a public forge.
+	PrivateRepos []string `json:"private_repos,omitempty"`
+	// Patterns are extra regular expressions; matches are replaced with
+	// <redacted:NAME>.
+	Patterns []Pattern `json:"patterns,omitempty"`
+}
+
+// Pattern is one user-defined redaction rule.
+type Pattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+// ConfigPath returns the path of the user's redaction config.
+func ConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".openboot", "redact.json")
+}
+
+// LoadConfig reads the user's redaction config. A missing file is the
+// zero Config; a malformed one is an error, so a typo never silently
+// publishes what the user meant to hide.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(ConfigPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("read redaction config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", ConfigPath(), err)
+	}
+	return cfg, nil
+}
+
+// Finding records one value the Redactor changed.
+type Finding struct {
+	Path   string // JSON path of the field
+	Rule   string // rule that fired first
+	Before string
+	After  string
+}
+
+// Redactor applies the built-in rules and a Config's patterns.
+type Redactor struct {
+	home         string
+	hostnames    []*regexp.Regexp
+	disabled     map[string]bool
+	privateRepos []string
+	patterns     []compiledPattern
+}
+
+type compiledPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+var patternNameRe = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// New returns a Redactor for a machine whose home directory is home and
+// that goes by hostnames. Empty entries are ignored.
+func New(cfg Config, home string, hostnames []string) (*Redactor, error) {
+	r := &Redactor{
+		home:     strings.TrimRight(home, "/"),
+		disabled: map[string]bool{},
+	}
+	for _, d := range cfg.Disable {
+		if !slices.Contains(builtinRules, d) {
+			return nil, fmt.Errorf("redaction config: unknown rule %q in disable (known: %s)", d, strings.Join(builtinRules, ", "))
+		}
+		r.disabled[d] = true
+	}
+	for _, p := range cfg.PrivateRepos {
+		if p = strings.Trim(strings.ToLower(p), "/"); p != "" {
+			r.privateRepos = append(r.privateRepos, p)
+		}
+	}
+	for _, p := range cfg.Patterns {
+		if !patternNameRe.MatchString(p.Name) || slices.Contains(builtinRules, p.Name) {
+			return nil, fmt.Errorf("redaction config: pattern name %q must be lowercase letters, digits, - or _ and not a built-in rule", p.Name)
+		}
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("redaction config: pattern %s: %w", p.Name, err)
+		}
+		r.patterns = append(r.patterns, compiledPattern{p.Name, re})
+	}
+
+	// Longest first, so "alice-mbp.local" is replaced whole before "alice-mbp".
+	seen := map[string]bool{}
+	var names []string
+	for _, h := range hostnames {
+		h = strings.TrimSpace(h)
+		if len(h) < 3 || seen[strings.ToLower(h)] {
+			continue
+		}
+		seen[strings.ToLower(h)] = true
+		names = append(names, h)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	for _, h := range names {
+		r.hostnames = append(r.hostnames, regexp.MustCompile(`(?i)(^|[^A-Za-z0-9-])(`+regexp.QuoteMeta(h)+`)($|[^A-Za-z0-9-])`))
+	}
+	return r, nil
+}
+
+// Redact redacts every string in the value v points to, in place, and
+// returns what it changed in path order.
+func (r *Redactor) Redact(v any) ([]Finding, error) {
+	var findings []Finding
+	err := rewriteJSON(v, func(path, s string) (string, error) {
+		out, rule := r.String(s)
+		if out != s {
+			findings = append(findings, Finding{Path: path, Rule: rule, Before: s, After: out})
+		}
+		return out, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("redact: %w", err)
+	}
+	return findings, nil
+}
+
+// String redacts s and returns the result with the name of the first rule
+// that changed it ("" when none did).
+func (r *Redactor) String(s string) (string, string) {
+	if s == "" {
+		return s, ""
+	}
+	first := ""
+	apply := func(rule, out string) {
+		if out != s && first == "" {
+			first = rule
+		}
+		s = out
+	}
+	if !r.disabled[RuleRepo] && r.privateRepo(s) {
+		return Placeholder(RuleRepo), RuleRepo
+	}
+	for _, p := range r.patterns {
+		apply(p.name, p.re.ReplaceAllLiteralString(s, Placeholder(p.name)))
+	}
+	if !r.disabled[RuleEmail] {
+		apply(RuleEmail, redactEmails(s))
+	}
+	if !r.disabled[RuleHostname] {
+		for _, re := range r.hostnames {
+			apply(RuleHostname, re.ReplaceAllString(s, "${1}"+Placeholder(RuleHostname)+"${3}"))
+		}
+	}
+	if !r.disabled[RuleHome] && r.home != "" && r.home != "/" {
+		apply(RuleHome, replaceHome(s, r.home))
+	}
+	return s, first
+}
+
+var emailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+// redactEmails replaces addresses in s, leaving alone the user@host parts
+// of URLs and scp-style git remotes (git@github.com:owner/repo).
+func redactEmails(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range emailRe.FindAllStringIndex(s, -1) {
+		if strings.HasSuffix(s[:m[0]], "//") || (m[1] < len(s) && s[m[1]] == ':') {
+			continue
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(Placeholder(RuleEmail))
+		last = m[1]
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// replaceHome rewrites home, wherever it appears as a whole path prefix,
+// to ~.
+func replaceHome(s, home string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, home)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := i + len(home)
+		b.WriteString(s[:i])
+		if end == len(s) || s[end] == '/' {
+			b.WriteString("~")
+		} else {
+			b.WriteString(home)
+		}
+		s = s[end:]
+	}
+}
+
+// publicForges host repositories that are public unless a PrivateRepos
+// prefix says otherwise. Repositories anywhere else are taken to be on a
+// self-hosted, private forge.
+var publicForges = []string{"github.com", "gitlab.com", "bitbucket.org", "codeberg.org", "sr.ht", "git.sr.ht"}
+
+var scpRemoteRe = regexp.MustCompile(`^[A-Za-z0-9._-]+@([A-Za-z0-9.-]+):([A-Za-z0-9._~/-]+)$`)
+
+// privateRepo reports whether s is a git repository URL on a private
+// forge or under a configured private prefix.
+func (r *Redactor) privateRepo(s string) bool {
+	host, path, ok := parseRepoURL(s)
+	if !ok {
+		return false
+	}
+	host = strings.ToLower(host)
+	full := host + "/" + strings.ToLower(strings.Trim(strings.TrimSuffix(path, ".git"), "/"))
+	for _, p := range r.privateRepos {
+		if full == p || strings.HasPrefix(full, p+"/") {
+			return true
+		}
+	}
+	return !slices.Contains(publicForges, host)
+}
+
+// parseRepoURL splits a git remote into host and path. It recognises
+// scp-style remotes, ssh:// and git:// URLs, and http(s) URLs that name a
+// public forge or end in .git.
+func parseRepoURL(s string) (host, path string, ok bool) {
+	if m := scpRemoteRe.FindStringSubmatch(s); m != nil {
+		return m[1], m[2], true
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" || strings.Count(strings.Trim(u.Path, "/"), "/") < 1 {
+		return "", "", false
+	}
+	switch u.Scheme {
+	case "ssh", "git", "git+ssh":
+		return u.Hostname(), u.Path, true
+	case "http", "https":
+		if strings.HasSuffix(u.Path, ".git") || slices.Contains(publicForges, strings.ToLower(u.Hostname())) {
+			return u.Hostname(), u.Path, true
+		}
+	}
+	return "", "", false
+}
+
+// Restore offers every string in the value v points to that still holds
+// a placeholder to prompt, and stores its answer in place. prompt gets the
+// field's JSON path and redacted value; an empty answer clears the field
+// so nothing is applied from it.
+func Restore(v any, prompt func(path, value string) (string, error)) error {
+	return rewriteJSON(v, restorer(prompt))
+}
+
+// RestoreJSON is Restore for a JSON document.
+func RestoreJSON(data []byte, prompt func(path, value string) (string, error)) ([]byte, error) {
+	if !placeholderRe.Match(data) {
+		return data, nil
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	doc, err := walk(doc, "", restorer(prompt))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// restorer wraps prompt for walk. An answer that still holds a placeholder
+// counts as empty.
+func restorer(prompt func(path, value string) (string, error)) func(path, s string) (string, error) {
+	return func(path, s string) (string, error) {
+		if !HasPlaceholder(s) {
+			return s, nil
+		}
+		out, err := prompt(path, s)
+		if err != nil {
+			return "", fmt.Errorf("restore %s: %w", path, err)
+		}
+		if HasPlaceholder(out) {
+			return "", nil
+		}
+		return out, nil
+	}
+}
+
+// rewriteJSON round-trips v through JSON, passing every string to fn.
+func rewriteJSON(v any, fn func(path, s string) (string, error)) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	doc, err = walk(doc, "", fn)
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// walk visits the strings in a decoded JSON value in a stable order:
+// object keys sorted, arrays by index.
+func walk(v any, path string, fn func(path, s string) (string, error)) (any, error) {
+	switch t := v.(type) {
+	case string:
+		return fn(path, t)
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			out, err := walk(t[k], p, fn)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = out
+		}
+		return t, nil
+	case []any:
+		for i := range t {
+			out, err := walk(t[i], path+"["+strconv.Itoa(i)+"]", fn)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = out
+		}
+		return t, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,147 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedactor(t *testing.T, cfg Config) *Redactor {
+	t.Helper()
+	r, err := New(cfg, "/Users/alice", []string{"alice-mbp.local", "alice-mbp", "", "x"})
+	require.NoError(t, err)
+	return r
+}
+
+func TestRedactor_String(t *testing.T) {
+	r := newTestRedactor(t, Config{})
+	for in, want := range map[string]string{
+		"alice@example.com":                     "<redacted:email>",
+		"Alice <alice@example.com>":             "Alice <<redacted:email>>",
+		"/Users/alice/Library/Fonts":            "~/Library/Fonts",
+		"/Users/alice":                          "~",
+		"/Users/alicebob/bin":                   "/Users/alicebob/bin",
+		"alice-mbp.local":                       "<redacted:hostname>",
+		"ssh alice-mbp -p 22":                   "ssh <redacted:hostname> -p 22",
+		"alice-mbpx":                            "alice-mbpx",
+		"https://git.acme.internal/me/dots":     "https://git.acme.internal/me/dots",
+		"https://git.acme.internal/me/dots.git": "<redacted:repo>",
+		"git@git.acme.internal:me/dots.git":     "<redacted:repo>",
+		"git@github.com:alice/dotfiles.git":     "git@github.com:alice/dotfiles.git",
+		"https://github.com/alice/dotfiles":     "https://github.com/alice/dotfiles",
+		"ssh://git@github.com/alice/dotfiles":   "ssh://git@github.com/alice/dotfiles",
+		"ripgrep":                               "ripgrep",
+		"":                                      "",
+	} {
+		got, _ := r.String(in)
+		assert.Equal(t, want, got, in)
+	}
+}
+
+func TestRedactor_ConfigRules(t *testing.T) {
+	r := newTestRedactor(t, Config{
+		Disable:      []string{RuleEmail},
+		PrivateRepos: []string{"github.com/acme/"},
+		Patterns:     []Pattern{{Name: "token", Regex: `ghp_[A-Za-z0-9]+`}},
+	})
+	got, rule := r.String("https://github.com/acme/infra")
+	assert.Equal(t, "<redacted:repo>", got)
+	assert.Equal(t, RuleRepo, rule)
+	got, _ = r.String("https://github.com/acmecorp/infra")
+	assert.Equal(t, "https://github.com/acmecorp/infra", got)
+	got, rule = r.String("export GITHUB_TOKEN=ghp_abc123")
+	assert.Equal(t, "export GITHUB_TOKEN=<redacted:token>", got)
+	assert.Equal(t, "token", rule)
+	got, _ = r.String("alice@example.com")
+	assert.Equal(t, "alice@example.com", got)
+
+	for name, cfg := range map[string]Config{
+		"unknown rule":     {Disable: []string{"phone"}},
+		"bad pattern":      {Patterns: []Pattern{{Name: "x", Regex: "("}}},
+		"bad pattern name": {Patterns: []Pattern{{Name: "My Token", Regex: "x"}}},
+		"built-in name":    {Patterns: []Pattern{{Name: RuleEmail, Regex: "x"}}},
+	} {
+		_, err := New(cfg, "/Users/alice", nil)
+		assert.Error(t, err, name)
+	}
+}
+
+type sample struct {
+	Hostname string            `json:"hostname"`
+	Git      map[string]string `json:"git"`
+	Items    []string          `json:"items"`
+	Count    int               `json:"count"`
+}
+
+func TestRedactor_RedactAndRestore(t *testing.T) {
+	r := newTestRedactor(t, Config{})
+	v := sample{
+		Hostname: "alice-mbp",
+		Git:      map[string]string{"user_email": "alice@example.com", "user_name": "Alice"},
+		Items:    []string{"/Users/alice/bin/sync", "git"},
+		Count:    3,
+	}
+	findings, err := r.Redact(&v)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{Path: "git.user_email", Rule: RuleEmail, Before: "alice@example.com", After: "<redacted:email>"},
+		{Path: "hostname", Rule: RuleHostname, Before: "alice-mbp", After: "<redacted:hostname>"},
+		{Path: "items[0]", Rule: RuleHome, Before: "/Users/alice/bin/sync", After: "~/bin/sync"},
+	}, findings)
+	assert.Equal(t, sample{
+		Hostname: "<redacted:hostname>",
+		Git:      map[string]string{"user_email": "<redacted:email>", "user_name": "Alice"},
+		Items:    []string{"~/bin/sync", "git"},
+		Count:    3,
+	}, v)
+
+	var asked []string
+	require.NoError(t, Restore(&v, func(path, value string) (string, error) {
+		asked = append(asked, path+"="+value)
+		if path == "git.user_email" {
+			return "alice@work.example", nil
+		}
+		return value, nil // still redacted: cleared
+	}))
+	assert.Equal(t, []string{"git.user_email=<redacted:email>", "hostname=<redacted:hostname>"}, asked)
+	assert.Equal(t, "alice@work.example", v.Git["user_email"])
+	assert.Empty(t, v.Hostname)
+}
+
+func TestRestoreJSON(t *testing.T) {
+	data := []byte(`{"packages":["git"]}`)
+	out, err := RestoreJSON(data, func(string, string) (string, error) {
+		t.Fatal("no placeholders, no prompts")
+		return "", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, data, out)
+
+	out, err = RestoreJSON([]byte(`{"git":{"email":"<redacted:email>"}}`), func(path, value string) (string, error) {
+		return "me@example.com", nil
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"git":{"email":"me@example.com"}}`, string(out))
+}
+
+func TestLoadConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, Config{}, cfg)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".openboot"), 0700))
+	require.NoError(t, os.WriteFile(ConfigPath(), []byte(`{"disable":["home"],"patterns":[{"name":"token","regex":"tok_\\w+"}]}`), 0600))
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Disable: []string{"home"}, Patterns: []Pattern{{Name: "token", Regex: `tok_\w+`}}}, cfg)
+
+	require.NoError(t, os.WriteFile(ConfigPath(), []byte(`{"disable":`), 0600))
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// runOutput runs a read-only lookup command and returns its stdout. Tests
+// replace it to avoid invoking `security` or `op`.
+var runOutput = system.RunCommandOutputContext
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvProvider reads secrets from environment variables:
+// secret://env/NPM_TOKEN.
+type EnvProvider struct{}
+
+// Resolve returns the value of the environment variable named path.
+func (EnvProvider) Resolve(_ context.Context, path string) (string, error) {
+	if !envNameRe.MatchString(path) {
+		return "", fmt.Errorf("invalid environment variable name %q", path)
+	}
+	v, ok := os.LookupEnv(path)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", path)
+	}
+	return v, nil
+}
+
+// FileProvider reads secrets from local files. Paths are relative to the
+// home directory (secret://file/.config/npm-token) unless they start with a
+// second slash (secret://file//etc/openboot/token). A single trailing newline
+// is stripped so `echo token > file` works as expected.
+type FileProvider struct{}
+
+// Resolve returns the contents of the file at path.
+func (FileProvider) Resolve(_ context.Context, path string) (string, error) {
+	full := path
+	if !strings.HasPrefix(path, "/") {
+		home, err := system.HomeDir()
+		if err != nil {
+			return "", fmt.Errorf("home dir: %w", err)
+		}
+		full = filepath.Join(home, strings.TrimPrefix(path, "~/"))
+	}
+	data, err := os.ReadFile(full) //nolint:gosec // path comes from the user's own config and is read, never executed
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("secret file %s does not exist", full)
+		}
+		return "", fmt.Errorf("read secret file: %w", err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
+
+// KeychainProvider reads generic passwords from the macOS login keychain via
+// `security find-generic-password`. The path is the item's service name,
+// optionally followed by /account: secret://keychain/github.com/alice.
+type KeychainProvider struct{}
+
+// Resolve returns the password stored for the given service and account.
+func (KeychainProvider) Resolve(ctx context.Context, path string) (string, error) {
+	service, account, _ := strings.Cut(path, "/")
+	args := []string{"find-generic-password", "-s", service}
+	if account != "" {
+		args = append(args, "-a", account)
+	}
+	args = append(args, "-w")
+	out, err := runOutput(ctx, "security", args...)
+	if err != nil {
+		return "", fmt.Errorf("keychain item %q not found: %w", path, err)
+	}
+	return out, nil
+}
+
+// CommandProvider delegates to an external secrets CLI. Args maps the
+// reference path to the command's arguments.
+type CommandProvider struct {
+	Command string
+	Args    func(path string) []string
+}
+
+// Resolve runs the command and returns its trimmed stdout.
+func (c CommandProvider) Resolve(ctx context.Context, path string) (string, error) {
+	out, err := runOutput(ctx, c.Command, c.Args(path)...)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", c.Command, err)
+	}
+	return out, nil
+}
+
+// OnePasswordProvider returns a CommandProvider for the 1Password CLI:
+// secret://op/vault/item/field runs `op read op://vault/item/field`.
+func OnePasswordProvider() CommandProvider {
+	return CommandProvider{
+		Command: "op",
+		Args:    func(path string) []string { return []string{"read", "op://" + path} },
+	}
+}
+
+// FakeProvider serves secrets from an in-memory map keyed by path. It
+// records every path it was asked for so tests can assert that resolution
+// happened (or, in dry-run, did not).
+type FakeProvider struct {
+	mu      sync.Mutex
+	Values  map[string]string
+	Lookups []string
+}
+
+// NewFakeProvider returns a FakeProvider serving values.
+func NewFakeProvider(values map[string]string) *FakeProvider {
+	return &FakeProvider{Values: values}
+}
+
+// Resolve returns the canned value for path.
+func (f *FakeProvider) Resolve(_ context.Context, path string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Lookups = append(f.Lookups, path)
+	v, ok := f.Values[path]
+	if !ok {
+		return "", fmt.Errorf("no fake secret at %q", path)
+	}
+	return v, nil
+}
@@ -0,0 +1,194 @@
+// Package secrets resolves secret://provider/path references at apply time.
+//
+// Configs published to openboot.dev must never carry credentials, so a config
+// names where a secret lives instead of what it is:
+//
+//	secret://env/NPM_TOKEN
+//	secret://file/.config/openboot/github-pat
+//	secret://keychain/github.com/alice
+//	secret://op/Private/npm/token
+//
+// References are resolved on the machine being set up, immediately before the
+// value is needed. Every resolved value is registered with the logging package
+// so it is masked in slog output, and nothing in this package writes a
+// resolved value anywhere.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openbootdotdev/openboot/internal/logging"
+)
+
+// Scheme prefixes every secret reference.
+const Scheme = "secret://"
+
+// referenceRe matches a reference embedded anywhere in a string. The path
+// character set is deliberately narrow: references usually sit inside shell
+// scripts, and stopping at quotes, whitespace and shell metacharacters keeps
+// `echo "secret://env/X"` from swallowing the closing quote.
+var referenceRe = regexp.MustCompile(`secret://([a-z][a-z0-9-]*)/([A-Za-z0-9._~/@:+=%,-]+)`)
+
+// Ref is a parsed secret reference.
+type Ref struct {
+	Provider string
+	Path     string
+}
+
+// String returns the reference in secret://provider/path form.
+func (r Ref) String() string {
+	return Scheme + r.Provider + "/" + r.Path
+}
+
+// Provider fetches the secret stored at path. Implementations must not log
+// or print the value they return.
+type Provider interface {
+	Resolve(ctx context.Context, path string) (string, error)
+}
+
+// IsReference reports whether s is exactly one secret reference.
+func IsReference(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// ContainsReference reports whether s contains at least one secret reference.
+func ContainsReference(s string) bool {
+	return referenceRe.MatchString(s)
+}
+
+// Parse parses s, which must consist of a single reference and nothing else.
+func Parse(s string) (Ref, error) {
+	if !strings.HasPrefix(s, Scheme) {
+		return Ref{}, fmt.Errorf("not a secret reference: missing %s prefix", Scheme)
+	}
+	m := referenceRe.FindStringSubmatch(s)
+	if m == nil || m[0] != s {
+		return Ref{}, fmt.Errorf("invalid secret reference %q: want secret://provider/path", s)
+	}
+	return Ref{Provider: m[1], Path: m[2]}, nil
+}
+
+// FindReferences returns every distinct reference in s, in order of first
+// appearance.
+func FindReferences(s string) []Ref {
+	var refs []Ref
+	seen := make(map[string]bool)
+	for _, m := range referenceRe.FindAllStringSubmatch(s, -1) {
+		if seen[m[0]] {
+			continue
+		}
+		seen[m[0]] = true
+		refs = append(refs, Ref{Provider: m[1], Path: m[2]})
+	}
+	return refs
+}
+
+// Resolver maps provider names to providers.
+type Resolver struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver with the built-in providers registered:
+// env, file, keychain, and op (1Password CLI).
+func NewResolver() *Resolver {
+	r := &Resolver{providers: make(map[string]Provider)}
+	r.Register("env", EnvProvider{})
+	r.Register("file", FileProvider{})
+	r.Register("keychain", KeychainProvider{})
+	r.Register("op", OnePasswordProvider())
+	return r
+}
+
+// Register adds or replaces the provider for name.
+func (r *Resolver) Register(name string, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = p
+}
+
+// Providers returns the registered provider names, sorted.
+func (r *Resolver) Providers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namesLocked()
+}
+
+// Check verifies that every reference in s names a registered provider,
+// without resolving anything. Dry runs use it to catch typos early.
+func (r *Resolver) Check(s string) error {
+	for _, ref := range FindReferences(s) {
+		if _, err := r.provider(ref.Provider); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resolve fetches the value behind ref and registers it for log redaction.
+// Errors name the reference, never the value.
+func (r *Resolver) Resolve(ctx context.Context, ref Ref) (string, error) {
+	p, err := r.provider(ref.Provider)
+	if err != nil {
+		return "", err
+	}
+	v, err := p.Resolve(ctx, ref.Path)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	if v == "" {
+		return "", fmt.Errorf("resolve %s: secret is empty", ref)
+	}
+	logging.RegisterSecret(v)
+	return v, nil
+}
+
+// Replace calls fn for every reference in s and substitutes its return
+// value. Each distinct reference is resolved once.
+func (r *Resolver) Replace(ctx context.Context, s string, fn func(ref Ref, value string) string) (string, error) {
+	refs := FindReferences(s)
+	if len(refs) == 0 {
+		return s, nil
+	}
+	values := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		v, err := r.Resolve(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		values[ref.String()] = fn(ref, v)
+	}
+	return referenceRe.ReplaceAllStringFunc(s, func(m string) string {
+		return values[m]
+	}), nil
+}
+
+// ResolveString returns s with every reference replaced by its value.
+func (r *Resolver) ResolveString(ctx context.Context, s string) (string, error) {
+	return r.Replace(ctx, s, func(_ Ref, value string) string { return value })
+}
+
+func (r *Resolver) provider(name string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown secret provider %q (available: %s)", name, strings.Join(r.namesLocked(), ", "))
+	}
+	return p, nil
+}
+
+func (r *Resolver) namesLocked() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,157 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openbootdotdev/openboot/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeResolver(values map[string]string) (*Resolver, *FakeProvider) {
+	r := NewResolver()
+	fake := NewFakeProvider(values)
+	r.Register("fake", fake)
+	return r, fake
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Ref
+		wantErr bool
+	}{
+		{name: "env", in: "secret://env/NPM_TOKEN", want: Ref{Provider: "env", Path: "NPM_TOKEN"}},
+		{name: "nested path", in: "secret://op/Private/npm/token", want: Ref{Provider: "op", Path: "Private/npm/token"}},
+		{name: "absolute file", in: "secret://file//etc/token", want: Ref{Provider: "file", Path: "/etc/token"}},
+		{name: "missing scheme", in: "env/NPM_TOKEN", wantErr: true},
+		{name: "missing path", in: "secret://env/", wantErr: true},
+		{name: "trailing text", in: "secret://env/X and more", wantErr: true},
+		{name: "uppercase provider", in: "secret://ENV/X", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.False(t, IsReference(tt.in))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String())
+		})
+	}
+}
+
+func TestFindReferences_StopsAtShellSyntax(t *testing.T) {
+	s := `npm config set //registry.npmjs.org/:_authToken="secret://env/NPM_TOKEN"; echo secret://env/NPM_TOKEN|cat`
+	refs := FindReferences(s)
+	require.Len(t, refs, 1, "duplicate references are reported once")
+	assert.Equal(t, Ref{Provider: "env", Path: "NPM_TOKEN"}, refs[0])
+	assert.True(t, ContainsReference(s))
+	assert.False(t, ContainsReference("brew install git"))
+}
+
+func TestResolveString(t *testing.T) {
+	r, fake := fakeResolver(map[string]string{"npm": "npm_abcdef123"})
+
+	got, err := r.ResolveString(context.Background(), "token=secret://fake/npm twice=secret://fake/npm")
+	require.NoError(t, err)
+	assert.Equal(t, "token=npm_abcdef123 twice=npm_abcdef123", got)
+	assert.Equal(t, []string{"npm"}, fake.Lookups, "each distinct reference resolves once")
+}
+
+func TestResolve_RegistersValueForLogRedaction(t *testing.T) {
+	r, _ := fakeResolver(map[string]string{"pat": "ghp_registered_value"})
+
+	_, err := r.Resolve(context.Background(), Ref{Provider: "fake", Path: "pat"})
+	require.NoError(t, err)
+	assert.Equal(t, "clone <redacted>", logging.RedactString("clone ghp_registered_value"))
+}
+
+func TestResolve_Errors(t *testing.T) {
+	r, _ := fakeResolver(map[string]string{"empty": ""})
+
+	_, err := r.Resolve(context.Background(), Ref{Provider: "vault", Path: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown secret provider "vault"`)
+
+	_, err = r.Resolve(context.Background(), Ref{Provider: "fake", Path: "empty"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret is empty")
+
+	_, err = r.ResolveString(context.Background(), "x secret://fake/missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret://fake/missing")
+}
+
+func TestCheck_DoesNotResolve(t *testing.T) {
+	r, fake := fakeResolver(nil)
+
+	assert.NoError(t, r.Check("echo secret://fake/anything"))
+	assert.Error(t, r.Check("echo secret://nope/anything"))
+	assert.Empty(t, fake.Lookups)
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("OPENBOOT_TEST_SECRET", "from-env")
+
+	v, err := EnvProvider{}.Resolve(context.Background(), "OPENBOOT_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", v)
+
+	_, err = EnvProvider{}.Resolve(context.Background(), "OPENBOOT_TEST_UNSET_SECRET")
+	assert.ErrorContains(t, err, "not set")
+
+	_, err = EnvProvider{}.Resolve(context.Background(), "bad/name")
+	assert.ErrorContains(t, err, "invalid environment variable name")
+}
+
+func TestFileProvider(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".config", "token"), []byte("file-secret\n"), 0600))
+
+	v, err := FileProvider{}.Resolve(context.Background(), ".config/token")
+	require.NoError(t, err)
+	assert.Equal(t, "file-secret", v)
+
+	v, err = FileProvider{}.Resolve(context.Background(), filepath.Join(home, ".config", "token"))
+	require.NoError(t, err)
+	assert.Equal(t, "file-secret", v)
+
+	_, err = FileProvider{}.Resolve(context.Background(), ".config/missing")
+	assert.ErrorContains(t, err, "does not exist")
+}
+
+func TestKeychainAndCommandProviders(t *testing.T) {
+	var calls [][]string
+	orig := runOutput
+	runOutput = func(_ context.Context, name string, args ...string) (string, error) {
+		calls = append(calls, append([]string{name}, args...))
+		if name == "op" {
+			return "", errors.New("not signed in")
+		}
+		return "keychain-secret", nil
+	}
+	t.Cleanup(func() { runOutput = orig })
+
+	v, err := KeychainProvider{}.Resolve(context.Background(), "github.com/alice")
+	require.NoError(t, err)
+	assert.Equal(t, "keychain-secret", v)
+
+	_, err = OnePasswordProvider().Resolve(context.Background(), "Private/npm/token")
+	assert.ErrorContains(t, err, "not signed in")
+
+	assert.Equal(t, [][]string{
+		{"security", "find-generic-password", "-s", "github.com", "-a", "alice", "-w"},
+		{"op", "read", "op://Private/npm/token"},
+	}, calls)
+}
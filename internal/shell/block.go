@@ -0,0 +1,36 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openbootdotdev/openboot/internal/managedblock"
+)
+
+// upsertBlock writes body as the named managed block in path; see
+// managedblock.Upsert.
+func upsertBlock(path, name, body string, dryRun bool) error {
+	_, err := managedblock.Upsert(path, name, body, dryRun)
+	return err
+}
+
+// writeFileAtomic writes data to path via a temp file and rename, creating
+// the parent directory when missing. Callers report dry runs themselves.
+func writeFileAtomic(path string, data []byte, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("rename %s: %w", path, err)
+	}
+	return nil
+}
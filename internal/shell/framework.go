@@ -0,0 +1,374 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/managedblock"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// Framework is a shell plugin manager openboot can install and configure.
+// Oh-My-Zsh, zinit and prezto run under zsh; fisher runs under fish. Bash has
+// no framework — a bash config only carries the starship prompt. Capturing a
+// framework's setup lives in internal/snapshot.
+type Framework interface {
+	// Name is the RemoteShellConfig.Framework value that selects it.
+	Name() string
+	// Shell is the shell the framework runs under.
+	Shell() string
+	// Installed reports whether the framework is present under home.
+	Installed(home string) bool
+	// Restore installs the framework when missing and applies theme and
+	// plugins. Theme and plugins must already be validated.
+	Restore(theme string, plugins []string, dryRun bool) error
+}
+
+// frameworks lists every supported framework.
+var frameworks = []Framework{ohMyZshFramework{}, preztoFramework{}, zinitFramework{}, fisherFramework{}}
+
+// LookupFramework returns the framework registered under name.
+func LookupFramework(name string) (Framework, bool) {
+	for _, fw := range frameworks {
+		if fw.Name() == name {
+			return fw, true
+		}
+	}
+	return nil, false
+}
+
+// gitRunner runs git for framework installs. fishRunner runs a fish -c
+// script. Both are vars so tests can record calls without the binaries.
+var (
+	gitRunner = func(args ...string) error {
+		return system.RunCommand("git", args...)
+	}
+	fishRunner = func(script string) error {
+		return system.RunCommand("fish", "-c", script)
+	}
+	lookPath = exec.LookPath
+)
+
+// rcPath returns the absolute rc file openboot manages for sh.
+func rcPath(home, sh string) string {
+	return filepath.Join(home, config.ShellRCFile(sh))
+}
+
+func readFileString(path string) string {
+	raw, err := os.ReadFile(path) //nolint:gosec // paths are fixed locations under the home directory
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Restore brings the local shell in line with cfg: it installs and
+// configures the framework, then wires up the starship prompt. A nil or
+// unmanaged cfg is a no-op.
+func Restore(cfg *config.RemoteShellConfig, dryRun bool) error {
+	if !cfg.Managed() {
+		return nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("restore shell: %w", err)
+	}
+	if name := cfg.EffectiveFramework(); name != "" {
+		fw, ok := LookupFramework(name)
+		if !ok {
+			return fmt.Errorf("restore shell: unknown framework %q", name)
+		}
+		if err := fw.Restore(cfg.Theme, cfg.Plugins, dryRun); err != nil {
+			return fmt.Errorf("restore %s: %w", name, err)
+		}
+	}
+	if cfg.Starship {
+		if err := restoreStarship(cfg.EffectiveShell(), cfg.StarshipConfig, dryRun); err != nil {
+			return fmt.Errorf("restore starship: %w", err)
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Oh-My-Zsh
+// ---------------------------------------------------------------------------
+
+type ohMyZshFramework struct{}
+
+func (ohMyZshFramework) Name() string  { return config.FrameworkOhMyZsh }
+func (ohMyZshFramework) Shell() string { return config.ShellZsh }
+
+func (ohMyZshFramework) Installed(home string) bool {
+	return fileExists(filepath.Join(home, ".oh-my-zsh"))
+}
+
+func (ohMyZshFramework) Restore(theme string, plugins []string, dryRun bool) error {
+	return RestoreFromSnapshot(true, theme, plugins, dryRun)
+}
+
+// ---------------------------------------------------------------------------
+// Prezto
+// ---------------------------------------------------------------------------
+
+const preztoRepoURL = "https://github.com/sorin-ionescu/prezto.git"
+
+type preztoFramework struct{}
+
+func (preztoFramework) Name() string  { return config.FrameworkPrezto }
+func (preztoFramework) Shell() string { return config.ShellZsh }
+
+func (preztoFramework) Installed(home string) bool {
+	return fileExists(filepath.Join(home, ".zprezto", "init.zsh"))
+}
+
+// Restore clones prezto when missing, sources it from .zshrc unless the
+// user's own .zshrc already does, and sets modules and theme in a managed
+// .zpreztorc block. The block is appended, and zstyle keeps the last
+// definition, so it overrides the stock pmodule list.
+func (p preztoFramework) Restore(theme string, modules []string, dryRun bool) error {
+	home, err := system.HomeDir()
+	if err != nil {
+		return fmt.Errorf("restore prezto: %w", err)
+	}
+	dir := filepath.Join(home, ".zprezto")
+	if !p.Installed(home) {
+		if dryRun {
+			ui.DryRunMsg("Would clone %s to %s", preztoRepoURL, dir)
+		} else if err := gitRunner("clone", "--depth", "1", "--recursive", "--shallow-submodules", preztoRepoURL, dir); err != nil {
+			return fmt.Errorf("clone prezto: %w", err)
+		}
+	}
+
+	zshrc := rcPath(home, config.ShellZsh)
+	if !strings.Contains(managedblock.Strip(readFileString(zshrc), "Prezto"), ".zprezto/init.zsh") {
+		body := `[[ -s "${ZDOTDIR:-$HOME}/.zprezto/init.zsh" ]] && source "${ZDOTDIR:-$HOME}/.zprezto/init.zsh"`
+		if err := upsertBlock(zshrc, "Prezto", body, dryRun); err != nil {
+			return err
+		}
+	}
+
+	var sb strings.Builder
+	if len(modules) > 0 {
+		quoted := make([]string, len(modules))
+		for i, m := range modules {
+			quoted[i] = "'" + m + "'"
+		}
+		fmt.Fprintf(&sb, "zstyle ':prezto:load' pmodule %s\n", strings.Join(quoted, " "))
+	}
+	if theme != "" {
+		fmt.Fprintf(&sb, "zstyle ':prezto:module:prompt' theme '%s'\n", theme)
+	}
+	return upsertBlock(filepath.Join(home, ".zpreztorc"), "Restore", sb.String(), dryRun)
+}
+
+// ---------------------------------------------------------------------------
+// zinit
+// ---------------------------------------------------------------------------
+
+const zinitRepoURL = "https://github.com/zdharma-continuum/zinit.git"
+
+// zinitPluginRe mirrors snapshot.zinitPluginRe.
+var zinitPluginRe = regexp.MustCompile(`(?m)^[ \t]*(?:zinit|zi)[ \t]+(?:light|load)[ \t]+([^\s;#]+)`)
+
+type zinitFramework struct{}
+
+func (zinitFramework) Name() string  { return config.FrameworkZinit }
+func (zinitFramework) Shell() string { return config.ShellZsh }
+
+func zinitDir(home string) string {
+	return filepath.Join(home, ".local", "share", "zinit", "zinit.git")
+}
+
+func (zinitFramework) Installed(home string) bool {
+	return fileExists(filepath.Join(zinitDir(home), "zinit.zsh")) ||
+		fileExists(filepath.Join(home, ".zinit", "bin", "zinit.zsh"))
+}
+
+// Restore clones zinit when missing and writes a managed .zshrc block that
+// sources it and loads each plugin the user's own .zshrc doesn't already.
+func (z zinitFramework) Restore(_ string, plugins []string, dryRun bool) error {
+	home, err := system.HomeDir()
+	if err != nil {
+		return fmt.Errorf("restore zinit: %w", err)
+	}
+	if !z.Installed(home) {
+		dir := zinitDir(home)
+		if dryRun {
+			ui.DryRunMsg("Would clone %s to %s", zinitRepoURL, dir)
+		} else {
+			if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+				return fmt.Errorf("create %s: %w", filepath.Dir(dir), err)
+			}
+			if err := gitRunner("clone", "--depth", "1", zinitRepoURL, dir); err != nil {
+				return fmt.Errorf("clone zinit: %w", err)
+			}
+		}
+	}
+
+	zshrc := rcPath(home, config.ShellZsh)
+	userRC := managedblock.Strip(readFileString(zshrc), "Zinit")
+	declared := make(map[string]bool)
+	for _, m := range zinitPluginRe.FindAllStringSubmatch(userRC, -1) {
+		declared[m[1]] = true
+	}
+
+	var sb strings.Builder
+	if !strings.Contains(userRC, "zinit.zsh") {
+		sb.WriteString(`source "${XDG_DATA_HOME:-$HOME/.local/share}/zinit/zinit.git/zinit.zsh"` + "\n")
+	}
+	for _, p := range plugins {
+		if !declared[p] {
+			fmt.Fprintf(&sb, "zinit light %s\n", p)
+		}
+	}
+	return upsertBlock(zshrc, "Zinit", sb.String(), dryRun)
+}
+
+// ---------------------------------------------------------------------------
+// fisher
+// ---------------------------------------------------------------------------
+
+const (
+	fisherSelf    = "jorgebucaran/fisher"
+	fisherRepoURL = "https://github.com/jorgebucaran/fisher.git"
+)
+
+type fisherFramework struct{}
+
+func (fisherFramework) Name() string  { return config.FrameworkFisher }
+func (fisherFramework) Shell() string { return config.ShellFish }
+
+func fishPluginsPath(home string) string {
+	return filepath.Join(home, ".config", "fish", "fish_plugins")
+}
+
+func (fisherFramework) Installed(home string) bool {
+	return fileExists(filepath.Join(home, ".config", "fish", "functions", "fisher.fish"))
+}
+
+func readFishPlugins(home string) []string {
+	var plugins []string
+	for _, line := range strings.Split(readFileString(fishPluginsPath(home)), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			plugins = append(plugins, line)
+		}
+	}
+	return plugins
+}
+
+// Restore adds the plugins to fish_plugins (keeping any already listed, since
+// fisher update removes whatever the file omits) and runs fisher update. When
+// fisher itself is missing it is bootstrapped from a fresh clone rather than
+// by piping the install script from the network into fish.
+func (f fisherFramework) Restore(_ string, plugins []string, dryRun bool) error {
+	home, err := system.HomeDir()
+	if err != nil {
+		return fmt.Errorf("restore fisher: %w", err)
+	}
+	if _, err := lookPath("fish"); err != nil && !dryRun {
+		return fmt.Errorf("fish is not installed; add it to your packages")
+	}
+
+	existing := readFishPlugins(home)
+	seen := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		seen[p] = true
+	}
+	merged := append([]string{}, existing...)
+	for _, p := range append([]string{fisherSelf}, plugins...) {
+		if !seen[p] {
+			seen[p] = true
+			merged = append(merged, p)
+		}
+	}
+	installed := f.Installed(home)
+	if installed && len(merged) == len(existing) {
+		return nil // nothing new to install
+	}
+
+	path := fishPluginsPath(home)
+	if dryRun {
+		ui.DryRunMsg("Would write %d plugin(s) to %s and run fisher update", len(merged), path)
+		return nil
+	}
+	if err := writeFileAtomic(path, []byte(strings.Join(merged, "\n")+"\n"), dryRun); err != nil {
+		return err
+	}
+
+	if installed {
+		if err := fishRunner("fisher update"); err != nil {
+			return fmt.Errorf("fisher update: %w", err)
+		}
+		return nil
+	}
+
+	tmp, err := os.MkdirTemp("", "openboot-fisher-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp) //nolint:errcheck // best-effort temp cleanup
+	if err := gitRunner("clone", "--depth", "1", fisherRepoURL, tmp); err != nil {
+		return fmt.Errorf("clone fisher: %w", err)
+	}
+	script := fmt.Sprintf("source '%s' && fisher update", filepath.Join(tmp, "functions", "fisher.fish"))
+	if err := fishRunner(script); err != nil {
+		return fmt.Errorf("bootstrap fisher: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// starship
+// ---------------------------------------------------------------------------
+
+func starshipInitLine(sh string) string {
+	if sh == config.ShellFish {
+		return "starship init fish | source"
+	}
+	return fmt.Sprintf(`eval "$(starship init %s)"`, sh)
+}
+
+// restoreStarship writes starship.toml when toml is set and adds the init
+// line to sh's rc file unless the user's own rc already has one. The
+// starship binary itself comes from packages; its absence only warns.
+func restoreStarship(sh, toml string, dryRun bool) error {
+	home, err := system.HomeDir()
+	if err != nil {
+		return fmt.Errorf("restore starship: %w", err)
+	}
+
+	if toml != "" {
+		path := filepath.Join(home, config.StarshipConfigFile)
+		if readFileString(path) != toml {
+			if dryRun {
+				ui.DryRunMsg("Would write %s", path)
+			} else if err := writeFileAtomic(path, []byte(toml), dryRun); err != nil {
+				return err
+			}
+		}
+	}
+
+	rc := rcPath(home, sh)
+	if !strings.Contains(managedblock.Strip(readFileString(rc), "Starship"), "starship init") {
+		if err := upsertBlock(rc, "Starship", starshipInitLine(sh), dryRun); err != nil {
+			return err
+		}
+	}
+
+	if _, err := lookPath("starship"); err != nil && !dryRun {
+		ui.Warn("starship is not on PATH; add it to your packages so the prompt loads")
+	}
+	return nil
+}
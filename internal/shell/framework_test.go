@@ -0,0 +1,218 @@
+package shell
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+// frameworkCalls records what the framework seams were asked to run.
+type frameworkCalls struct {
+	git  [][]string
+	fish []string
+}
+
+// withFrameworkFakes swaps gitRunner, fishRunner and lookPath. A git clone
+// creates the file the framework's Installed check looks for, so a second
+// Restore exercises the already-installed path. onPath lists the binaries
+// lookPath should find.
+func withFrameworkFakes(t *testing.T, onPath ...string) *frameworkCalls {
+	t.Helper()
+	calls := &frameworkCalls{}
+	origGit, origFish, origLook := gitRunner, fishRunner, lookPath
+	t.Cleanup(func() { gitRunner, fishRunner, lookPath = origGit, origFish, origLook })
+
+	gitRunner = func(args ...string) error {
+		calls.git = append(calls.git, args)
+		dest := args[len(args)-1]
+		for _, marker := range []string{"init.zsh", "zinit.zsh", filepath.Join("functions", "fisher.fish")} {
+			_ = os.MkdirAll(filepath.Dir(filepath.Join(dest, marker)), 0755)
+			_ = os.WriteFile(filepath.Join(dest, marker), nil, 0644)
+		}
+		return nil
+	}
+	fishRunner = func(script string) error {
+		calls.fish = append(calls.fish, script)
+		return nil
+	}
+	lookPath = func(name string) (string, error) {
+		for _, p := range onPath {
+			if p == name {
+				return "/usr/local/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	return calls
+}
+
+func readHome(t *testing.T, home, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(home, rel))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRestore_Zinit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := withFrameworkFakes(t)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"),
+		[]byte("zinit light zsh-users/zsh-autosuggestions\n"), 0644))
+
+	cfg := &config.RemoteShellConfig{
+		Framework: config.FrameworkZinit,
+		Plugins:   []string{"zsh-users/zsh-autosuggestions", "zdharma-continuum/fast-syntax-highlighting"},
+	}
+	require.NoError(t, Restore(cfg, false))
+
+	require.Len(t, calls.git, 1)
+	assert.Equal(t, []string{"clone", "--depth", "1", zinitRepoURL, filepath.Join(home, ".local", "share", "zinit", "zinit.git")}, calls.git[0])
+	zshrc := readHome(t, home, ".zshrc")
+	assert.Contains(t, zshrc, "zinit.git/zinit.zsh")
+	assert.Contains(t, zshrc, "zinit light zdharma-continuum/fast-syntax-highlighting")
+	assert.Equal(t, 1, strings.Count(zshrc, "zsh-users/zsh-autosuggestions"), "plugin the user already loads is not repeated")
+
+	// Idempotent: no second clone, block unchanged.
+	require.NoError(t, Restore(cfg, false))
+	assert.Len(t, calls.git, 1)
+	assert.Equal(t, zshrc, readHome(t, home, ".zshrc"))
+}
+
+func TestRestore_Prezto(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := withFrameworkFakes(t)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zpreztorc"),
+		[]byte("zstyle ':prezto:load' pmodule 'environment'\n"), 0644))
+
+	err := Restore(&config.RemoteShellConfig{
+		Framework: config.FrameworkPrezto,
+		Theme:     "pure",
+		Plugins:   []string{"git", "syntax-highlighting"},
+	}, false)
+	require.NoError(t, err)
+
+	require.Len(t, calls.git, 1)
+	assert.Contains(t, calls.git[0], "--recursive")
+	assert.Contains(t, readHome(t, home, ".zshrc"), `source "${ZDOTDIR:-$HOME}/.zprezto/init.zsh"`)
+	rc := readHome(t, home, ".zpreztorc")
+	assert.True(t, strings.HasPrefix(rc, "zstyle ':prezto:load' pmodule 'environment'\n"), "user lines kept")
+	assert.Contains(t, rc, "zstyle ':prezto:load' pmodule 'git' 'syntax-highlighting'\n")
+	assert.Contains(t, rc, "zstyle ':prezto:module:prompt' theme 'pure'\n")
+}
+
+func TestRestore_FisherBootstrap(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := withFrameworkFakes(t, "fish")
+	pluginsPath := filepath.Join(home, ".config", "fish", "fish_plugins")
+	require.NoError(t, os.MkdirAll(filepath.Dir(pluginsPath), 0755))
+	require.NoError(t, os.WriteFile(pluginsPath, []byte("patrickf1/fzf.fish\n"), 0644))
+
+	err := Restore(&config.RemoteShellConfig{
+		Framework: config.FrameworkFisher,
+		Plugins:   []string{"ilancosman/tide@v6"},
+	}, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "patrickf1/fzf.fish\njorgebucaran/fisher\nilancosman/tide@v6\n", readHome(t, home, ".config/fish/fish_plugins"))
+	require.Len(t, calls.git, 1, "fisher is bootstrapped from a clone")
+	require.Len(t, calls.fish, 1)
+	assert.Contains(t, calls.fish[0], "fisher.fish' && fisher update")
+}
+
+func TestRestore_FisherInstalledUpToDate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := withFrameworkFakes(t, "fish")
+	fishDir := filepath.Join(home, ".config", "fish")
+	require.NoError(t, os.MkdirAll(filepath.Join(fishDir, "functions"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(fishDir, "functions", "fisher.fish"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(fishDir, "fish_plugins"), []byte("jorgebucaran/fisher\nilancosman/tide@v6\n"), 0644))
+
+	err := Restore(&config.RemoteShellConfig{Framework: config.FrameworkFisher, Plugins: []string{"ilancosman/tide@v6"}}, false)
+	require.NoError(t, err)
+	assert.Empty(t, calls.git)
+	assert.Empty(t, calls.fish, "nothing new means no fisher update")
+}
+
+func TestRestore_FisherRequiresFish(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	withFrameworkFakes(t)
+	err := Restore(&config.RemoteShellConfig{Framework: config.FrameworkFisher}, false)
+	assert.ErrorContains(t, err, "fish is not installed")
+}
+
+func TestRestore_StarshipPerShell(t *testing.T) {
+	for _, tt := range []struct {
+		shell, rc, line string
+	}{
+		{config.ShellZsh, ".zshrc", `eval "$(starship init zsh)"`},
+		{config.ShellBash, ".bashrc", `eval "$(starship init bash)"`},
+		{config.ShellFish, ".config/fish/config.fish", "starship init fish | source"},
+	} {
+		t.Run(tt.shell, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			withFrameworkFakes(t, "starship")
+
+			cfg := &config.RemoteShellConfig{Shell: tt.shell, Starship: true, StarshipConfig: "add_newline = false\n"}
+			require.NoError(t, Restore(cfg, false))
+			require.NoError(t, Restore(cfg, false))
+
+			rc := readHome(t, home, tt.rc)
+			assert.Equal(t, 1, strings.Count(rc, tt.line))
+			assert.Equal(t, "add_newline = false\n", readHome(t, home, ".config/starship.toml"))
+		})
+	}
+}
+
+func TestRestore_StarshipRespectsUserInit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	withFrameworkFakes(t, "starship")
+	original := "eval \"$(starship init zsh)\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), []byte(original), 0644))
+
+	require.NoError(t, Restore(&config.RemoteShellConfig{Starship: true}, false))
+	assert.Equal(t, original, readHome(t, home, ".zshrc"))
+	assert.NoFileExists(t, filepath.Join(home, ".config", "starship.toml"), "no config given, none written")
+}
+
+func TestRestore_DryRunWritesNothing(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := withFrameworkFakes(t)
+
+	err := Restore(&config.RemoteShellConfig{
+		Framework:      config.FrameworkZinit,
+		Plugins:        []string{"zsh-users/zsh-autosuggestions"},
+		Starship:       true,
+		StarshipConfig: "x = 1\n",
+	}, true)
+	require.NoError(t, err)
+	assert.Empty(t, calls.git)
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc"))
+	assert.NoFileExists(t, filepath.Join(home, ".config", "starship.toml"))
+}
+
+func TestRestore_RejectsInvalidConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	calls := withFrameworkFakes(t)
+	err := Restore(&config.RemoteShellConfig{Framework: config.FrameworkZinit, Plugins: []string{"$(evil)"}}, false)
+	assert.ErrorContains(t, err, "invalid zinit plugin")
+	assert.Empty(t, calls.git)
+}
+
+func TestRestore_UnmanagedIsNoop(t *testing.T) {
+	assert.NoError(t, Restore(nil, false))
+	assert.NoError(t, Restore(&config.RemoteShellConfig{Shell: config.ShellBash}, false))
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/httputil"
+	"github.com/openbootdotdev/openboot/internal/managedblock"
 	"github.com/openbootdotdev/openboot/internal/system"
 	"github.com/openbootdotdev/openboot/internal/ui"
 )
@@ -192,10 +193,12 @@ func SetDefaultShell(dryRun bool) error {
 	return cmd.Run()
 }
 
-const restoreBlockStart = "# >>> OpenBoot-Restore"
-const restoreBlockEnd = "# <<< OpenBoot-Restore"
+// restoreBlockName is the managed .zshrc block holding the oh-my-zsh theme
+// and plugins.
+const restoreBlockName = "Restore"
 
-var restoreBlockRe = regexp.MustCompile(`(?s)# >>> OpenBoot-Restore\n.*?# <<< OpenBoot-Restore\n?`)
+const restoreBlockStart = "# >>> OpenBoot-" + restoreBlockName
+const restoreBlockEnd = "# <<< OpenBoot-" + restoreBlockName
 
 func buildRestoreBlock(theme string, plugins []string) (string, error) {
 	if err := validateShellIdentifier(theme, "ZSH_THEME"); err != nil {
@@ -208,15 +211,13 @@ func buildRestoreBlock(theme string, plugins []string) (string, error) {
 	}
 
 	var sb strings.Builder
-	sb.WriteString(restoreBlockStart + "\n")
 	if theme != "" {
 		fmt.Fprintf(&sb, "ZSH_THEME=\"%s\"\n", theme)
 	}
 	if len(plugins) > 0 {
 		fmt.Fprintf(&sb, "plugins=(%s)\n", strings.Join(plugins, " "))
 	}
-	sb.WriteString(restoreBlockEnd + "\n")
-	return sb.String(), nil
+	return managedblock.Render(restoreBlockName, sb.String()), nil
 }
 
 var (
@@ -239,20 +240,17 @@ func patchZshrcBlock(zshrcPath, theme string, plugins []string, dryRun bool) err
 	}
 	content := string(raw)
 
-	if restoreBlockRe.MatchString(content) {
-		content = restoreBlockRe.ReplaceAllString(content, block)
-	} else {
+	// Without a block yet, drop the stock ZSH_THEME/plugins lines the
+	// block supersedes so they aren't defined twice.
+	if !managedblock.Has(content, restoreBlockName) {
 		if theme != "" {
 			content = looseThemeRe.ReplaceAllString(content, "")
 		}
 		if len(plugins) > 0 {
 			content = loosePluginsRe.ReplaceAllString(content, "")
 		}
-		if len(content) > 0 && content[len(content)-1] != '\n' {
-			content = content + "\n"
-		}
-		content = content + block
 	}
+	content = managedblock.Replace(content, restoreBlockName, block)
 
 	tmpPath := zshrcPath + ".tmp"
 	if err := os.WriteFile(tmpPath, []byte(content), 0600); err != nil { //nolint:gosec // path derived from os.UserHomeDir, not user input
@@ -336,7 +334,7 @@ func cloneExternalPlugins(plugins []string, dryRun bool) error {
 }
 
 // zshrcPluginsRe extracts the names inside a plugins=(...) declaration from a
-// .zshrc. Mirrors snapshot.zshPluginsRe but tolerates leading whitespace so it
+// .zshrc. Mirrors zshPluginsRe but tolerates leading whitespace so it
 // also matches indented declarations in user-authored dotfiles.
 var zshrcPluginsRe = regexp.MustCompile(`(?m)^\s*plugins=\((?s:(.*?))\)`)
 
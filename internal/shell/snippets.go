@@ -0,0 +1,53 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/managedblock"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// ApplySnippets writes s as the managed snippets block in sh's rc file,
+// updating the block in place when it already exists. An empty s removes the
+// block. Any snippets block left in another shell's rc file — from a config
+// that targeted a different shell — is removed too, so exactly one rc file
+// carries the current snippets.
+func ApplySnippets(sh string, s *config.ShellSnippets, dryRun bool) error {
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("apply shell snippets: %w", err)
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return fmt.Errorf("apply shell snippets: %w", err)
+	}
+	if sh == "" {
+		sh = config.ShellZsh
+	}
+	for _, other := range []string{config.ShellZsh, config.ShellBash, config.ShellFish} {
+		body := ""
+		if other == sh {
+			body = s.Render(sh)
+		}
+		if err := upsertBlock(rcPath(home, other), config.SnippetsBlockName, body, dryRun); err != nil {
+			return fmt.Errorf("apply shell snippets: %w", err)
+		}
+	}
+	return nil
+}
+
+// HasSnippets reports whether any shell's rc file carries a snippets block.
+func HasSnippets() bool {
+	home, err := system.HomeDir()
+	if err != nil {
+		return false
+	}
+	for _, sh := range []string{config.ShellZsh, config.ShellBash, config.ShellFish} {
+		data, err := os.ReadFile(rcPath(home, sh)) //nolint:gosec // the user's own rc file
+		if err == nil && managedblock.Has(string(data), config.SnippetsBlockName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func TestApplySnippets_UpdateInPlaceAndRemove(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), []byte("export EDITOR=vim\n"), 0644))
+
+	s := &config.ShellSnippets{Env: []config.ShellEnvVar{{Name: "GOPRIVATE", Value: "github.com/acme/*"}}}
+	require.NoError(t, ApplySnippets(config.ShellZsh, s, false))
+	assert.Equal(t, "export EDITOR=vim\n# >>> OpenBoot-Snippets\nexport GOPRIVATE=\"github.com/acme/*\"\n# <<< OpenBoot-Snippets\n",
+		readHome(t, home, ".zshrc"))
+
+	// The user adds lines after the block; an update keeps them and the
+	// block's position.
+	f, err := os.OpenFile(filepath.Join(home, ".zshrc"), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("alias ll='ls -l'\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s.Aliases = []config.ShellAlias{{Name: "k", Command: "kubectl"}}
+	require.NoError(t, ApplySnippets(config.ShellZsh, s, false))
+	assert.Equal(t, "export EDITOR=vim\n# >>> OpenBoot-Snippets\nexport GOPRIVATE=\"github.com/acme/*\"\nalias k='kubectl'\n# <<< OpenBoot-Snippets\nalias ll='ls -l'\n",
+		readHome(t, home, ".zshrc"))
+
+	assert.True(t, HasSnippets())
+
+	require.NoError(t, ApplySnippets(config.ShellZsh, nil, false))
+	assert.Equal(t, "export EDITOR=vim\nalias ll='ls -l'\n", readHome(t, home, ".zshrc"))
+	assert.False(t, HasSnippets())
+}
+
+func TestApplySnippets_MovesBetweenShells(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	s := &config.ShellSnippets{Path: []string{"$HOME/bin"}}
+
+	require.NoError(t, ApplySnippets(config.ShellBash, s, false))
+	assert.Contains(t, readHome(t, home, ".bashrc"), `export PATH="$HOME/bin:$PATH"`)
+
+	require.NoError(t, ApplySnippets(config.ShellFish, s, false))
+	assert.Contains(t, readHome(t, home, ".config/fish/config.fish"), `set -gx PATH "$HOME/bin" $PATH`)
+	assert.Empty(t, readHome(t, home, ".bashrc"), "old shell's block is removed")
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc"), "untouched shells get no file")
+}
+
+func TestApplySnippets_FollowsSymlinkedRC(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	target := filepath.Join(home, ".dotfiles", "zsh", ".zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0755))
+	require.NoError(t, os.WriteFile(target, []byte("# mine\n"), 0644))
+	require.NoError(t, os.Symlink(target, filepath.Join(home, ".zshrc")))
+
+	require.NoError(t, ApplySnippets(config.ShellZsh, &config.ShellSnippets{Path: []string{"/opt/bin"}}, false))
+
+	info, err := os.Lstat(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "link is kept")
+	assert.Contains(t, readHome(t, home, ".dotfiles/zsh/.zshrc"), "/opt/bin")
+}
+
+func TestApplySnippets_DryRunAndInvalid(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	require.NoError(t, ApplySnippets(config.ShellZsh, &config.ShellSnippets{Path: []string{"/opt/bin"}}, true))
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc"))
+
+	err := ApplySnippets(config.ShellZsh, &config.ShellSnippets{Env: []config.ShellEnvVar{{Name: "A", Value: "`id`"}}}, false)
+	assert.ErrorContains(t, err, "must not contain")
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc"))
+}
@@ -0,0 +1,166 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TrustedKey is a public key whose signatures are accepted.
+type TrustedKey struct {
+	Name string `json:"name"`
+	Key  string `json:"key"` // "ed25519:<base64>"
+}
+
+// Policy is the trusted key list in ~/.openboot/trusted_keys.json, and
+// whether installs require a signature from one of those keys.
+type Policy struct {
+	RequireSignature bool         `json:"require_signature,omitempty"`
+	Keys             []TrustedKey `json:"keys"`
+}
+
+// PolicyPath returns the path of the trusted key list.
+func PolicyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".openboot", "trusted_keys.json"), nil
+}
+
+// LoadPolicy reads the trusted key list. A missing file is an empty
+// policy; a malformed one is an error, so a typo cannot quietly switch
+// verification off.
+func LoadPolicy() (Policy, error) {
+	path, err := PolicyPath()
+	if err != nil {
+		return Policy{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Policy{}, nil
+		}
+		return Policy{}, fmt.Errorf("read trusted keys: %w", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Policy{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, k := range p.Keys {
+		if _, err := ParsePublicKey(k.Key); err != nil {
+			return Policy{}, fmt.Errorf("%s: key %q: %w", path, k.Name, err)
+		}
+	}
+	return p, nil
+}
+
+// SavePolicy writes p to PolicyPath.
+func SavePolicy(p Policy) error {
+	path, err := PolicyPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create openboot dir: %w", err)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal trusted keys: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write trusted keys: %w", err)
+	}
+	return nil
+}
+
+// Trust adds key under name, replacing a key of the same name.
+func (p *Policy) Trust(name, key string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("trusted key needs a name")
+	}
+	pub, err := ParsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	entry := TrustedKey{Name: name, Key: EncodePublicKey(pub)}
+	for i, k := range p.Keys {
+		if k.Name == name {
+			p.Keys[i] = entry
+			return nil
+		}
+	}
+	p.Keys = append(p.Keys, entry)
+	return nil
+}
+
+// Untrust removes the key called name and reports whether it was there.
+func (p *Policy) Untrust(name string) bool {
+	for i, k := range p.Keys {
+		if k.Name == name {
+			p.Keys = append(p.Keys[:i], p.Keys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Result describes the signature Inspect or Check found.
+type Result struct {
+	Signed  bool
+	KeyID   string // fingerprint of the signing key, when signed
+	Trusted string // name of the matching trusted key, or ""
+
+	key ed25519.PublicKey // the signing key, for Accept
+}
+
+// Inspect verifies data's signature, if it has one, without a policy: a
+// signature that does not match its content is an error, and whether the
+// key is trusted is left to Accept.
+func Inspect(data []byte) (Result, error) {
+	pub, err := Verify(data)
+	if err != nil {
+		return Result{}, err
+	}
+	if pub == nil {
+		return Result{}, nil
+	}
+	return Result{Signed: true, KeyID: KeyID(pub), key: pub}, nil
+}
+
+// Accept applies p to what Inspect found, naming the trusted key that
+// made the signature. An unsigned document, or one signed by a key not in
+// p, is an error only when p requires signatures.
+func (p Policy) Accept(res Result) (Result, error) {
+	if !res.Signed {
+		if p.RequireSignature {
+			return Result{}, ErrUnsigned
+		}
+		return Result{}, nil
+	}
+	res.Trusted = ""
+	for _, k := range p.Keys {
+		if trusted, err := ParsePublicKey(k.Key); err == nil && trusted.Equal(res.key) {
+			res.Trusted = k.Name
+			return res, nil
+		}
+	}
+	if p.RequireSignature {
+		return res, fmt.Errorf("%w (key %s)", ErrUntrusted, res.KeyID)
+	}
+	return res, nil
+}
+
+// Check verifies data against p: Inspect, then Accept. A signature that
+// does not match its content is always an error.
+func (p Policy) Check(data []byte) (Result, error) {
+	res, err := Inspect(data)
+	if err != nil {
+		return Result{}, err
+	}
+	return p.Accept(res)
+}
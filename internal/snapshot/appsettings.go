@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// exportAppDomain reads a defaults domain as an XML plist. It is a var so
+// tests can capture without a real preferences store.
+var exportAppDomain = func(ctx context.Context, domain string) (string, error) {
+	return system.RunCommandOutputContext(ctx, "defaults", "export", domain, "-")
+}
+
+// CaptureAppSettings captures the settings of the apps opted in with
+// `openboot app-settings enable`. Returns nil when no app is opted in or
+// none of them has settings on this Mac.
+func CaptureAppSettings() (*config.AppSettings, error) {
+	return captureAppSettings(context.Background())
+}
+
+func captureAppSettings(ctx context.Context) (*config.AppSettings, error) {
+	ids, err := config.EnabledAppSettings()
+	if err != nil {
+		return nil, err
+	}
+	return captureAppSettingsFor(ctx, ids)
+}
+
+// CaptureAppSettingsFor captures the settings of the given registry apps,
+// opted in or not. diff uses it to read the apps a reference carries.
+func CaptureAppSettingsFor(ids []string) (*config.AppSettings, error) {
+	return captureAppSettingsFor(context.Background(), ids)
+}
+
+func captureAppSettingsFor(ctx context.Context, ids []string) (*config.AppSettings, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	s := &config.AppSettings{}
+	for _, id := range ids {
+		spec, ok := config.LookupAppSettings(id)
+		if !ok {
+			continue
+		}
+		app := config.AppSettingsApp{App: id}
+		for _, f := range spec.Files {
+			data, err := os.ReadFile(config.AppSettingsPath(f, home)) //nolint:gosec // path comes from the embedded registry
+			if err != nil {
+				continue
+			}
+			app.Files = append(app.Files, config.AppSettingsRef{Target: f, Blob: s.Put(data)})
+		}
+		for _, d := range spec.Domains {
+			out, err := exportAppDomain(ctx, d)
+			if err != nil || emptyPlist(out) {
+				continue
+			}
+			app.Domains = append(app.Domains, config.AppSettingsRef{Target: d, Blob: s.Put([]byte(out))})
+		}
+		if len(app.Files)+len(app.Domains) > 0 {
+			s.Apps = append(s.Apps, app)
+		}
+	}
+	if s.Empty() {
+		return nil, nil
+	}
+	return s, nil
+}
+
+// emptyPlist reports whether a `defaults export` holds no keys, which is
+// what it prints for a domain that does not exist.
+func emptyPlist(out string) bool {
+	return !strings.Contains(out, "<key>")
+}
@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/snapshot/schema"
+)
+
+func stubExportAppDomain(t *testing.T, domains map[string]string) {
+	t.Helper()
+	orig := exportAppDomain
+	t.Cleanup(func() { exportAppDomain = orig })
+	exportAppDomain = func(_ context.Context, domain string) (string, error) {
+		out, ok := domains[domain]
+		if !ok {
+			return "", errors.New("no such domain")
+		}
+		return out, nil
+	}
+}
+
+func TestCaptureAppSettings_OnlyOptedInApps(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	stubExportAppDomain(t, map[string]string{
+		"com.googlecode.iterm2":   "<plist><dict><key>Default Bookmark Guid</key></dict></plist>",
+		"com.knollsoft.Rectangle": "<plist><dict><key>gapSize</key></dict></plist>",
+		"com.raycast.macos":       "<plist><dict/></plist>",
+	})
+	settings := filepath.Join(home, "Library/Application Support/Code/User/settings.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(settings), 0755))
+	require.NoError(t, os.WriteFile(settings, []byte(`{"editor.tabSize": 2}`), 0644))
+
+	s, err := CaptureAppSettings()
+	require.NoError(t, err)
+	assert.Nil(t, s, "nothing is captured before an app is opted in")
+
+	require.NoError(t, config.EnableAppSettings("vscode", "iterm2", "raycast", "warp"))
+	s, err = CaptureAppSettings()
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	require.NoError(t, s.Validate())
+
+	// raycast's domain is empty and warp has nothing on disk: both omitted.
+	assert.Equal(t, []string{"iterm2", "vscode"}, s.AppIDs())
+	vscode := s.Apps[1]
+	require.Len(t, vscode.Files, 1, "keybindings.json does not exist")
+	assert.Equal(t, "~/Library/Application Support/Code/User/settings.json", vscode.Files[0].Target)
+	data, err := s.Blob(vscode.Files[0].Blob)
+	require.NoError(t, err)
+	assert.Equal(t, `{"editor.tabSize": 2}`, string(data))
+}
+
+func TestSnapshotJSON_AppSettingsRoundTrip(t *testing.T) {
+	s := &config.AppSettings{}
+	s.Apps = []config.AppSettingsApp{{App: "rectangle", Domains: []config.AppSettingsRef{{Target: "com.knollsoft.Rectangle", Blob: s.Put([]byte("<plist/>"))}}}}
+	snap := &Snapshot{Version: schema.Current, AppSettings: s}
+
+	data, err := json.Marshal(snap)
+	require.NoError(t, err)
+	back, err := ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, s, back.AppSettings)
+}
@@ -0,0 +1,191 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// PackageInfo is what Homebrew reports about one installed formula or
+// cask. OnRequest and Pinned only apply to formulae.
+type PackageInfo struct {
+	Name      string `json:"name"`              // full name, tap-qualified outside homebrew/core
+	Version   string `json:"version,omitempty"` // installed version
+	Tap       string `json:"tap,omitempty"`     // tap it came from, e.g. "homebrew/core"
+	OnRequest bool   `json:"on_request,omitempty"`
+	Pinned    bool   `json:"pinned,omitempty"`
+}
+
+// BrewPackages is everything Homebrew has installed. Formulae includes
+// the ones installed only as dependencies; Requested lists the rest.
+type BrewPackages struct {
+	Formulae []PackageInfo
+	Casks    []PackageInfo
+}
+
+// Requested returns the names of the formulae installed on request,
+// sorted. These are what a snapshot's formulae list restores.
+func (b *BrewPackages) Requested() []string {
+	names := []string{}
+	for _, f := range b.Formulae {
+		if f.OnRequest {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// CaskNames returns the names of the installed casks, sorted.
+func (b *BrewPackages) CaskNames() []string {
+	names := make([]string, 0, len(b.Casks))
+	for _, c := range b.Casks {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// brewInfoInstalled returns the JSON `brew info --json=v2 --installed`
+// prints, or "" without Homebrew. It is a var so tests can capture without
+// Homebrew.
+var brewInfoInstalled = func(ctx context.Context) (string, error) {
+	if !isBrewInstalled() {
+		return "", nil
+	}
+	return system.RunCommandOutputContext(ctx, "brew", "info", "--json=v2", "--installed")
+}
+
+// CaptureBrewPackages returns every installed formula and cask from a
+// single `brew info` call.
+func CaptureBrewPackages() (*BrewPackages, error) {
+	return captureBrewPackages(context.Background())
+}
+
+func captureBrewPackages(ctx context.Context) (*BrewPackages, error) {
+	out, err := brewInfoInstalled(ctx)
+	if err != nil {
+		return &BrewPackages{}, fmt.Errorf("brew info: %w", err)
+	}
+	if strings.TrimSpace(out) == "" {
+		return &BrewPackages{}, nil
+	}
+	return parseBrewInfo([]byte(out))
+}
+
+// brewInfoV2 is the subset of `brew info --json=v2` capture reads.
+type brewInfoV2 struct {
+	Formulae []struct {
+		FullName  string `json:"full_name"`
+		Tap       string `json:"tap"`
+		Pinned    bool   `json:"pinned"`
+		LinkedKeg string `json:"linked_keg"`
+		Installed []struct {
+			Version               string `json:"version"`
+			InstalledOnRequest    bool   `json:"installed_on_request"`
+			InstalledAsDependency bool   `json:"installed_as_dependency"`
+		} `json:"installed"`
+	} `json:"formulae"`
+	Casks []struct {
+		FullToken string  `json:"full_token"`
+		Token     string  `json:"token"`
+		Tap       string  `json:"tap"`
+		Installed *string `json:"installed"`
+	} `json:"casks"`
+}
+
+func parseBrewInfo(data []byte) (*BrewPackages, error) {
+	var info brewInfoV2
+	if err := json.Unmarshal(data, &info); err != nil {
+		return &BrewPackages{}, fmt.Errorf("parse brew info: %w", err)
+	}
+
+	b := &BrewPackages{Formulae: []PackageInfo{}, Casks: []PackageInfo{}}
+	for _, f := range info.Formulae {
+		if len(f.Installed) == 0 {
+			continue
+		}
+		// The linked keg is the version in use; without one, the newest
+		// keg brew lists (they are in install order).
+		keg := f.Installed[len(f.Installed)-1]
+		for _, k := range f.Installed {
+			if k.Version == f.LinkedKeg {
+				keg = k
+			}
+		}
+		// Kegs installed before Homebrew recorded a reason have neither
+		// flag set; count them as requested rather than drop them.
+		onRequest := false
+		for _, k := range f.Installed {
+			if k.InstalledOnRequest || !k.InstalledAsDependency {
+				onRequest = true
+			}
+		}
+		b.Formulae = append(b.Formulae, PackageInfo{
+			Name:      f.FullName,
+			Version:   keg.Version,
+			Tap:       f.Tap,
+			OnRequest: onRequest,
+			Pinned:    f.Pinned,
+		})
+	}
+	for _, c := range info.Casks {
+		if c.Installed == nil {
+			continue
+		}
+		// `brew list --cask` prints the short token, so casks keep it.
+		b.Casks = append(b.Casks, PackageInfo{
+			Name:    c.Token,
+			Version: *c.Installed,
+			Tap:     c.Tap,
+		})
+	}
+	sortPackageInfo(b.Formulae)
+	sortPackageInfo(b.Casks)
+	return b, nil
+}
+
+func sortPackageInfo(infos []PackageInfo) {
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+}
+
+// InstalledNames returns the set of formula or cask names in infos,
+// spelled both in full and without the tap prefix, so a config that names
+// "hashicorp/tap/terraform" or "terraform" matches either way.
+func InstalledNames(infos []PackageInfo) map[string]bool {
+	names := make(map[string]bool, 2*len(infos))
+	for _, p := range infos {
+		names[p.Name] = true
+		names[path.Base(p.Name)] = true
+	}
+	return names
+}
+
+// LookupPackageInfo returns the entry for name in infos, matching the full
+// or, failing that, the short name.
+func LookupPackageInfo(infos []PackageInfo, name string) (PackageInfo, bool) {
+	i := slices.IndexFunc(infos, func(p PackageInfo) bool { return p.Name == name })
+	if i < 0 {
+		i = slices.IndexFunc(infos, func(p PackageInfo) bool { return path.Base(p.Name) == name })
+	}
+	if i < 0 {
+		return PackageInfo{}, false
+	}
+	return infos[i], true
+}
+
+// PruneInfo drops the info of requested formulae and of casks that are no
+// longer in ps's lists, e.g. after the snapshot editor deselects them.
+// Dependencies keep their info.
+func (ps *PackageSnapshot) PruneInfo() {
+	ps.FormulaInfo = slices.DeleteFunc(slices.Clone(ps.FormulaInfo), func(p PackageInfo) bool {
+		return p.OnRequest && !slices.Contains(ps.Formulae, p.Name)
+	})
+	ps.CaskInfo = slices.DeleteFunc(slices.Clone(ps.CaskInfo), func(p PackageInfo) bool {
+		return !slices.Contains(ps.Casks, p.Name)
+	})
+}
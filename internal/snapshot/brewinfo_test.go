@@ -0,0 +1,118 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brewInfoJSON trims `brew info --json=v2 --installed` to the fields
+// capture reads, plus a few it ignores.
+const brewInfoJSON = `{
+  "formulae": [
+    {"name": "openssl@3", "full_name": "openssl@3", "tap": "homebrew/core", "pinned": false, "linked_keg": "3.3.1",
+     "installed": [{"version": "3.3.1", "installed_on_request": false, "installed_as_dependency": true}]},
+    {"name": "python@3.12", "full_name": "python@3.12", "tap": "homebrew/core", "pinned": false, "linked_keg": "3.12.4",
+     "installed": [{"version": "3.12.4", "installed_on_request": true, "installed_as_dependency": true}]},
+    {"name": "node", "full_name": "node", "tap": "homebrew/core", "pinned": true, "linked_keg": "20.11.0",
+     "installed": [{"version": "20.11.0", "installed_on_request": true, "installed_as_dependency": false},
+                   {"version": "22.3.0", "installed_on_request": true, "installed_as_dependency": false}]},
+    {"name": "terraform", "full_name": "hashicorp/tap/terraform", "tap": "hashicorp/tap", "pinned": false, "linked_keg": null,
+     "installed": [{"version": "1.9.0", "installed_on_request": false, "installed_as_dependency": false}]},
+    {"name": "gone", "full_name": "gone", "tap": "homebrew/core", "installed": []}
+  ],
+  "casks": [
+    {"token": "firefox", "full_token": "firefox", "tap": "homebrew/cask", "installed": "127.0", "version": "128.0"},
+    {"token": "font-inter", "full_token": "homebrew/cask-fonts/font-inter", "tap": "homebrew/cask-fonts", "installed": "4.0"},
+    {"token": "never", "full_token": "never", "tap": "homebrew/cask", "installed": null}
+  ]
+}`
+
+func stubBrewInfo(t *testing.T, out string, err error) {
+	t.Helper()
+	orig := brewInfoInstalled
+	t.Cleanup(func() { brewInfoInstalled = orig })
+	brewInfoInstalled = func(context.Context) (string, error) { return out, err }
+}
+
+func TestCaptureBrewPackages_ParsesInfo(t *testing.T) {
+	stubBrewInfo(t, brewInfoJSON, nil)
+
+	b, err := CaptureBrewPackages()
+	require.NoError(t, err)
+	assert.Equal(t, []PackageInfo{
+		{Name: "hashicorp/tap/terraform", Version: "1.9.0", Tap: "hashicorp/tap", OnRequest: true},
+		{Name: "node", Version: "20.11.0", Tap: "homebrew/core", OnRequest: true, Pinned: true},
+		{Name: "openssl@3", Version: "3.3.1", Tap: "homebrew/core"},
+		{Name: "python@3.12", Version: "3.12.4", Tap: "homebrew/core", OnRequest: true},
+	}, b.Formulae)
+	assert.Equal(t, []PackageInfo{
+		{Name: "firefox", Version: "127.0", Tap: "homebrew/cask"},
+		{Name: "font-inter", Version: "4.0", Tap: "homebrew/cask-fonts"},
+	}, b.Casks)
+}
+
+// python@3.12 is a dependency of something else but was also installed on
+// request; `brew leaves` dropped it.
+func TestCaptureFormulae_KeepsRequestedDependencies(t *testing.T) {
+	stubBrewInfo(t, brewInfoJSON, nil)
+
+	formulae, err := CaptureFormulae()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hashicorp/tap/terraform", "node", "python@3.12"}, formulae)
+
+	casks, err := CaptureCasks()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"firefox", "font-inter"}, casks)
+}
+
+func TestCaptureBrewPackages_NoHomebrew(t *testing.T) {
+	stubBrewInfo(t, "", nil)
+
+	formulae, err := CaptureFormulae()
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, formulae)
+}
+
+func TestCaptureBrewPackages_Errors(t *testing.T) {
+	stubBrewInfo(t, "", errors.New("exit status 1"))
+	_, err := CaptureFormulae()
+	assert.ErrorContains(t, err, "brew info")
+
+	stubBrewInfo(t, "Error: not json", nil)
+	_, err = CaptureCasks()
+	assert.ErrorContains(t, err, "parse brew info")
+}
+
+func TestLookupPackageInfo(t *testing.T) {
+	infos := []PackageInfo{{Name: "hashicorp/tap/terraform", Version: "1.9.0"}, {Name: "node", Version: "22.3.0"}}
+
+	p, ok := LookupPackageInfo(infos, "terraform")
+	require.True(t, ok)
+	assert.Equal(t, "1.9.0", p.Version)
+	_, ok = LookupPackageInfo(infos, "hashicorp/tap/terraform")
+	assert.True(t, ok)
+	_, ok = LookupPackageInfo(infos, "go")
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]bool{"hashicorp/tap/terraform": true, "terraform": true, "node": true}, InstalledNames(infos))
+}
+
+func TestPruneInfo_DropsDeselectedPackages(t *testing.T) {
+	ps := PackageSnapshot{
+		Formulae: []string{"node"},
+		Casks:    []string{"firefox"},
+		FormulaInfo: []PackageInfo{
+			{Name: "node", OnRequest: true},
+			{Name: "openssl@3"},
+			{Name: "python@3.12", OnRequest: true},
+		},
+		CaskInfo: []PackageInfo{{Name: "firefox"}, {Name: "slack"}},
+	}
+	ps.PruneInfo()
+	assert.Equal(t, []PackageInfo{{Name: "node", OnRequest: true}, {Name: "openssl@3"}}, ps.FormulaInfo)
+	assert.Equal(t, []PackageInfo{{Name: "firefox"}}, ps.CaskInfo)
+}
@@ -1,15 +1,20 @@
 package snapshot
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/macos"
+	"github.com/openbootdotdev/openboot/internal/snapshot/schema"
 	"github.com/openbootdotdev/openboot/internal/system"
 )
 
@@ -26,80 +31,153 @@ type ScanStep struct {
 	Total  int    `json:"total"`
 	Status string `json:"status"` // "scanning" | "done" | "error"
 	Count  int    `json:"count"`
+	// Elapsed is how long the step itself ran. Set on "done" and "error"
+	// updates, which can arrive after the step finished because they are
+	// delivered in step order.
+	Elapsed time.Duration `json:"elapsed,omitempty"`
 }
 
-// CaptureResults holds the typed output of each capture step. Fields are
-// populated one at a time by CaptureWithProgress and then read by
-// assembleSnapshot — no type assertions needed.
+// CaptureResults holds the typed output of each capture step. Each step
+// fills its own fields in a private copy, CaptureWithProgress merges the
+// copies, and assembleSnapshot reads the result — no type assertions needed.
 type CaptureResults struct {
-	Formulae   []string
-	Casks      []string
-	Taps       []string
-	Npm        []string
-	Bun        []string
-	Prefs      []MacOSPref
-	DockApps   []string
-	LoginItems []LoginItem
-	Git        *GitSnapshot
-	Dotfiles   *DotfilesSnapshot
-	DevTools   []DevTool
-	Shell      *ShellSnapshot
+	Formulae     []string
+	Casks        []string
+	FormulaInfo  []PackageInfo
+	CaskInfo     []PackageInfo
+	Taps         []string
+	Npm          []string
+	Bun          []string
+	Prefs        []MacOSPref
+	DockApps     []string
+	Dock         *config.DockLayout
+	LoginItems   []LoginItem
+	LaunchAgents []config.LaunchAgent
+	Fonts        []config.Font
+	DefaultApps  []config.DefaultApp
+	Keyboard     *config.RemoteKeyboardConfig
+	Git          *GitSnapshot
+	Dotfiles     *DotfilesSnapshot
+	DevTools     []DevTool
+	Shell        *ShellSnapshot
+	SSH          *config.RemoteSSHConfig
+	Machine      *config.RemoteMachineConfig
+	AppSettings  *config.AppSettings
+	Services     []string
 }
 
 type captureStep struct {
 	name    string
-	capture func(r *CaptureResults) error
+	capture func(ctx context.Context, r *CaptureResults) error
 	count   func(r *CaptureResults) int
 }
 
 var captureSteps = []captureStep{
-	{"Homebrew Formulae", func(r *CaptureResults) error {
-		v, err := CaptureFormulae()
-		r.Formulae = v
+	{"Machine Name", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureMachine(ctx)
+		r.Machine = v
 		return err
-	}, func(r *CaptureResults) int { return len(r.Formulae) }},
-	{"Homebrew Casks", func(r *CaptureResults) error {
-		v, err := CaptureCasks()
-		r.Casks = v
+	}, func(r *CaptureResults) int { return len(r.Machine.Names()) }},
+	{"Homebrew Packages", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureBrewPackages(ctx)
+		r.Formulae = v.Requested()
+		r.Casks = v.CaskNames()
+		r.FormulaInfo = v.Formulae
+		r.CaskInfo = v.Casks
 		return err
-	}, func(r *CaptureResults) int { return len(r.Casks) }},
-	{"Homebrew Taps", func(r *CaptureResults) error {
-		v, err := CaptureTaps()
+	}, func(r *CaptureResults) int { return len(r.Formulae) + len(r.Casks) }},
+	{"Homebrew Taps", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureTaps(ctx)
 		r.Taps = v
 		return err
 	}, func(r *CaptureResults) int { return len(r.Taps) }},
-	{"NPM Global Packages", func(r *CaptureResults) error {
-		v, err := CaptureNpm()
+	{"Homebrew Services", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureServices(ctx)
+		r.Services = v
+		return err
+	}, func(r *CaptureResults) int { return len(r.Services) }},
+	{"Fonts", func(ctx context.Context, r *CaptureResults) error {
+		v, err := CaptureFonts()
+		r.Fonts = v
+		return err
+	}, func(r *CaptureResults) int { return len(r.Fonts) }},
+	{"NPM Global Packages", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureNpm(ctx)
 		r.Npm = v
 		return err
 	}, func(r *CaptureResults) int { return len(r.Npm) }},
-	{"Bun Global Packages", func(r *CaptureResults) error {
-		v, err := CaptureBun()
+	{"Bun Global Packages", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureBun(ctx)
 		r.Bun = v
 		return err
 	}, func(r *CaptureResults) int { return len(r.Bun) }},
-	{"macOS Preferences", func(r *CaptureResults) error {
-		v, err := CaptureMacOSPrefs()
+	{"macOS Preferences", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureMacOSPrefs(ctx)
 		r.Prefs = v
 		return err
 	}, func(r *CaptureResults) int { return len(r.Prefs) }},
-	{"Dock Apps", func(r *CaptureResults) error {
-		v, err := CaptureDockApps()
-		r.DockApps = v
+	{"Dock", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureDockLayout(ctx)
+		r.Dock = v
+		r.DockApps = v.AppPaths()
 		return err
-	}, func(r *CaptureResults) int { return len(r.DockApps) }},
-	{"Login Items", func(r *CaptureResults) error {
-		v, err := CaptureLoginItems()
+	}, func(r *CaptureResults) int {
+		if r.Dock == nil {
+			return 0
+		}
+		return len(r.Dock.Apps) + len(r.Dock.Others)
+	}},
+	{"Login Items", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureLoginItems(ctx)
 		r.LoginItems = v
 		return err
 	}, func(r *CaptureResults) int { return len(r.LoginItems) }},
-	{"Git Configuration", func(r *CaptureResults) error {
-		v, err := CaptureGit()
+	{"Launch Agents", func(ctx context.Context, r *CaptureResults) error {
+		v, err := CaptureLaunchAgents()
+		r.LaunchAgents = v
+		return err
+	}, func(r *CaptureResults) int { return len(r.LaunchAgents) }},
+	{"Default Apps", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureDefaultApps(ctx)
+		r.DefaultApps = v
+		return err
+	}, func(r *CaptureResults) int { return len(r.DefaultApps) }},
+	{"Keyboard", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureKeyboard(ctx)
+		r.Keyboard = v
+		return err
+	}, func(r *CaptureResults) int {
+		if r.Keyboard == nil {
+			return 0
+		}
+		n := len(r.Keyboard.SymbolicHotKeys) + len(r.Keyboard.KeyMappings)
+		for _, equivs := range r.Keyboard.AppShortcuts {
+			n += len(equivs)
+		}
+		return n
+	}},
+	{"Git Configuration", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureGit(ctx)
 		r.Git = v
 		return err
-	}, func(r *CaptureResults) int { return 1 }},
-	{"Dotfiles", func(r *CaptureResults) error {
-		v, err := CaptureDotfiles()
+	}, func(r *CaptureResults) int {
+		if r.Git == nil {
+			return 1
+		}
+		return 1 + len(r.Git.Settings)
+	}},
+	{"SSH Config", func(ctx context.Context, r *CaptureResults) error {
+		v, err := CaptureSSH()
+		r.SSH = v
+		return err
+	}, func(r *CaptureResults) int {
+		if r.SSH == nil {
+			return 0
+		}
+		return len(r.SSH.Hosts) + len(r.SSH.Keys)
+	}},
+	{"Dotfiles", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureDotfiles(ctx)
 		r.Dotfiles = v
 		return err
 	}, func(r *CaptureResults) int {
@@ -108,17 +186,22 @@ var captureSteps = []captureStep{
 		}
 		return 0
 	}},
-	{"Dev Tools", func(r *CaptureResults) error {
-		v, err := CaptureDevTools()
+	{"Dev Tools", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureDevTools(ctx)
 		r.DevTools = v
 		return err
 	}, func(r *CaptureResults) int { return len(r.DevTools) }},
-	{"Shell Config", func(r *CaptureResults) error {
+	{"App Settings", func(ctx context.Context, r *CaptureResults) error {
+		v, err := captureAppSettings(ctx)
+		r.AppSettings = v
+		return err
+	}, func(r *CaptureResults) int { return len(r.AppSettings.AppIDs()) }},
+	{"Shell Config", func(ctx context.Context, r *CaptureResults) error {
 		v, err := CaptureShell()
 		r.Shell = v
 		return err
 	}, func(r *CaptureResults) int {
-		if r.Shell != nil && (r.Shell.Theme != "" || len(r.Shell.Plugins) > 0 || r.Shell.OhMyZsh) {
+		if r.Shell != nil && (r.Shell.Theme != "" || len(r.Shell.Plugins) > 0 || r.Shell.Framework != "" || r.Shell.Starship || !r.Shell.Snippets.Empty()) {
 			return 1
 		}
 		return 0
@@ -150,6 +233,9 @@ func assembleSnapshot(r *CaptureResults, failedSteps []string, hostname string)
 	if r.LoginItems == nil {
 		r.LoginItems = []LoginItem{}
 	}
+	if r.DefaultApps == nil {
+		r.DefaultApps = []config.DefaultApp{}
+	}
 	if r.Git == nil {
 		r.Git = &GitSnapshot{}
 	}
@@ -164,21 +250,32 @@ func assembleSnapshot(r *CaptureResults, failedSteps []string, hostname string)
 	}
 
 	return &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now(),
 		Hostname:   hostname,
+		Machine:    r.Machine,
 		Packages: PackageSnapshot{
-			Formulae: r.Formulae,
-			Casks:    r.Casks,
-			Taps:     r.Taps,
-			Npm:      r.Npm,
-			Bun:      r.Bun,
+			Formulae:    r.Formulae,
+			Casks:       r.Casks,
+			Taps:        r.Taps,
+			Npm:         r.Npm,
+			Bun:         r.Bun,
+			FormulaInfo: r.FormulaInfo,
+			CaskInfo:    r.CaskInfo,
 		},
 		MacOSPrefs:    r.Prefs,
 		DockApps:      r.DockApps,
+		Dock:          r.Dock,
 		LoginItems:    r.LoginItems,
+		LaunchAgents:  r.LaunchAgents,
+		AppSettings:   r.AppSettings,
+		Services:      r.Services,
+		Fonts:         r.Fonts,
+		DefaultApps:   r.DefaultApps,
+		Keyboard:      r.Keyboard,
 		Shell:         *r.Shell,
 		Git:           *r.Git,
+		SSH:           r.SSH,
 		Dotfiles:      *r.Dotfiles,
 		DevTools:      r.DevTools,
 		MatchedPreset: "",
@@ -194,32 +291,209 @@ func assembleSnapshot(r *CaptureResults, failedSteps []string, hostname string)
 	}
 }
 
+// captureParallelism bounds how many capture steps run at once, and
+// captureStepTimeout bounds how long any one of them may take. Both are
+// vars so tests can tighten them.
+var (
+	captureParallelism = 4
+	captureStepTimeout = 45 * time.Second
+)
+
+// CaptureStepCount returns the number of steps CaptureWithProgress reports,
+// so progress renderers can size themselves up front.
+func CaptureStepCount() int {
+	return len(captureSteps)
+}
+
+// stepOutcome is what a finished (or abandoned) capture step hands back to
+// CaptureWithProgress. results is nil when the step timed out.
+type stepOutcome struct {
+	index   int
+	results *CaptureResults
+	err     error
+	elapsed time.Duration
+}
+
+// runCaptureStep runs step against a private CaptureResults under a
+// timeout. A step that overruns is abandoned: its context is cancelled so
+// any command it started is killed, and whatever it writes afterwards lands
+// in a struct nobody reads.
+func runCaptureStep(step captureStep, timeout time.Duration) (*CaptureResults, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	r := &CaptureResults{}
+	done := make(chan error, 1)
+	go func() { done <- step.capture(ctx, r) }()
+
+	select {
+	case err := <-done:
+		return r, err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// mergeResults copies the fields a step populated in src into dst. Steps
+// own disjoint fields, so nothing set by one step is overwritten by another.
+func mergeResults(dst, src *CaptureResults) {
+	if src.Formulae != nil {
+		dst.Formulae = src.Formulae
+	}
+	if src.Casks != nil {
+		dst.Casks = src.Casks
+	}
+	if src.FormulaInfo != nil {
+		dst.FormulaInfo = src.FormulaInfo
+	}
+	if src.CaskInfo != nil {
+		dst.CaskInfo = src.CaskInfo
+	}
+	if src.Taps != nil {
+		dst.Taps = src.Taps
+	}
+	if src.Npm != nil {
+		dst.Npm = src.Npm
+	}
+	if src.Bun != nil {
+		dst.Bun = src.Bun
+	}
+	if src.Prefs != nil {
+		dst.Prefs = src.Prefs
+	}
+	if src.DockApps != nil {
+		dst.DockApps = src.DockApps
+	}
+	if src.Dock != nil {
+		dst.Dock = src.Dock
+	}
+	if src.LoginItems != nil {
+		dst.LoginItems = src.LoginItems
+	}
+	if src.LaunchAgents != nil {
+		dst.LaunchAgents = src.LaunchAgents
+	}
+	if src.Fonts != nil {
+		dst.Fonts = src.Fonts
+	}
+	if src.DefaultApps != nil {
+		dst.DefaultApps = src.DefaultApps
+	}
+	if src.Keyboard != nil {
+		dst.Keyboard = src.Keyboard
+	}
+	if src.Git != nil {
+		dst.Git = src.Git
+	}
+	if src.Dotfiles != nil {
+		dst.Dotfiles = src.Dotfiles
+	}
+	if src.DevTools != nil {
+		dst.DevTools = src.DevTools
+	}
+	if src.Shell != nil {
+		dst.Shell = src.Shell
+	}
+	if src.SSH != nil {
+		dst.SSH = src.SSH
+	}
+	if src.Machine != nil {
+		dst.Machine = src.Machine
+	}
+	if src.AppSettings != nil {
+		dst.AppSettings = src.AppSettings
+	}
+	if src.Services != nil {
+		dst.Services = src.Services
+	}
+}
+
+// CaptureWithProgress runs every capture step, up to captureParallelism at a
+// time, each bounded by captureStepTimeout. callback is only ever invoked
+// from the calling goroutine: "scanning" updates arrive as steps start, and
+// "done"/"error" updates arrive in step order regardless of which step
+// actually finished first.
 func CaptureWithProgress(callback func(step ScanStep)) (*Snapshot, error) {
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "unknown"
 	}
 
-	results := &CaptureResults{}
-	var failedSteps []string
-	for i, step := range captureSteps {
+	steps := captureSteps
+	total := len(steps)
+	timeout := captureStepTimeout
+	parallelism := captureParallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	report := func(s ScanStep) {
 		if callback != nil {
-			callback(ScanStep{Name: step.name, Index: i, Total: len(captureSteps), Status: "scanning", Count: 0})
+			callback(s)
 		}
+	}
 
-		err := step.capture(results)
+	started := make(chan int)
+	finished := make(chan stepOutcome)
+	go func() {
+		sem := make(chan struct{}, parallelism)
+		for i, step := range steps {
+			sem <- struct{}{}
+			started <- i
+			go func(i int, step captureStep) {
+				defer func() { <-sem }()
+				begin := time.Now()
+				r, err := runCaptureStep(step, timeout)
+				finished <- stepOutcome{index: i, results: r, err: err, elapsed: time.Since(begin)}
+			}(i, step)
+		}
+		close(started)
+	}()
 
-		if err != nil {
-			failedSteps = append(failedSteps, step.name)
-			if callback != nil {
-				callback(ScanStep{Name: step.name, Index: i, Total: len(captureSteps), Status: "error", Count: 0})
+	results := &CaptureResults{}
+	var failedSteps []string
+	stepErrors := map[string]string{}
+	pending := make(map[int]stepOutcome, total)
+	next := 0
+	for received := 0; received < total; {
+		select {
+		case i, ok := <-started:
+			if !ok {
+				started = nil
+				continue
 			}
-		} else if callback != nil {
-			callback(ScanStep{Name: step.name, Index: i, Total: len(captureSteps), Status: "done", Count: step.count(results)})
+			report(ScanStep{Name: steps[i].name, Index: i, Total: total, Status: "scanning", Count: 0})
+		case o := <-finished:
+			received++
+			pending[o.index] = o
+		}
+
+		for {
+			o, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			step := steps[next]
+			if o.results != nil {
+				mergeResults(results, o.results)
+			}
+			if o.err != nil {
+				failedSteps = append(failedSteps, step.name)
+				stepErrors[step.name] = o.err.Error()
+				report(ScanStep{Name: step.name, Index: next, Total: total, Status: "error", Count: 0, Elapsed: o.elapsed})
+			} else {
+				report(ScanStep{Name: step.name, Index: next, Total: total, Status: "done", Count: step.count(o.results), Elapsed: o.elapsed})
+			}
+			next++
 		}
 	}
 
-	return assembleSnapshot(results, failedSteps, hostname), nil
+	snap := assembleSnapshot(results, failedSteps, hostname)
+	if len(stepErrors) > 0 {
+		snap.Health.Errors = stepErrors
+	}
+	return snap, nil
 }
 
 // bunListEntryRe matches a single `bun pm ls -g` entry line, after tree-drawing
@@ -254,11 +528,15 @@ func parseBunList(output string) []string {
 }
 
 func CaptureBun() ([]string, error) {
+	return captureBun(context.Background())
+}
+
+func captureBun(ctx context.Context) ([]string, error) {
 	if _, err := exec.LookPath("bun"); err != nil {
 		return []string{}, nil
 	}
 
-	output, err := system.RunCommandOutput("bun", "pm", "ls", "-g")
+	output, err := system.RunCommandOutputContext(ctx, "bun", "pm", "ls", "-g")
 	if err != nil {
 		return []string{}, nil
 	}
@@ -267,11 +545,15 @@ func CaptureBun() ([]string, error) {
 }
 
 func CaptureNpm() ([]string, error) {
+	return captureNpm(context.Background())
+}
+
+func captureNpm(ctx context.Context) ([]string, error) {
 	if _, err := exec.LookPath("npm"); err != nil {
 		return []string{}, nil
 	}
 
-	output, err := system.RunCommandOutput("npm", "list", "-g", "--depth=0", "--parseable")
+	output, err := system.RunCommandOutputContext(ctx, "npm", "list", "-g", "--depth=0", "--parseable")
 	if err != nil {
 		return []string{}, nil
 	}
@@ -306,11 +588,15 @@ func isBrewInstalled() bool {
 }
 
 func captureBrewList(args ...string) ([]string, error) {
+	return captureBrewListContext(context.Background(), args...)
+}
+
+func captureBrewListContext(ctx context.Context, args ...string) ([]string, error) {
 	if !isBrewInstalled() {
 		return []string{}, nil
 	}
 
-	output, err := system.RunCommandOutput("brew", args...)
+	output, err := system.RunCommandOutputContext(ctx, "brew", args...)
 	if err != nil {
 		return []string{}, fmt.Errorf("brew %s: %w", args[0], err)
 	}
@@ -318,19 +604,32 @@ func captureBrewList(args ...string) ([]string, error) {
 	return parseLines(output), nil
 }
 
+// CaptureFormulae returns the formulae installed on request, including
+// ones that are also a dependency of another formula.
 func CaptureFormulae() ([]string, error) {
-	return captureBrewList("leaves")
+	b, err := captureBrewPackages(context.Background())
+	return b.Requested(), err
 }
 
+// CaptureCasks returns the installed casks.
 func CaptureCasks() ([]string, error) {
-	return captureBrewList("list", "--cask")
+	b, err := captureBrewPackages(context.Background())
+	return b.CaskNames(), err
 }
 
 func CaptureTaps() ([]string, error) {
-	return captureBrewList("tap")
+	return captureTaps(context.Background())
+}
+
+func captureTaps(ctx context.Context) ([]string, error) {
+	return captureBrewListContext(ctx, "tap")
 }
 
 func CaptureMacOSPrefs() ([]MacOSPref, error) {
+	return captureMacOSPrefs(context.Background())
+}
+
+func captureMacOSPrefs(ctx context.Context) ([]MacOSPref, error) {
 	prefs := []MacOSPref{}
 
 	for _, p := range macos.DefaultPreferences {
@@ -340,7 +639,7 @@ func CaptureMacOSPrefs() ([]MacOSPref, error) {
 		}
 		args = append(args, "read", p.Domain, p.Key)
 
-		output, err := system.RunCommandOutput("defaults", args...)
+		output, err := system.RunCommandOutputContext(ctx, "defaults", args...)
 		if err != nil {
 			// Key isn't set on this machine — record it with the catalog's
 			// default value and the Unset marker so consumers (UI, restore,
@@ -372,19 +671,162 @@ func CaptureMacOSPrefs() ([]MacOSPref, error) {
 }
 
 func CaptureGit() (*GitSnapshot, error) {
+	return captureGit(context.Background())
+}
+
+func captureGit(ctx context.Context) (*GitSnapshot, error) {
 	snap := &GitSnapshot{}
 
-	if out, err := system.RunCommandOutput("git", "config", "--global", "user.name"); err == nil {
+	if out, err := system.RunCommandOutputContext(ctx, "git", "config", "--global", "user.name"); err == nil {
 		snap.UserName = out
 	}
 
-	if out, err := system.RunCommandOutput("git", "config", "--global", "user.email"); err == nil {
+	if out, err := system.RunCommandOutputContext(ctx, "git", "config", "--global", "user.email"); err == nil {
 		snap.UserEmail = out
 	}
 
+	// The rest of the global config: only allow-listed keys, and never one
+	// that looks like it holds a credential.
+	all := map[string]string{}
+	if out, err := system.RunCommandOutputContext(ctx, "git", "config", "--global", "--null", "--list"); err == nil {
+		all = parseGitConfigList(out)
+	}
+	for k, v := range all {
+		canon, ok := config.CanonicalGitKey(k)
+		if !ok || config.GitSettingLooksSecret(k, v) {
+			continue
+		}
+		if snap.Settings == nil {
+			snap.Settings = make(map[string]string)
+		}
+		snap.Settings[canon] = v
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		snap.Gitignore = readGitFile(config.GitFilePath(home, all["core.excludesfile"], config.GitignoreFile))
+		snap.Gitattributes = readGitFile(config.GitFilePath(home, all["core.attributesfile"], config.GitattributesFile))
+		snap.Profiles = captureGitProfiles(ctx, home, all)
+	}
+
+	// Signing keys are per machine; record only that commits are signed, and
+	// how, so a restore sets up a key of the same kind.
+	if strings.EqualFold(all["commit.gpgsign"], "true") {
+		format := config.GitSigningGPG
+		if all["gpg.format"] == config.GitSigningSSH {
+			format = config.GitSigningSSH
+		}
+		snap.Signing = &config.GitSigning{Format: format}
+	}
+
 	return snap, nil
 }
 
+// captureGitProfiles reads the identity in each file included by an
+// [includeIf "gitdir:<dir>"] rule. Includes without an email, and other
+// include conditions (onbranch:, gitdir/i:), are skipped.
+func captureGitProfiles(ctx context.Context, home string, all map[string]string) []config.GitProfile {
+	var profiles []config.GitProfile
+	seen := make(map[string]bool)
+	// Walk the keys in order: colliding file names get numbered suffixes,
+	// and which one gets which must not depend on map order.
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		path := all[key]
+		dir, ok := strings.CutPrefix(key, "includeif.gitdir:")
+		if !ok || !strings.HasSuffix(dir, ".path") {
+			continue
+		}
+		dir = strings.TrimSuffix(dir, ".path")
+		out, err := system.RunCommandOutputContext(ctx, "git", "config", "--file", config.GitFilePath(home, path, ""), "--null", "--list")
+		if err != nil {
+			continue
+		}
+		inc := parseGitConfigList(out)
+		p := config.GitProfile{
+			Name:       gitProfileName(path, seen),
+			Directory:  dir,
+			UserName:   inc["user.name"],
+			Email:      inc["user.email"],
+			SigningKey: inc["user.signingkey"],
+		}
+		if p.Validate() != nil {
+			continue
+		}
+		seen[p.Name] = true
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// gitProfileName derives a profile name from an included file's name:
+// "~/.gitconfig-work" becomes "gitconfig-work", and a taken name gets a
+// numeric suffix.
+func gitProfileName(path string, taken map[string]bool) string {
+	base := strings.TrimSuffix(filepath.Base(path), ".gitconfig")
+	var sb strings.Builder
+	for _, r := range strings.ToLower(base) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			sb.WriteRune(r)
+		case sb.Len() > 0:
+			sb.WriteByte('-')
+		}
+	}
+	name := strings.Trim(sb.String(), "-")
+	if len(name) > 28 {
+		name = name[:28]
+	}
+	if name == "" {
+		name = "profile"
+	}
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	return candidate
+}
+
+// parseGitConfigList parses `git config --null --list` output: entries end
+// in NUL, and each is the key, a newline, then the value. Section and
+// variable names are lowercased, as git compares them; a subsection keeps its
+// case. A key later in the output overrides an earlier one, as it does for
+// git.
+func parseGitConfigList(out string) map[string]string {
+	entries := make(map[string]string)
+	for _, entry := range strings.Split(out, "\x00") {
+		if entry == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimLeft(entry, "\n"), "\n")
+		entries[normalizeGitKey(key)] = value
+	}
+	return entries
+}
+
+// normalizeGitKey lowercases the section and variable name of key.
+func normalizeGitKey(key string) string {
+	first, last := strings.IndexByte(key, '.'), strings.LastIndexByte(key, '.')
+	if first < 0 || first == last {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
+
+// readGitFile returns the contents of a global git file, or "" when it is
+// missing or over config.MaxGitFileLen.
+func readGitFile(path string) string {
+	data, err := os.ReadFile(path) //nolint:gosec // path is the user's own global git file
+	if err != nil || len(data) > config.MaxGitFileLen {
+		return ""
+	}
+	return string(data)
+}
+
 var devToolCommands = []struct {
 	name string
 	args []string
@@ -399,6 +841,10 @@ var devToolCommands = []struct {
 }
 
 func CaptureDevTools() ([]DevTool, error) {
+	return captureDevTools(context.Background())
+}
+
+func captureDevTools(ctx context.Context) ([]DevTool, error) {
 	tools := []DevTool{}
 
 	for _, dt := range devToolCommands {
@@ -406,7 +852,7 @@ func CaptureDevTools() ([]DevTool, error) {
 			continue
 		}
 
-		output, err := system.RunCommandOutput(dt.name, dt.args...)
+		output, err := system.RunCommandOutputContext(ctx, dt.name, dt.args...)
 		if err != nil {
 			continue
 		}
@@ -422,6 +868,10 @@ func CaptureDevTools() ([]DevTool, error) {
 }
 
 func CaptureDotfiles() (*DotfilesSnapshot, error) {
+	return captureDotfiles(context.Background())
+}
+
+func captureDotfiles(ctx context.Context) (*DotfilesSnapshot, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return &DotfilesSnapshot{}, nil
@@ -432,50 +882,27 @@ func CaptureDotfiles() (*DotfilesSnapshot, error) {
 		return &DotfilesSnapshot{}, nil
 	}
 
-	out, err := system.RunCommandOutput("git", "-C", dotfilesPath, "remote", "get-url", "origin")
+	out, err := system.RunCommandOutputContext(ctx, "git", "-C", dotfilesPath, "remote", "get-url", "origin")
 	if err != nil {
 		return &DotfilesSnapshot{}, nil
 	}
 
 	return &DotfilesSnapshot{
-		RepoURL: out,
+		RepoURL: stripURLCredentials(out),
 	}, nil
 }
 
-var (
-	zshThemeRe   = regexp.MustCompile(`(?m)^ZSH_THEME="([^"]*)"`)
-	zshPluginsRe = regexp.MustCompile(`(?m)^plugins=\((?s:(.*?))\)`)
-)
-
-// CaptureShell reads the current Oh-My-Zsh state and .zshrc theme/plugins.
-// Returns a zero-value ShellSnapshot (not an error) when .zshrc is absent.
-func CaptureShell() (*ShellSnapshot, error) {
-	snap := &ShellSnapshot{}
-
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return snap, nil
-	}
-
-	if _, err := os.Stat(filepath.Join(home, ".oh-my-zsh")); err == nil {
-		snap.OhMyZsh = true
-	}
-
-	raw, err := os.ReadFile(filepath.Join(home, ".zshrc"))
-	if err != nil {
-		return snap, nil // no .zshrc is fine
-	}
-	content := string(raw)
-
-	if m := zshThemeRe.FindStringSubmatch(content); len(m) > 1 {
-		snap.Theme = m[1]
-	}
-
-	if m := zshPluginsRe.FindStringSubmatch(content); len(m) > 1 {
-		snap.Plugins = strings.Fields(m[1])
-	}
-
-	return snap, nil
+// stripURLCredentials drops any user:token@ part from an https remote. A
+// clone made with a resolved secret:// token (or a pasted PAT) keeps that
+// token in .git/config; it must not ride along into a published snapshot.
+// scp-style SSH remotes (git@github.com:...) carry no secret and pass through.
+func stripURLCredentials(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil || u.Scheme == "" {
+		return raw
+	}
+	u.User = nil
+	return u.String()
 }
 
 func sanitizePath(path string) string {
@@ -0,0 +1,91 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/plist"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// launchServicesPlist holds the user's default-handler choices, relative to
+// the home directory.
+const launchServicesPlist = "Library/Preferences/com.apple.LaunchServices/com.apple.launchservices.secure.plist"
+
+// extensionTagClass marks an LSHandlers entry keyed by file extension.
+const extensionTagClass = "public.filename-extension"
+
+// readLSHandlers returns the LSHandlers array of the LaunchServices plist as
+// plist XML. It is a var so tests can supply a fixture.
+var readLSHandlers = func(ctx context.Context) (string, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return system.RunCommandOutputContext(ctx, "plutil", "-extract", "LSHandlers", "xml1", "-o", "-", filepath.Join(home, launchServicesPlist))
+}
+
+// CaptureDefaultApps returns the default handlers the user has chosen for
+// content types, file extensions and URL schemes. Returns
+// ([]config.DefaultApp{}, nil) when none were ever changed.
+func CaptureDefaultApps() ([]config.DefaultApp, error) {
+	return captureDefaultApps(context.Background())
+}
+
+func captureDefaultApps(ctx context.Context) ([]config.DefaultApp, error) {
+	out, err := readLSHandlers(ctx)
+	if err != nil || strings.TrimSpace(out) == "" {
+		// No plist until the first handler is changed.
+		return []config.DefaultApp{}, nil
+	}
+	return parseLSHandlersXML([]byte(out))
+}
+
+// parseLSHandlersXML turns the plist XML of an LSHandlers array into
+// default apps. Entries with no handler, or with one that would not pass
+// validation, are skipped.
+func parseLSHandlersXML(data []byte) ([]config.DefaultApp, error) {
+	entries, err := plist.ParseArray(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse launchservices plist xml: %w", err)
+	}
+
+	apps := make([]config.DefaultApp, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		var app config.DefaultApp
+		for _, role := range []string{"LSHandlerRoleAll", "LSHandlerRoleViewer", "LSHandlerRoleEditor"} {
+			if id, _ := e[role].(string); id != "" && id != "-" {
+				app.BundleID = id
+				break
+			}
+		}
+		if app.BundleID == "" {
+			continue
+		}
+		scheme, _ := e["LSHandlerURLScheme"].(string)
+		uti, _ := e["LSHandlerContentType"].(string)
+		tag, _ := e["LSHandlerContentTag"].(string)
+		tagClass, _ := e["LSHandlerContentTagClass"].(string)
+		switch {
+		case scheme != "":
+			app.URLScheme = scheme
+		case uti != "":
+			app.UTI = uti
+		case tag != "" && tagClass == extensionTagClass:
+			app.Extension = tag
+		default:
+			continue
+		}
+		key := strings.ToLower(app.Target())
+		if seen[key] || config.ValidateDefaultApps([]config.DefaultApp{app}) != nil {
+			continue
+		}
+		seen[key] = true
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+const lsHandlersFixture = plistHeader + `<array>
+	<dict>
+		<key>LSHandlerPreferredVersions</key>
+		<dict>
+			<key>LSHandlerRoleAll</key>
+			<string>-</string>
+		</dict>
+		<key>LSHandlerRoleAll</key>
+		<string>com.google.chrome</string>
+		<key>LSHandlerURLScheme</key>
+		<string>https</string>
+	</dict>
+	<dict>
+		<key>LSHandlerContentType</key>
+		<string>public.json</string>
+		<key>LSHandlerRoleAll</key>
+		<string>com.microsoft.vscode</string>
+	</dict>
+	<dict>
+		<key>LSHandlerContentTag</key>
+		<string>md</string>
+		<key>LSHandlerContentTagClass</key>
+		<string>public.filename-extension</string>
+		<key>LSHandlerRoleViewer</key>
+		<string>com.microsoft.vscode</string>
+	</dict>
+	<dict>
+		<key>LSHandlerContentType</key>
+		<string>public.plain-text</string>
+		<key>LSHandlerRoleAll</key>
+		<string>-</string>
+	</dict>
+	<dict>
+		<key>LSHandlerContentTag</key>
+		<string>text/html</string>
+		<key>LSHandlerContentTagClass</key>
+		<string>public.mime-type</string>
+		<key>LSHandlerRoleAll</key>
+		<string>com.google.chrome</string>
+	</dict>
+	<dict>
+		<key>LSHandlerRoleAll</key>
+		<string>com.google.chrome</string>
+		<key>LSHandlerURLScheme</key>
+		<string>https</string>
+	</dict>
+</array>
+</plist>`
+
+func TestParseLSHandlersXML(t *testing.T) {
+	got, err := parseLSHandlersXML([]byte(lsHandlersFixture))
+	require.NoError(t, err)
+	assert.Equal(t, []config.DefaultApp{
+		{URLScheme: "https", BundleID: "com.google.chrome"},
+		{UTI: "public.json", BundleID: "com.microsoft.vscode"},
+		{Extension: "md", BundleID: "com.microsoft.vscode"},
+	}, got, "no-handler, mime-type and duplicate entries are skipped")
+}
+
+func TestCaptureDefaultApps_NoPlist(t *testing.T) {
+	orig := readLSHandlers
+	t.Cleanup(func() { readLSHandlers = orig })
+	readLSHandlers = func(context.Context) (string, error) { return "", errors.New("no such file") }
+
+	got, err := CaptureDefaultApps()
+	require.NoError(t, err)
+	assert.Equal(t, []config.DefaultApp{}, got)
+}
@@ -1,8 +1,11 @@
 package snapshot
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -180,3 +183,49 @@ func TestCaptureDockApps_NoPanic(t *testing.T) {
 	_ = apps
 	_ = err
 }
+
+func TestCaptureDockLayout(t *testing.T) {
+	orig := exportDockPrefs
+	t.Cleanup(func() { exportDockPrefs = orig })
+
+	exportDockPrefs = func(context.Context) (string, error) {
+		return plistHeader + `<dict>
+	<key>orientation</key>
+	<string>right</string>
+	<key>persistent-apps</key>
+	<array>
+		<dict>
+			<key>tile-data</key>
+			<dict>
+				<key>file-data</key>
+				<dict>
+					<key>_CFURLString</key>
+					<string>file:///Applications/Zed.app/</string>
+				</dict>
+			</dict>
+			<key>tile-type</key>
+			<string>file-tile</string>
+		</dict>
+		<dict>
+			<key>tile-data</key>
+			<dict/>
+			<key>tile-type</key>
+			<string>flex-spacer-tile</string>
+		</dict>
+	</array>
+</dict>
+</plist>`, nil
+	}
+	got, err := CaptureDockLayout()
+	require.NoError(t, err)
+	assert.Equal(t, &config.DockLayout{
+		Apps:     []config.DockTile{{Type: config.DockTileApp, Path: "/Applications/Zed.app"}, {Type: config.DockTileFlexSpacer}},
+		Settings: &config.DockSettings{Orientation: "right"},
+	}, got)
+	assert.Equal(t, []string{"/Applications/Zed.app"}, got.AppPaths())
+
+	exportDockPrefs = func(context.Context) (string, error) { return "", errors.New("no such domain") }
+	got, err = CaptureDockLayout()
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,56 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// FontsDir holds the user's fonts, relative to the home directory.
+// Homebrew font casks install here too.
+const FontsDir = "Library/Fonts"
+
+// CaptureFonts lists the fonts in ~/Library/Fonts. Files the catalog maps
+// to a font cask become one cask entry per cask; the rest are grouped by
+// family (the file name before the first "-") with the files found, and
+// carry no URL since their source is unknown.
+func CaptureFonts() ([]config.Font, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(home, FontsDir))
+	if err != nil {
+		return nil, nil
+	}
+
+	casks := map[string]bool{}
+	families := map[string][]string{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !config.IsFontFile(name) {
+			continue
+		}
+		if cask := config.FontCaskForFile(name); cask != "" {
+			casks[cask] = true
+			continue
+		}
+		family, _, _ := strings.Cut(strings.TrimSuffix(name, filepath.Ext(name)), "-")
+		families[family] = append(families[family], name)
+	}
+
+	fonts := make([]config.Font, 0, len(casks)+len(families))
+	for cask := range casks {
+		fonts = append(fonts, config.Font{Cask: cask})
+	}
+	for family, files := range families {
+		sort.Strings(files)
+		fonts = append(fonts, config.Font{Name: family, Files: files})
+	}
+	sort.Slice(fonts, func(i, j int) bool { return fonts[i].Label() < fonts[j].Label() })
+	return fonts, nil
+}
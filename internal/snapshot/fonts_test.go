@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func TestCaptureFonts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	fonts, err := CaptureFonts()
+	require.NoError(t, err)
+	assert.Empty(t, fonts)
+
+	dir := filepath.Join(home, FontsDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Subdir"), 0755))
+	for _, f := range []string{
+		"JetBrainsMonoNerdFont-Regular.ttf",
+		"JetBrainsMonoNerdFont-Bold.ttf",
+		"FiraCode-Retina.ttf",
+		"BerkeleyMono-Regular.otf",
+		"BerkeleyMono-Bold.otf",
+		".DS_Store",
+		"README.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), nil, 0644))
+	}
+
+	fonts, err = CaptureFonts()
+	require.NoError(t, err)
+	assert.Equal(t, []config.Font{
+		{Name: "BerkeleyMono", Files: []string{"BerkeleyMono-Bold.otf", "BerkeleyMono-Regular.otf"}},
+		{Cask: "font-fira-code"},
+		{Cask: "font-jetbrains-mono-nerd-font"},
+	}, fonts)
+	assert.NoError(t, config.ValidateFonts(fonts))
+}
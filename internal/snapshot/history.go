@@ -0,0 +1,196 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openbootdotdev/openboot/internal/envelope"
+)
+
+// historyRetention is how many snapshots ~/.openboot/snapshots keeps;
+// older ones are pruned on save.
+const historyRetention = 20
+
+// historyIDFormat names history files after the capture time. Saves within
+// the same second get a "-2", "-3", ... suffix; historyIDs orders those
+// numerically.
+const historyIDFormat = "20060102-150405"
+
+// HistoryDir returns the directory that holds past local snapshots.
+func HistoryDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".openboot", "snapshots")
+}
+
+// HistoryEntry is one snapshot in the local history.
+type HistoryEntry struct {
+	ID         string    `json:"id"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Hostname   string    `json:"hostname,omitempty"`
+	CapturedAt time.Time `json:"captured_at,omitzero"`
+	Encrypted  bool      `json:"encrypted,omitempty"`
+}
+
+// saveHistory writes data, the file SaveLocal just wrote, into the history
+// under an ID taken from at, and prunes the history to historyRetention
+// entries.
+func saveHistory(data []byte, at time.Time, dryRun bool) (string, error) {
+	if dryRun {
+		return "", nil
+	}
+	dir := HistoryDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create snapshot history dir: %w", err)
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	id := at.Format(historyIDFormat)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", at.Format(historyIDFormat), n)
+	}
+	path := filepath.Join(dir, id+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("write snapshot history: %w", err)
+	}
+
+	ids, err := historyIDs(dir)
+	if err != nil {
+		return id, err
+	}
+	if len(ids) > historyRetention {
+		for _, old := range ids[:len(ids)-historyRetention] {
+			if err := os.Remove(filepath.Join(dir, old+".json")); err != nil {
+				return id, fmt.Errorf("prune snapshot history: %w", err)
+			}
+		}
+	}
+	return id, nil
+}
+
+// historyIDs returns the IDs in dir, oldest first.
+func historyIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		bi, ni := splitHistoryID(ids[i])
+		bj, nj := splitHistoryID(ids[j])
+		if bi != bj {
+			return bi < bj
+		}
+		return ni < nj
+	})
+	return ids, nil
+}
+
+// splitHistoryID splits id into its timestamp and same-second counter, 1
+// for an ID without a suffix, so "-10" sorts after "-9".
+func splitHistoryID(id string) (string, int) {
+	if len(id) > len(historyIDFormat)+1 && id[len(historyIDFormat)] == '-' {
+		if n, err := strconv.Atoi(id[len(historyIDFormat)+1:]); err == nil {
+			return id[:len(historyIDFormat)], n
+		}
+	}
+	return id, 1
+}
+
+// ListHistory returns the local snapshot history, newest first. Encrypted
+// entries are listed without their hostname and capture time rather than
+// asking for a passphrase. A missing history is empty, not an error.
+func ListHistory() ([]HistoryEntry, error) {
+	dir := HistoryDir()
+	ids, err := historyIDs(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read snapshot history: %w", err)
+	}
+	entries := make([]HistoryEntry, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		e := HistoryEntry{ID: ids[i], Path: filepath.Join(dir, ids[i]+".json")}
+		data, err := os.ReadFile(e.Path)
+		if err != nil {
+			continue
+		}
+		e.Size = int64(len(data))
+		if envelope.IsSealed(data) {
+			e.Encrypted = true
+		} else {
+			var head struct {
+				Hostname   string    `json:"hostname"`
+				CapturedAt time.Time `json:"captured_at"`
+			}
+			_ = json.Unmarshal(data, &head)
+			e.Hostname, e.CapturedAt = head.Hostname, head.CapturedAt
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// FindHistory resolves ref to a history entry: "latest", an ID, or a
+// unique ID prefix such as "20261018".
+func FindHistory(ref string) (HistoryEntry, error) {
+	entries, err := ListHistory()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if len(entries) == 0 {
+		return HistoryEntry{}, fmt.Errorf("no snapshot history yet; save one with 'openboot snapshot --local'")
+	}
+	if ref == "latest" {
+		return entries[0], nil
+	}
+	var matches []HistoryEntry
+	for _, e := range entries {
+		if e.ID == ref {
+			return e, nil
+		}
+		if strings.HasPrefix(e.ID, ref) {
+			matches = append(matches, e)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return HistoryEntry{}, fmt.Errorf("no snapshot %q in history; run 'openboot snapshot list'", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return HistoryEntry{}, fmt.Errorf("snapshot %q is ambiguous (%d matches); use more of the ID", ref, len(matches))
+	}
+}
+
+// LoadHistory loads the history snapshot ref names (see FindHistory).
+func LoadHistory(ref string) (HistoryEntry, *Snapshot, error) {
+	e, err := FindHistory(ref)
+	if err != nil {
+		return HistoryEntry{}, nil, err
+	}
+	snap, err := LoadFile(e.Path)
+	if err != nil {
+		return e, nil, err
+	}
+	return e, snap, nil
+}
@@ -0,0 +1,144 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLocal_AddsHistoryEntry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	at := time.Date(2026, 9, 1, 10, 30, 0, 0, time.UTC)
+	snap := &Snapshot{Version: 1, CapturedAt: at, Hostname: "work-mac", Packages: PackageSnapshot{Formulae: []string{"git"}}}
+	_, err := SaveLocal(snap, false)
+	require.NoError(t, err)
+	// Same capture time again: the ID gets a suffix instead of overwriting.
+	_, err = SaveLocal(snap, false)
+	require.NoError(t, err)
+
+	entries, err := ListHistory()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "20260901-103000-2", entries[0].ID)
+	assert.Equal(t, "20260901-103000", entries[1].ID)
+	assert.Equal(t, "work-mac", entries[0].Hostname)
+	assert.True(t, entries[0].CapturedAt.Equal(at))
+	assert.Positive(t, entries[0].Size)
+	assert.False(t, entries[0].Encrypted)
+
+	info, err := os.Stat(entries[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestSaveLocal_DryRunSkipsHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := SaveLocal(&Snapshot{Version: 1, CapturedAt: time.Now()}, true)
+	require.NoError(t, err)
+	entries, err := ListHistory()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSaveLocalEncrypted_HistoryStaysSealed(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	snap := &Snapshot{Version: 1, CapturedAt: time.Now(), Hostname: "secret-mac"}
+	_, err := SaveLocalEncrypted(snap, []byte("passphrase"), false)
+	require.NoError(t, err)
+
+	entries, err := ListHistory()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].Encrypted)
+	assert.Empty(t, entries[0].Hostname)
+	data, err := os.ReadFile(entries[0].Path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-mac")
+}
+
+func TestSaveHistory_PrunesOldest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range historyRetention + 3 {
+		_, err := saveHistory([]byte("{}"), start.Add(time.Duration(i)*time.Hour), false)
+		require.NoError(t, err)
+	}
+
+	ids, err := historyIDs(HistoryDir())
+	require.NoError(t, err)
+	require.Len(t, ids, historyRetention)
+	assert.Equal(t, "20260101-030000", ids[0])
+	assert.Equal(t, start.Add(time.Duration(historyRetention+2)*time.Hour).Format(historyIDFormat), ids[len(ids)-1])
+}
+
+func TestHistoryIDs_OrdersSameSecondSaves(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var saved []string
+	for range 12 {
+		id, err := saveHistory([]byte("{}"), at, false)
+		require.NoError(t, err)
+		saved = append(saved, id)
+	}
+	_, err := saveHistory([]byte("{}"), at.Add(time.Second), false)
+	require.NoError(t, err)
+
+	ids, err := historyIDs(HistoryDir())
+	require.NoError(t, err)
+	assert.Equal(t, "20260101-000000-12", saved[len(saved)-1])
+	assert.Equal(t, append(saved, "20260101-000001"), ids)
+}
+
+func TestFindHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := FindHistory("latest")
+	assert.ErrorContains(t, err, "no snapshot history")
+
+	for _, at := range []time.Time{
+		time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 2, 9, 0, 0, 0, time.UTC),
+	} {
+		_, err := saveHistory(fmt.Appendf(nil, `{"version":1,"hostname":"mac","captured_at":%q}`, at.Format(time.RFC3339)), at, false)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "latest", want: "20260902-090000"},
+		{ref: "20260801-090000", want: "20260801-090000"},
+		{ref: "202608", want: "20260801-090000"},
+		{ref: "202609", wantErr: "ambiguous"},
+		{ref: "2025", wantErr: "no snapshot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			e, err := FindHistory(tt.ref)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.ID)
+		})
+	}
+
+	e, snap, err := LoadHistory("202608")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(HistoryDir(), "20260801-090000.json"), e.Path)
+	assert.Equal(t, "mac", snap.Hostname)
+}
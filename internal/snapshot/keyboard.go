@@ -0,0 +1,182 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// customMenuAppsKey in com.apple.universalaccess lists the apps that have
+// shortcuts in Keyboard settings → App Shortcuts.
+const customMenuAppsKey = "com.apple.custommenu.apps"
+
+// readDefaultsJSON, readCustomMenuApps and readUserKeyMapping wrap the
+// defaults, plutil and hidutil reads so tests can supply fixtures.
+var (
+	// readDefaultsJSON returns one key of a defaults domain as JSON. The
+	// domain is passed as an argument, not spliced into the script.
+	readDefaultsJSON = func(ctx context.Context, domain, key string) (string, error) {
+		return system.RunCommandOutputContext(ctx, "sh", "-c",
+			`defaults export "$1" - | plutil -extract "$2" json -o - -`, "sh", domain, key)
+	}
+	readCustomMenuApps = func(ctx context.Context) (string, error) {
+		return system.RunCommandOutputContext(ctx, "defaults", "read", "com.apple.universalaccess", customMenuAppsKey)
+	}
+	readUserKeyMapping = func(ctx context.Context) (string, error) {
+		return system.RunCommandOutputContext(ctx, "hidutil", "property", "--get", "UserKeyMapping")
+	}
+)
+
+// CaptureKeyboard returns the user's app menu shortcuts, system shortcut
+// settings and hidutil key remapping. It returns nil when there are none
+// (e.g. off macOS).
+func CaptureKeyboard() (*config.RemoteKeyboardConfig, error) {
+	return captureKeyboard(context.Background())
+}
+
+func captureKeyboard(ctx context.Context) (*config.RemoteKeyboardConfig, error) {
+	k := &config.RemoteKeyboardConfig{
+		AppShortcuts:    captureAppShortcuts(ctx),
+		SymbolicHotKeys: captureSymbolicHotKeys(ctx),
+	}
+	if out, err := readUserKeyMapping(ctx); err == nil {
+		k.KeyMappings = parseUserKeyMapping(out)
+	}
+	if k.Empty() {
+		return nil, nil
+	}
+	return k, nil
+}
+
+// captureAppShortcuts reads NSUserKeyEquivalents from the global domain and
+// from every app Keyboard settings lists as having shortcuts.
+func captureAppShortcuts(ctx context.Context) map[string]map[string]string {
+	domains := []string{config.KeyboardGlobalDomain}
+	if out, err := readCustomMenuApps(ctx); err == nil {
+		domains = append(domains, parseDefaultsArray(out)...)
+	}
+	shortcuts := make(map[string]map[string]string)
+	for _, d := range domains {
+		out, err := readDefaultsJSON(ctx, d, "NSUserKeyEquivalents")
+		if err != nil {
+			continue
+		}
+		var equivs map[string]string
+		if json.Unmarshal([]byte(out), &equivs) != nil || len(equivs) == 0 {
+			continue
+		}
+		shortcuts[d] = equivs
+	}
+	if len(shortcuts) == 0 {
+		return nil
+	}
+	return shortcuts
+}
+
+func captureSymbolicHotKeys(ctx context.Context) []config.SymbolicHotKey {
+	out, err := readDefaultsJSON(ctx, "com.apple.symbolichotkeys", "AppleSymbolicHotKeys")
+	if err != nil {
+		return nil
+	}
+	hotkeys, err := parseSymbolicHotKeysJSON([]byte(out))
+	if err != nil {
+		return nil
+	}
+	return hotkeys
+}
+
+// parseSymbolicHotKeysJSON reads the AppleSymbolicHotKeys dictionary as
+// plutil prints it in JSON: {"64": {"enabled": true, "value":
+// {"parameters": [65535, 49, 1048576], "type": "standard"}}}. Entries are
+// returned sorted by ID.
+func parseSymbolicHotKeysJSON(data []byte) ([]config.SymbolicHotKey, error) {
+	var raw map[string]struct {
+		Enabled any `json:"enabled"`
+		Value   struct {
+			Parameters []int `json:"parameters"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse symbolic hotkeys: %w", err)
+	}
+	hotkeys := make([]config.SymbolicHotKey, 0, len(raw))
+	for id, e := range raw {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		h := config.SymbolicHotKey{ID: n, Enabled: plistBool(e.Enabled)}
+		if len(e.Value.Parameters) == 3 {
+			h.Parameters = e.Value.Parameters
+		}
+		hotkeys = append(hotkeys, h)
+	}
+	sort.Slice(hotkeys, func(i, j int) bool { return hotkeys[i].ID < hotkeys[j].ID })
+	return hotkeys, nil
+}
+
+// plistBool reads a plist boolean that plutil may print as true/false or,
+// when it was written as an integer, 1/0.
+func plistBool(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case float64:
+		return b != 0
+	default:
+		return false
+	}
+}
+
+// parseDefaultsArray reads a string array as `defaults read` prints it:
+//
+//	(
+//	    "com.apple.Safari",
+//	    "com.apple.mail"
+//	)
+func parseDefaultsArray(out string) []string {
+	out = strings.TrimSpace(out)
+	out = strings.TrimSuffix(strings.TrimPrefix(out, "("), ")")
+	var items []string
+	for _, item := range strings.Split(out, ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"`)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+var hidMappingRe = regexp.MustCompile(`HIDKeyboardModifierMapping(Src|Dst)\s*=\s*(\d+)`)
+
+// parseUserKeyMapping reads `hidutil property --get UserKeyMapping`, an
+// array of dictionaries with a source and destination usage each, or
+// "(null)" when nothing is mapped.
+func parseUserKeyMapping(out string) []config.KeyMapping {
+	var mappings []config.KeyMapping
+	for _, entry := range strings.Split(out, "}") {
+		var src, dst uint64
+		for _, m := range hidMappingRe.FindAllStringSubmatch(entry, -1) {
+			v, err := strconv.ParseUint(m[2], 10, 64)
+			if err != nil {
+				continue
+			}
+			if m[1] == "Src" {
+				src = v
+			} else {
+				dst = v
+			}
+		}
+		if src != 0 && dst != 0 {
+			mappings = append(mappings, config.KeyMapping{From: config.KeyName(src), To: config.KeyName(dst)})
+		}
+	}
+	return mappings
+}
@@ -0,0 +1,77 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func stubKeyboardReads(t *testing.T, defaults map[string]string, menuApps, mapping string) {
+	t.Helper()
+	origDefaults, origApps, origMapping := readDefaultsJSON, readCustomMenuApps, readUserKeyMapping
+	t.Cleanup(func() { readDefaultsJSON, readCustomMenuApps, readUserKeyMapping = origDefaults, origApps, origMapping })
+	readDefaultsJSON = func(_ context.Context, domain, key string) (string, error) {
+		if out, ok := defaults[domain+" "+key]; ok {
+			return out, nil
+		}
+		return "", errors.New("no such key")
+	}
+	readCustomMenuApps = func(context.Context) (string, error) {
+		if menuApps == "" {
+			return "", errors.New("no such key")
+		}
+		return menuApps, nil
+	}
+	readUserKeyMapping = func(context.Context) (string, error) { return mapping, nil }
+}
+
+func TestCaptureKeyboard(t *testing.T) {
+	stubKeyboardReads(t, map[string]string{
+		"NSGlobalDomain NSUserKeyEquivalents":            `{"Minimize":"@m"}`,
+		"com.apple.Safari NSUserKeyEquivalents":          `{"Show All Tabs":"@~t"}`,
+		"com.apple.symbolichotkeys AppleSymbolicHotKeys": `{"64":{"enabled":false,"value":{"parameters":[32,49,1048576],"type":"standard"}},"60":{"enabled":1},"x":{}}`,
+	}, "(\n    \"com.apple.Safari\",\n    \"com.apple.mail\"\n)", `(
+        {
+        HIDKeyboardModifierMappingDst = 30064771113;
+        HIDKeyboardModifierMappingSrc = 30064771129;
+    },
+        {
+        HIDKeyboardModifierMappingDst = 1095216660483;
+        HIDKeyboardModifierMappingSrc = 30064771302;
+    }
+)`)
+
+	k, err := CaptureKeyboard()
+	require.NoError(t, err)
+	require.NotNil(t, k)
+	assert.Equal(t, map[string]map[string]string{
+		"NSGlobalDomain":   {"Minimize": "@m"},
+		"com.apple.Safari": {"Show All Tabs": "@~t"},
+	}, k.AppShortcuts, "com.apple.mail has no shortcuts")
+	assert.Equal(t, []config.SymbolicHotKey{
+		{ID: 60, Enabled: true},
+		{ID: 64, Enabled: false, Parameters: []int{32, 49, 1048576}},
+	}, k.SymbolicHotKeys)
+	assert.Equal(t, []config.KeyMapping{
+		{From: "caps_lock", To: "escape"},
+		{From: "right_option", To: "0xFF00000003"},
+	}, k.KeyMappings)
+}
+
+func TestCaptureKeyboard_Nothing(t *testing.T) {
+	stubKeyboardReads(t, nil, "", "(null)")
+	k, err := CaptureKeyboard()
+	require.NoError(t, err)
+	assert.Nil(t, k)
+}
+
+func TestParseDefaultsArray(t *testing.T) {
+	assert.Equal(t, []string{"com.apple.Safari", "com.googlecode.iterm2"},
+		parseDefaultsArray("(\n    \"com.apple.Safari\",\n    \"com.googlecode.iterm2\"\n)\n"))
+	assert.Empty(t, parseDefaultsArray("(\n)"))
+}
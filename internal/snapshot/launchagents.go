@@ -0,0 +1,40 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// LaunchAgentsDir holds the user's LaunchAgents, relative to the home
+// directory.
+const LaunchAgentsDir = "Library/LaunchAgents"
+
+// CaptureLaunchAgents returns the LaunchAgents openboot generated, sorted
+// by label. Agents without openboot's marker — installed by apps or
+// written by hand — are not captured.
+func CaptureLaunchAgents() ([]config.LaunchAgent, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(home, LaunchAgentsDir, "*.plist"))
+	if err != nil {
+		return nil, nil
+	}
+	var agents []config.LaunchAgent
+	for _, f := range files {
+		data, err := os.ReadFile(f) //nolint:gosec // user's own LaunchAgents directory
+		if err != nil {
+			continue
+		}
+		if a, ok := config.ParseLaunchAgent(data, home); ok {
+			agents = append(agents, a)
+		}
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Label < agents[j].Label })
+	return agents, nil
+}
@@ -0,0 +1,38 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func TestCaptureLaunchAgents(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	agents, err := CaptureLaunchAgents()
+	require.NoError(t, err)
+	assert.Empty(t, agents)
+
+	dir := filepath.Join(home, LaunchAgentsDir)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	managed := []config.LaunchAgent{
+		{Label: "com.example.sync", ProgramArguments: []string{"~/bin/sync"}, StartInterval: 60},
+		{Label: "com.example.colima", ProgramArguments: []string{"/opt/homebrew/bin/colima", "start"}, RunAtLoad: true},
+	}
+	for _, a := range managed {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, a.File()), a.Plist(home), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "com.vendor.updater.plist"), []byte(`<?xml version="1.0"?>
+<plist version="1.0"><dict><key>Label</key><string>com.vendor.updater</string>
+<key>ProgramArguments</key><array><string>/usr/local/bin/updater</string></array></dict></plist>`), 0644))
+
+	agents, err = CaptureLaunchAgents()
+	require.NoError(t, err)
+	assert.Equal(t, []config.LaunchAgent{managed[1], managed[0]}, agents)
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/openbootdotdev/openboot/internal/envelope"
+	"github.com/openbootdotdev/openboot/internal/signing"
+	"github.com/openbootdotdev/openboot/internal/snapshot/schema"
 )
 
 func LocalPath() string {
@@ -16,6 +20,16 @@ func LocalPath() string {
 }
 
 func SaveLocal(snap *Snapshot, dryRun bool) (string, error) {
+	return saveLocal(snap, nil, dryRun)
+}
+
+// SaveLocalEncrypted is SaveLocal with the file sealed under passphrase.
+// LoadFile and ParseBytes open it transparently.
+func SaveLocalEncrypted(snap *Snapshot, passphrase []byte, dryRun bool) (string, error) {
+	return saveLocal(snap, passphrase, dryRun)
+}
+
+func saveLocal(snap *Snapshot, passphrase []byte, dryRun bool) (string, error) {
 	if dryRun {
 		return "", nil
 	}
@@ -31,6 +45,11 @@ func SaveLocal(snap *Snapshot, dryRun bool) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("marshal snapshot: %w", err)
 	}
+	if passphrase != nil {
+		if data, err = envelope.Seal(data, passphrase); err != nil {
+			return "", fmt.Errorf("encrypt snapshot: %w", err)
+		}
+	}
 
 	tmpPath := path + ".tmp"
 	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
@@ -40,6 +59,9 @@ func SaveLocal(snap *Snapshot, dryRun bool) (string, error) {
 		_ = os.Remove(tmpPath)
 		return "", fmt.Errorf("rename snapshot: %w", err)
 	}
+	if _, err := saveHistory(data, snap.CapturedAt, dryRun); err != nil {
+		return path, err
+	}
 
 	return path, nil
 }
@@ -56,19 +78,89 @@ func LoadFile(path string) (*Snapshot, error) {
 		}
 		return nil, fmt.Errorf("read snapshot: %w", err)
 	}
+	return ParseBytes(data)
+}
 
+// ParseBytes parses a snapshot, decrypting it first when it is sealed and
+// migrating it when it was written in an older format.
+func ParseBytes(data []byte) (*Snapshot, error) {
+	data, err := envelope.Unseal(data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt snapshot: %w", err)
+	}
+	if data, _, err = schema.Migrate(data); err != nil {
+		return nil, err
+	}
 	var snap Snapshot
 	if err := json.Unmarshal(data, &snap); err != nil {
 		return nil, fmt.Errorf("parse snapshot: %w", err)
 	}
-
 	return &snap, nil
 }
 
-func ParseBytes(data []byte) (*Snapshot, error) {
-	var snap Snapshot
-	if err := json.Unmarshal(data, &snap); err != nil {
-		return nil, fmt.Errorf("parse snapshot: %w", err)
+// MigrateResult describes what MigrateFile did.
+type MigrateResult struct {
+	From, To         int
+	Backup           string // copy of the original file, when rewritten
+	DroppedSignature bool   // the file was signed; sign it again
+}
+
+// MigrateFile rewrites the snapshot at path in the current format and
+// keeps the original as <path>.v<N>.bak. A file already current is left
+// alone. Encrypted files are refused, since sealing them again needs the
+// passphrase; every reader migrates them in memory anyway. A signature is
+// dropped because it cannot cover the rewritten file.
+func MigrateFile(path string, dryRun bool) (MigrateResult, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-supplied snapshot path
+	if err != nil {
+		return MigrateResult{}, fmt.Errorf("read snapshot: %w", err)
 	}
-	return &snap, nil
+	if envelope.IsSealed(data) {
+		return MigrateResult{}, fmt.Errorf("%s is encrypted; encrypted snapshots are migrated when they are read", path)
+	}
+	doc, err := schema.Decode(data)
+	if err != nil {
+		return MigrateResult{}, err
+	}
+	from, err := schema.Version(doc)
+	if err != nil {
+		return MigrateResult{}, err
+	}
+	res := MigrateResult{From: from, To: from}
+	if from == schema.Current {
+		return res, nil
+	}
+	if err := schema.MigrateDoc(doc); err != nil {
+		return res, err
+	}
+	res.To = schema.Current
+	if _, ok := doc[signing.Field]; ok {
+		delete(doc, signing.Field)
+		res.DroppedSignature = true
+	}
+	out, err := schema.Encode(doc)
+	if err != nil {
+		return res, err
+	}
+	// Reject anything the current reader cannot load before touching the file.
+	var check Snapshot
+	if err := json.Unmarshal(out, &check); err != nil {
+		return res, fmt.Errorf("migrated snapshot does not parse: %w", err)
+	}
+	if dryRun {
+		return res, nil
+	}
+
+	res.Backup = fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(res.Backup, data, 0600); err != nil {
+		return res, fmt.Errorf("back up snapshot: %w", err)
+	}
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, append(out, '\n'), mode); err != nil {
+		return res, fmt.Errorf("write migrated snapshot: %w", err)
+	}
+	return res, nil
 }
@@ -9,6 +9,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/envelope"
+	"github.com/openbootdotdev/openboot/internal/snapshot/schema"
 )
 
 // TestLocalPath tests the LocalPath function.
@@ -34,7 +37,7 @@ func TestSaveLocal_CreatesDirectory(t *testing.T) {
 	testPath := filepath.Join(tmpDir, ".openboot", "snapshot.json")
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now(),
 		Hostname:   "test",
 		Packages: PackageSnapshot{
@@ -59,13 +62,39 @@ func TestSaveLocal_CreatesDirectory(t *testing.T) {
 	assert.True(t, info.IsDir())
 }
 
+func TestSaveLocalEncrypted_RoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(envelope.PassphraseEnv, "")
+
+	snap := &Snapshot{Version: 1, Hostname: "test", Packages: PackageSnapshot{Formulae: []string{"git"}}}
+	path, err := SaveLocalEncrypted(snap, []byte("passphrase"), false)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, envelope.IsSealed(data))
+	assert.NotContains(t, string(data), "git")
+
+	_, err = LoadFile(path)
+	assert.ErrorContains(t, err, envelope.PassphraseEnv)
+
+	t.Setenv(envelope.PassphraseEnv, "passphrase")
+	loaded, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git"}, loaded.Packages.Formulae)
+
+	t.Setenv(envelope.PassphraseEnv, "wrong")
+	_, err = ParseBytes(data)
+	assert.ErrorIs(t, err, envelope.ErrPassphrase)
+}
+
 // TestLoadFile_ValidSnapshot tests loading a valid snapshot file.
 func TestLoadFile_ValidSnapshot(t *testing.T) {
 	tmpDir := t.TempDir()
 	testFile := filepath.Join(tmpDir, "snapshot.json")
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "test-machine",
 		Packages: PackageSnapshot{
@@ -160,7 +189,7 @@ func TestLoadFile_LargeSnapshot(t *testing.T) {
 	}
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "large-machine",
 		Packages: PackageSnapshot{
@@ -190,7 +219,7 @@ func TestLoadFile_WithAllFields(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "full.json")
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "full-machine",
 		Packages: PackageSnapshot{
@@ -244,7 +273,7 @@ func TestLoadFile_WithNilPackages(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "nil.json")
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "nil-machine",
 		Packages: PackageSnapshot{
@@ -275,7 +304,7 @@ func TestLoadFile_WithEmptyPackages(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "empty.json")
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "empty-machine",
 		Packages: PackageSnapshot{
@@ -305,7 +334,7 @@ func TestLoadFile_RoundTrip(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "roundtrip.json")
 
 	original := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "roundtrip-machine",
 		Packages: PackageSnapshot{
@@ -341,7 +370,7 @@ func TestLoadFile_SpecialCharacters(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "special.json")
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "special-machine",
 		Packages: PackageSnapshot{
@@ -370,7 +399,7 @@ func TestLoadFile_FilePermissions(t *testing.T) {
 	testFile := filepath.Join(tmpDir, "restricted.json")
 
 	snap := &Snapshot{
-		Version:    1,
+		Version:    schema.Current,
 		CapturedAt: time.Now().Truncate(time.Millisecond),
 		Hostname:   "test",
 		Packages: PackageSnapshot{
@@ -388,3 +417,61 @@ func TestLoadFile_FilePermissions(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, loaded)
 }
+
+func TestParseBytes_HistoricalShapes(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("schema", "testdata", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+	for _, f := range fixtures {
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			data, err := os.ReadFile(f)
+			require.NoError(t, err)
+			snap, err := ParseBytes(data)
+			require.NoError(t, err)
+			assert.Equal(t, schema.Current, snap.Version)
+			assert.NotEmpty(t, snap.Hostname)
+		})
+	}
+
+	_, err = ParseBytes([]byte(`{"version": 99, "hostname": "future"}`))
+	assert.ErrorIs(t, err, schema.ErrNewer)
+}
+
+func TestMigrateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "old.json")
+	v1 := `{"version":1,"hostname":"old","packages":[{"name":"docker","type":"cask"}],"signature":{"alg":"ed25519","key":"k","sig":"s"}}`
+	require.NoError(t, os.WriteFile(path, []byte(v1), 0600))
+
+	res, err := MigrateFile(path, true)
+	require.NoError(t, err)
+	assert.Equal(t, MigrateResult{From: 1, To: schema.Current, DroppedSignature: true}, res)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, v1, string(data), "dry run leaves the file alone")
+
+	res, err = MigrateFile(path, false)
+	require.NoError(t, err)
+	assert.Equal(t, path+".v1.bak", res.Backup)
+	backup, err := os.ReadFile(res.Backup)
+	require.NoError(t, err)
+	assert.Equal(t, v1, string(backup))
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "signature")
+	snap, err := ParseBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker"}, snap.Packages.Casks)
+
+	res, err = MigrateFile(path, false)
+	require.NoError(t, err)
+	assert.Equal(t, MigrateResult{From: schema.Current, To: schema.Current}, res)
+
+	sealed := filepath.Join(dir, "sealed.json")
+	data, err = envelope.Seal([]byte(v1), []byte("passphrase"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(sealed, data, 0600))
+	_, err = MigrateFile(sealed, false)
+	assert.ErrorContains(t, err, "encrypted")
+}
@@ -1,6 +1,7 @@
 package snapshot
 
 import (
+	"context"
 	"strings"
 
 	"github.com/openbootdotdev/openboot/internal/system"
@@ -23,7 +24,11 @@ end tell`
 // Returns ([]LoginItem{}, nil) when none are registered or when System
 // Events denies access — capture is best-effort.
 func CaptureLoginItems() ([]LoginItem, error) {
-	out, err := system.RunCommandOutput("osascript", "-e", loginItemsScript)
+	return captureLoginItems(context.Background())
+}
+
+func captureLoginItems(ctx context.Context) ([]LoginItem, error) {
+	out, err := system.RunCommandOutputContext(ctx, "osascript", "-e", loginItemsScript)
 	if err != nil {
 		return []LoginItem{}, nil
 	}
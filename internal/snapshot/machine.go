@@ -0,0 +1,38 @@
+package snapshot
+
+import (
+	"context"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// scutilGet reads one scutil name, returning "" when it was never set. It is
+// a var so tests can run without scutil.
+var scutilGet = func(ctx context.Context, key string) string {
+	out, err := system.RunCommandOutputContext(ctx, "scutil", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// CaptureMachine reads the machine's ComputerName, LocalHostName and
+// HostName. They describe this machine rather than its setup, so they feed
+// diff and sync but are not carried into configs built from a snapshot. It
+// returns nil when scutil reports none (e.g. off macOS).
+func CaptureMachine() (*config.RemoteMachineConfig, error) {
+	return captureMachine(context.Background())
+}
+
+func captureMachine(ctx context.Context) (*config.RemoteMachineConfig, error) {
+	m := &config.RemoteMachineConfig{
+		ComputerName:  scutilGet(ctx, "ComputerName"),
+		LocalHostName: scutilGet(ctx, "LocalHostName"),
+		HostName:      scutilGet(ctx, "HostName"),
+	}
+	if m.Empty() {
+		return nil, nil
+	}
+	return m, nil
+}
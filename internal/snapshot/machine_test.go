@@ -0,0 +1,57 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func TestCaptureMachine(t *testing.T) {
+	orig := scutilGet
+	t.Cleanup(func() { scutilGet = orig })
+
+	scutilGet = func(context.Context, string) string { return "" }
+	m, err := CaptureMachine()
+	require.NoError(t, err)
+	assert.Nil(t, m, "no names, e.g. off macOS")
+
+	names := map[string]string{"ComputerName": "Jane's MacBook Pro", "LocalHostName": "jane-mbp"}
+	scutilGet = func(_ context.Context, key string) string { return names[key] }
+	m, err = CaptureMachine()
+	require.NoError(t, err)
+	assert.Equal(t, &config.RemoteMachineConfig{ComputerName: "Jane's MacBook Pro", LocalHostName: "jane-mbp"}, m)
+}
+
+// A step that overruns its timeout must see its context cancelled, so the
+// command it is waiting on is killed rather than left running.
+func TestCaptureMachineStep_TimeoutCancelsCommand(t *testing.T) {
+	orig := scutilGet
+	t.Cleanup(func() { scutilGet = orig })
+	cancelled := make(chan struct{})
+	scutilGet = func(ctx context.Context, key string) string {
+		<-ctx.Done()
+		if key == "ComputerName" {
+			close(cancelled)
+		}
+		return ""
+	}
+
+	var step captureStep
+	for _, s := range captureSteps {
+		if s.name == "Machine Name" {
+			step = s
+		}
+	}
+	_, err := runCaptureStep(step, 10*time.Millisecond)
+	require.Error(t, err)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("scutil was not cancelled")
+	}
+}
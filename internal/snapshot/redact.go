@@ -0,0 +1,54 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/redact"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// Redact returns a copy of snap with this machine's hostnames, home
+// directory, email addresses, private repository URLs and the patterns in
+// cfg redacted, and the list of what was changed. snap is not modified.
+func Redact(snap *Snapshot, cfg redact.Config) (*Snapshot, []redact.Finding, error) {
+	home, _ := system.HomeDir()
+	r, err := redact.New(cfg, home, snapshotHostnames(snap))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal snapshot: %w", err)
+	}
+	var out Snapshot
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, nil, fmt.Errorf("copy snapshot: %w", err)
+	}
+	findings, err := r.Redact(&out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &out, findings, nil
+}
+
+// snapshotHostnames lists the names snap's machine goes by, with and
+// without a domain.
+func snapshotHostnames(snap *Snapshot) []string {
+	names := []string{snap.Hostname}
+	if h, err := os.Hostname(); err == nil {
+		names = append(names, h)
+	}
+	for _, n := range snap.Machine.Names() {
+		names = append(names, n.Value)
+	}
+	for _, n := range names {
+		if short, _, ok := strings.Cut(n, "."); ok {
+			names = append(names, short)
+		}
+	}
+	return names
+}
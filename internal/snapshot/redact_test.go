@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/redact"
+)
+
+func TestRedact(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	snap := &Snapshot{
+		Hostname: "alice-mbp.local",
+		Machine:  &config.RemoteMachineConfig{ComputerName: "Alice's MacBook Pro", LocalHostName: "alice-mbp"},
+		Git:      GitSnapshot{UserName: "Alice", UserEmail: "alice@example.com"},
+		Dotfiles: DotfilesSnapshot{RepoURL: "https://git.acme.internal/alice/dotfiles.git"},
+		LoginItems: []LoginItem{
+			{Name: "Sync", Path: home + "/Applications/Sync.app"},
+		},
+	}
+
+	out, findings, err := Redact(snap, redact.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "<redacted:hostname>", out.Hostname)
+	assert.Equal(t, "<redacted:hostname>", out.Machine.LocalHostName)
+	assert.Equal(t, "<redacted:hostname>", out.Machine.ComputerName)
+	assert.Equal(t, "<redacted:email>", out.Git.UserEmail)
+	assert.Equal(t, "Alice", out.Git.UserName)
+	assert.Equal(t, "<redacted:repo>", out.Dotfiles.RepoURL)
+	assert.Equal(t, "~/Applications/Sync.app", out.LoginItems[0].Path)
+	assert.Len(t, findings, 6)
+
+	// The original is untouched.
+	assert.Equal(t, "alice@example.com", snap.Git.UserEmail)
+	assert.Equal(t, home+"/Applications/Sync.app", snap.LoginItems[0].Path)
+
+	_, _, err = Redact(snap, redact.Config{Disable: []string{"phone"}})
+	assert.Error(t, err)
+}
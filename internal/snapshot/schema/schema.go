@@ -0,0 +1,137 @@
+// Package schema versions the snapshot file format and upgrades older
+// files to the current one.
+//
+// Every change to the shape of a snapshot bumps Current and registers a
+// Migration from the previous version. Readers run Migrate on the raw JSON
+// before decoding it, so the Snapshot types only ever see the current
+// shape. The package works on plain JSON and imports nothing from
+// openboot, so both internal/snapshot and internal/config can use it.
+//
+// Version history:
+//
+//	1  packages as a {formulae,casks,taps,npm,bun} object of names or of
+//	   {name,desc} objects, a typed [{name,type,desc}] array, or a flat
+//	   array of formula names; shell framework only as the oh_my_zsh flag.
+//	   Files without a version are version 1.
+//	2  packages always a {formulae,casks,taps,npm,bun} object of names,
+//	   with descriptions in packages.descriptions; shell.framework set.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Current is the snapshot schema version this build writes.
+const Current = 2
+
+// ErrNewer is returned for a file written by a newer openboot.
+var ErrNewer = errors.New("snapshot format is newer than this openboot supports")
+
+// Migration upgrades a decoded snapshot from version From to From+1 in
+// place.
+type Migration struct {
+	From    int
+	Summary string
+	Apply   func(doc map[string]any) error
+}
+
+// migrations is the upgrade chain, one entry per version step, in order.
+var migrations = []Migration{
+	{From: 1, Summary: "normalize packages to an object of names; set shell.framework", Apply: v1ToV2},
+}
+
+// Version returns the schema version of a decoded snapshot.
+func Version(doc map[string]any) (int, error) {
+	raw, ok := doc["version"]
+	if !ok || raw == nil {
+		return 1, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("snapshot version must be a number, got %v", raw)
+	}
+	v, err := n.Int64()
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid snapshot version %s", n)
+	}
+	if v == 0 {
+		return 1, nil
+	}
+	return int(v), nil
+}
+
+// Migrate upgrades the snapshot in data to Current and returns it with the
+// version it started at. Data already at Current is returned unchanged.
+func Migrate(data []byte) ([]byte, int, error) {
+	doc, err := Decode(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	from, err := Version(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from == Current {
+		return data, from, nil
+	}
+	if err := MigrateDoc(doc); err != nil {
+		return nil, from, err
+	}
+	out, err := Encode(doc)
+	if err != nil {
+		return nil, from, err
+	}
+	return out, from, nil
+}
+
+// MigrateDoc upgrades a decoded snapshot to Current in place.
+func MigrateDoc(doc map[string]any) error {
+	v, err := Version(doc)
+	if err != nil {
+		return err
+	}
+	if v > Current {
+		return fmt.Errorf("%w: file is v%d, this openboot reads up to v%d; upgrade openboot (brew upgrade openboot)", ErrNewer, v, Current)
+	}
+	for _, m := range migrations {
+		if m.From < v {
+			continue
+		}
+		if err := m.Apply(doc); err != nil {
+			return fmt.Errorf("migrate snapshot v%d to v%d: %w", m.From, m.From+1, err)
+		}
+		v = m.From + 1
+		doc["version"] = json.Number(fmt.Sprint(v))
+	}
+	return nil
+}
+
+// Decode parses a snapshot into the generic form MigrateDoc works on,
+// keeping numbers as written.
+func Decode(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse snapshot: %w", err)
+	}
+	if doc == nil {
+		return nil, errors.New("parse snapshot: not a JSON object")
+	}
+	return doc, nil
+}
+
+// Encode renders a migrated document as indented JSON.
+func Encode(doc map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode snapshot: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
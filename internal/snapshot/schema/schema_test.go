@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Set SCHEMA_UPDATE_GOLDEN=1 to rewrite the .golden files after an
+// intended change to a migration.
+const updateGoldenEnv = "SCHEMA_UPDATE_GOLDEN"
+
+// TestMigrate_Golden migrates every historical snapshot shape in testdata
+// and compares the result with its .golden file. Add a fixture here for
+// each new shape a version introduces.
+func TestMigrate_Golden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	for _, in := range inputs {
+		t.Run(filepath.Base(in), func(t *testing.T) {
+			data, err := os.ReadFile(in)
+			require.NoError(t, err)
+			out, from, err := Migrate(data)
+			require.NoError(t, err)
+
+			wantFrom := strings.TrimPrefix(strings.SplitN(filepath.Base(in), "-", 2)[0], "v")
+			assert.Equal(t, wantFrom, strconv.Itoa(from))
+
+			golden := strings.TrimSuffix(in, ".json") + ".golden"
+			if os.Getenv(updateGoldenEnv) != "" {
+				require.NoError(t, os.WriteFile(golden, append(out, '\n'), 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err, "run with %s=1 to create it", updateGoldenEnv)
+			if from == Current {
+				assert.Equal(t, string(data), string(out), "current files pass through untouched")
+			} else {
+				assert.Equal(t, strings.TrimSpace(string(want)), string(out))
+			}
+
+			// Migrated output is current and migrating it again is a no-op.
+			again, v, err := Migrate(out)
+			require.NoError(t, err)
+			assert.Equal(t, Current, v)
+			assert.Equal(t, string(out), string(again))
+		})
+	}
+}
+
+func TestMigrate_Newer(t *testing.T) {
+	_, _, err := Migrate([]byte(`{"version": 99, "packages": {}}`))
+	assert.ErrorIs(t, err, ErrNewer)
+	assert.ErrorContains(t, err, "upgrade openboot")
+}
+
+func TestMigrate_Invalid(t *testing.T) {
+	for _, in := range []string{
+		`[]`,
+		`{"version": "two"}`,
+		`{"version": 1, "packages": 42}`,
+		`{"version": 1, "packages": {"formulae": "git"}}`,
+		`{"version": 1, "packages": [{"type": "cask"}]}`,
+	} {
+		_, _, err := Migrate([]byte(in))
+		assert.Error(t, err, in)
+	}
+}
+
+func TestMigrations_Chain(t *testing.T) {
+	// One step per version, contiguous from 1 up to Current.
+	require.Len(t, migrations, Current-1)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.From)
+		assert.NotEmpty(t, m.Summary)
+		assert.NotNil(t, m.Apply)
+	}
+}
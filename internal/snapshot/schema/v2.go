@@ -0,0 +1,119 @@
+package schema
+
+import "fmt"
+
+// packageLists are the package kinds of a v2 packages object, in the order
+// they are written.
+var packageLists = []string{"formulae", "casks", "taps", "npm", "bun"}
+
+// v1ToV2 rewrites packages from any of its v1 shapes into the v2 object
+// and fills in shell.framework from the v1 oh_my_zsh flag.
+func v1ToV2(doc map[string]any) error {
+	if raw, ok := doc["packages"]; ok && raw != nil {
+		pkgs, err := v2Packages(raw)
+		if err != nil {
+			return fmt.Errorf("packages: %w", err)
+		}
+		doc["packages"] = pkgs
+	}
+	if shell, ok := doc["shell"].(map[string]any); ok {
+		fw, _ := shell["framework"].(string)
+		if omz, _ := shell["oh_my_zsh"].(bool); omz && fw == "" {
+			shell["framework"] = "oh-my-zsh"
+		}
+	}
+	return nil
+}
+
+func v2Packages(raw any) (map[string]any, error) {
+	lists := map[string][]any{}
+	descs := map[string]any{}
+	add := func(kind string, entry any) error {
+		name, desc, err := packageEntry(entry)
+		if err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+		lists[kind] = append(lists[kind], name)
+		if desc != "" {
+			descs[name] = desc
+		}
+		return nil
+	}
+
+	out := map[string]any{}
+	switch v := raw.(type) {
+	case map[string]any:
+		// Object of names or of {name,desc}: keep the lists it has.
+		for _, kind := range packageLists {
+			entries, ok := v[kind]
+			if !ok || entries == nil {
+				continue
+			}
+			arr, ok := entries.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an array", kind)
+			}
+			lists[kind] = []any{}
+			for _, e := range arr {
+				if err := add(kind, e); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case []any:
+		// Flat array of formula names, or typed [{name,type,desc}] array.
+		for _, e := range v {
+			kind := "formulae"
+			if obj, ok := e.(map[string]any); ok {
+				kind = typedKind(obj["type"])
+			}
+			if err := add(kind, e); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("must be an object {formulae,casks,taps,npm,bun} or an array")
+	}
+
+	for kind, names := range lists {
+		out[kind] = names
+	}
+	if len(descs) > 0 {
+		out["descriptions"] = descs
+	}
+	return out, nil
+}
+
+// packageEntry reads "name" or {"name": ..., "desc": ...}.
+func packageEntry(e any) (name, desc string, err error) {
+	switch v := e.(type) {
+	case string:
+		return v, "", nil
+	case map[string]any:
+		name, _ = v["name"].(string)
+		desc, _ = v["desc"].(string)
+		if name == "" {
+			return "", "", fmt.Errorf("entry without a name")
+		}
+		return name, desc, nil
+	default:
+		return "", "", fmt.Errorf("entry must be a name or an object, got %v", e)
+	}
+}
+
+// typedKind maps a v1 typed-array "type" to its list; anything unknown was
+// read as a formula.
+func typedKind(t any) string {
+	switch t {
+	case "cask":
+		return "casks"
+	case "tap":
+		return "taps"
+	case "npm":
+		return "npm"
+	case "bun":
+		return "bun"
+	default:
+		return "formulae"
+	}
+}
@@ -0,0 +1,53 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// listBrewServices returns the JSON `brew services list` prints, or ""
+// without Homebrew. It is a var so tests can capture without Homebrew.
+var listBrewServices = func(ctx context.Context) (string, error) {
+	if !isBrewInstalled() {
+		return "", nil
+	}
+	return system.RunCommandOutputContext(ctx, "brew", "services", "list", "--json")
+}
+
+// CaptureServices returns the Homebrew services that are running, sorted.
+// Stopped services are not recorded: restore starts what was running.
+func CaptureServices() ([]string, error) {
+	return captureServices(context.Background())
+}
+
+func captureServices(ctx context.Context) ([]string, error) {
+	out, err := listBrewServices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("brew services: %w", err)
+	}
+	if strings.TrimSpace(out) == "" {
+		return nil, nil
+	}
+	// The brew package has the full parser, but importing it here would
+	// cycle back through ui; name and status are all capture needs.
+	var services []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(out), &services); err != nil {
+		return nil, fmt.Errorf("parse brew services: %w", err)
+	}
+	var names []string
+	for _, s := range services {
+		if s.Status == "started" || s.Status == "scheduled" {
+			names = append(names, s.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
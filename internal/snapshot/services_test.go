@@ -0,0 +1,49 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubListBrewServices(t *testing.T, out string, err error) {
+	t.Helper()
+	orig := listBrewServices
+	t.Cleanup(func() { listBrewServices = orig })
+	listBrewServices = func(context.Context) (string, error) { return out, err }
+}
+
+func TestCaptureServices_RecordsRunningOnly(t *testing.T) {
+	stubListBrewServices(t, `[
+  {"name":"redis","status":"started"},
+  {"name":"postgresql@16","status":"none"},
+  {"name":"colima","status":"started"},
+  {"name":"unbound","status":"scheduled"},
+  {"name":"mysql","status":"error"}
+]`, nil)
+
+	names, err := CaptureServices()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"colima", "redis", "unbound"}, names)
+}
+
+func TestCaptureServices_NoHomebrew(t *testing.T) {
+	stubListBrewServices(t, "", nil)
+
+	names, err := CaptureServices()
+	require.NoError(t, err)
+	assert.Nil(t, names)
+}
+
+func TestCaptureServices_Errors(t *testing.T) {
+	stubListBrewServices(t, "", errors.New("exit status 1"))
+	_, err := CaptureServices()
+	assert.ErrorContains(t, err, "brew services")
+
+	stubListBrewServices(t, "Warning: not json", nil)
+	_, err = CaptureServices()
+	assert.ErrorContains(t, err, "parse brew services")
+}
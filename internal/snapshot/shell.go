@@ -0,0 +1,229 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+// shellFramework describes how to recognise one shell framework and read
+// its theme and plugins. internal/shell holds the matching restore side.
+type shellFramework struct {
+	name  string
+	shell string
+	// referenced reports whether rc, the shell's rc file, loads the framework.
+	referenced func(rc string) bool
+	// installed reports whether the framework is present under home.
+	installed func(home string) bool
+	// capture reads the theme and plugins; rc is the shell's rc file.
+	capture func(home, rc string) (theme string, plugins []string)
+}
+
+var (
+	zshThemeRe   = regexp.MustCompile(`(?m)^ZSH_THEME="([^"]*)"`)
+	zshPluginsRe = regexp.MustCompile(`(?m)^plugins=\((?s:(.*?))\)`)
+
+	// preztoModulesRe matches a pmodule zstyle, including the backslash
+	// continuation lines the stock .zpreztorc spreads it over.
+	preztoModulesRe = regexp.MustCompile(`(?m)^[ \t]*zstyle[ \t]+':prezto:load'[ \t]+pmodule((?:[ \t]*\\\n|[ \t]+'[^'\n]*')*)`)
+	preztoThemeRe   = regexp.MustCompile(`(?m)^[ \t]*zstyle[ \t]+':prezto:module:prompt'[ \t]+theme[ \t]+'([^'\n]*)'`)
+	quotedWordRe    = regexp.MustCompile(`'([^'\n]*)'`)
+
+	zinitPluginRe = regexp.MustCompile(`(?m)^[ \t]*(?:zinit|zi)[ \t]+(?:light|load)[ \t]+([^\s;#]+)`)
+)
+
+// fisherSelf is fisher's own entry in fish_plugins; it is not captured as a
+// plugin since restoring fisher always installs it.
+const fisherSelf = "jorgebucaran/fisher"
+
+// shellFrameworks is ordered by detection priority.
+var shellFrameworks = []shellFramework{
+	{
+		name:  config.FrameworkOhMyZsh,
+		shell: config.ShellZsh,
+		referenced: func(rc string) bool {
+			return strings.Contains(rc, "oh-my-zsh.sh") || zshThemeRe.MatchString(rc) || zshPluginsRe.MatchString(rc)
+		},
+		installed: func(home string) bool { return pathExists(filepath.Join(home, ".oh-my-zsh")) },
+		capture:   captureOhMyZsh,
+	},
+	{
+		name:       config.FrameworkPrezto,
+		shell:      config.ShellZsh,
+		referenced: func(rc string) bool { return strings.Contains(rc, ".zprezto/init.zsh") },
+		installed:  func(home string) bool { return pathExists(filepath.Join(home, ".zprezto", "init.zsh")) },
+		capture:    capturePrezto,
+	},
+	{
+		name:       config.FrameworkZinit,
+		shell:      config.ShellZsh,
+		referenced: func(rc string) bool { return strings.Contains(rc, "zinit.zsh") },
+		installed: func(home string) bool {
+			return pathExists(filepath.Join(home, ".local", "share", "zinit", "zinit.git", "zinit.zsh")) ||
+				pathExists(filepath.Join(home, ".zinit", "bin", "zinit.zsh"))
+		},
+		capture: captureZinit,
+	},
+	{
+		name:  config.FrameworkFisher,
+		shell: config.ShellFish,
+		// fisher loads through fish's function path, not config.fish.
+		referenced: func(string) bool { return false },
+		installed: func(home string) bool {
+			return pathExists(filepath.Join(home, ".config", "fish", "functions", "fisher.fish"))
+		},
+		capture: captureFisher,
+	},
+}
+
+// CaptureShell records the shell framework in use with its theme and
+// plugins, whether the starship prompt is set up, and the snippets in the
+// managed block of the captured shell's rc file. The framework is the
+// first one an rc file loads; failing that, the first one installed,
+// preferring those for the login shell. Returns a zero-value ShellSnapshot
+// (not an error) when there is nothing to record.
+func CaptureShell() (*ShellSnapshot, error) {
+	snap := &ShellSnapshot{}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return snap, nil
+	}
+
+	rcs := make(map[string]string)
+	rc := func(sh string) string {
+		if _, ok := rcs[sh]; !ok {
+			rcs[sh] = readString(filepath.Join(home, config.ShellRCFile(sh)))
+		}
+		return rcs[sh]
+	}
+
+	if fw := detectShellFramework(home, rc); fw != nil {
+		snap.Shell = fw.shell
+		snap.Framework = fw.name
+		snap.OhMyZsh = fw.name == config.FrameworkOhMyZsh
+		snap.Theme, snap.Plugins = fw.capture(home, rc(fw.shell))
+	} else {
+		snap.Shell = loginShell()
+	}
+
+	for _, sh := range []string{config.ShellZsh, config.ShellBash, config.ShellFish} {
+		if strings.Contains(rc(sh), "starship init") {
+			snap.Starship = true
+			break
+		}
+	}
+	if snap.Starship {
+		if toml := readString(filepath.Join(home, config.StarshipConfigFile)); len(toml) <= config.MaxStarshipConfigLen {
+			snap.StarshipConfig = toml
+		}
+	}
+
+	sh := snap.Shell
+	if sh == "" {
+		sh = config.ShellZsh
+	}
+	snap.Snippets = config.ParseShellSnippets(sh, rc(sh))
+	return snap, nil
+}
+
+func detectShellFramework(home string, rc func(sh string) string) *shellFramework {
+	for i := range shellFrameworks {
+		if fw := &shellFrameworks[i]; fw.referenced(rc(fw.shell)) {
+			return fw
+		}
+	}
+	login := loginShell()
+	for i := range shellFrameworks {
+		if fw := &shellFrameworks[i]; fw.shell == login && fw.installed(home) {
+			return fw
+		}
+	}
+	for i := range shellFrameworks {
+		if fw := &shellFrameworks[i]; fw.installed(home) {
+			return fw
+		}
+	}
+	return nil
+}
+
+// loginShell returns the base name of $SHELL when it is a shell openboot
+// supports, or "".
+func loginShell() string {
+	switch sh := filepath.Base(os.Getenv("SHELL")); sh {
+	case config.ShellZsh, config.ShellBash, config.ShellFish:
+		return sh
+	}
+	return ""
+}
+
+func captureOhMyZsh(_, rc string) (string, []string) {
+	var theme string
+	var plugins []string
+	if m := zshThemeRe.FindStringSubmatch(rc); len(m) > 1 {
+		theme = m[1]
+	}
+	if m := zshPluginsRe.FindStringSubmatch(rc); len(m) > 1 {
+		plugins = strings.Fields(m[1])
+	}
+	return theme, plugins
+}
+
+// capturePrezto reads ~/.zpreztorc. zstyle keeps the last definition, so the
+// last pmodule and theme lines win.
+func capturePrezto(home, _ string) (string, []string) {
+	rc := readString(filepath.Join(home, ".zpreztorc"))
+	var theme string
+	var modules []string
+	if all := preztoThemeRe.FindAllStringSubmatch(rc, -1); len(all) > 0 {
+		theme = all[len(all)-1][1]
+	}
+	if all := preztoModulesRe.FindAllStringSubmatch(rc, -1); len(all) > 0 {
+		for _, m := range quotedWordRe.FindAllStringSubmatch(all[len(all)-1][1], -1) {
+			modules = append(modules, m[1])
+		}
+	}
+	return theme, modules
+}
+
+// captureZinit returns the plugins .zshrc loads with zinit light/load, in
+// order and without duplicates. zinit has no theme.
+func captureZinit(_, rc string) (string, []string) {
+	seen := make(map[string]bool)
+	var plugins []string
+	for _, m := range zinitPluginRe.FindAllStringSubmatch(rc, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			plugins = append(plugins, m[1])
+		}
+	}
+	return "", plugins
+}
+
+// captureFisher reads fish_plugins, leaving out fisher itself.
+func captureFisher(home, _ string) (string, []string) {
+	var plugins []string
+	for _, line := range strings.Split(readString(filepath.Join(home, ".config", "fish", "fish_plugins")), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && line != fisherSelf {
+			plugins = append(plugins, line)
+		}
+	}
+	return "", plugins
+}
+
+func readString(path string) string {
+	raw, err := os.ReadFile(path) //nolint:gosec // fixed locations under the home directory
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
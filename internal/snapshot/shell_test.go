@@ -0,0 +1,132 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func writeHomeFile(t *testing.T, home, rel, content string) {
+	t.Helper()
+	path := filepath.Join(home, rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCaptureShell_Prezto(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeHomeFile(t, home, ".zshrc", `source "${ZDOTDIR:-$HOME}/.zprezto/init.zsh"`+"\n")
+	writeHomeFile(t, home, ".zpreztorc", `zstyle ':prezto:load' pmodule \
+  'environment' \
+  'terminal' \
+  'git'
+
+zstyle ':prezto:module:prompt' theme 'sorin'
+`)
+
+	snap, err := CaptureShell()
+	require.NoError(t, err)
+	assert.Equal(t, "zsh", snap.Shell)
+	assert.Equal(t, "prezto", snap.Framework)
+	assert.False(t, snap.OhMyZsh)
+	assert.Equal(t, "sorin", snap.Theme)
+	assert.Equal(t, []string{"environment", "terminal", "git"}, snap.Plugins)
+}
+
+func TestCaptureShell_PreztoLastZstyleWins(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".zprezto"), 0755))
+	writeHomeFile(t, home, ".zprezto/init.zsh", "")
+	writeHomeFile(t, home, ".zpreztorc", `zstyle ':prezto:load' pmodule 'environment'
+zstyle ':prezto:module:prompt' theme 'sorin'
+# >>> OpenBoot-Restore
+zstyle ':prezto:load' pmodule 'git' 'history'
+zstyle ':prezto:module:prompt' theme 'pure'
+# <<< OpenBoot-Restore
+`)
+
+	snap, err := CaptureShell()
+	require.NoError(t, err)
+	assert.Equal(t, "prezto", snap.Framework, "detected from the install when .zshrc is absent")
+	assert.Equal(t, "pure", snap.Theme)
+	assert.Equal(t, []string{"git", "history"}, snap.Plugins)
+}
+
+func TestCaptureShell_Zinit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeHomeFile(t, home, ".zshrc", `source "${XDG_DATA_HOME:-$HOME/.local/share}/zinit/zinit.git/zinit.zsh"
+zinit light zsh-users/zsh-autosuggestions
+zi load zdharma-continuum/fast-syntax-highlighting # highlighting
+zinit light zsh-users/zsh-autosuggestions
+`)
+	// A leftover oh-my-zsh install must not win over what .zshrc loads.
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".oh-my-zsh"), 0755))
+
+	snap, err := CaptureShell()
+	require.NoError(t, err)
+	assert.Equal(t, "zinit", snap.Framework)
+	assert.False(t, snap.OhMyZsh)
+	assert.Empty(t, snap.Theme)
+	assert.Equal(t, []string{"zsh-users/zsh-autosuggestions", "zdharma-continuum/fast-syntax-highlighting"}, snap.Plugins)
+}
+
+func TestCaptureShell_FishFisherAndStarship(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/opt/homebrew/bin/fish")
+	writeHomeFile(t, home, ".config/fish/functions/fisher.fish", "function fisher\nend\n")
+	writeHomeFile(t, home, ".config/fish/fish_plugins", "jorgebucaran/fisher\nilancosman/tide@v6\n\n# comment\npatrickf1/fzf.fish\n")
+	writeHomeFile(t, home, ".config/fish/config.fish", "starship init fish | source\n")
+	writeHomeFile(t, home, ".config/starship.toml", "add_newline = false\n")
+
+	snap, err := CaptureShell()
+	require.NoError(t, err)
+	assert.Equal(t, "fish", snap.Shell)
+	assert.Equal(t, "fisher", snap.Framework)
+	assert.Equal(t, []string{"ilancosman/tide@v6", "patrickf1/fzf.fish"}, snap.Plugins)
+	assert.True(t, snap.Starship)
+	assert.Equal(t, "add_newline = false\n", snap.StarshipConfig)
+}
+
+func TestCaptureShell_BashStarshipOnly(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	writeHomeFile(t, home, ".bashrc", `eval "$(starship init bash)"`+"\n")
+
+	snap, err := CaptureShell()
+	require.NoError(t, err)
+	assert.Equal(t, "bash", snap.Shell)
+	assert.Empty(t, snap.Framework)
+	assert.True(t, snap.Starship)
+	assert.Empty(t, snap.StarshipConfig, "no starship.toml to capture")
+}
+
+func TestCaptureShell_Snippets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	writeHomeFile(t, home, ".bashrc", "export EDITOR=vim\n"+
+		"# >>> OpenBoot-Snippets\n"+
+		"export GOPRIVATE=\"github.com/acme/*\"\n"+
+		"alias k='kubectl'\n"+
+		"export PATH=\"$HOME/go/bin:$PATH\"\n"+
+		"# <<< OpenBoot-Snippets\n")
+	writeHomeFile(t, home, ".zshrc", "# >>> OpenBoot-Snippets\nalias z='zsh only'\n# <<< OpenBoot-Snippets\n")
+
+	snap, err := CaptureShell()
+	require.NoError(t, err)
+	assert.Equal(t, &config.ShellSnippets{
+		Env:     []config.ShellEnvVar{{Name: "GOPRIVATE", Value: "github.com/acme/*"}},
+		Aliases: []config.ShellAlias{{Name: "k", Command: "kubectl"}},
+		Path:    []string{"$HOME/go/bin"},
+	}, snap.Snippets, "read from the captured shell's rc only")
+}
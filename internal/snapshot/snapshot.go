@@ -4,28 +4,42 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/openbootdotdev/openboot/internal/config"
 )
 
 type CaptureHealth struct {
 	FailedSteps []string `json:"failed_steps"`
 	Partial     bool     `json:"partial"`
+	// Errors maps a failed step's name to why it failed, e.g.
+	// "timed out after 45s".
+	Errors map[string]string `json:"errors,omitempty"`
 }
 
 type Snapshot struct {
-	Version       int              `json:"version"`
-	CapturedAt    time.Time        `json:"captured_at"`
-	Hostname      string           `json:"hostname"`
-	Packages      PackageSnapshot  `json:"packages"`
-	MacOSPrefs    []MacOSPref      `json:"macos_prefs"`
-	Shell         ShellSnapshot    `json:"shell"`
-	Git           GitSnapshot      `json:"git"`
-	Dotfiles      DotfilesSnapshot `json:"dotfiles"`
-	DevTools      []DevTool        `json:"dev_tools"`
-	MatchedPreset string           `json:"matched_preset"`
-	CatalogMatch  CatalogMatch     `json:"catalog_match"`
-	DockApps      []string         `json:"dock_apps,omitempty"`
-	LoginItems    []LoginItem      `json:"login_items,omitempty"`
-	Health        CaptureHealth    `json:"health"`
+	Version       int                          `json:"version"`
+	CapturedAt    time.Time                    `json:"captured_at"`
+	Hostname      string                       `json:"hostname"`
+	Machine       *config.RemoteMachineConfig  `json:"machine,omitempty"`
+	Packages      PackageSnapshot              `json:"packages"`
+	MacOSPrefs    []MacOSPref                  `json:"macos_prefs"`
+	Shell         ShellSnapshot                `json:"shell"`
+	Git           GitSnapshot                  `json:"git"`
+	SSH           *config.RemoteSSHConfig      `json:"ssh,omitempty"`
+	Dotfiles      DotfilesSnapshot             `json:"dotfiles"`
+	DevTools      []DevTool                    `json:"dev_tools"`
+	MatchedPreset string                       `json:"matched_preset"`
+	CatalogMatch  CatalogMatch                 `json:"catalog_match"`
+	DockApps      []string                     `json:"dock_apps,omitempty"`
+	Dock          *config.DockLayout           `json:"dock,omitempty"`
+	LoginItems    []LoginItem                  `json:"login_items,omitempty"`
+	LaunchAgents  []config.LaunchAgent         `json:"launch_agents,omitempty"`
+	Fonts         []config.Font                `json:"fonts,omitempty"`
+	DefaultApps   []config.DefaultApp          `json:"default_apps,omitempty"`
+	Keyboard      *config.RemoteKeyboardConfig `json:"keyboard,omitempty"`
+	AppSettings   *config.AppSettings          `json:"app_settings,omitempty"`
+	Services      []string                     `json:"services,omitempty"` // running brew services
+	Health        CaptureHealth                `json:"health"`
 }
 
 // LoginItem represents one entry under System Events → Login Items.
@@ -48,109 +62,30 @@ type PackageSnapshot struct {
 	Taps         []string          `json:"taps"`
 	Npm          []string          `json:"npm"`
 	Bun          []string          `json:"bun,omitempty"`
-	Descriptions map[string]string `json:"-"` // populated during unmarshal, not serialised
+	Descriptions map[string]string `json:"-"` // read from "descriptions", never written
+
+	// Version, tap and install reason of every installed formula, including
+	// dependencies, and of every cask. Formulae and Casks stay the lists
+	// restore installs; older snapshots have no info.
+	FormulaInfo []PackageInfo `json:"formula_info,omitempty"`
+	CaskInfo    []PackageInfo `json:"cask_info,omitempty"`
 }
 
-// UnmarshalJSON accepts three formats:
-//   - Structured object: {"formulae":[],"casks":[],"taps":[],"npm":[],"bun":[]}
-//   - Typed object array: [{"name":"git","type":"formula"},{"name":"docker","type":"cask"}]
-//   - Flat string array:  ["git","curl"] (all treated as formulae)
-func (ps *PackageSnapshot) UnmarshalJSON(data []byte) error { //nolint:gocyclo // parses multiple legacy JSON shapes; each branch is a distinct schema variant
-	// Try structured object first (plain string arrays).
+// UnmarshalJSON reads the v2 packages object, including the descriptions
+// older files carried inline. Older shapes are rewritten into it by
+// schema.Migrate before decoding; see internal/snapshot/schema.
+func (ps *PackageSnapshot) UnmarshalJSON(data []byte) error {
 	type alias PackageSnapshot
-	var obj alias
-	if err := json.Unmarshal(data, &obj); err == nil {
-		*ps = PackageSnapshot(obj)
-		return nil
-	}
-
-	// Try structured object with entry objects: {"formulae":[{"name":"x","desc":"y"}],...}
-	var richObj struct {
-		Formulae []struct {
-			Name string `json:"name"`
-			Desc string `json:"desc"`
-		} `json:"formulae"`
-		Casks []struct {
-			Name string `json:"name"`
-			Desc string `json:"desc"`
-		} `json:"casks"`
-		Taps []string `json:"taps"`
-		Npm  []struct {
-			Name string `json:"name"`
-			Desc string `json:"desc"`
-		} `json:"npm"`
-		Bun []struct {
-			Name string `json:"name"`
-			Desc string `json:"desc"`
-		} `json:"bun"`
-	}
-	if err := json.Unmarshal(data, &richObj); err == nil &&
-		(len(richObj.Formulae) > 0 || len(richObj.Casks) > 0 || len(richObj.Npm) > 0 || len(richObj.Bun) > 0) {
-		ps.Descriptions = make(map[string]string)
-		for _, p := range richObj.Formulae {
-			ps.Formulae = append(ps.Formulae, p.Name)
-			if p.Desc != "" {
-				ps.Descriptions[p.Name] = p.Desc
-			}
-		}
-		for _, p := range richObj.Casks {
-			ps.Casks = append(ps.Casks, p.Name)
-			if p.Desc != "" {
-				ps.Descriptions[p.Name] = p.Desc
-			}
-		}
-		ps.Taps = richObj.Taps
-		for _, p := range richObj.Npm {
-			ps.Npm = append(ps.Npm, p.Name)
-			if p.Desc != "" {
-				ps.Descriptions[p.Name] = p.Desc
-			}
-		}
-		for _, p := range richObj.Bun {
-			ps.Bun = append(ps.Bun, p.Name)
-			if p.Desc != "" {
-				ps.Descriptions[p.Name] = p.Desc
-			}
-		}
-		return nil
-	}
-
-	// Try typed object array: [{"name":"x","type":"formula|cask|tap|npm|bun","desc":"..."}]
-	var typed []struct {
-		Name string `json:"name"`
-		Type string `json:"type"`
-		Desc string `json:"desc"`
+	var v struct {
+		alias
+		Descriptions map[string]string `json:"descriptions"`
 	}
-	if err := json.Unmarshal(data, &typed); err == nil && len(typed) > 0 && typed[0].Name != "" {
-		ps.Descriptions = make(map[string]string)
-		for _, p := range typed {
-			switch p.Type {
-			case "cask":
-				ps.Casks = append(ps.Casks, p.Name)
-			case "tap":
-				ps.Taps = append(ps.Taps, p.Name)
-			case "npm":
-				ps.Npm = append(ps.Npm, p.Name)
-			case "bun":
-				ps.Bun = append(ps.Bun, p.Name)
-			default:
-				ps.Formulae = append(ps.Formulae, p.Name)
-			}
-			if p.Desc != "" {
-				ps.Descriptions[p.Name] = p.Desc
-			}
-		}
-		return nil
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("packages must be an object {formulae,casks,taps,npm,bun}: %w", err)
 	}
-
-	// Try flat string array.
-	var arr []string
-	if err := json.Unmarshal(data, &arr); err == nil {
-		ps.Formulae = arr
-		return nil
-	}
-
-	return fmt.Errorf("packages must be an object {formulae,casks,taps,npm,bun} or an array")
+	*ps = PackageSnapshot(v.alias)
+	ps.Descriptions = v.Descriptions
+	return nil
 }
 
 // MarshalJSON always outputs the canonical format: plain string arrays.
@@ -182,6 +117,25 @@ type MacOSPref struct {
 type GitSnapshot struct {
 	UserName  string `json:"user_name"`
 	UserEmail string `json:"user_email"`
+	// Allow-listed global settings and the global ignore/attributes files;
+	// see config.RemoteGitConfig.
+	Settings      map[string]string `json:"settings,omitempty"`
+	Gitignore     string            `json:"gitignore,omitempty"`
+	Gitattributes string            `json:"gitattributes,omitempty"`
+	// Identities included by [includeIf "gitdir:..."] rules.
+	Profiles []config.GitProfile `json:"git_profiles,omitempty"`
+	// Signing is set when commits are signed; only the format is kept.
+	Signing *config.GitSigning `json:"signing,omitempty"`
+}
+
+// GitConfig returns the non-identity part of g as a config section, or nil
+// when there is none.
+func (g *GitSnapshot) GitConfig() *config.RemoteGitConfig {
+	gc := &config.RemoteGitConfig{Settings: g.Settings, Gitignore: g.Gitignore, Gitattributes: g.Gitattributes, Profiles: g.Profiles, Signing: g.Signing}
+	if gc.Empty() {
+		return nil
+	}
+	return gc
 }
 
 type DevTool struct {
@@ -189,10 +143,16 @@ type DevTool struct {
 	Version string `json:"version"`
 }
 
+// ShellSnapshot mirrors config.RemoteShellConfig; see it for field meanings.
 type ShellSnapshot struct {
-	OhMyZsh bool     `json:"oh_my_zsh"`
-	Theme   string   `json:"theme"`
-	Plugins []string `json:"plugins"`
+	Shell          string                `json:"shell,omitempty"`
+	Framework      string                `json:"framework,omitempty"`
+	OhMyZsh        bool                  `json:"oh_my_zsh"`
+	Theme          string                `json:"theme"`
+	Plugins        []string              `json:"plugins"`
+	Starship       bool                  `json:"starship,omitempty"`
+	StarshipConfig string                `json:"starship_config,omitempty"`
+	Snippets       *config.ShellSnippets `json:"snippets,omitempty"`
 }
 
 type CatalogMatch struct {
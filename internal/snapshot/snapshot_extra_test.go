@@ -158,10 +158,10 @@ func TestLocalPath_ContainsOpenboot(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// UnmarshalJSON — PackageSnapshot additional edge cases
+// v1 package shapes — additional edge cases
 // ---------------------------------------------------------------------------
 
-func TestPackageSnapshot_UnmarshalJSON_RichObjectWithDesc(t *testing.T) {
+func TestPackageSnapshot_V1_RichObjectWithDesc(t *testing.T) {
 	// Rich object format with descriptions.
 	input := `{
 		"formulae": [{"name":"git","desc":"Version control system"}],
@@ -170,8 +170,7 @@ func TestPackageSnapshot_UnmarshalJSON_RichObjectWithDesc(t *testing.T) {
 		"taps": ["homebrew/cask"]
 	}`
 
-	var ps PackageSnapshot
-	err := json.Unmarshal([]byte(input), &ps)
+	ps, err := parseV1Packages(input)
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{"git"}, ps.Formulae)
@@ -183,46 +182,41 @@ func TestPackageSnapshot_UnmarshalJSON_RichObjectWithDesc(t *testing.T) {
 	assert.Equal(t, "Container platform", ps.Descriptions["docker"])
 }
 
-func TestPackageSnapshot_UnmarshalJSON_EmptyObject(t *testing.T) {
+func TestPackageSnapshot_V1_EmptyObject(t *testing.T) {
 	input := `{"formulae":[],"casks":[],"taps":[],"npm":[]}`
 
-	var ps PackageSnapshot
-	err := json.Unmarshal([]byte(input), &ps)
+	ps, err := parseV1Packages(input)
 	require.NoError(t, err)
 	assert.Empty(t, ps.Formulae)
 	assert.Empty(t, ps.Casks)
 }
 
-func TestPackageSnapshot_UnmarshalJSON_TypedArrayDefaultIsFormula(t *testing.T) {
+func TestPackageSnapshot_V1_TypedArrayDefaultIsFormula(t *testing.T) {
 	// Unknown type defaults to formula.
 	input := `[{"name":"mypkg","type":"unknown-type"}]`
 
-	var ps PackageSnapshot
-	err := json.Unmarshal([]byte(input), &ps)
+	ps, err := parseV1Packages(input)
 	require.NoError(t, err)
 	assert.Contains(t, ps.Formulae, "mypkg")
 }
 
-func TestPackageSnapshot_UnmarshalJSON_FlatArrayAllFormulae(t *testing.T) {
+func TestPackageSnapshot_V1_FlatArrayAllFormulae(t *testing.T) {
 	input := `["git","curl","ripgrep"]`
 
-	var ps PackageSnapshot
-	err := json.Unmarshal([]byte(input), &ps)
+	ps, err := parseV1Packages(input)
 	require.NoError(t, err)
 	assert.Equal(t, []string{"git", "curl", "ripgrep"}, ps.Formulae)
 	assert.Empty(t, ps.Casks)
 	assert.Empty(t, ps.Npm)
 }
 
-func TestPackageSnapshot_UnmarshalJSON_InvalidType(t *testing.T) {
-	var ps PackageSnapshot
-	err := json.Unmarshal([]byte(`42`), &ps)
+func TestPackageSnapshot_V1_InvalidType(t *testing.T) {
+	_, err := parseV1Packages(`42`)
 	assert.Error(t, err)
 }
 
-func TestPackageSnapshot_UnmarshalJSON_BoolInvalid(t *testing.T) {
-	var ps PackageSnapshot
-	err := json.Unmarshal([]byte(`true`), &ps)
+func TestPackageSnapshot_V1_BoolInvalid(t *testing.T) {
+	_, err := parseV1Packages(`true`)
 	assert.Error(t, err)
 }
 
@@ -9,7 +9,17 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestPackageSnapshot_UnmarshalJSON(t *testing.T) {
+// parseV1Packages loads a v1 snapshot whose packages field is input, the
+// way every reader does: through the schema migration.
+func parseV1Packages(input string) (PackageSnapshot, error) {
+	snap, err := ParseBytes([]byte(`{"version":1,"packages":` + input + `}`))
+	if err != nil {
+		return PackageSnapshot{}, err
+	}
+	return snap.Packages, nil
+}
+
+func TestPackageSnapshot_V1Shapes(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -31,12 +41,11 @@ func TestPackageSnapshot_UnmarshalJSON(t *testing.T) {
 			name:  "typed object array",
 			input: `[{"name":"git","type":"formula"},{"name":"docker","type":"cask"},{"name":"homebrew/core","type":"tap"},{"name":"typescript","type":"npm"},{"name":"prettier","type":"bun"}]`,
 			expected: PackageSnapshot{
-				Formulae:     []string{"git"},
-				Casks:        []string{"docker"},
-				Taps:         []string{"homebrew/core"},
-				Npm:          []string{"typescript"},
-				Bun:          []string{"prettier"},
-				Descriptions: map[string]string{},
+				Formulae: []string{"git"},
+				Casks:    []string{"docker"},
+				Taps:     []string{"homebrew/core"},
+				Npm:      []string{"typescript"},
+				Bun:      []string{"prettier"},
 			},
 		},
 		{
@@ -64,8 +73,7 @@ func TestPackageSnapshot_UnmarshalJSON(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var ps PackageSnapshot
-			err := json.Unmarshal([]byte(tt.input), &ps)
+			ps, err := parseV1Packages(tt.input)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
@@ -0,0 +1,39 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/system"
+)
+
+// sshConfigFile is read for the OpenBoot managed block; the rest of the
+// file, and every private key, stays on the machine.
+const sshConfigFile = ".ssh/config"
+
+// CaptureSSH reads back the hosts and keys in the managed block of
+// ~/.ssh/config. Key comments come from the public key files. Captured keys
+// ask for a passphrase when restored, since whether the original has one
+// cannot be told without reading the private key. It returns nil when there
+// is no managed block.
+func CaptureSSH() (*config.RemoteSSHConfig, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	s := config.ParseSSHBlock(readString(filepath.Join(home, sshConfigFile)))
+	if s == nil {
+		return nil, nil
+	}
+	for i, k := range s.Keys {
+		s.Keys[i].Passphrase = true
+		if f := strings.Fields(readString(filepath.Join(home, k.KeyPath()+".pub"))); len(f) > 2 {
+			s.Keys[i].Comment = strings.Join(f[2:], " ")
+		}
+	}
+	if s.Empty() || s.Validate() != nil {
+		return nil, nil
+	}
+	return s, nil
+}
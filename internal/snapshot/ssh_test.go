@@ -0,0 +1,40 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+func TestCaptureSSH_ManagedBlockOnly(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+
+	s, err := CaptureSSH()
+	require.NoError(t, err)
+	assert.Nil(t, s, "no ~/.ssh/config")
+
+	want := &config.RemoteSSHConfig{
+		Keys:  []config.SSHKey{{Name: "id_ed25519"}},
+		Hosts: []config.SSHHost{{Host: "github.com", User: "git"}},
+	}
+	start, end := config.ManagedBlockMarkers(config.SSHBlockName)
+	content := start + "\n" + want.Render() + end + "\nHost private\n  HostName 10.0.0.9\n"
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "config"), []byte(content), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "id_ed25519"), []byte("PRIVATE KEY"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "id_ed25519.pub"), []byte("ssh-ed25519 AAAA jane@acme.com\n"), 0600))
+
+	s, err = CaptureSSH()
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	assert.Equal(t, want.Hosts, s.Hosts, "hosts outside the block are not captured")
+	assert.Equal(t, []config.SSHKey{{Name: "id_ed25519", Comment: "jane@acme.com", Passphrase: true}}, s.Keys)
+	assert.Empty(t, s.KnownHosts)
+}
@@ -0,0 +1,222 @@
+// Package sshconfig applies the ssh section of a config: it generates the
+// declared keys, writes the Host blocks into a managed block at the top of
+// ~/.ssh/config and pins known_hosts entries. Private keys never leave the
+// machine; capture (internal/snapshot) reads back only the managed block.
+package sshconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/managedblock"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
+)
+
+// Files openboot manages, relative to the home directory.
+const (
+	ConfigFile     = ".ssh/config"
+	KnownHostsFile = ".ssh/known_hosts"
+)
+
+// sshKeygen runs ssh-keygen attached to the terminal, so it can ask for a
+// passphrase itself; hasTTY reports whether there is a terminal to ask on.
+// Both are vars so tests can run without generating real keys.
+var (
+	sshKeygen = func(args ...string) error {
+		return system.RunCommand("ssh-keygen", args...)
+	}
+	hasTTY = system.HasTTY
+)
+
+// Apply brings ~/.ssh in line with cfg. Missing keys are generated —
+// asking for a passphrase on the terminal when the key wants one — and
+// existing keys are never touched. The managed block in ~/.ssh/config is
+// rewritten when it differs, and known_hosts lines cfg pins are appended
+// when missing. It returns the number of keys, files and entries changed.
+func Apply(cfg *config.RemoteSSHConfig, dryRun bool) (int, error) {
+	if cfg.Empty() {
+		return 0, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return 0, fmt.Errorf("apply ssh config: %w", err)
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return 0, fmt.Errorf("apply ssh config: %w", err)
+	}
+	sshDir := filepath.Join(home, ".ssh")
+	if !dryRun {
+		if err := os.MkdirAll(sshDir, 0700); err != nil {
+			return 0, fmt.Errorf("create %s: %w", sshDir, err)
+		}
+	}
+
+	changed := 0
+	for _, k := range cfg.Keys {
+		ok, err := generateKey(home, k, dryRun)
+		if err != nil {
+			return changed, fmt.Errorf("apply ssh config: %w", err)
+		}
+		if ok {
+			changed++
+		}
+	}
+
+	ok, err := managedblock.UpsertFirst(filepath.Join(home, ConfigFile), config.SSHBlockName, cfg.Render(), dryRun)
+	if err != nil {
+		return changed, fmt.Errorf("apply ssh config: %w", err)
+	}
+	if ok {
+		changed++
+	}
+
+	n, err := pinKnownHosts(filepath.Join(home, KnownHostsFile), cfg.KnownHosts, dryRun)
+	if err != nil {
+		return changed, fmt.Errorf("apply ssh config: %w", err)
+	}
+	return changed + n, nil
+}
+
+// MissingKeys returns the names of cfg's keys with no private key file.
+func MissingKeys(cfg *config.RemoteSSHConfig) []string {
+	if cfg == nil {
+		return nil
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil
+	}
+	var missing []string
+	for _, k := range cfg.Keys {
+		if _, err := os.Stat(filepath.Join(home, k.KeyPath())); err != nil {
+			missing = append(missing, k.Name)
+		}
+	}
+	return missing
+}
+
+// PublicKey returns the public key line of k, read from its .pub file.
+func PublicKey(k config.SSHKey) (string, error) {
+	home, err := system.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(home, k.KeyPath()+".pub")) //nolint:gosec // the user's own public key
+	if err != nil {
+		return "", fmt.Errorf("read public key %s: %w", k.Name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// MissingKnownHosts returns the lines cfg pins that ~/.ssh/known_hosts
+// does not have yet.
+func MissingKnownHosts(cfg *config.RemoteSSHConfig) []string {
+	if cfg == nil || len(cfg.KnownHosts) == 0 {
+		return nil
+	}
+	home, err := system.HomeDir()
+	if err != nil {
+		return nil
+	}
+	current, _ := os.ReadFile(filepath.Join(home, KnownHostsFile)) //nolint:gosec // the user's own known_hosts
+	return missingHostKeys(string(current), cfg.KnownHosts)
+}
+
+// generateKey creates k as an ed25519 key pair unless its private key
+// exists. It reports whether a key was (or would be) generated.
+func generateKey(home string, k config.SSHKey, dryRun bool) (bool, error) {
+	path := filepath.Join(home, k.KeyPath())
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+	if dryRun {
+		ui.DryRunMsg("Would generate SSH key %s", path)
+		return true, nil
+	}
+	args := []string{"-t", "ed25519", "-f", path}
+	if k.Comment != "" {
+		args = append(args, "-C", k.Comment)
+	}
+	if k.Passphrase {
+		if !hasTTY() {
+			return false, fmt.Errorf("ssh key %s needs a passphrase, but there is no terminal to ask on", k.Name)
+		}
+		ui.Info(fmt.Sprintf("Generating SSH key %s — choose a passphrase", path))
+	} else {
+		args = append(args, "-q", "-N", "")
+	}
+	if err := sshKeygen(args...); err != nil {
+		return false, fmt.Errorf("generate ssh key %s: %w", k.Name, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return false, errors.New("ssh-keygen did not create " + path)
+	}
+	return true, nil
+}
+
+// pinKnownHosts appends the lines of want that path lacks. It returns how
+// many it added.
+func pinKnownHosts(path string, want []string, dryRun bool) (int, error) {
+	if len(want) == 0 {
+		return 0, nil
+	}
+	current, err := os.ReadFile(path) //nolint:gosec // the user's own known_hosts
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("read %s: %w", path, err)
+	}
+	missing := missingHostKeys(string(current), want)
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if dryRun {
+		ui.DryRunMsg("Would pin %d known host key(s) in %s", len(missing), path)
+		return len(missing), nil
+	}
+	content := string(current)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += strings.Join(missing, "\n") + "\n"
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content), 0600); err != nil {
+		return 0, fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, fmt.Errorf("rename %s: %w", path, err)
+	}
+	return len(missing), nil
+}
+
+// missingHostKeys returns the lines of want whose host, key type and key
+// are not already in known, the contents of a known_hosts file. Comments
+// are ignored on both sides.
+func missingHostKeys(known string, want []string) []string {
+	have := make(map[string]bool)
+	for _, line := range strings.Split(known, "\n") {
+		have[hostKeyID(line)] = true
+	}
+	var missing []string
+	for _, line := range want {
+		id := hostKeyID(line)
+		if have[id] {
+			continue
+		}
+		have[id] = true
+		missing = append(missing, strings.TrimSpace(line))
+	}
+	return missing
+}
+
+func hostKeyID(line string) string {
+	f := strings.Fields(line)
+	if len(f) < 3 {
+		return ""
+	}
+	return f[0] + " " + f[1] + " " + f[2]
+}
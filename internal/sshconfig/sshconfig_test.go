@@ -0,0 +1,110 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+const githubEd25519 = "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+
+// fakeSSHKeygen replaces ssh-keygen with one that writes a key pair at the
+// -f path. It records each call.
+func fakeSSHKeygen(t *testing.T, tty bool) *[][]string {
+	t.Helper()
+	var calls [][]string
+	origKeygen, origTTY := sshKeygen, hasTTY
+	t.Cleanup(func() { sshKeygen, hasTTY = origKeygen, origTTY })
+	hasTTY = func() bool { return tty }
+	sshKeygen = func(args ...string) error {
+		calls = append(calls, args)
+		path := args[slices.Index(args, "-f")+1]
+		if err := os.WriteFile(path, []byte("PRIVATE"), 0600); err != nil {
+			return err
+		}
+		return os.WriteFile(path+".pub", []byte("ssh-ed25519 AAAAGENERATED jane@acme.com\n"), 0600)
+	}
+	return &calls
+}
+
+func sampleConfig() *config.RemoteSSHConfig {
+	return &config.RemoteSSHConfig{
+		Keys:       []config.SSHKey{{Name: "id_ed25519", Comment: "jane@acme.com"}},
+		Hosts:      []config.SSHHost{{Host: "github.com", User: "git"}},
+		KnownHosts: []string{githubEd25519},
+	}
+}
+
+func TestApply_WritesEverythingOnceAndIsIdempotent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := fakeSSHKeygen(t, false)
+	userConfig := "Host *\n  ServerAliveInterval 30\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".ssh"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ConfigFile), []byte(userConfig), 0600))
+
+	n, err := Apply(sampleConfig(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n, "key, config block, known host")
+	require.Len(t, *calls, 1)
+	assert.Equal(t, []string{"-t", "ed25519", "-f", filepath.Join(home, ".ssh", "id_ed25519"), "-C", "jane@acme.com", "-q", "-N", ""}, (*calls)[0])
+
+	data, err := os.ReadFile(filepath.Join(home, ConfigFile))
+	require.NoError(t, err)
+	start, _ := config.ManagedBlockMarkers(config.SSHBlockName)
+	assert.True(t, strings.HasPrefix(string(data), start+"\n"), "managed block goes first so its values win")
+	assert.True(t, strings.HasSuffix(string(data), userConfig), "user config kept")
+	assert.Equal(t, sampleConfig().Hosts, config.ParseSSHBlock(string(data)).Hosts)
+
+	known, err := os.ReadFile(filepath.Join(home, KnownHostsFile))
+	require.NoError(t, err)
+	assert.Equal(t, githubEd25519+"\n", string(known))
+
+	n, err = Apply(sampleConfig(), false)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Len(t, *calls, 1, "existing key is never regenerated")
+}
+
+func TestApply_DryRunWritesNothing(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := fakeSSHKeygen(t, false)
+
+	n, err := Apply(sampleConfig(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Empty(t, *calls)
+	_, err = os.Stat(filepath.Join(home, ".ssh"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestApply_PassphraseNeedsTerminal(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfg := &config.RemoteSSHConfig{Keys: []config.SSHKey{{Name: "id_ed25519", Passphrase: true}}}
+
+	calls := fakeSSHKeygen(t, false)
+	_, err := Apply(cfg, false)
+	assert.ErrorContains(t, err, "no terminal")
+	assert.Empty(t, *calls)
+
+	calls = fakeSSHKeygen(t, true)
+	_, err = Apply(cfg, false)
+	require.NoError(t, err)
+	require.Len(t, *calls, 1)
+	assert.NotContains(t, (*calls)[0], "-N", "ssh-keygen asks for the passphrase itself")
+}
+
+func TestMissingHostKeys(t *testing.T) {
+	known := "# pinned\ngithub.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl old comment\n"
+	other := "gitlab.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAfuCHKVTjquxvt6CM6tdG4SLp1Btn/nOeHHE5UOzRdf"
+	assert.Equal(t, []string{other}, missingHostKeys(known, []string{githubEd25519, other, other}))
+}
@@ -2,12 +2,17 @@ package sync
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 
+	"github.com/openbootdotdev/openboot/internal/brew"
 	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/diff"
 	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/sshconfig"
 	"github.com/openbootdotdev/openboot/internal/system"
 )
 
@@ -23,6 +28,11 @@ type SyncDiff struct {
 	ExtraNpm        []string
 	ExtraTaps       []string
 
+	// Services the remote lists as running that are stopped or not
+	// installed here. Services running locally but not listed are left
+	// alone, so there is no extra side.
+	ServicesNotRunning []string
+
 	// Dotfiles
 	DotfilesChanged bool
 	RemoteDotfiles  string
@@ -33,17 +43,60 @@ type SyncDiff struct {
 
 	// Shell (non-nil when theme or plugins differ from remote)
 	Shell *ShellDiff
+
+	// Git (global settings, gitignore and gitattributes that differ, names
+	// of remote identity profiles missing or different locally, and whether
+	// commit signing needs setting up)
+	GitChanged           []GitSettingDiff
+	GitignoreChanged     bool
+	GitattributesChanged bool
+	GitProfilesChanged   []string
+	GitSigningChanged    bool
+
+	// SSH (host blocks missing or different in the managed ~/.ssh/config
+	// block, keys not generated or not loaded, whether the block holds
+	// entries the remote lacks, and known_hosts pins still to add)
+	SSHHostsChanged      []string
+	SSHKeysMissing       []string
+	SSHStale             bool
+	SSHKnownHostsMissing int
+
+	// Machine names that differ, with the remote's {{user}} filled in
+	MachineChanged []MachineNameDiff
+}
+
+// MachineNameDiff records a machine name that differs. LocalValue is ""
+// when the name is unset locally.
+type MachineNameDiff struct {
+	Key         string
+	RemoteValue string
+	LocalValue  string
+}
+
+// GitSettingDiff records a global git setting that differs. LocalValue is
+// "" when the setting is unset locally.
+type GitSettingDiff struct {
+	Key         string
+	RemoteValue string
+	LocalValue  string
 }
 
 // ShellDiff records a shell config difference between remote and local.
 // RemoteTheme/RemotePlugins always reflect the remote config values.
 type ShellDiff struct {
-	ThemeChanged   bool
-	RemoteTheme    string
-	LocalTheme     string
-	PluginsChanged bool
-	RemotePlugins  []string
-	LocalPlugins   []string
+	FrameworkChanged bool
+	RemoteFramework  string
+	LocalFramework   string
+	ThemeChanged     bool
+	RemoteTheme      string
+	LocalTheme       string
+	PluginsChanged   bool
+	RemotePlugins    []string
+	LocalPlugins     []string
+	StarshipChanged  bool
+	SnippetsChanged  bool
+	RemoteSnippets   *config.ShellSnippets
+	LocalSnippets    *config.ShellSnippets
 }
 
 // MacOSPrefDiff records a single macOS preference that differs.
@@ -67,14 +120,17 @@ func (d *SyncDiff) HasChanges() bool {
 		len(d.ExtraCasks) > 0 ||
 		len(d.ExtraNpm) > 0 ||
 		len(d.ExtraTaps) > 0 ||
+		len(d.ServicesNotRunning) > 0 ||
 		d.DotfilesChanged ||
 		len(d.MacOSChanged) > 0 ||
-		d.Shell != nil
+		d.Shell != nil ||
+		d.gitChangeCount() > 0
 }
 
 // TotalMissing returns the count of items in remote but not on the local system.
 func (d *SyncDiff) TotalMissing() int {
-	return len(d.MissingFormulae) + len(d.MissingCasks) + len(d.MissingNpm) + len(d.MissingTaps)
+	return len(d.MissingFormulae) + len(d.MissingCasks) + len(d.MissingNpm) + len(d.MissingTaps) +
+		len(d.ServicesNotRunning)
 }
 
 // TotalExtra returns the count of items on the local system but not in remote.
@@ -91,6 +147,28 @@ func (d *SyncDiff) TotalChanged() int {
 	if d.Shell != nil {
 		n++
 	}
+	return n + d.gitChangeCount() + d.sshChangeCount() + len(d.MachineChanged)
+}
+
+func (d *SyncDiff) sshChangeCount() int {
+	n := len(d.SSHHostsChanged) + len(d.SSHKeysMissing) + d.SSHKnownHostsMissing
+	if d.SSHStale {
+		n++
+	}
+	return n
+}
+
+func (d *SyncDiff) gitChangeCount() int {
+	n := len(d.GitChanged) + len(d.GitProfilesChanged)
+	if d.GitignoreChanged {
+		n++
+	}
+	if d.GitattributesChanged {
+		n++
+	}
+	if d.GitSigningChanged {
+		n++
+	}
 	return n
 }
 
@@ -102,6 +180,10 @@ func ComputeDiff(rc *config.RemoteConfig) (*SyncDiff, error) {
 		return nil, fmt.Errorf("diff packages: %w", err)
 	}
 
+	if err := diffServices(rc, d); err != nil {
+		return nil, fmt.Errorf("diff services: %w", err)
+	}
+
 	diffDotfiles(rc, d)
 
 	if err := diffShell(rc, d); err != nil {
@@ -112,6 +194,18 @@ func ComputeDiff(rc *config.RemoteConfig) (*SyncDiff, error) {
 		return nil, fmt.Errorf("diff macos prefs: %w", err)
 	}
 
+	if err := diffGit(rc, d); err != nil {
+		return nil, fmt.Errorf("diff git: %w", err)
+	}
+
+	if err := diffSSH(rc, d); err != nil {
+		return nil, fmt.Errorf("diff ssh: %w", err)
+	}
+
+	if err := diffMachine(rc, d); err != nil {
+		return nil, fmt.Errorf("diff machine: %w", err)
+	}
+
 	return d, nil
 }
 
@@ -120,13 +214,9 @@ func ComputeDiff(rc *config.RemoteConfig) (*SyncDiff, error) {
 func diffPackages(rc *config.RemoteConfig, d *SyncDiff) error {
 	// Capture local package state — fail fast on errors to prevent
 	// false positives (showing everything as "missing" if brew is down).
-	localFormulae, err := snapshot.CaptureFormulae()
+	localBrew, err := snapshot.CaptureBrewPackages()
 	if err != nil {
-		return fmt.Errorf("capture local formulae: %w", err)
-	}
-	localCasks, err := snapshot.CaptureCasks()
-	if err != nil {
-		return fmt.Errorf("capture local casks: %w", err)
+		return fmt.Errorf("capture local formulae and casks: %w", err)
 	}
 	localTaps, err := snapshot.CaptureTaps()
 	if err != nil {
@@ -145,13 +235,40 @@ func diffPackages(rc *config.RemoteConfig, d *SyncDiff) error {
 			remoteFormulae = append(remoteFormulae, p.Name)
 		}
 	}
-	d.MissingFormulae, d.ExtraFormulae = diffLists(remoteFormulae, localFormulae)
-	d.MissingCasks, d.ExtraCasks = diffLists(rc.Casks.Names(), localCasks)
+	d.MissingFormulae, d.ExtraFormulae = diffFormulae(remoteFormulae, localBrew)
+	d.MissingCasks, d.ExtraCasks = diffLists(rc.Casks.Names(), localBrew.CaskNames())
 	d.MissingTaps, d.ExtraTaps = diffLists(rc.Taps, localTaps)
 	d.MissingNpm, d.ExtraNpm = diffLists(rc.Npm.Names(), localNpm)
 	return nil
 }
 
+// diffServices lists the remote's services that are not running locally.
+// brew is only asked when the remote lists services.
+func diffServices(rc *config.RemoteConfig, d *SyncDiff) error {
+	if len(rc.Services) == 0 {
+		return nil
+	}
+	running, err := brew.StartedServices()
+	if err != nil {
+		return fmt.Errorf("list local services: %w", err)
+	}
+	d.ServicesNotRunning, _ = diffLists(rc.Services, running)
+	return nil
+}
+
+// diffFormulae splits remote formulae against the local install. A remote
+// formula installed here only as a dependency is not missing; only
+// formulae installed on request count as extra, so dependencies are never
+// offered for removal. Tapped formulae match by full or short name.
+func diffFormulae(remote []string, local *snapshot.BrewPackages) (missing, extra []string) {
+	installed := slices.Collect(maps.Keys(snapshot.InstalledNames(local.Formulae)))
+	missing, _ = diffLists(remote, installed)
+	remoteSet := diff.ToSet(remote)
+	_, extra = diffLists(remote, local.Requested())
+	extra = slices.DeleteFunc(extra, func(name string) bool { return remoteSet[path.Base(name)] })
+	return missing, extra
+}
+
 // diffDotfiles checks whether the remote dotfiles URL differs from the local one.
 func diffDotfiles(rc *config.RemoteConfig, d *SyncDiff) {
 	if rc.DotfilesRepo == "" {
@@ -165,29 +282,103 @@ func diffDotfiles(rc *config.RemoteConfig, d *SyncDiff) {
 	}
 }
 
-// diffShell checks theme and plugin differences when the remote config enables Oh My Zsh.
+// diffShell checks framework, theme, plugin and starship differences when
+// the remote config sets up a shell framework or the starship prompt, and
+// snippet differences always, so a removed snippets section is noticed.
 func diffShell(rc *config.RemoteConfig, d *SyncDiff) error {
-	if rc.Shell == nil || !rc.Shell.OhMyZsh {
-		return nil
-	}
 	localShell, err := snapshot.CaptureShell()
 	if err != nil {
 		return fmt.Errorf("capture local shell: %w", err)
 	}
-	var sd *ShellDiff
-	if rc.Shell.Theme != "" && rc.Shell.Theme != localShell.Theme {
-		if sd == nil {
-			sd = &ShellDiff{RemoteTheme: rc.Shell.Theme, LocalTheme: localShell.Theme, RemotePlugins: rc.Shell.Plugins, LocalPlugins: localShell.Plugins}
+	sd := diff.CompareShell(localShell, rc.Shell)
+	if sd == nil {
+		return nil
+	}
+	d.Shell = &ShellDiff{
+		FrameworkChanged: sd.FrameworkChanged,
+		RemoteFramework:  sd.ReferenceFramework,
+		LocalFramework:   sd.LocalFramework,
+		ThemeChanged:     sd.ThemeChanged,
+		RemoteTheme:      sd.ReferenceTheme,
+		LocalTheme:       sd.LocalTheme,
+		PluginsChanged:   sd.PluginsChanged,
+		RemotePlugins:    sd.ReferencePlugins,
+		LocalPlugins:     sd.LocalPlugins,
+		StarshipChanged:  sd.StarshipChanged,
+		SnippetsChanged:  sd.SnippetsChanged,
+		RemoteSnippets:   sd.ReferenceSnippets,
+		LocalSnippets:    sd.LocalSnippets,
+	}
+	return nil
+}
+
+// diffGit compares the global git settings, files and profiles the remote
+// config sets against the local ones.
+func diffGit(rc *config.RemoteConfig, d *SyncDiff) error {
+	if rc.Git.Empty() {
+		return nil
+	}
+	local, err := snapshot.CaptureGit()
+	if err != nil {
+		return fmt.Errorf("capture local git config: %w", err)
+	}
+	gd := diff.CompareGit(local, rc.Git)
+	if gd == nil {
+		return nil
+	}
+	for _, c := range gd.Changed {
+		d.GitChanged = append(d.GitChanged, GitSettingDiff{Key: c.Key, RemoteValue: c.Reference, LocalValue: c.System})
+	}
+	d.GitignoreChanged = gd.GitignoreChanged
+	d.GitattributesChanged = gd.GitattributesChanged
+	d.GitProfilesChanged = gd.ProfilesChanged
+	d.GitSigningChanged = gd.SigningChanged
+	return nil
+}
+
+// diffSSH compares the remote ssh section against the managed block in
+// ~/.ssh/config, the key files on disk and ~/.ssh/known_hosts.
+func diffSSH(rc *config.RemoteConfig, d *SyncDiff) error {
+	if rc.SSH.Empty() {
+		return nil
+	}
+	local, err := snapshot.CaptureSSH()
+	if err != nil {
+		return fmt.Errorf("capture local ssh config: %w", err)
+	}
+	if sd := diff.CompareSSH(local, rc.SSH); sd != nil {
+		d.SSHHostsChanged = sd.HostsChanged
+		d.SSHKeysMissing = sd.KeysMissing
+		d.SSHStale = sd.Stale
+	}
+	for _, name := range sshconfig.MissingKeys(rc.SSH) {
+		if !slices.Contains(d.SSHKeysMissing, name) {
+			d.SSHKeysMissing = append(d.SSHKeysMissing, name)
 		}
-		sd.ThemeChanged = true
 	}
-	if len(rc.Shell.Plugins) > 0 && !diff.PluginsEqual(rc.Shell.Plugins, localShell.Plugins) {
-		if sd == nil {
-			sd = &ShellDiff{RemoteTheme: rc.Shell.Theme, LocalTheme: localShell.Theme, RemotePlugins: rc.Shell.Plugins, LocalPlugins: localShell.Plugins}
+	d.SSHKnownHostsMissing = len(sshconfig.MissingKnownHosts(rc.SSH))
+	return nil
+}
+
+// diffMachine compares the machine names the remote config sets against
+// the local ones.
+func diffMachine(rc *config.RemoteConfig, d *SyncDiff) error {
+	if rc.Machine.Empty() {
+		return nil
+	}
+	local, err := snapshot.CaptureMachine()
+	if err != nil {
+		return fmt.Errorf("capture machine names: %w", err)
+	}
+	user, err := system.Username()
+	if err != nil {
+		return err
+	}
+	if md := diff.CompareMachine(local, rc.Machine, user); md != nil {
+		for _, c := range md.Changed {
+			d.MachineChanged = append(d.MachineChanged, MachineNameDiff{Key: c.Key, RemoteValue: c.Reference, LocalValue: c.System})
 		}
-		sd.PluginsChanged = true
 	}
-	d.Shell = sd
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package sync
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -8,6 +9,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/openbootdotdev/openboot/internal/brew"
+	"github.com/openbootdotdev/openboot/internal/config"
+	"github.com/openbootdotdev/openboot/internal/snapshot"
 )
 
 func TestDiffLists(t *testing.T) {
@@ -168,6 +173,7 @@ func TestSyncDiffHasChangesAllFields(t *testing.T) {
 		{"ExtraFormulae", SyncDiff{ExtraFormulae: []string{"x"}}},
 		{"ExtraNpm", SyncDiff{ExtraNpm: []string{"x"}}},
 		{"ExtraTaps", SyncDiff{ExtraTaps: []string{"x"}}},
+		{"ServicesNotRunning", SyncDiff{ServicesNotRunning: []string{"x"}}},
 	}
 	for _, tt := range fields {
 		t.Run(tt.name, func(t *testing.T) {
@@ -176,6 +182,46 @@ func TestSyncDiffHasChangesAllFields(t *testing.T) {
 	}
 }
 
+func TestDiffFormulae(t *testing.T) {
+	local := &snapshot.BrewPackages{Formulae: []snapshot.PackageInfo{
+		{Name: "git", OnRequest: true},
+		{Name: "hashicorp/tap/terraform", OnRequest: true},
+		{Name: "htop", OnRequest: true},
+		{Name: "openssl@3"},
+	}}
+
+	missing, extra := diffFormulae([]string{"git", "openssl@3", "ripgrep", "terraform"}, local)
+	// openssl@3 is installed as a dependency; terraform by its short name.
+	assert.Equal(t, []string{"ripgrep"}, missing)
+	// Dependencies are never extra.
+	assert.Equal(t, []string{"htop"}, extra)
+}
+
+func TestDiffServices(t *testing.T) {
+	restoreBrew := brew.SetRunner(fakeBrewRunner{output: []byte(`[
+  {"name":"redis","status":"started"},
+  {"name":"postgresql@16","status":"stopped"}
+]`)})
+	t.Cleanup(restoreBrew)
+
+	d := &SyncDiff{}
+	require.NoError(t, diffServices(&config.RemoteConfig{Services: []string{"colima", "postgresql@16", "redis"}}, d))
+	assert.Equal(t, []string{"colima", "postgresql@16"}, d.ServicesNotRunning)
+	assert.Equal(t, 2, d.TotalMissing())
+}
+
+func TestDiffServices_SkipsBrewWithoutServices(t *testing.T) {
+	restoreBrew := brew.SetRunner(fakeBrewRunner{err: errors.New("brew should not run")})
+	t.Cleanup(restoreBrew)
+
+	d := &SyncDiff{}
+	require.NoError(t, diffServices(&config.RemoteConfig{}, d))
+	assert.Empty(t, d.ServicesNotRunning)
+
+	err := diffServices(&config.RemoteConfig{Services: []string{"redis"}}, d)
+	assert.ErrorContains(t, err, "list local services")
+}
+
 func TestToSet(t *testing.T) {
 	s := ToSet([]string{"a", "b", "a"})
 	assert.Equal(t, map[string]bool{"a": true, "b": true}, s)
@@ -416,3 +416,27 @@ func TestExecute_DryRun_ResultCountsAreAccurate(t *testing.T) {
 		})
 	}
 }
+
+// TestExecute_StartServices_Updates verifies that started services count as
+// updates.
+func TestExecute_StartServices_Updates(t *testing.T) {
+	restoreBrew := brew.SetRunner(fakeBrewRunner{output: []byte("[]")})
+	t.Cleanup(restoreBrew)
+
+	result, err := Execute(&SyncPlan{StartServices: []string{"redis", "colima"}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, 0, result.Installed)
+}
+
+// TestExecute_StartServices_BrewRunnerFails verifies that a service that
+// fails to start is collected and returned.
+func TestExecute_StartServices_BrewRunnerFails(t *testing.T) {
+	restoreBrew := brew.SetRunner(fakeBrewRunner{err: errors.New("brew: formula not installed")})
+	t.Cleanup(restoreBrew)
+
+	result, err := Execute(&SyncPlan{StartServices: []string{"redis"}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start services")
+	assert.Len(t, result.Errors, 1)
+}
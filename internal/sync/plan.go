@@ -8,9 +8,14 @@ import (
 	"github.com/openbootdotdev/openboot/internal/brew"
 	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/dotfiles"
+	"github.com/openbootdotdev/openboot/internal/gitconfig"
+	"github.com/openbootdotdev/openboot/internal/machine"
 	"github.com/openbootdotdev/openboot/internal/macos"
 	"github.com/openbootdotdev/openboot/internal/npm"
 	"github.com/openbootdotdev/openboot/internal/shell"
+	"github.com/openbootdotdev/openboot/internal/sshconfig"
+	"github.com/openbootdotdev/openboot/internal/system"
+	"github.com/openbootdotdev/openboot/internal/ui"
 )
 
 // SyncPlan describes the concrete actions to apply after the user selects
@@ -28,6 +33,9 @@ type SyncPlan struct {
 	UninstallNpm      []string
 	UninstallTaps     []string
 
+	// brew services to start, after packages are installed
+	StartServices []string
+
 	// Dotfiles
 	UpdateDotfiles string // new repo URL (empty = no change)
 
@@ -35,10 +43,29 @@ type SyncPlan struct {
 	UpdateMacOSPrefs []config.RemoteMacOSPref
 
 	// Shell
-	UpdateShell  bool
-	ShellOhMyZsh bool
-	ShellTheme   string
-	ShellPlugins []string
+	UpdateShell    bool
+	ShellName      string
+	ShellFramework string
+	ShellOhMyZsh   bool
+	ShellTheme     string
+	ShellPlugins   []string
+	Starship       bool
+	StarshipConfig string
+
+	// Shell snippets, applied independently of UpdateShell. A nil
+	// ShellSnippets removes the managed block.
+	UpdateShellSnippets bool
+	ShellSnippets       *config.ShellSnippets
+
+	// Git: only the settings, files and profiles to change, and Signing when
+	// commit signing needs setting up.
+	UpdateGit *config.RemoteGitConfig
+
+	// SSH: the whole remote ssh section, applied idempotently.
+	UpdateSSH *config.RemoteSSHConfig
+
+	// Machine: only the names to change, {{user}} already filled in.
+	UpdateMachine *config.RemoteMachineConfig
 }
 
 // SyncResult summarizes what was applied.
@@ -53,13 +80,32 @@ type SyncResult struct {
 func (p *SyncPlan) TotalActions() int {
 	n := len(p.InstallFormulae) + len(p.InstallCasks) + len(p.InstallNpm) + len(p.InstallTaps) +
 		len(p.UninstallFormulae) + len(p.UninstallCasks) + len(p.UninstallNpm) + len(p.UninstallTaps) +
-		len(p.UpdateMacOSPrefs)
+		len(p.StartServices) + len(p.UpdateMacOSPrefs)
 	if p.UpdateDotfiles != "" {
 		n++
 	}
 	if p.UpdateShell {
 		n++
 	}
+	if p.UpdateShellSnippets {
+		n++
+	}
+	if p.UpdateGit != nil {
+		n += len(p.UpdateGit.Settings) + len(p.UpdateGit.Profiles)
+		if p.UpdateGit.Gitignore != "" {
+			n++
+		}
+		if p.UpdateGit.Gitattributes != "" {
+			n++
+		}
+		if p.UpdateGit.Signing != nil {
+			n++
+		}
+	}
+	if p.UpdateSSH != nil {
+		n++
+	}
+	n += len(p.UpdateMachine.Names())
 	return n
 }
 
@@ -122,6 +168,16 @@ func ExecuteContext(ctx context.Context, plan *SyncPlan, dryRun bool) (*SyncResu
 			return npm.Install(plan.InstallNpm, dryRun)
 		}),
 	)
+	// Start services once their formulae are installed. They count as
+	// updated, not installed.
+	if len(plan.StartServices) > 0 {
+		n, err := brew.StartServices(plan.StartServices, dryRun)
+		result.Updated += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("start services: %w", err))
+			result.Errors = append(result.Errors, fmt.Sprintf("services: %v", err))
+		}
+	}
 	for _, s := range installSteps {
 		if s.err != nil {
 			errs = append(errs, fmt.Errorf("install %s: %w", s.label, s.err))
@@ -169,7 +225,15 @@ func ExecuteContext(ctx context.Context, plan *SyncPlan, dryRun bool) (*SyncResu
 
 	// Update shell config
 	if plan.UpdateShell {
-		if err := shell.RestoreFromSnapshot(plan.ShellOhMyZsh, plan.ShellTheme, plan.ShellPlugins, dryRun); err != nil {
+		if err := shell.Restore(&config.RemoteShellConfig{
+			Shell:          plan.ShellName,
+			Framework:      plan.ShellFramework,
+			OhMyZsh:        plan.ShellOhMyZsh,
+			Theme:          plan.ShellTheme,
+			Plugins:        plan.ShellPlugins,
+			Starship:       plan.Starship,
+			StarshipConfig: plan.StarshipConfig,
+		}, dryRun); err != nil {
 			errs = append(errs, fmt.Errorf("update shell: %w", err))
 			result.Errors = append(result.Errors, fmt.Sprintf("shell: %v", err))
 		} else {
@@ -177,6 +241,57 @@ func ExecuteContext(ctx context.Context, plan *SyncPlan, dryRun bool) (*SyncResu
 		}
 	}
 
+	if plan.UpdateShellSnippets {
+		sh := (&config.RemoteShellConfig{Shell: plan.ShellName, Framework: plan.ShellFramework, OhMyZsh: plan.ShellOhMyZsh}).EffectiveShell()
+		if err := shell.ApplySnippets(sh, plan.ShellSnippets, dryRun); err != nil {
+			errs = append(errs, fmt.Errorf("update shell snippets: %w", err))
+			result.Errors = append(result.Errors, fmt.Sprintf("shell snippets: %v", err))
+		} else {
+			result.Updated++
+		}
+	}
+
+	if !plan.UpdateGit.Empty() {
+		n, err := gitconfig.Apply(plan.UpdateGit, dryRun)
+		result.Updated += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("update git config: %w", err))
+			result.Errors = append(result.Errors, fmt.Sprintf("git: %v", err))
+		}
+	}
+	if plan.UpdateGit != nil && plan.UpdateGit.Signing != nil {
+		name, email := system.GetExistingGitConfig()
+		res, err := gitconfig.SetupSigning(plan.UpdateGit.Signing, name, email, dryRun)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("set up commit signing: %w", err))
+			result.Errors = append(result.Errors, fmt.Sprintf("git signing: %v", err))
+		} else {
+			result.Updated += res.Changed
+			if !dryRun && res.Changed > 0 && res.PublicKey != "" {
+				ui.Info("Add this public key to your git host as a signing key:")
+				ui.Println(res.PublicKey)
+			}
+		}
+	}
+
+	if plan.UpdateSSH != nil {
+		n, err := sshconfig.Apply(plan.UpdateSSH, dryRun)
+		result.Updated += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("update ssh config: %w", err))
+			result.Errors = append(result.Errors, fmt.Sprintf("ssh: %v", err))
+		}
+	}
+
+	if !plan.UpdateMachine.Empty() {
+		n, err := machine.Apply(plan.UpdateMachine, dryRun)
+		result.Updated += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("update machine name: %w", err))
+			result.Errors = append(result.Errors, fmt.Sprintf("machine: %v", err))
+		}
+	}
+
 	// Apply macOS preferences
 	if len(plan.UpdateMacOSPrefs) > 0 {
 		if err := applyMacOSPrefs(plan.UpdateMacOSPrefs, dryRun); err != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
 	"strings"
 )
 
@@ -28,6 +29,30 @@ func RunCommandContext(ctx context.Context, name string, args ...string) error {
 	return cmd.Run()
 }
 
+// RunCommandWithTTY runs name with args like RunCommand, but reads stdin
+// from the terminal (see OpenTTY) so sudo can prompt for a password even
+// when openboot itself was piped in through curl | bash.
+func RunCommandWithTTY(name string, args ...string) error {
+	cmd := exec.CommandContext(context.Background(), name, args...) //nolint:gosec // intentional generic runner; callers are responsible for validating name and args
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	tty, opened := OpenTTY()
+	if opened {
+		defer tty.Close() //nolint:errcheck // best-effort TTY cleanup
+	}
+	cmd.Stdin = tty
+	return cmd.Run()
+}
+
+// Username returns the login name of the current user.
+func Username() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("current user: %w", err)
+	}
+	return u.Username, nil
+}
+
 // RunCommandInDir runs name with args in the given working directory,
 // forwarding stdout and stderr to the terminal.
 func RunCommandInDir(dir string, name string, args ...string) error {
@@ -59,6 +84,16 @@ func RunCommandSilentContext(ctx context.Context, name string, args ...string) (
 	return strings.TrimSpace(string(output)), err
 }
 
+// RunCommandSilentInput is RunCommandSilent with input fed to the
+// command's stdin, for writing a file through `sudo tee` without staging it
+// on disk first.
+func RunCommandSilentInput(input string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(context.Background(), name, args...) //nolint:gosec // intentional generic runner; callers are responsible for validating name and args
+	cmd.Stdin = strings.NewReader(input)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
 // RunCommandOutput runs name with args and returns stdout only (not stderr).
 // Use when stderr output must not contaminate parsed stdout (e.g. version probes, list commands).
 func RunCommandOutput(name string, args ...string) (string, error) {
@@ -111,6 +111,9 @@ func (sp *ScanProgress) Update(step snapshot.ScanStep) {
 
 	if (step.Status == "done" || step.Status == "error") && sp.steps[step.Index].status == "scanning" {
 		sp.steps[step.Index].elapsed = time.Since(sp.stepStartTimes[step.Index])
+		if step.Elapsed > 0 {
+			sp.steps[step.Index].elapsed = step.Elapsed
+		}
 		sp.completedCount++
 	}
 
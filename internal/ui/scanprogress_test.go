@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/openbootdotdev/openboot/internal/snapshot"
 )
 
 func TestFormatStepCount(t *testing.T) {
@@ -59,3 +61,15 @@ func TestNewScanProgressInitialState(t *testing.T) {
 		assert.Equal(t, "pending", s.status)
 	}
 }
+
+func TestScanProgressUpdatePrefersReportedElapsed(t *testing.T) {
+	sp := NewScanProgress(1)
+	defer close(sp.spinnerStop)
+	sp.isTTY = false
+
+	sp.Update(snapshot.ScanStep{Name: "Fonts", Index: 0, Total: 1, Status: "scanning"})
+	sp.Update(snapshot.ScanStep{Name: "Fonts", Index: 0, Total: 1, Status: "done", Count: 3, Elapsed: 1500 * time.Millisecond})
+
+	assert.Equal(t, 1500*time.Millisecond, sp.steps[0].elapsed)
+	assert.Equal(t, 1, sp.completedCount)
+}
@@ -20,12 +20,13 @@ const (
 	editorItemNpm
 	editorItemTap
 	editorItemMacOSPref
+	editorItemKeyboard
 )
 
 type editorItem struct {
 	name        string
 	description string
-	value       string // for macOS pref items: the raw preference value
+	value       string // macOS pref items: the raw value; keyboard items: a keyboardRef
 	selected    bool
 	itemType    editorItemType
 	isAdded     bool // true = user added this, not from original snapshot
@@ -88,7 +89,7 @@ func NewSnapshotEditor(snap *snapshot.Snapshot) SnapshotEditorModel {
 		}
 	}
 
-	tabs := make([]editorTab, 5)
+	tabs := make([]editorTab, 6)
 
 	formulaeItems := make([]editorItem, len(snap.Packages.Formulae))
 	for i, pkg := range snap.Packages.Formulae {
@@ -135,6 +136,7 @@ func NewSnapshotEditor(snap *snapshot.Snapshot) SnapshotEditorModel {
 		})
 	}
 	tabs[4] = editorTab{name: "macOS Prefs", icon: "⚙️ ", items: prefItems, itemType: editorItemMacOSPref}
+	tabs[5] = editorTab{name: "Keyboard", icon: "⌨️ ", items: keyboardItems(snap.Keyboard), itemType: editorItemKeyboard}
 
 	return SnapshotEditorModel{
 		tabs:      tabs,
@@ -212,6 +214,10 @@ func (m SnapshotEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint
 			m = m.withFilteredItems()
 
 		case msg.String() == "+":
+			if m.tabs[m.activeTab].itemType == editorItemKeyboard {
+				m.toastMessage = "Keyboard settings come from capture; add new ones to the config's keyboard section"
+				return m, editorToastClearCmd()
+			}
 			m.addMode = true
 			m.addInput = ""
 
@@ -800,6 +806,9 @@ func (m SnapshotEditorModel) selectedCountsSummary() string {
 	if c := counts[editorItemMacOSPref]; c > 0 {
 		parts = append(parts, fmt.Sprintf("%d preferences", c))
 	}
+	if c := counts[editorItemKeyboard]; c > 0 {
+		parts = append(parts, fmt.Sprintf("%d keyboard settings", c))
+	}
 
 	if len(parts) == 0 {
 		return "nothing selected"
@@ -844,8 +853,16 @@ func buildEditedSnapshot(original *snapshot.Snapshot, m *SnapshotEditorModel) *s
 		Version:       original.Version,
 		CapturedAt:    original.CapturedAt,
 		Hostname:      original.Hostname,
+		Machine:       original.Machine,
+		DefaultApps:   original.DefaultApps,
+		Dock:          original.Dock,
+		LaunchAgents:  original.LaunchAgents,
+		AppSettings:   original.AppSettings,
+		Services:      original.Services,
+		Fonts:         original.Fonts,
 		Shell:         original.Shell,
 		Git:           original.Git,
+		SSH:           original.SSH,
 		Dotfiles:      original.Dotfiles,
 		DevTools:      original.DevTools,
 		MatchedPreset: original.MatchedPreset,
@@ -859,6 +876,7 @@ func buildEditedSnapshot(original *snapshot.Snapshot, m *SnapshotEditorModel) *s
 		originalPrefs[fmt.Sprintf("%s.%s", p.Domain, p.Key)] = p
 	}
 
+	var keyboardRefs []string
 	for _, tab := range m.tabs {
 		for _, item := range tab.items {
 			if !item.selected {
@@ -886,9 +904,15 @@ func buildEditedSnapshot(original *snapshot.Snapshot, m *SnapshotEditorModel) *s
 						})
 					}
 				}
+			case editorItemKeyboard:
+				keyboardRefs = append(keyboardRefs, item.value)
 			}
 		}
 	}
+	edited.Keyboard = selectedKeyboard(original.Keyboard, keyboardRefs)
+	edited.Packages.FormulaInfo = original.Packages.FormulaInfo
+	edited.Packages.CaskInfo = original.Packages.CaskInfo
+	edited.Packages.PruneInfo()
 
 	return edited
 }
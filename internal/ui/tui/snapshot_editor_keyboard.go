@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openbootdotdev/openboot/internal/config"
+)
+
+// Keyboard tab items carry a ref in editorItem.value naming the captured
+// setting they stand for, so buildEditedSnapshot can keep just the
+// selected ones. Parts are joined with keyboardRefSep, which cannot occur
+// in a bundle ID or a menu title.
+const keyboardRefSep = "\x1f"
+
+const (
+	keyboardRefShortcut = "shortcut"
+	keyboardRefHotKey   = "hotkey"
+	keyboardRefMapping  = "mapping"
+)
+
+func keyboardRef(parts ...string) string {
+	return strings.Join(parts, keyboardRefSep)
+}
+
+// keyboardItems lists k's app shortcuts, symbolic hotkeys and key mappings
+// as editor items, all selected.
+func keyboardItems(k *config.RemoteKeyboardConfig) []editorItem {
+	if k.Empty() {
+		return nil
+	}
+	var items []editorItem
+	for _, domain := range k.Domains() {
+		titles := make([]string, 0, len(k.AppShortcuts[domain]))
+		for t := range k.AppShortcuts[domain] {
+			titles = append(titles, t)
+		}
+		sort.Strings(titles)
+		for _, title := range titles {
+			items = append(items, editorItem{
+				name:        fmt.Sprintf("%s › %s", domain, title),
+				description: "= " + k.AppShortcuts[domain][title],
+				value:       keyboardRef(keyboardRefShortcut, domain, title),
+				selected:    true,
+				itemType:    editorItemKeyboard,
+			})
+		}
+	}
+	for _, h := range k.SymbolicHotKeys {
+		state := "disabled"
+		if h.Enabled {
+			state = "enabled"
+		}
+		items = append(items, editorItem{
+			name:        fmt.Sprintf("Symbolic hotkey %d", h.ID),
+			description: state,
+			value:       keyboardRef(keyboardRefHotKey, strconv.Itoa(h.ID)),
+			selected:    true,
+			itemType:    editorItemKeyboard,
+		})
+	}
+	for i, m := range k.KeyMappings {
+		items = append(items, editorItem{
+			name:        fmt.Sprintf("%s → %s", m.From, m.To),
+			description: "(key mapping)",
+			value:       keyboardRef(keyboardRefMapping, strconv.Itoa(i)),
+			selected:    true,
+			itemType:    editorItemKeyboard,
+		})
+	}
+	return items
+}
+
+// selectedKeyboard returns the part of original that refs name, or nil
+// when refs keep nothing.
+func selectedKeyboard(original *config.RemoteKeyboardConfig, refs []string) *config.RemoteKeyboardConfig {
+	if original == nil {
+		return nil
+	}
+	hotkeys := make(map[int]config.SymbolicHotKey, len(original.SymbolicHotKeys))
+	for _, h := range original.SymbolicHotKeys {
+		hotkeys[h.ID] = h
+	}
+
+	k := &config.RemoteKeyboardConfig{}
+	for _, ref := range refs {
+		parts := strings.Split(ref, keyboardRefSep)
+		switch {
+		case parts[0] == keyboardRefShortcut && len(parts) == 3:
+			equiv, ok := original.AppShortcuts[parts[1]][parts[2]]
+			if !ok {
+				continue
+			}
+			if k.AppShortcuts == nil {
+				k.AppShortcuts = make(map[string]map[string]string)
+			}
+			if k.AppShortcuts[parts[1]] == nil {
+				k.AppShortcuts[parts[1]] = make(map[string]string)
+			}
+			k.AppShortcuts[parts[1]][parts[2]] = equiv
+		case parts[0] == keyboardRefHotKey && len(parts) == 2:
+			id, err := strconv.Atoi(parts[1])
+			if h, ok := hotkeys[id]; err == nil && ok {
+				k.SymbolicHotKeys = append(k.SymbolicHotKeys, h)
+			}
+		case parts[0] == keyboardRefMapping && len(parts) == 2:
+			i, err := strconv.Atoi(parts[1])
+			if err == nil && i >= 0 && i < len(original.KeyMappings) {
+				k.KeyMappings = append(k.KeyMappings, original.KeyMappings[i])
+			}
+		}
+	}
+	if k.Empty() {
+		return nil
+	}
+	return k
+}
@@ -82,6 +82,8 @@ func tabIndexForItemType(t editorItemType) int {
 		return 3
 	case editorItemMacOSPref:
 		return 4
+	case editorItemKeyboard:
+		return 5
 	default:
 		return 0
 	}
@@ -100,6 +102,8 @@ func tabNameForItemType(t editorItemType) string {
 		return "Taps"
 	case editorItemMacOSPref:
 		return "macOS Prefs"
+	case editorItemKeyboard:
+		return "Keyboard"
 	default:
 		return "Unknown"
 	}
@@ -57,12 +57,13 @@ func TestNewSnapshotEditorTabs(t *testing.T) {
 	snap := makeTestSnapshot()
 	m := NewSnapshotEditor(snap)
 
-	assert.Equal(t, 5, len(m.tabs))
+	assert.Equal(t, 6, len(m.tabs))
 	assert.Equal(t, "Formulae", m.tabs[0].name)
 	assert.Equal(t, "Casks", m.tabs[1].name)
 	assert.Equal(t, "NPM", m.tabs[2].name)
 	assert.Equal(t, "Taps", m.tabs[3].name)
 	assert.Equal(t, "macOS Prefs", m.tabs[4].name)
+	assert.Equal(t, "Keyboard", m.tabs[5].name)
 }
 
 func TestNewSnapshotEditorItems(t *testing.T) {
@@ -259,6 +260,10 @@ func TestSnapshotEditorTabSwitch(t *testing.T) {
 	updated = result.(SnapshotEditorModel)
 	assert.Equal(t, 4, updated.activeTab)
 
+	result, _ = updated.Update(tea.KeyMsg{Type: tea.KeyTab})
+	updated = result.(SnapshotEditorModel)
+	assert.Equal(t, 5, updated.activeTab)
+
 	// Wraps back to 0
 	result, _ = updated.Update(tea.KeyMsg{Type: tea.KeyTab})
 	updated = result.(SnapshotEditorModel)
@@ -272,7 +277,7 @@ func TestSnapshotEditorRenderTabBarFitsWidth(t *testing.T) {
 
 	rendered := m.renderTabBar()
 	assert.LessOrEqual(t, lipgloss.Width(rendered), m.width)
-	assert.Contains(t, rendered, "5/5")
+	assert.Contains(t, rendered, "5/6")
 	assert.Contains(t, rendered, "macOS Prefs")
 }
 
@@ -388,6 +393,7 @@ func TestBuildEditedSnapshotFiltersDeselected(t *testing.T) {
 
 func TestBuildEditedSnapshotPreservesMetadata(t *testing.T) {
 	snap := makeTestSnapshot()
+	snap.SSH = &config.RemoteSSHConfig{Hosts: []config.SSHHost{{Host: "github.com", User: "git"}}}
 	m := NewSnapshotEditor(snap)
 
 	edited := buildEditedSnapshot(snap, &m)
@@ -396,6 +402,7 @@ func TestBuildEditedSnapshotPreservesMetadata(t *testing.T) {
 	assert.Equal(t, snap.Hostname, edited.Hostname)
 	assert.Equal(t, snap.Shell, edited.Shell)
 	assert.Equal(t, snap.Git, edited.Git)
+	assert.Equal(t, snap.SSH, edited.SSH)
 	assert.Equal(t, snap.Dotfiles, edited.Dotfiles)
 	assert.Equal(t, snap.DevTools, edited.DevTools)
 	assert.Equal(t, snap.Health, edited.Health)
@@ -871,3 +878,59 @@ func TestNewSnapshotEditorDescriptionEmptyForUnknown(t *testing.T) {
 	require.Len(t, m.tabs[0].items, 1)
 	assert.Empty(t, m.tabs[0].items[0].description)
 }
+
+func makeKeyboardSnapshot() *snapshot.Snapshot {
+	snap := makeTestSnapshot()
+	snap.Keyboard = &config.RemoteKeyboardConfig{
+		AppShortcuts: map[string]map[string]string{
+			"com.apple.Safari": {"Show All Tabs": "@~t", "Pin Tab": "@$p"},
+		},
+		SymbolicHotKeys: []config.SymbolicHotKey{{ID: 64, Enabled: false}, {ID: 65, Enabled: true, Parameters: []int{65535, 49, 1572864}}},
+		KeyMappings:     []config.KeyMapping{{From: "caps_lock", To: "escape"}},
+	}
+	return snap
+}
+
+func TestSnapshotEditorKeyboardTab(t *testing.T) {
+	m := NewSnapshotEditor(makeKeyboardSnapshot())
+	tab := m.tabs[5]
+	require.Len(t, tab.items, 5)
+	assert.Equal(t, "com.apple.Safari › Pin Tab", tab.items[0].name)
+	assert.Equal(t, "= @$p", tab.items[0].description)
+	assert.Equal(t, "Symbolic hotkey 64", tab.items[2].name)
+	assert.Equal(t, "disabled", tab.items[2].description)
+	assert.Equal(t, "caps_lock → escape", tab.items[4].name)
+	for _, item := range tab.items {
+		assert.True(t, item.selected)
+		assert.Equal(t, editorItemKeyboard, item.itemType)
+	}
+}
+
+func TestBuildEditedSnapshotKeyboardSelection(t *testing.T) {
+	snap := makeKeyboardSnapshot()
+	m := NewSnapshotEditor(snap)
+	// Drop "Pin Tab" and hotkey 65.
+	m.tabs[5].items[0].selected = false
+	m.tabs[5].items[3].selected = false
+
+	edited := buildEditedSnapshot(snap, &m)
+	require.NotNil(t, edited.Keyboard)
+	assert.Equal(t, map[string]map[string]string{"com.apple.Safari": {"Show All Tabs": "@~t"}}, edited.Keyboard.AppShortcuts)
+	assert.Equal(t, []config.SymbolicHotKey{{ID: 64, Enabled: false}}, edited.Keyboard.SymbolicHotKeys)
+	assert.Equal(t, snap.Keyboard.KeyMappings, edited.Keyboard.KeyMappings)
+
+	for i := range m.tabs[5].items {
+		m.tabs[5].items[i].selected = false
+	}
+	assert.Nil(t, buildEditedSnapshot(snap, &m).Keyboard)
+}
+
+func TestSnapshotEditorKeyboardTabRejectsManualAdd(t *testing.T) {
+	m := NewSnapshotEditor(makeKeyboardSnapshot())
+	m.activeTab = 5
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("+")})
+	updated := result.(SnapshotEditorModel)
+	assert.False(t, updated.addMode)
+	assert.Contains(t, updated.toastMessage, "keyboard section")
+}
@@ -69,6 +69,10 @@ func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.confPrefs = !m.confPrefs
 			}
 		}
+	case "g":
+		if !m.opts.PackagesOnly {
+			return m.enterGitFromReview()
+		}
 	case "q":
 		m.quit = true
 		return m, tea.Quit
@@ -120,6 +124,13 @@ func (m Model) confirmBody(_, _ int) string {
 		}
 	}
 	b = append(b, pad+"  "+fg(cDim2).Render(padTo("git", 13))+fg(cMuted).Render(gitVal))
+	if len(m.gitProfiles) > 0 {
+		var profiles []string
+		for _, p := range m.gitProfiles {
+			profiles = append(profiles, p.Name+" → "+p.Directory)
+		}
+		b = append(b, pad+"  "+fg(cDim2).Render(padTo("git profiles", 13))+fg(cMuted).Render(strings.Join(profiles, " · ")))
+	}
 	// Post-install (informational): the script can't run inside the
 	// alt-screen, so it executes after the wizard, with its own confirm.
 	if n := len(m.preview.PostInstall); n > 0 {
@@ -139,7 +150,7 @@ func (m Model) confirmBody(_, _ int) string {
 	if len(rows) > 0 {
 		b = append(b, "")
 	}
-	b = append(b, pad+fg(cDim3).Render("↑↓ move · space toggle · ↵ "+action+" · esc back"))
+	b = append(b, pad+fg(cDim3).Render(m.confirmKeys(action)))
 	return strings.Join(b, "\n")
 }
 
@@ -198,11 +209,22 @@ func (m Model) updateConfirmMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// confirmKeys is the review screen's key hint.
+func (m Model) confirmKeys(action string) string {
+	if m.opts.PackagesOnly {
+		return "↑↓ move · space toggle · ↵ " + action + " · esc back"
+	}
+	return "↑↓ move · space toggle · g git profiles · ↵ " + action + " · esc back"
+}
+
 // confirmHeaderRows is the number of body rows above the first toggleable row
 // (2 blanks + title + subtitle + blank + packages + git + blank = 8, plus one
-// for the post-install line when the plan carries a script).
+// each for the git profiles line and the post-install line when present).
 func (m Model) confirmHeaderRows() int {
 	n := 8
+	if len(m.gitProfiles) > 0 {
+		n++
+	}
 	if len(m.preview.PostInstall) > 0 {
 		n++
 	}
@@ -39,6 +39,10 @@ func key(s string) tea.KeyMsg {
 		return tea.KeyMsg{Type: tea.KeyRight}
 	case "backspace":
 		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "ctrl+d":
+		return tea.KeyMsg{Type: tea.KeyCtrlD}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
 	}
 	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
 }
@@ -88,6 +92,12 @@ func TestDumpFrames(t *testing.T) {
 	g.gitField = 1
 	t.Log("\n===== GIT (capture) =====\n" + g.View())
 
+	// Git identity profile editor.
+	g.gitProfiles = []config.GitProfile{{Name: "oss", Directory: "~/src/oss/", Email: "jane@oss.dev"}}
+	g.gitEditing, g.gitDraftField = 1, 1
+	g.gitDraft = config.GitProfile{Name: "work", Directory: "~/work"}
+	t.Log("\n===== GIT (profile editor) =====\n" + g.View())
+
 	// Confirm (review plan).
 	c := send(sel, key("2"))
 	c.gitName, c.gitEmail = "Jane Developer", "jane@ex.io"
@@ -6,12 +6,28 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/system"
 )
 
 // gitConfigLookup is a seam so tests can stub the existing-identity probe.
 var gitConfigLookup = system.GetExistingGitConfig
 
+// The git screen holds the global identity (name, email) above a list of
+// identity profiles — per-directory identities such as a work email for
+// ~/work/. Rows are indexed by gitField: 0 name, 1 email, then one row per
+// profile, then "add profile". ↵ on a profile row (or the add row) opens the
+// profile editor in place of the list.
+
+// gitProfileFields labels the editor's rows, in draftField order.
+var gitProfileFields = []struct{ label, placeholder string }{
+	{"Profile", "work"},
+	{"Directory", "~/work/"},
+	{"Name", "optional — global name when empty"},
+	{"Email", "jane@company.com"},
+	{"Signing", "optional — signing key id"},
+}
+
 // needsGitCapture reports whether the wizard should prompt for a git identity
 // before installing: only when system config is not fully set and the run
 // configures system state. It also returns any partial existing values to
@@ -33,39 +49,96 @@ func (m Model) needsGitCapture() (need bool, name, email string) {
 	return true, name, email
 }
 
+// enterGitFromReview opens the git screen from the review screen so profiles
+// can be added to a run that did not need an identity.
+func (m Model) enterGitFromReview() (tea.Model, tea.Cmd) {
+	if strings.TrimSpace(m.gitName) == "" && strings.TrimSpace(m.gitEmail) == "" {
+		_, m.gitName, m.gitEmail = m.needsGitCapture()
+	}
+	m.gitField, m.gitEditing, m.gitErr = 0, -1, ""
+	m.gitFromReview = true
+	m.screen, m.hoverRow = scrGit, -1
+	return m, nil
+}
+
+// gitRows is the number of focusable rows on the git screen's list.
+func (m Model) gitRows() int {
+	return 3 + len(m.gitProfiles)
+}
+
+// gitIdentityOK reports whether the identity fields allow leaving the
+// screen: both set, or — when an identity is already configured — both empty.
+func (m Model) gitIdentityOK() bool {
+	name, email := strings.TrimSpace(m.gitName) != "", strings.TrimSpace(m.gitEmail) != ""
+	if need, _, _ := m.needsGitCapture(); need {
+		return name && email
+	}
+	return name == email
+}
+
+// leaveGit returns to where the git screen was opened from.
+func (m Model) leaveGit(back bool) (tea.Model, tea.Cmd) {
+	from := m.gitFromReview
+	m.gitFromReview = false
+	m.hoverRow = -1
+	switch {
+	case from:
+		m.screen = scrConfirm
+	case back:
+		m.screen = scrSelect
+	default:
+		return m.enterConfirm()
+	}
+	return m, nil
+}
+
 func (m Model) updateGit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.gitEditing >= 0 {
+		return m.updateGitProfile(msg)
+	}
+	rows := m.gitRows()
 	switch msg.String() {
 	case "esc":
-		m.screen = scrSelect
-		return m, nil
-	case "tab", "down", "up", "shift+tab":
-		m.gitField = (m.gitField + 1) % 2
+		return m.leaveGit(true)
+	case "tab", "down":
+		m.gitField = (m.gitField + 1) % rows
+	case "shift+tab", "up":
+		m.gitField = (m.gitField + rows - 1) % rows
 	case "enter":
-		if m.gitField == 0 {
+		switch {
+		case m.gitField == 0:
 			m.gitField = 1
-			return m, nil
-		}
-		if strings.TrimSpace(m.gitName) != "" && strings.TrimSpace(m.gitEmail) != "" {
-			return m.enterConfirm()
+		case m.gitField == 1:
+			if m.gitIdentityOK() {
+				return m.leaveGit(false)
+			}
+			// Focus whichever field is still empty.
+			if strings.TrimSpace(m.gitName) == "" {
+				m.gitField = 0
+			}
+		default:
+			m.openGitProfile(m.gitField - 2)
 		}
-		// Focus whichever field is still empty.
-		if strings.TrimSpace(m.gitName) == "" {
-			m.gitField = 0
+	case "ctrl+d", "delete":
+		if i := m.gitField - 2; i >= 0 && i < len(m.gitProfiles) {
+			m.gitProfiles = append(m.gitProfiles[:i:i], m.gitProfiles[i+1:]...)
+			m.gitField = clamp(m.gitField, 0, m.gitRows()-1)
 		}
-		return m, nil
 	case "backspace":
-		if m.gitField == 0 {
+		switch m.gitField {
+		case 0:
 			m.gitName = trimLast(m.gitName)
-		} else {
+		case 1:
 			m.gitEmail = trimLast(m.gitEmail)
 		}
 	default:
 		// KeyRunes covers both single keystrokes and multi-rune input
 		// (bubbletea coalesces pasted/fast text into one message).
 		if s := msg.String(); msg.Type == tea.KeyRunes || s == " " {
-			if m.gitField == 0 {
+			switch m.gitField {
+			case 0:
 				m.gitName += s
-			} else {
+			case 1:
 				m.gitEmail += s
 			}
 		}
@@ -73,6 +146,89 @@ func (m Model) updateGit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openGitProfile starts editing profile i, or a new one when i is past the
+// end of the list.
+func (m *Model) openGitProfile(i int) {
+	m.gitDraft = config.GitProfile{}
+	if i < len(m.gitProfiles) {
+		m.gitDraft = m.gitProfiles[i]
+	} else {
+		i = len(m.gitProfiles)
+	}
+	m.gitEditing, m.gitDraftField, m.gitErr = i, 0, ""
+	m.hoverRow = -1
+}
+
+func (m Model) updateGitProfile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := len(gitProfileFields)
+	switch msg.String() {
+	case "esc":
+		m.gitEditing, m.gitErr = -1, ""
+	case "tab", "down":
+		m.gitDraftField = (m.gitDraftField + 1) % n
+	case "shift+tab", "up":
+		m.gitDraftField = (m.gitDraftField + n - 1) % n
+	case "enter":
+		if m.gitDraftField < n-1 {
+			m.gitDraftField++
+			return m, nil
+		}
+		m.saveGitProfile()
+	case "ctrl+s":
+		m.saveGitProfile()
+	case "backspace":
+		f := draftField(&m.gitDraft, m.gitDraftField)
+		*f = trimLast(*f)
+	default:
+		if s := msg.String(); msg.Type == tea.KeyRunes || s == " " {
+			f := draftField(&m.gitDraft, m.gitDraftField)
+			*f += s
+		}
+	}
+	return m, nil
+}
+
+// saveGitProfile validates the draft against the other profiles and, when it
+// is valid, stores it and closes the editor. Otherwise gitErr says why.
+func (m *Model) saveGitProfile() {
+	p := m.gitDraft
+	for i := range gitProfileFields {
+		f := draftField(&p, i)
+		*f = strings.TrimSpace(*f)
+	}
+	p.Directory = config.GitProfileDir(p.Directory)
+
+	profiles := append([]config.GitProfile(nil), m.gitProfiles...)
+	if m.gitEditing < len(profiles) {
+		profiles[m.gitEditing] = p
+	} else {
+		profiles = append(profiles, p)
+	}
+	if err := (&config.RemoteGitConfig{Profiles: profiles}).Validate(); err != nil {
+		m.gitErr = err.Error()
+		return
+	}
+	m.gitProfiles = profiles
+	m.gitField = 2 + m.gitEditing
+	m.gitEditing, m.gitErr = -1, ""
+}
+
+// draftField returns the profile field shown on editor row i.
+func draftField(p *config.GitProfile, i int) *string {
+	switch i {
+	case 0:
+		return &p.Name
+	case 1:
+		return &p.Directory
+	case 2:
+		return &p.UserName
+	case 3:
+		return &p.Email
+	default:
+		return &p.SigningKey
+	}
+}
+
 // trimLast removes the final rune (not byte) — a byte slice would leave
 // invalid UTF-8 behind after backspacing multi-byte input like "张" or "é".
 func trimLast(s string) string {
@@ -83,21 +239,82 @@ func trimLast(s string) string {
 	return s[:len(s)-size]
 }
 
+// gitListTop is the body row of the first profile (or editor) row:
+// 2 blank + title + subtitle + blank + name + email + blank + heading = 9.
+const gitListTop = 9
+
 func (m Model) gitBody(_, _ int) string {
 	const pad = "   "
+	subtitle := "Used to author your commits on this Mac."
+	if need, _, _ := m.needsGitCapture(); need {
+		subtitle = "No git config found — used to author your commits on this Mac."
+	}
+	editing := m.gitEditing >= 0
 	var b []string
 	b = append(b, "")
 	b = append(b, "")
 	b = append(b, pad+fg(cTextHi).Bold(true).Render("Set your git identity"))
-	b = append(b, pad+fg(cDim3).Render("No git config found — used to author your commits on this Mac."))
+	b = append(b, pad+fg(cDim3).Render(subtitle))
 	b = append(b, "")
-	b = append(b, pad+m.gitFieldRow(0, "Name", m.gitName, "Jane Developer"))
-	b = append(b, pad+m.gitFieldRow(1, "Email", m.gitEmail, "jane@example.com"))
+	b = append(b, pad+m.gitFieldRow(0, "Name", m.gitName, "Jane Developer", !editing))
+	b = append(b, pad+m.gitFieldRow(1, "Email", m.gitEmail, "jane@example.com", !editing))
+	b = append(b, "")
+
+	if editing {
+		heading := "New profile"
+		if m.gitEditing < len(m.gitProfiles) {
+			heading = "Edit profile"
+		}
+		b = append(b, pad+fg(cTextHi).Render(heading)+fg(cDim3).Render(" · used for repositories under the directory"))
+		for i, f := range gitProfileFields {
+			b = append(b, pad+fieldRow(f.label, 9, *draftField(&m.gitDraft, i), f.placeholder,
+				m.gitDraftField == i, m.hoverRow == i))
+		}
+		if m.gitErr != "" {
+			b = append(b, "", pad+fg(cWarn).Render(m.gitErr))
+		}
+		b = append(b, "")
+		b = append(b, pad+fg(cDim3).Render("↑↓/tab switch field · ↵ next, save on last · ctrl+s save · esc cancel"))
+		return strings.Join(b, "\n")
+	}
+
+	b = append(b, pad+fg(cTextHi).Render("Identity profiles")+fg(cDim3).Render(" · a different identity per directory"))
+	for i, p := range m.gitProfiles {
+		b = append(b, pad+m.gitProfileRow(2+i, p))
+	}
+	add := fg(cDim).Render("+ add profile")
+	if m.gitField == m.gitRows()-1 {
+		add = fg(cAccent).Render("› ") + fg(cWhite).Bold(true).Render("+ add profile")
+	} else {
+		add = "  " + add
+	}
+	if m.hoverRow == m.gitRows()-1 {
+		add = hoverBg(add)
+	}
+	b = append(b, pad+add)
 	b = append(b, "")
-	b = append(b, pad+fg(cDim3).Render("↑↓/tab switch field · ↵ continue · esc back"))
+	b = append(b, pad+fg(cDim3).Render("↑↓/tab switch field · ↵ continue or edit · ctrl+d delete profile · esc back"))
 	return strings.Join(b, "\n")
 }
 
+func (m Model) gitProfileRow(idx int, p config.GitProfile) string {
+	prefix := "  "
+	nameStyle := fg(cText)
+	if m.gitField == idx {
+		prefix = fg(cAccent).Render("› ")
+		nameStyle = fg(cWhite).Bold(true)
+	}
+	desc := p.Directory + " → " + p.Email
+	if p.SigningKey != "" {
+		desc += " · signed"
+	}
+	line := prefix + nameStyle.Render(padTo(p.Name, 12)) + fg(cDim).Render(desc)
+	if m.hoverRow == idx {
+		line = hoverBg(line)
+	}
+	return line
+}
+
 func (m Model) updateGitMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	if msg.Action == tea.MouseActionMotion {
 		_, idx := m.gitHitTest(msg.X, msg.Y)
@@ -108,16 +325,28 @@ func (m Model) updateGitMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	if _, idx := m.gitHitTest(msg.X, msg.Y); idx >= 0 {
-		m.gitField = idx
+		if m.gitEditing >= 0 {
+			m.gitDraftField = idx
+		} else {
+			m.gitField = idx
+		}
 	}
 	return m, nil
 }
 
+// gitHitTest maps a screen position to a row index: a gitField index on the
+// list, or an editor row while a profile is open.
 func (m Model) gitHitTest(x, y int) (string, int) {
 	if !m.inBody(y) {
 		return "", -1
 	}
 	bodyRow := y - 1
+	if m.gitEditing >= 0 {
+		if i := bodyRow - gitListTop; i >= 0 && i < len(gitProfileFields) {
+			return "profile field", i
+		}
+		return "", -1
+	}
 	// gitBody layout: 2 blank + title + subtitle + blank = 5 rows before fields
 	if bodyRow == 5 {
 		return "name", 0
@@ -125,12 +354,19 @@ func (m Model) gitHitTest(x, y int) (string, int) {
 	if bodyRow == 6 {
 		return "email", 1
 	}
+	if i := bodyRow - gitListTop; i >= 0 && i <= len(m.gitProfiles) {
+		return "profile", 2 + i
+	}
 	return "", -1
 }
 
-func (m Model) gitFieldRow(idx int, label, value, placeholder string) string {
-	focused := m.gitField == idx
-	hovered := m.hoverRow == idx
+// gitFieldRow renders an identity field. active is false while the profile
+// editor has focus, so the identity rows neither focus nor hover.
+func (m Model) gitFieldRow(idx int, label, value, placeholder string, active bool) string {
+	return fieldRow(label, 7, value, placeholder, active && m.gitField == idx, active && m.hoverRow == idx)
+}
+
+func fieldRow(label string, labelW int, value, placeholder string, focused, hovered bool) string {
 	sep := fg(cBorder).Render("┃")
 	var val string
 	switch {
@@ -142,7 +378,7 @@ func (m Model) gitFieldRow(idx int, label, value, placeholder string) string {
 	default:
 		val = fg(cTextHi).Render(value)
 	}
-	line := fg(cDim).Render(padTo(label, 7)) + " " + sep + " " + val
+	line := fg(cDim).Render(padTo(label, labelW)) + " " + sep + " " + val
 	if hovered {
 		line = hoverBg(line)
 	}
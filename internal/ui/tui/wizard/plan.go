@@ -5,6 +5,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/openbootdotdev/openboot/internal/config"
 	"github.com/openbootdotdev/openboot/internal/installer"
 )
 
@@ -41,10 +42,27 @@ func (m Model) finish() (tea.Model, tea.Cmd) {
 		plan.GitEmail = m.gitEmail
 		plan.SkipGit = false
 	}
+	// Profiles as left on the git screen: in config mode these start as the
+	// config's own, so one removed there is dropped from the plan too.
+	if !m.opts.PackagesOnly {
+		gc := config.RemoteGitConfig{}
+		if plan.GitConfig != nil {
+			gc = *plan.GitConfig
+		}
+		gc.Profiles = m.gitProfiles
+		plan.GitConfig = nil
+		if !gc.Empty() {
+			plan.GitConfig = &gc
+		}
+	}
 	if !m.confShell {
 		plan.InstallOhMyZsh = false
 		plan.ShellTheme = ""
 		plan.ShellPlugins = nil
+		plan.ShellFramework = ""
+		plan.Starship = false
+		plan.StarshipConfig = ""
+		plan.ShellSnippets = nil
 	}
 	if !m.confDotfiles {
 		plan.DotfilesURL = ""
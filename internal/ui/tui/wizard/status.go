@@ -33,14 +33,17 @@ func (m Model) statusContent() (mode string, color lipgloss.Color, keys, right s
 			fmt.Sprintf("%d pkgs · ~%d min", m.selCount(), m.estMin())
 
 	case scrGit:
-		return "GIT", cAccent, "↑↓/tab switch field · ↵ continue · esc back", "identity for your commits"
+		if m.gitEditing >= 0 {
+			return "GIT", cAccent, "↑↓/tab switch field · ctrl+s save · esc cancel", "identity profile"
+		}
+		return "GIT", cAccent, "↑↓/tab move · ↵ continue or edit · esc back", "identity for your commits"
 
 	default: // scrConfirm
 		action := "install"
 		if m.opts.DryRun {
 			action = "preview"
 		}
-		return "REVIEW", cAccent, "↑↓ move · space toggle · ↵ " + action + " · esc back",
+		return "REVIEW", cAccent, m.confirmKeys(action),
 			fmt.Sprintf("%d pkgs · ~%d min", m.selCount(), m.estMin())
 	}
 }
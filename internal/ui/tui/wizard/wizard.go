@@ -93,10 +93,16 @@ type Model struct {
 	onlineResults []config.Package // current query's online hits (deduped vs catalog)
 	onlineKnown   map[string]bool  // names sourced from openboot.dev, for the row badge
 
-	// ── git identity (captured only when none is configured) ──
-	gitName  string
-	gitEmail string
-	gitField int // 0 = name, 1 = email
+	// ── git identity (captured only when none is configured) and profiles ──
+	gitName       string
+	gitEmail      string
+	gitField      int // 0 = name, 1 = email, then profile rows, then "add"
+	gitProfiles   []config.GitProfile
+	gitEditing    int               // profile being edited (len = new), -1 when none
+	gitDraft      config.GitProfile // the profile editor's working copy
+	gitDraftField int               // focused editor row; see gitProfileFields
+	gitErr        string            // why the draft was not saved
+	gitFromReview bool              // opened with g from review; return there
 
 	// ── confirm (pre-install review) ──
 	preview      installer.InstallPlan // what this run would do, for display + toggles
@@ -124,6 +130,7 @@ func New(version string, opts *config.InstallOptions) Model {
 		hoverRow:    -1,
 		selected:    map[string]bool{},
 		onlineKnown: map[string]bool{},
+		gitEditing:  -1,
 	}
 }
 
@@ -135,6 +142,9 @@ func NewForConfig(version string, opts *config.InstallOptions, rc *config.Remote
 	m.rc = rc
 	m.srcLabel = configLabel(rc)
 	m.cats = categoriesFromConfig(rc)
+	if rc.Git != nil {
+		m.gitProfiles = append([]config.GitProfile(nil), rc.Git.Profiles...)
+	}
 	for _, c := range m.cats {
 		for _, p := range c.Packages {
 			m.selected[p.Name] = true
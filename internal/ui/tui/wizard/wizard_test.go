@@ -740,3 +740,97 @@ func TestTextRampIsTerminalRelative(t *testing.T) {
 		assert.NotContains(t, string(c), "#", "%s must use an ANSI index, not a hex guess at the background", name)
 	}
 }
+
+func typeText(m Model, s string) Model {
+	return send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+}
+
+func TestGitProfileAddFromReview(t *testing.T) {
+	defer stubGitConfig("Jane Dev", "jane@ex.io")()
+	m := finishProbes(sized(96, 30))
+	m = send(m, key("2"))
+	m.installed = map[string]bool{}
+	m = send(m, key("enter"))
+	require.Equal(t, scrConfirm, m.screen, "identity exists, so review comes first")
+
+	m = send(m, key("g"))
+	require.Equal(t, scrGit, m.screen)
+	assert.Equal(t, "Jane Dev", m.gitName, "existing identity prefilled")
+
+	// name → email → "+ add profile"
+	m = send(m, key("down"))
+	m = send(m, key("down"))
+	m = send(m, key("enter"))
+	require.Equal(t, 0, m.gitEditing, "editor opens on a new profile")
+
+	m = typeText(m, "work")
+	m = send(m, key("tab"))
+	m = typeText(m, "~/work")
+	m = send(m, key("tab"))
+	m = send(m, key("tab"))
+	m = typeText(m, "jane@acme.com")
+	m = send(m, key("tab"))
+	m = send(m, key("enter"))
+	require.Equal(t, -1, m.gitEditing, "enter on the last row saves")
+	require.Equal(t, []config.GitProfile{{Name: "work", Directory: "~/work/", Email: "jane@acme.com"}}, m.gitProfiles)
+	assert.Contains(t, m.View(), "~/work/ → jane@acme.com")
+
+	m = send(m, key("esc"))
+	require.Equal(t, scrConfirm, m.screen, "esc returns to review")
+	assert.Contains(t, m.View(), "work → ~/work/")
+
+	m = send(m, key("enter"))
+	require.True(t, m.confirmed)
+	require.NotNil(t, m.plan.GitConfig)
+	assert.Equal(t, m.gitProfiles, m.plan.GitConfig.Profiles)
+}
+
+func TestGitProfileEditorRejectsInvalid(t *testing.T) {
+	defer stubGitConfig("Jane Dev", "jane@ex.io")()
+	m := finishProbes(sized(96, 30))
+	m = send(m, key("2"))
+	m.installed = map[string]bool{}
+	m = send(m, key("enter"))
+	m = send(m, key("g"))
+	m.gitField = 2
+	m = send(m, key("enter"))
+	m = typeText(m, "work")
+	m = send(m, key("ctrl+s"))
+	assert.Equal(t, 0, m.gitEditing, "editor stays open")
+	assert.Contains(t, m.gitErr, "directory is required")
+	assert.Contains(t, m.View(), "directory is required")
+
+	m = send(m, key("esc"))
+	assert.Equal(t, -1, m.gitEditing)
+	assert.Empty(t, m.gitProfiles)
+}
+
+func TestConfigModeGitProfilesEditable(t *testing.T) {
+	defer stubGitConfig("", "")()
+	rc := testRemoteConfig()
+	rc.Git = &config.RemoteGitConfig{
+		Settings: map[string]string{"pull.rebase": "true"},
+		Profiles: []config.GitProfile{
+			{Name: "work", Directory: "~/work/", Email: "a@acme.com"},
+			{Name: "oss", Directory: "~/oss/", Email: "a@oss.dev"},
+		},
+	}
+	m := finishProbes(sizedConfig(rc))
+	m.installed = map[string]bool{}
+	m = send(m, key("enter"))
+	require.Equal(t, scrConfirm, m.screen)
+
+	m = send(m, key("g"))
+	m.gitField = 2 // the "work" profile
+	m = send(m, key("ctrl+d"))
+	require.Len(t, m.gitProfiles, 1)
+	m = send(m, key("up"))
+	m = send(m, key("enter"))
+	require.Equal(t, scrConfirm, m.screen, "empty identity is fine when the config does not ask for one")
+
+	m = send(m, key("enter"))
+	require.True(t, m.confirmed)
+	assert.Equal(t, []config.GitProfile{{Name: "oss", Directory: "~/oss/", Email: "a@oss.dev"}}, m.plan.GitConfig.Profiles)
+	assert.Equal(t, "true", m.plan.GitConfig.Settings["pull.rebase"], "other git settings kept")
+	assert.Len(t, rc.Git.Profiles, 2, "the fetched config is not mutated")
+}
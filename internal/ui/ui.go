@@ -217,6 +217,23 @@ func Input(title, placeholder string) (string, error) {
 	return InputWithDefault(title, placeholder, "")
 }
 
+// Password reads a value without echoing it.
+func Password(title string) (string, error) {
+	var value string
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(title).
+				EchoMode(huh.EchoModePassword).
+				Value(&value),
+		),
+	)
+
+	err := form.Run()
+	return value, err
+}
+
 func InputWithDefault(title, placeholder, defaultValue string) (string, error) {
 	value := defaultValue
 
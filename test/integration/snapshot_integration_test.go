@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/openbootdotdev/openboot/internal/snapshot"
+	"github.com/openbootdotdev/openboot/internal/snapshot/schema"
 )
 
 // TestIntegration_SnapshotSaveLoad tests snapshot file I/O operations.
@@ -68,7 +69,7 @@ func TestIntegration_SnapshotSaveLoad(t *testing.T) {
 	assert.NotNil(t, loaded)
 
 	// Verify loaded snapshot matches original
-	assert.Equal(t, snap.Version, loaded.Version)
+	assert.Equal(t, schema.Current, loaded.Version, "a version 1 file is migrated on load")
 	assert.Equal(t, snap.Hostname, loaded.Hostname)
 	assert.Equal(t, snap.Packages.Formulae, loaded.Packages.Formulae)
 	assert.Equal(t, snap.Packages.Casks, loaded.Packages.Casks)
@@ -596,7 +597,7 @@ func TestIntegration_SnapshotRoundTrip(t *testing.T) {
 	require.NoError(t, err)
 
 	// Deep comparison
-	assert.Equal(t, original.Version, loaded.Version)
+	assert.Equal(t, schema.Current, loaded.Version, "a version 1 file is migrated on load")
 	assert.Equal(t, original.Hostname, loaded.Hostname)
 	assert.Equal(t, original.Packages.Formulae, loaded.Packages.Formulae)
 	assert.Equal(t, original.Packages.Casks, loaded.Packages.Casks)